@@ -0,0 +1,31 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package expvar provides a bridge from the standard library expvar package
+// to OpenTelemetry metrics.  The NewGauge and NewCounter functions register
+// an asynchronous instrument that reports the current value of an
+// expvar.Var each time metrics are collected, so that existing expvar
+// variables are exported without rewriting the code that updates them.
+//
+// The value of an *expvar.Int or *expvar.Float is reported as a single
+// series.  Each entry of an *expvar.Map is reported as a separate series,
+// with the entry key as an attribute.  The value of any other expvar.Var
+// is parsed from its JSON representation, which must be a number; the
+// callback returns an error for the values that are not.
+//
+// This package is currently in a pre-GA phase. Backwards incompatible
+// changes may be introduced in subsequent minor version releases as we
+// work to track the evolving OpenTelemetry specification and user
+// feedback.
+package expvar // import "go.opentelemetry.io/otel/bridge/expvar"
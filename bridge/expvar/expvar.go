@@ -0,0 +1,169 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expvar // import "go.opentelemetry.io/otel/bridge/expvar"
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+)
+
+// DefaultAttributeKey is the attribute key of the series of an
+// *expvar.Map, unless set using WithAttributeKey.
+const DefaultAttributeKey = attribute.Key("key")
+
+// errNotNumber is returned by the callbacks when an expvar value is not a
+// number.
+var errNotNumber = fmt.Errorf("expvar value is not a number")
+
+type config struct {
+	attributeKey attribute.Key
+	instOptions  []instrument.Option
+}
+
+// Option configures the instruments created by NewGauge and NewCounter.
+type Option interface {
+	apply(config) config
+}
+
+type attributeKeyOption attribute.Key
+
+func (o attributeKeyOption) apply(cfg config) config {
+	cfg.attributeKey = attribute.Key(o)
+	return cfg
+}
+
+// WithAttributeKey sets the attribute key that holds the entry key of the
+// series of an *expvar.Map.  The default is DefaultAttributeKey.
+func WithAttributeKey(key attribute.Key) Option {
+	return attributeKeyOption(key)
+}
+
+type instrumentOptions []instrument.Option
+
+func (o instrumentOptions) apply(cfg config) config {
+	cfg.instOptions = append(cfg.instOptions, o...)
+	return cfg
+}
+
+// WithInstrumentOptions sets the options, such as the description and
+// unit, of the created instrument.
+func WithInstrumentOptions(opts ...instrument.Option) Option {
+	return instrumentOptions(opts)
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{attributeKey: DefaultAttributeKey}
+	for _, opt := range opts {
+		cfg = opt.apply(cfg)
+	}
+	return cfg
+}
+
+// NewGauge registers an asynchronous gauge named name with meter that
+// reports the current value of v.
+func NewGauge(meter metric.Meter, name string, v expvar.Var, opts ...Option) error {
+	cfg := newConfig(opts)
+	if _, ok := v.(*expvar.Int); ok {
+		inst, err := meter.AsyncInt64().Gauge(name, cfg.instOptions...)
+		if err != nil {
+			return err
+		}
+		return register(meter, name, inst, v, cfg, inst.Observe, nil)
+	}
+	inst, err := meter.AsyncFloat64().Gauge(name, cfg.instOptions...)
+	if err != nil {
+		return err
+	}
+	return register(meter, name, inst, v, cfg, nil, inst.Observe)
+}
+
+// NewCounter registers an asynchronous counter named name with meter that
+// reports the current value of v, which must be monotonically increasing.
+func NewCounter(meter metric.Meter, name string, v expvar.Var, opts ...Option) error {
+	cfg := newConfig(opts)
+	if _, ok := v.(*expvar.Int); ok {
+		inst, err := meter.AsyncInt64().Counter(name, cfg.instOptions...)
+		if err != nil {
+			return err
+		}
+		return register(meter, name, inst, v, cfg, inst.Observe, nil)
+	}
+	inst, err := meter.AsyncFloat64().Counter(name, cfg.instOptions...)
+	if err != nil {
+		return err
+	}
+	return register(meter, name, inst, v, cfg, nil, inst.Observe)
+}
+
+type (
+	observeInt64   func(context.Context, int64, ...attribute.KeyValue)
+	observeFloat64 func(context.Context, float64, ...attribute.KeyValue)
+)
+
+// register registers a callback that observes v with either observeInt,
+// for an *expvar.Int, or observeFloat.
+func register(meter metric.Meter, name string, inst instrument.Asynchronous, v expvar.Var, cfg config, observeInt observeInt64, observeFloat observeFloat64) error {
+	_, err := meter.RegisterCallback([]instrument.Asynchronous{inst}, func(ctx context.Context) error {
+		switch v := v.(type) {
+		case *expvar.Int:
+			observeInt(ctx, v.Value())
+		case *expvar.Map:
+			// The other entries are observed, and the
+			// error of the first non-numeric one is returned.
+			var err error
+			v.Do(func(kv expvar.KeyValue) {
+				value, verr := float64Value(kv.Value)
+				if verr != nil {
+					if err == nil {
+						err = fmt.Errorf("%s: %s: %w", name, kv.Key, verr)
+					}
+					return
+				}
+				observeFloat(ctx, value, cfg.attributeKey.String(kv.Key))
+			})
+			return err
+		default:
+			value, err := float64Value(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			observeFloat(ctx, value)
+		}
+		return nil
+	})
+	return err
+}
+
+// float64Value returns the numeric value of v.
+func float64Value(v expvar.Var) (float64, error) {
+	switch v := v.(type) {
+	case *expvar.Int:
+		return float64(v.Value()), nil
+	case *expvar.Float:
+		return v.Value(), nil
+	}
+	s := v.String()
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q: %w", s, errNotNumber)
+	}
+	return f, nil
+}
@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expvar_test
+
+import (
+	"context"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelexpvar "go.opentelemetry.io/otel/bridge/expvar"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/metrictest"
+)
+
+type errorHandler struct {
+	errs []error
+}
+
+func (h *errorHandler) Handle(err error) {
+	h.errs = append(h.errs, err)
+}
+
+func TestBridge(t *testing.T) {
+	ctx := context.Background()
+	mp, exp := metrictest.NewTestMeterProvider()
+	meter := mp.Meter("go.opentelemetry.io/otel/bridge/expvar_test")
+
+	requests := new(expvar.Int)
+	load := new(expvar.Float)
+	bytes := new(expvar.Map).Init()
+	version := expvar.Func(func() interface{} { return 3 })
+
+	require.NoError(t, otelexpvar.NewCounter(meter, "requests", requests,
+		otelexpvar.WithInstrumentOptions(instrument.WithDescription("Requests served"))))
+	require.NoError(t, otelexpvar.NewGauge(meter, "load", load))
+	require.NoError(t, otelexpvar.NewCounter(meter, "bytes", bytes, otelexpvar.WithAttributeKey("direction")))
+	require.NoError(t, otelexpvar.NewGauge(meter, "version", version))
+
+	requests.Add(7)
+	load.Set(0.5)
+	bytes.Add("in", 10)
+	bytes.AddFloat("out", 2.5)
+
+	require.NoError(t, exp.Collect(ctx))
+
+	rec, err := exp.GetByName("requests")
+	require.NoError(t, err)
+	assert.Equal(t, aggregation.SumKind, rec.AggregationKind)
+	assert.Equal(t, int64(7), rec.Sum.AsInt64())
+
+	rec, err = exp.GetByName("load")
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, rec.LastValue.AsFloat64())
+
+	rec, err = exp.GetByNameAndAttributes("bytes", []attribute.KeyValue{attribute.String("direction", "in")})
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, rec.Sum.AsFloat64())
+	rec, err = exp.GetByNameAndAttributes("bytes", []attribute.KeyValue{attribute.String("direction", "out")})
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, rec.Sum.AsFloat64())
+
+	rec, err = exp.GetByName("version")
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, rec.LastValue.AsFloat64())
+
+	// The current value is read at each collection.
+	requests.Add(1)
+	require.NoError(t, exp.Collect(ctx))
+	rec, err = exp.GetByName("requests")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), rec.Sum.AsInt64())
+}
+
+func TestBridgeNotNumber(t *testing.T) {
+	orig := otel.GetErrorHandler()
+	handler := &errorHandler{}
+	otel.SetErrorHandler(handler)
+	t.Cleanup(func() { otel.SetErrorHandler(orig) })
+
+	mp, exp := metrictest.NewTestMeterProvider()
+	meter := mp.Meter("go.opentelemetry.io/otel/bridge/expvar_test")
+
+	s := new(expvar.String)
+	s.Set("not a number")
+	require.NoError(t, otelexpvar.NewGauge(meter, "string", s))
+	m := new(expvar.Map).Init()
+	m.Set("invalid", s)
+	require.NoError(t, otelexpvar.NewGauge(meter, "map", m))
+
+	// The callbacks return their errors instead of handling them.
+	err := exp.Collect(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "string: ")
+	assert.Contains(t, err.Error(), "map: invalid: ")
+	assert.Contains(t, err.Error(), "expvar value is not a number")
+	assert.Empty(t, handler.errs)
+}
@@ -83,12 +83,13 @@ func main() {
 	if err != nil {
 		log.Panicf("failed to initialize instrument: %v", err)
 	}
-	_ = meter.RegisterCallback([]instrument.Asynchronous{gaugeObserver}, func(ctx context.Context) {
+	_, _ = meter.RegisterCallback([]instrument.Asynchronous{gaugeObserver}, func(ctx context.Context) error {
 		(*observerLock).RLock()
 		value := *observerValueToReport
 		attrs := *observerAttrsToReport
 		(*observerLock).RUnlock()
 		gaugeObserver.Observe(ctx, value, attrs...)
+		return nil
 	})
 
 	hist, err := meter.SyncFloat64().Histogram("ex.com.two")
@@ -0,0 +1,222 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrictransform
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/exponential"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/sum"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/metrictest"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+	"go.opentelemetry.io/otel/trace"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+var (
+	goldenStart = time.Unix(1000, 0)
+	goldenEnd   = time.Unix(2000, 0)
+)
+
+// fixedLastValue is a LastValue aggregation with a fixed time, unlike
+// lastvalue.Aggregator.
+type fixedLastValue struct {
+	value number.Number
+	time  time.Time
+}
+
+func (fixedLastValue) Kind() aggregation.Kind {
+	return aggregation.LastValueKind
+}
+
+func (v fixedLastValue) LastValue() (number.Number, time.Time, error) {
+	return v.value, v.time, nil
+}
+
+// fixedExemplars adds fixed exemplars to a Sum aggregation.
+type fixedExemplars struct {
+	sum       aggregation.Sum
+	exemplars []aggregation.Exemplar
+}
+
+func (e fixedExemplars) Kind() aggregation.Kind {
+	return e.sum.Kind()
+}
+
+func (e fixedExemplars) Sum() (number.Number, error) {
+	return e.sum.Sum()
+}
+
+func (e fixedExemplars) Exemplars() ([]aggregation.Exemplar, error) {
+	return e.exemplars, nil
+}
+
+// checkpoint updates a new aggregator of desc with values and returns
+// its checkpoint.
+func checkpoint(t *testing.T, desc *sdkapi.Descriptor, aggs []aggregator.Aggregator, values ...number.Number) aggregation.Aggregation {
+	agg, ckpt := aggs[0], aggs[1]
+	for _, v := range values {
+		require.NoError(t, agg.Update(context.Background(), v, desc))
+	}
+	require.NoError(t, agg.SynchronizedMove(ckpt, desc))
+	return ckpt.Aggregation()
+}
+
+func sums(cnt int) []aggregator.Aggregator {
+	aggs := sum.New(cnt)
+	out := make([]aggregator.Aggregator, cnt)
+	for i := range aggs {
+		out[i] = &aggs[i]
+	}
+	return out
+}
+
+func TestGoldenRecords(t *testing.T) {
+	emptyAttrs := attribute.NewSet()
+	attrs := attribute.NewSet(attribute.String("host", "a"), attribute.Int("port", 80))
+
+	counter := metrictest.NewDescriptor("requests", sdkapi.CounterInstrumentKind, number.Int64Kind)
+	upDownCounter := metrictest.NewDescriptor("queue.delta", sdkapi.UpDownCounterInstrumentKind, number.Float64Kind)
+	gauge := metrictest.NewDescriptor("temperature", sdkapi.GaugeObserverInstrumentKind, number.Float64Kind)
+	hist := metrictest.NewDescriptor("latency", sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	intHist := metrictest.NewDescriptor("size", sdkapi.HistogramInstrumentKind, number.Int64Kind)
+
+	histAggs := histogram.New(2, &hist, histogram.WithExplicitBoundaries([]float64{0, 10}))
+	expAggs := exponential.New(2, &intHist, exponential.WithMaxSize(4))
+
+	sampled := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10},
+		SpanID:     trace.SpanID{0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	for _, tc := range []struct {
+		name        string
+		desc        *sdkapi.Descriptor
+		attrs       *attribute.Set
+		agg         aggregation.Aggregation
+		temporality aggregation.Temporality
+	}{
+		{
+			name:        "sum_empty_attributes",
+			desc:        &counter,
+			attrs:       &emptyAttrs,
+			agg:         checkpoint(t, &counter, sums(2), number.NewInt64Number(3), number.NewInt64Number(4)),
+			temporality: aggregation.CumulativeTemporality,
+		},
+		{
+			name:        "sum_negative_updowncounter",
+			desc:        &upDownCounter,
+			attrs:       &attrs,
+			agg:         checkpoint(t, &upDownCounter, sums(2), number.NewFloat64Number(1.5), number.NewFloat64Number(-4)),
+			temporality: aggregation.DeltaTemporality,
+		},
+		{
+			name:  "gauge",
+			desc:  &gauge,
+			attrs: &attrs,
+			agg:   fixedLastValue{value: number.NewFloat64Number(-12.5), time: goldenEnd},
+		},
+		{
+			name:  "histogram",
+			desc:  &hist,
+			attrs: &attrs,
+			agg: checkpoint(t, &hist, []aggregator.Aggregator{&histAggs[0], &histAggs[1]},
+				number.NewFloat64Number(-1),
+				number.NewFloat64Number(0),
+				number.NewFloat64Number(5),
+				number.NewFloat64Number(10),
+				number.NewFloat64Number(20),
+			),
+			temporality: aggregation.CumulativeTemporality,
+		},
+		{
+			name:  "exponential_histogram",
+			desc:  &intHist,
+			attrs: &emptyAttrs,
+			agg: checkpoint(t, &intHist, []aggregator.Aggregator{&expAggs[0], &expAggs[1]},
+				number.NewInt64Number(0),
+				number.NewInt64Number(1),
+				number.NewInt64Number(2),
+				number.NewInt64Number(16),
+				number.NewInt64Number(-4),
+			),
+			temporality: aggregation.DeltaTemporality,
+		},
+		{
+			name:  "sum_exemplars",
+			desc:  &counter,
+			attrs: &attrs,
+			agg: fixedExemplars{
+				sum: checkpoint(t, &counter, sums(2), number.NewInt64Number(7)).(aggregation.Sum),
+				exemplars: []aggregation.Exemplar{{
+					Value:       number.NewInt64Number(7),
+					Time:        goldenStart.Add(time.Second),
+					SpanContext: sampled,
+					FilteredAttributes: []attribute.KeyValue{
+						attribute.String("user", "u-42"),
+					},
+				}},
+			},
+			temporality: aggregation.CumulativeTemporality,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			record := export.NewRecord(tc.desc, tc.attrs, tc.agg, goldenStart, goldenEnd)
+			if tc.temporality != 0 {
+				record = record.WithTemporality(tc.temporality)
+			}
+			got, err := Record(aggregation.CumulativeTemporalitySelector(), record)
+			require.NoError(t, err)
+
+			path := filepath.Join("testdata", tc.name+".json")
+			if *update {
+				data, err := protojson.MarshalOptions{Multiline: true}.Marshal(got)
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(path, append(data, '\n'), 0o644))
+			}
+
+			// Round-trip through the wire format.
+			wire, err := proto.Marshal(got)
+			require.NoError(t, err)
+			decoded := &metricpb.Metric{}
+			require.NoError(t, proto.Unmarshal(wire, decoded))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			golden := &metricpb.Metric{}
+			require.NoError(t, protojson.Unmarshal(data, golden))
+
+			require.True(t, proto.Equal(golden, decoded), "got:\n%s\nwant:\n%s", protojson.Format(decoded), protojson.Format(golden))
+		})
+	}
+}
@@ -206,6 +206,8 @@ func sink(ctx context.Context, in <-chan result) ([]*metricpb.Metric, error) {
 			m.GetSum().DataPoints = append(m.GetSum().DataPoints, res.Metric.GetSum().DataPoints...)
 		case *metricpb.Metric_Histogram:
 			m.GetHistogram().DataPoints = append(m.GetHistogram().DataPoints, res.Metric.GetHistogram().DataPoints...)
+		case *metricpb.Metric_ExponentialHistogram:
+			m.GetExponentialHistogram().DataPoints = append(m.GetExponentialHistogram().DataPoints, res.Metric.GetExponentialHistogram().DataPoints...)
 		case *metricpb.Metric_Summary:
 			m.GetSummary().DataPoints = append(m.GetSummary().DataPoints, res.Metric.GetSummary().DataPoints...)
 		default:
@@ -230,6 +232,15 @@ func sink(ctx context.Context, in <-chan result) ([]*metricpb.Metric, error) {
 	return ms, nil
 }
 
+// temporality returns the temporality of r, as reported by the Reader or,
+// when the Reader does not report it, as chosen by temporalitySelector.
+func temporality(temporalitySelector aggregation.TemporalitySelector, r export.Record, kind aggregation.Kind) aggregation.Temporality {
+	if t := r.Temporality(); t != 0 {
+		return t
+	}
+	return temporalitySelector.TemporalityFor(r.Descriptor(), kind)
+}
+
 // Record transforms a Record into an OTLP Metric. An ErrIncompatibleAgg
 // error is returned if the Record Aggregator is not supported.
 func Record(temporalitySelector aggregation.TemporalitySelector, r export.Record) (*metricpb.Metric, error) {
@@ -240,7 +251,21 @@ func Record(temporalitySelector aggregation.TemporalitySelector, r export.Record
 		if !ok {
 			return nil, fmt.Errorf("%w: %T", ErrIncompatibleAgg, agg)
 		}
-		return histogramPoint(r, temporalitySelector.TemporalityFor(r.Descriptor(), aggregation.HistogramKind), h)
+		return histogramPoint(r, temporality(temporalitySelector, r, aggregation.HistogramKind), h)
+
+	case aggregation.ExponentialHistogramKind:
+		h, ok := agg.(aggregation.ExponentialHistogram)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", ErrIncompatibleAgg, agg)
+		}
+		return exponentialHistogramPoint(r, temporality(temporalitySelector, r, aggregation.ExponentialHistogramKind), h)
+
+	case aggregation.MinMaxSumCountKind:
+		mmsc, ok := agg.(aggregation.MinMaxSumCount)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", ErrIncompatibleAgg, agg)
+		}
+		return minMaxSumCountPoint(r, mmsc)
 
 	case aggregation.SumKind:
 		s, ok := agg.(aggregation.Sum)
@@ -251,7 +276,7 @@ func Record(temporalitySelector aggregation.TemporalitySelector, r export.Record
 		if err != nil {
 			return nil, err
 		}
-		return sumPoint(r, sum, r.StartTime(), r.EndTime(), temporalitySelector.TemporalityFor(r.Descriptor(), aggregation.SumKind), r.Descriptor().InstrumentKind().Monotonic())
+		return sumPoint(r, sum, r.StartTime(), r.EndTime(), temporality(temporalitySelector, r, aggregation.SumKind), r.Descriptor().InstrumentKind().Monotonic())
 
 	case aggregation.LastValueKind:
 		lv, ok := agg.(aggregation.LastValue)
@@ -337,6 +362,11 @@ func sumPoint(record export.Record, num number.Number, start, end time.Time, tem
 		Unit:        string(desc.Unit()),
 	}
 
+	exemplars, err := recordExemplars(record)
+	if err != nil {
+		return nil, err
+	}
+
 	switch n := desc.NumberKind(); n {
 	case number.Int64Kind:
 		m.Data = &metricpb.Metric_Sum{
@@ -351,6 +381,7 @@ func sumPoint(record export.Record, num number.Number, start, end time.Time, tem
 						Attributes:        Iterator(attrs.Iter()),
 						StartTimeUnixNano: toNanos(start),
 						TimeUnixNano:      toNanos(end),
+						Exemplars:         exemplars,
 					},
 				},
 			},
@@ -368,6 +399,7 @@ func sumPoint(record export.Record, num number.Number, start, end time.Time, tem
 						Attributes:        Iterator(attrs.Iter()),
 						StartTimeUnixNano: toNanos(start),
 						TimeUnixNano:      toNanos(end),
+						Exemplars:         exemplars,
 					},
 				},
 			},
@@ -411,6 +443,11 @@ func histogramPoint(record export.Record, temporality aggregation.Temporality, a
 		return nil, err
 	}
 
+	exemplars, err := recordExemplars(record)
+	if err != nil {
+		return nil, err
+	}
+
 	sumFloat64 := sum.CoerceToFloat64(desc.NumberKind())
 	m := &metricpb.Metric{
 		Name:        desc.Name(),
@@ -428,6 +465,55 @@ func histogramPoint(record export.Record, temporality aggregation.Temporality, a
 						Count:             uint64(count),
 						BucketCounts:      counts,
 						ExplicitBounds:    boundaries,
+						Exemplars:         exemplars,
+					},
+				},
+			},
+		},
+	}
+	return m, nil
+}
+
+// minMaxSumCountPoint transforms a MinMaxSumCount Aggregator into an OTLP
+// Summary, with the minimum and maximum as its 0.0 and 1.0 quantiles.
+func minMaxSumCountPoint(record export.Record, a aggregation.MinMaxSumCount) (*metricpb.Metric, error) {
+	desc := record.Descriptor()
+	attrs := record.Attributes()
+
+	count, err := a.Count()
+	if err != nil {
+		return nil, err
+	}
+	sum, err := a.Sum()
+	if err != nil {
+		return nil, err
+	}
+	min, err := a.Min()
+	if err != nil {
+		return nil, err
+	}
+	max, err := a.Max()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &metricpb.Metric{
+		Name:        desc.Name(),
+		Description: desc.Description(),
+		Unit:        string(desc.Unit()),
+		Data: &metricpb.Metric_Summary{
+			Summary: &metricpb.Summary{
+				DataPoints: []*metricpb.SummaryDataPoint{
+					{
+						Attributes:        Iterator(attrs.Iter()),
+						StartTimeUnixNano: toNanos(record.StartTime()),
+						TimeUnixNano:      toNanos(record.EndTime()),
+						Count:             count,
+						Sum:               sum.CoerceToFloat64(desc.NumberKind()),
+						QuantileValues: []*metricpb.SummaryDataPoint_ValueAtQuantile{
+							{Quantile: 0, Value: min.CoerceToFloat64(desc.NumberKind())},
+							{Quantile: 1, Value: max.CoerceToFloat64(desc.NumberKind())},
+						},
 					},
 				},
 			},
@@ -435,3 +521,103 @@ func histogramPoint(record export.Record, temporality aggregation.Temporality, a
 	}
 	return m, nil
 }
+
+// exponentialHistogramPoint transforms an ExponentialHistogram Aggregator
+// into an OTLP Metric.
+func exponentialHistogramPoint(record export.Record, temporality aggregation.Temporality, a aggregation.ExponentialHistogram) (*metricpb.Metric, error) {
+	desc := record.Descriptor()
+	attrs := record.Attributes()
+	buckets, err := a.ExponentialHistogram()
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := a.Count()
+	if err != nil {
+		return nil, err
+	}
+
+	sum, err := a.Sum()
+	if err != nil {
+		return nil, err
+	}
+
+	exemplars, err := recordExemplars(record)
+	if err != nil {
+		return nil, err
+	}
+
+	sumFloat64 := sum.CoerceToFloat64(desc.NumberKind())
+	m := &metricpb.Metric{
+		Name:        desc.Name(),
+		Description: desc.Description(),
+		Unit:        string(desc.Unit()),
+		Data: &metricpb.Metric_ExponentialHistogram{
+			ExponentialHistogram: &metricpb.ExponentialHistogram{
+				AggregationTemporality: sdkTemporalityToTemporality(temporality),
+				DataPoints: []*metricpb.ExponentialHistogramDataPoint{
+					{
+						Sum:               &sumFloat64,
+						Attributes:        Iterator(attrs.Iter()),
+						StartTimeUnixNano: toNanos(record.StartTime()),
+						TimeUnixNano:      toNanos(record.EndTime()),
+						Count:             count,
+						Scale:             buckets.Scale,
+						ZeroCount:         buckets.ZeroCount,
+						Positive:          exponentialBuckets(buckets.Positive),
+						Negative:          exponentialBuckets(buckets.Negative),
+						Exemplars:         exemplars,
+					},
+				},
+			},
+		},
+	}
+	return m, nil
+}
+
+func exponentialBuckets(b aggregation.ExponentialBucketCounts) *metricpb.ExponentialHistogramDataPoint_Buckets {
+	return &metricpb.ExponentialHistogramDataPoint_Buckets{
+		Offset:       b.Offset,
+		BucketCounts: b.Counts,
+	}
+}
+
+// recordExemplars transforms the exemplars of the aggregation of record, if it
+// has any, into OTLP Exemplars.
+func recordExemplars(record export.Record) ([]*metricpb.Exemplar, error) {
+	a, ok := record.Aggregation().(aggregation.Exemplars)
+	if !ok {
+		return nil, nil
+	}
+	exemplars, err := a.Exemplars()
+	if err != nil || len(exemplars) == 0 {
+		return nil, err
+	}
+
+	kind := record.Descriptor().NumberKind()
+	out := make([]*metricpb.Exemplar, 0, len(exemplars))
+	for _, e := range exemplars {
+		pb := &metricpb.Exemplar{
+			TimeUnixNano:       toNanos(e.Time),
+			FilteredAttributes: KeyValues(e.FilteredAttributes),
+		}
+		// Exemplars kept by exemplar.ContextWithExemplarDecision may
+		// have no span.
+		if e.SpanContext.IsValid() {
+			traceID := e.SpanContext.TraceID()
+			spanID := e.SpanContext.SpanID()
+			pb.TraceId = traceID[:]
+			pb.SpanId = spanID[:]
+		}
+		switch kind {
+		case number.Int64Kind:
+			pb.Value = &metricpb.Exemplar_AsInt{AsInt: e.Value.AsInt64()}
+		case number.Float64Kind:
+			pb.Value = &metricpb.Exemplar_AsDouble{AsDouble: e.Value.AsFloat64()}
+		default:
+			return nil, fmt.Errorf("%w: %v", ErrUnknownValueType, kind)
+		}
+		out = append(out, pb)
+	}
+	return out, nil
+}
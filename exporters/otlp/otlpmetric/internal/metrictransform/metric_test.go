@@ -27,6 +27,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/lastvalue"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/minmaxsumcount"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/sum"
 	"go.opentelemetry.io/otel/sdk/metric/export"
 	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
@@ -267,6 +268,41 @@ var _ aggregation.Aggregation = &testAgg{}
 var _ aggregation.Sum = &testErrSum{}
 var _ aggregation.LastValue = &testErrLastValue{}
 
+func TestMinMaxSumCountDataPoints(t *testing.T) {
+	desc := metrictest.NewDescriptor("", sdkapi.HistogramInstrumentKind, number.Int64Kind)
+	attrs := attribute.NewSet(attribute.String("one", "1"))
+	mmscs := minmaxsumcount.New(2, &desc)
+	mmsc, ckpt := &mmscs[0], &mmscs[1]
+
+	for _, v := range []int64{-3, 7, 1} {
+		assert.NoError(t, mmsc.Update(context.Background(), number.NewInt64Number(v), &desc))
+	}
+	require.NoError(t, mmsc.SynchronizedMove(ckpt, &desc))
+	record := export.NewRecord(&desc, &attrs, ckpt.Aggregation(), intervalStart, intervalEnd)
+
+	m, err := Record(aggregation.CumulativeTemporalitySelector(), record)
+	require.NoError(t, err)
+	assert.Nil(t, m.GetGauge())
+	assert.Nil(t, m.GetSum())
+	assert.Nil(t, m.GetHistogram())
+	assert.Equal(t, []*metricpb.SummaryDataPoint{{
+		StartTimeUnixNano: uint64(intervalStart.UnixNano()),
+		TimeUnixNano:      uint64(intervalEnd.UnixNano()),
+		Attributes: []*commonpb.KeyValue{
+			{
+				Key:   "one",
+				Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "1"}},
+			},
+		},
+		Count: 3,
+		Sum:   5,
+		QuantileValues: []*metricpb.SummaryDataPoint_ValueAtQuantile{
+			{Quantile: 0, Value: -3},
+			{Quantile: 1, Value: 7},
+		},
+	}}, m.GetSummary().DataPoints)
+}
+
 func TestRecordAggregatorIncompatibleErrors(t *testing.T) {
 	makeMpb := func(kind aggregation.Kind, agg aggregation.Aggregation) (*metricpb.Metric, error) {
 		desc := metrictest.NewDescriptor("things", sdkapi.CounterInstrumentKind, number.Int64Kind)
@@ -312,3 +348,24 @@ func TestRecordAggregatorUnexpectedErrors(t *testing.T) {
 	require.Nil(t, mpb)
 	require.True(t, errors.Is(err, errEx))
 }
+
+func TestRecordTemporality(t *testing.T) {
+	desc := metrictest.NewDescriptor("", sdkapi.CounterInstrumentKind, number.Int64Kind)
+	attrs := attribute.NewSet()
+	sums := sum.New(2)
+	s, ckpt := &sums[0], &sums[1]
+
+	require.NoError(t, s.Update(context.Background(), number.Number(1), &desc))
+	require.NoError(t, s.SynchronizedMove(ckpt, &desc))
+	record := export.NewRecord(&desc, &attrs, ckpt.Aggregation(), intervalStart, intervalEnd)
+
+	// Without a reported temporality, the selector decides.
+	m, err := Record(aggregation.CumulativeTemporalitySelector(), record)
+	require.NoError(t, err)
+	assert.Equal(t, otelCumulative, m.GetSum().AggregationTemporality)
+
+	// The temporality reported by the Reader takes precedence.
+	m, err = Record(aggregation.CumulativeTemporalitySelector(), record.WithTemporality(aggregation.DeltaTemporality))
+	require.NoError(t, err)
+	assert.Equal(t, otelDelta, m.GetSum().AggregationTemporality)
+}
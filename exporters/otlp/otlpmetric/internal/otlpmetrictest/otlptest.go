@@ -88,13 +88,15 @@ func RunEndToEndTest(ctx context.Context, t *testing.T, exp *otlpmetric.Exporter
 			switch data.nKind {
 			case number.Int64Kind:
 				g, _ := meter.AsyncInt64().Gauge(name)
-				_ = meter.RegisterCallback([]instrument.Asynchronous{g}, func(ctx context.Context) {
+				_, _ = meter.RegisterCallback([]instrument.Asynchronous{g}, func(ctx context.Context) error {
 					g.Observe(ctx, data.val, attrs...)
+					return nil
 				})
 			case number.Float64Kind:
 				g, _ := meter.AsyncFloat64().Gauge(name)
-				_ = meter.RegisterCallback([]instrument.Asynchronous{g}, func(ctx context.Context) {
+				_, _ = meter.RegisterCallback([]instrument.Asynchronous{g}, func(ctx context.Context) error {
 					g.Observe(ctx, float64(data.val), attrs...)
+					return nil
 				})
 			default:
 				assert.Failf(t, "unsupported number testing kind", data.nKind.String())
@@ -0,0 +1,154 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus // import "go.opentelemetry.io/otel/exporters/prometheus"
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+// counterSuffix is appended to the names of counters, see the
+// Prometheus naming conventions.
+const counterSuffix = "total"
+
+// unitSuffixes maps the UCUM units of instruments to the base unit names
+// appended to the Prometheus metric names.  Other units are not
+// represented in the names.
+var unitSuffixes = map[unit.Unit]string{
+	// Time
+	"d":   "days",
+	"h":   "hours",
+	"min": "minutes",
+	"s":   "seconds",
+	"ms":  "milliseconds",
+	"us":  "microseconds",
+	"ns":  "nanoseconds",
+
+	// Bytes
+	"By":   "bytes",
+	"KiBy": "kibibytes",
+	"MiBy": "mebibytes",
+	"GiBy": "gibibytes",
+	"TiBy": "tibibytes",
+	"KBy":  "kilobytes",
+	"MBy":  "megabytes",
+	"GBy":  "gigabytes",
+	"TBy":  "terabytes",
+
+	// SI
+	"m":   "meters",
+	"V":   "volts",
+	"A":   "amperes",
+	"J":   "joules",
+	"W":   "watts",
+	"g":   "grams",
+	"Cel": "celsius",
+	"Hz":  "hertz",
+	"%":   "percent",
+}
+
+// toSeconds maps the units of time to the functions converting them to
+// seconds, see Config.DurationsInSeconds.
+var toSeconds = map[unit.Unit]func(float64) float64{
+	"d":   func(v float64) float64 { return v * 86400 },
+	"h":   func(v float64) float64 { return v * 3600 },
+	"min": func(v float64) float64 { return v * 60 },
+	"ms":  func(v float64) float64 { return v / 1e3 },
+	"us":  func(v float64) float64 { return v / 1e6 },
+	"ns":  func(v float64) float64 { return v / 1e9 },
+}
+
+// converter converts the numbers of a record to the exported values.
+type converter struct {
+	kind number.Kind
+	unit unit.Unit
+
+	// convert, if not nil, converts the values from the unit of
+	// the instrument to unit.
+	convert func(float64) float64
+}
+
+// converter returns the converter of the records of desc.
+func (e *Exporter) converter(desc *sdkapi.Descriptor) converter {
+	c := converter{kind: desc.NumberKind(), unit: desc.Unit()}
+	if convert, ok := toSeconds[desc.Unit()]; ok && e.durationsInSeconds {
+		c.unit = unit.Seconds
+		c.convert = convert
+	}
+	return c
+}
+
+// float64 returns the exported value of n.
+func (c converter) float64(n number.Number) float64 {
+	return c.value(n.CoerceToFloat64(c.kind))
+}
+
+// value returns the exported value of v, e.g., of a histogram boundary.
+func (c converter) value(v float64) float64 {
+	if c.convert == nil {
+		return v
+	}
+	return c.convert(v)
+}
+
+// metricName returns the Prometheus name of the metric of record: its
+// sanitized instrument name followed by its unit and, for counters, by
+// the counter suffix, unless the name already ends with them.
+func (e *Exporter) metricName(record export.Record) string {
+	desc := record.Descriptor()
+	name := sanitize(desc.Name())
+	counter := isCounter(record)
+
+	if !e.withoutUnits {
+		u := e.converter(desc).unit
+		suffix, ok := unitSuffixes[u]
+		if !ok && u == unit.Dimensionless && !counter {
+			// Dimensionless gauges are ratios.
+			suffix, ok = "ratio", true
+		}
+		if ok {
+			name = appendSuffix(name, suffix)
+		}
+	}
+	if counter && !e.withoutCounterSuffixes {
+		name = appendSuffix(name, counterSuffix)
+	}
+	return name
+}
+
+// appendSuffix appends suffix to name, separated by an underscore,
+// unless name already ends with it.
+func appendSuffix(name, suffix string) string {
+	if strings.HasSuffix(name, "_"+suffix) {
+		return name
+	}
+	return name + "_" + suffix
+}
+
+// isCounter returns whether record is exported as a Prometheus counter.
+func isCounter(record export.Record) bool {
+	switch record.Aggregation().(type) {
+	case aggregation.Histogram:
+		return false
+	case aggregation.Sum:
+		return record.Descriptor().InstrumentKind().Monotonic()
+	}
+	return false
+}
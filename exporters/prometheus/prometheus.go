@@ -33,7 +33,6 @@ import (
 	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
 	"go.opentelemetry.io/otel/sdk/metric/export"
 	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
-	"go.opentelemetry.io/otel/sdk/metric/number"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
 	"go.opentelemetry.io/otel/sdk/resource"
 )
@@ -53,6 +52,10 @@ type Exporter struct {
 	// controllers (e.g., with different resources).
 	lock       sync.RWMutex
 	controller *controller.Controller
+
+	withoutUnits           bool
+	withoutCounterSuffixes bool
+	durationsInSeconds     bool
 }
 
 // ErrUnsupportedAggregator is returned for unrepresentable aggregator
@@ -84,6 +87,22 @@ type Config struct {
 	// DefaultHistogramBoundaries defines the default histogram bucket
 	// boundaries.
 	DefaultHistogramBoundaries []float64
+
+	// WithoutUnits disables the unit suffixes of metric names, e.g.,
+	// "_seconds" for instruments in "s".  By default, the names
+	// of instruments with a known unit end with the unit, as
+	// recommended by the Prometheus naming conventions.
+	WithoutUnits bool
+
+	// WithoutCounterSuffixes disables the "_total" suffix of the
+	// names of counters.
+	WithoutCounterSuffixes bool
+
+	// DurationsInSeconds converts the values of the instruments
+	// measuring time, e.g., the int64 instruments recording
+	// durations in "ns", to seconds, the base unit of Prometheus.
+	// Their names end with "_seconds" rather than with their unit.
+	DurationsInSeconds bool
 }
 
 // New returns a new Prometheus exporter using the configured metric
@@ -106,6 +125,10 @@ func New(config Config, ctrl *controller.Controller) (*Exporter, error) {
 		registerer: config.Registerer,
 		gatherer:   config.Gatherer,
 		controller: ctrl,
+
+		withoutUnits:           config.WithoutUnits,
+		withoutCounterSuffixes: config.WithoutCounterSuffixes,
+		durationsInSeconds:     config.DurationsInSeconds,
 	}
 
 	c := &collector{
@@ -177,7 +200,7 @@ func (c *collector) Collect(ch chan<- prometheus.Metric) {
 	err := ctrl.ForEach(func(_ instrumentation.Library, reader export.Reader) error {
 		return reader.ForEach(c.exp, func(record export.Record) error {
 			agg := record.Aggregation()
-			numberKind := record.Descriptor().NumberKind()
+			conv := c.exp.converter(record.Descriptor())
 			instrumentKind := record.Descriptor().InstrumentKind()
 
 			var attrKeys, attrs []string
@@ -187,21 +210,21 @@ func (c *collector) Collect(ch chan<- prometheus.Metric) {
 
 			switch v := agg.(type) {
 			case aggregation.Histogram:
-				if err := c.exportHistogram(ch, v, numberKind, desc, attrs); err != nil {
+				if err := c.exportHistogram(ch, v, conv, desc, attrs); err != nil {
 					return fmt.Errorf("exporting histogram: %w", err)
 				}
 			case aggregation.Sum:
 				if instrumentKind.Monotonic() {
-					if err := c.exportMonotonicCounter(ch, v, numberKind, desc, attrs); err != nil {
+					if err := c.exportMonotonicCounter(ch, v, conv, desc, attrs); err != nil {
 						return fmt.Errorf("exporting monotonic counter: %w", err)
 					}
 				} else {
-					if err := c.exportNonMonotonicCounter(ch, v, numberKind, desc, attrs); err != nil {
+					if err := c.exportNonMonotonicCounter(ch, v, conv, desc, attrs); err != nil {
 						return fmt.Errorf("exporting non monotonic counter: %w", err)
 					}
 				}
 			case aggregation.LastValue:
-				if err := c.exportLastValue(ch, v, numberKind, desc, attrs); err != nil {
+				if err := c.exportLastValue(ch, v, conv, desc, attrs); err != nil {
 					return fmt.Errorf("exporting last value: %w", err)
 				}
 			default:
@@ -215,13 +238,13 @@ func (c *collector) Collect(ch chan<- prometheus.Metric) {
 	}
 }
 
-func (c *collector) exportLastValue(ch chan<- prometheus.Metric, lvagg aggregation.LastValue, kind number.Kind, desc *prometheus.Desc, attrs []string) error {
+func (c *collector) exportLastValue(ch chan<- prometheus.Metric, lvagg aggregation.LastValue, conv converter, desc *prometheus.Desc, attrs []string) error {
 	lv, _, err := lvagg.LastValue()
 	if err != nil {
 		return fmt.Errorf("error retrieving last value: %w", err)
 	}
 
-	m, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, lv.CoerceToFloat64(kind), attrs...)
+	m, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, conv.float64(lv), attrs...)
 	if err != nil {
 		return fmt.Errorf("error creating constant metric: %w", err)
 	}
@@ -230,13 +253,13 @@ func (c *collector) exportLastValue(ch chan<- prometheus.Metric, lvagg aggregati
 	return nil
 }
 
-func (c *collector) exportNonMonotonicCounter(ch chan<- prometheus.Metric, sum aggregation.Sum, kind number.Kind, desc *prometheus.Desc, attrs []string) error {
+func (c *collector) exportNonMonotonicCounter(ch chan<- prometheus.Metric, sum aggregation.Sum, conv converter, desc *prometheus.Desc, attrs []string) error {
 	v, err := sum.Sum()
 	if err != nil {
 		return fmt.Errorf("error retrieving counter: %w", err)
 	}
 
-	m, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, v.CoerceToFloat64(kind), attrs...)
+	m, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, conv.float64(v), attrs...)
 	if err != nil {
 		return fmt.Errorf("error creating constant metric: %w", err)
 	}
@@ -245,13 +268,13 @@ func (c *collector) exportNonMonotonicCounter(ch chan<- prometheus.Metric, sum a
 	return nil
 }
 
-func (c *collector) exportMonotonicCounter(ch chan<- prometheus.Metric, sum aggregation.Sum, kind number.Kind, desc *prometheus.Desc, attrs []string) error {
+func (c *collector) exportMonotonicCounter(ch chan<- prometheus.Metric, sum aggregation.Sum, conv converter, desc *prometheus.Desc, attrs []string) error {
 	v, err := sum.Sum()
 	if err != nil {
 		return fmt.Errorf("error retrieving counter: %w", err)
 	}
 
-	m, err := prometheus.NewConstMetric(desc, prometheus.CounterValue, v.CoerceToFloat64(kind), attrs...)
+	m, err := prometheus.NewConstMetric(desc, prometheus.CounterValue, conv.float64(v), attrs...)
 	if err != nil {
 		return fmt.Errorf("error creating constant metric: %w", err)
 	}
@@ -260,7 +283,7 @@ func (c *collector) exportMonotonicCounter(ch chan<- prometheus.Metric, sum aggr
 	return nil
 }
 
-func (c *collector) exportHistogram(ch chan<- prometheus.Metric, hist aggregation.Histogram, kind number.Kind, desc *prometheus.Desc, attrs []string) error {
+func (c *collector) exportHistogram(ch chan<- prometheus.Metric, hist aggregation.Histogram, conv converter, desc *prometheus.Desc, attrs []string) error {
 	buckets, err := hist.Histogram()
 	if err != nil {
 		return fmt.Errorf("error retrieving histogram: %w", err)
@@ -275,14 +298,14 @@ func (c *collector) exportHistogram(ch chan<- prometheus.Metric, hist aggregatio
 	// The bucket with upper-bound +inf is not included.
 	counts := make(map[float64]uint64, len(buckets.Boundaries))
 	for i := range buckets.Boundaries {
-		boundary := buckets.Boundaries[i]
+		boundary := conv.value(buckets.Boundaries[i])
 		totalCount += uint64(buckets.Counts[i])
 		counts[boundary] = totalCount
 	}
 	// Include the +inf bucket in the total count.
 	totalCount += uint64(buckets.Counts[len(buckets.Counts)-1])
 
-	m, err := prometheus.NewConstHistogram(desc, totalCount, sum.CoerceToFloat64(kind), counts, attrs...)
+	m, err := prometheus.NewConstHistogram(desc, totalCount, conv.float64(sum), counts, attrs...)
 	if err != nil {
 		return fmt.Errorf("error creating constant histogram: %w", err)
 	}
@@ -293,7 +316,7 @@ func (c *collector) exportHistogram(ch chan<- prometheus.Metric, hist aggregatio
 
 func (c *collector) toDesc(record export.Record, attrKeys []string) *prometheus.Desc {
 	desc := record.Descriptor()
-	return prometheus.NewDesc(sanitize(desc.Name()), desc.Description(), attrKeys, nil)
+	return prometheus.NewDesc(c.exp.metricName(record), desc.Description(), attrKeys, nil)
 }
 
 // mergeAttrs merges the export.Record's attributes and resources into a
@@ -21,12 +21,14 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/unit"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
 	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
 	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
@@ -125,13 +127,14 @@ func TestPrometheusExporter(t *testing.T) {
 	counter.Add(ctx, 10, attrs...)
 	counter.Add(ctx, 5.3, attrs...)
 
-	expected = append(expected, expectCounter("counter", `counter{A="B",C="D",R="V"} 15.3`))
+	expected = append(expected, expectCounter("counter_total", `counter_total{A="B",C="D",R="V"} 15.3`))
 
 	gaugeObserver, err := meter.AsyncInt64().Gauge("intgaugeobserver")
 	require.NoError(t, err)
 
-	err = meter.RegisterCallback([]instrument.Asynchronous{gaugeObserver}, func(ctx context.Context) {
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{gaugeObserver}, func(ctx context.Context) error {
 		gaugeObserver.Observe(ctx, 1, attrs...)
+		return nil
 	})
 	require.NoError(t, err)
 
@@ -158,18 +161,20 @@ func TestPrometheusExporter(t *testing.T) {
 	counterObserver, err := meter.AsyncFloat64().Counter("floatcounterobserver")
 	require.NoError(t, err)
 
-	err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) {
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) error {
 		counterObserver.Observe(ctx, 7.7, attrs...)
+		return nil
 	})
 	require.NoError(t, err)
 
-	expected = append(expected, expectCounter("floatcounterobserver", `floatcounterobserver{A="B",C="D",R="V"} 7.7`))
+	expected = append(expected, expectCounter("floatcounterobserver_total", `floatcounterobserver_total{A="B",C="D",R="V"} 7.7`))
 
 	upDownCounterObserver, err := meter.AsyncFloat64().UpDownCounter("floatupdowncounterobserver")
 	require.NoError(t, err)
 
-	err = meter.RegisterCallback([]instrument.Asynchronous{upDownCounterObserver}, func(ctx context.Context) {
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{upDownCounterObserver}, func(ctx context.Context) error {
 		upDownCounterObserver.Observe(ctx, -7.7, attrs...)
+		return nil
 	})
 	require.NoError(t, err)
 
@@ -217,12 +222,160 @@ func TestPrometheusStatefulness(t *testing.T) {
 	counter.Add(ctx, 100, attribute.String("key", "value"))
 
 	compareExport(t, exporter, []expectedMetric{
-		expectCounterWithHelp("a_counter", "Counts things", `a_counter{key="value"} 100`),
+		expectCounterWithHelp("a_counter_total", "Counts things", `a_counter_total{key="value"} 100`),
 	})
 
 	counter.Add(ctx, 100, attribute.String("key", "value"))
 
 	compareExport(t, exporter, []expectedMetric{
-		expectCounterWithHelp("a_counter", "Counts things", `a_counter{key="value"} 200`),
+		expectCounterWithHelp("a_counter_total", "Counts things", `a_counter_total{key="value"} 200`),
 	})
 }
+
+func TestPrometheusNames(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		config   prometheus.Config
+		expected []expectedMetric
+	}{
+		{
+			name: "default",
+			expected: []expectedMetric{
+				expectCounterWithHelp("http_server_duration_milliseconds_total", "Time spent", `http_server_duration_milliseconds_total{a_b="c"} 5`),
+				expectCounter("requests_total", `requests_total{a_b="c"} 1`),
+				expectGauge("memory_bytes", `memory_bytes{a_b="c"} 7`),
+				expectGauge("cpu_utilization_ratio", `cpu_utilization_ratio{a_b="c"} 0.5`),
+				expectHistogram("request_size_bytes",
+					`request_size_bytes_bucket{a_b="c",le="+Inf"} 1`,
+					`request_size_bytes_sum{a_b="c"} 3`,
+					`request_size_bytes_count{a_b="c"} 1`,
+				),
+			},
+		},
+		{
+			name: "without suffixes",
+			config: prometheus.Config{
+				WithoutUnits:           true,
+				WithoutCounterSuffixes: true,
+			},
+			expected: []expectedMetric{
+				expectCounterWithHelp("http_server_duration", "Time spent", `http_server_duration{a_b="c"} 5`),
+				expectCounter("requests_total", `requests_total{a_b="c"} 1`),
+				expectGauge("memory", `memory{a_b="c"} 7`),
+				expectGauge("cpu_utilization", `cpu_utilization{a_b="c"} 0.5`),
+				expectHistogram("request_size_bytes",
+					`request_size_bytes_bucket{a_b="c",le="+Inf"} 1`,
+					`request_size_bytes_sum{a_b="c"} 3`,
+					`request_size_bytes_count{a_b="c"} 1`,
+				),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.config.DefaultHistogramBoundaries = []float64{}
+			exporter, err := newPipeline(
+				tc.config,
+				controller.WithCollectPeriod(0),
+				controller.WithResource(resource.Empty()),
+			)
+			require.NoError(t, err)
+
+			meter := exporter.MeterProvider().Meter("test")
+			ctx := context.Background()
+			attrs := []attribute.KeyValue{attribute.String("a.b", "c")}
+
+			duration, err := meter.SyncInt64().Counter("http.server.duration", instrument.WithUnit(unit.Milliseconds), instrument.WithDescription("Time spent"))
+			require.NoError(t, err)
+			duration.Add(ctx, 5, attrs...)
+
+			// The name already ends with the counter suffix.
+			requests, err := meter.SyncInt64().Counter("requests_total")
+			require.NoError(t, err)
+			requests.Add(ctx, 1, attrs...)
+
+			// The name already ends with the unit.
+			memory, err := meter.SyncInt64().UpDownCounter("memory", instrument.WithUnit(unit.Bytes))
+			require.NoError(t, err)
+			memory.Add(ctx, 7, attrs...)
+
+			utilization, err := meter.AsyncFloat64().Gauge("cpu.utilization", instrument.WithUnit(unit.Dimensionless))
+			require.NoError(t, err)
+			_, err = meter.RegisterCallback([]instrument.Asynchronous{utilization}, func(ctx context.Context) error {
+				utilization.Observe(ctx, 0.5, attrs...)
+				return nil
+			})
+			require.NoError(t, err)
+
+			size, err := meter.SyncInt64().Histogram("request_size_bytes", instrument.WithUnit(unit.Bytes))
+			require.NoError(t, err)
+			size.Record(ctx, 3, attrs...)
+
+			compareExport(t, exporter, tc.expected)
+		})
+	}
+}
+
+func TestPrometheusDurations(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		config   prometheus.Config
+		expected []expectedMetric
+	}{
+		{
+			name: "nanoseconds",
+			expected: []expectedMetric{
+				expectHistogram("request_duration_nanoseconds",
+					`request_duration_nanoseconds_bucket{a_b="c",le="1e+06"} 1`,
+					`request_duration_nanoseconds_bucket{a_b="c",le="1e+09"} 2`,
+					`request_duration_nanoseconds_bucket{a_b="c",le="+Inf"} 3`,
+					`request_duration_nanoseconds_sum{a_b="c"} 2.3005e+09`,
+					`request_duration_nanoseconds_count{a_b="c"} 3`,
+				),
+				expectCounter("busy_milliseconds_total", `busy_milliseconds_total{a_b="c"} 1500`),
+			},
+		},
+		{
+			name:   "seconds",
+			config: prometheus.Config{DurationsInSeconds: true},
+			expected: []expectedMetric{
+				expectHistogram("request_duration_seconds",
+					`request_duration_seconds_bucket{a_b="c",le="0.001"} 1`,
+					`request_duration_seconds_bucket{a_b="c",le="1"} 2`,
+					`request_duration_seconds_bucket{a_b="c",le="+Inf"} 3`,
+					`request_duration_seconds_sum{a_b="c"} 2.3005`,
+					`request_duration_seconds_count{a_b="c"} 3`,
+				),
+				expectCounter("busy_seconds_total", `busy_seconds_total{a_b="c"} 1.5`),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.config.DefaultHistogramBoundaries = []float64{
+				float64(time.Millisecond),
+				float64(time.Second),
+			}
+			exporter, err := newPipeline(
+				tc.config,
+				controller.WithCollectPeriod(0),
+				controller.WithResource(resource.Empty()),
+			)
+			require.NoError(t, err)
+
+			meter := exporter.MeterProvider().Meter("test")
+			ctx := context.Background()
+			attrs := []attribute.KeyValue{attribute.String("a.b", "c")}
+
+			duration, err := meter.SyncInt64().Histogram("request.duration", instrument.WithUnit(unit.Nanoseconds))
+			require.NoError(t, err)
+			for _, d := range []time.Duration{500 * time.Microsecond, 800 * time.Millisecond, 1500 * time.Millisecond} {
+				duration.Record(ctx, int64(d), attrs...)
+			}
+
+			busy, err := meter.SyncInt64().Counter("busy", instrument.WithUnit(unit.Milliseconds))
+			require.NoError(t, err)
+			busy.Add(ctx, 1500, attrs...)
+
+			compareExport(t, exporter, tc.expected)
+		})
+	}
+}
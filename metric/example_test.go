@@ -61,14 +61,15 @@ func ExampleMeter_asynchronous_single() {
 		panic(err)
 	}
 
-	err = meter.RegisterCallback([]instrument.Asynchronous{memoryUsage},
-		func(ctx context.Context) {
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{memoryUsage},
+		func(ctx context.Context) error {
 			// instrument.WithCallbackFunc(func(ctx context.Context) {
 			//Do Work to get the real memoryUsage
 			// mem := GatherMemory(ctx)
 			mem := 75000
 
 			memoryUsage.Observe(ctx, int64(mem))
+			return nil
 		})
 	if err != nil {
 		fmt.Println("Failed to register callback")
@@ -86,11 +87,11 @@ func ExampleMeter_asynchronous_multiple() {
 	gcCount, _ := meter.AsyncInt64().Counter("gcCount")
 	gcPause, _ := meter.SyncFloat64().Histogram("gcPause")
 
-	err := meter.RegisterCallback([]instrument.Asynchronous{
+	_, err := meter.RegisterCallback([]instrument.Asynchronous{
 		heapAlloc,
 		gcCount,
 	},
-		func(ctx context.Context) {
+		func(ctx context.Context) error {
 			memStats := &runtime.MemStats{}
 			// This call does work
 			runtime.ReadMemStats(memStats)
@@ -100,6 +101,7 @@ func ExampleMeter_asynchronous_multiple() {
 
 			// This function synchronously records the pauses
 			computeGCPauses(ctx, gcPause, memStats.PauseNs[:])
+			return nil
 		},
 	)
 
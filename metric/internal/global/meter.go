@@ -15,7 +15,6 @@
 package global // import "go.opentelemetry.io/otel/metric/internal/global"
 
 import (
-	"context"
 	"sync"
 	"sync/atomic"
 
@@ -109,7 +108,7 @@ type meter struct {
 
 	mtx         sync.Mutex
 	instruments []delegatedInstrument
-	callbacks   []delegatedCallback
+	callbacks   []*registration
 
 	delegate atomic.Value // metric.Meter
 }
@@ -167,7 +166,7 @@ func (m *meter) AsyncFloat64() asyncfloat64.InstrumentProvider {
 //
 // It is only valid to call Observe within the scope of the passed function,
 // and only on the instruments that were registered with this call.
-func (m *meter) RegisterCallback(insts []instrument.Asynchronous, function func(context.Context)) error {
+func (m *meter) RegisterCallback(insts []instrument.Asynchronous, function metric.Callback) (metric.Registration, error) {
 	if del, ok := m.delegate.Load().(metric.Meter); ok {
 		insts = unwrapInstruments(insts)
 		return del.RegisterCallback(insts, function)
@@ -175,12 +174,14 @@ func (m *meter) RegisterCallback(insts []instrument.Asynchronous, function func(
 
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
-	m.callbacks = append(m.callbacks, delegatedCallback{
+	reg := &registration{
+		meter:       m,
 		instruments: insts,
 		function:    function,
-	})
+	}
+	m.callbacks = append(m.callbacks, reg)
 
-	return nil
+	return reg, nil
 }
 
 type wrapped interface {
@@ -217,17 +218,46 @@ func (m *meter) SyncFloat64() syncfloat64.InstrumentProvider {
 	return (*sfInstProvider)(m)
 }
 
-type delegatedCallback struct {
+// registration is a callback registered before the delegate is set.
+//
+// Once the delegate is set, the callback is registered with the delegate and
+// Unregister is forwarded to the Registration it returned.
+type registration struct {
+	meter       *meter
 	instruments []instrument.Asynchronous
-	function    func(context.Context)
+	function    metric.Callback
+
+	// delegate is protected by the meter mtx.
+	delegate metric.Registration
 }
 
-func (c *delegatedCallback) setDelegate(m metric.Meter) {
-	insts := unwrapInstruments(c.instruments)
-	err := m.RegisterCallback(insts, c.function)
+func (r *registration) setDelegate(m metric.Meter) {
+	insts := unwrapInstruments(r.instruments)
+	reg, err := m.RegisterCallback(insts, r.function)
 	if err != nil {
 		otel.Handle(err)
+		return
+	}
+	r.delegate = reg
+}
+
+// Unregister removes the callback, before or after the delegate is set.
+func (r *registration) Unregister() error {
+	m := r.meter
+	m.mtx.Lock()
+	for i, cb := range m.callbacks {
+		if cb == r {
+			m.callbacks = append(m.callbacks[:i], m.callbacks[i+1:]...)
+			break
+		}
+	}
+	del := r.delegate
+	m.mtx.Unlock()
+
+	if del == nil {
+		return nil
 	}
+	return del.Unregister()
 }
 
 type afInstProvider meter
@@ -68,7 +68,7 @@ func TestMeterRace(t *testing.T) {
 			_, _ = mtr.SyncInt64().Counter(name)
 			_, _ = mtr.SyncInt64().UpDownCounter(name)
 			_, _ = mtr.SyncInt64().Histogram(name)
-			_ = mtr.RegisterCallback(nil, func(ctx context.Context) {})
+			_, _ = mtr.RegisterCallback(nil, func(ctx context.Context) error { return nil })
 			if !once {
 				wg.Done()
 				once = true
@@ -101,9 +101,11 @@ func testSetupAllInstrumentTypes(t *testing.T, m metric.Meter) (syncfloat64.Coun
 	_, err = m.AsyncInt64().Gauge("test_Async_Gauge")
 	assert.NoError(t, err)
 
-	require.NoError(t, m.RegisterCallback([]instrument.Asynchronous{afcounter}, func(ctx context.Context) {
+	_, err = m.RegisterCallback([]instrument.Asynchronous{afcounter}, func(ctx context.Context) error {
 		afcounter.Observe(ctx, 3)
-	}))
+		return nil
+	})
+	require.NoError(t, err)
 
 	sfcounter, err := m.SyncFloat64().Counter("test_Async_Counter")
 	require.NoError(t, err)
@@ -257,3 +259,46 @@ func TestMeterDefersDelegations(t *testing.T) {
 	assert.IsType(t, &afCounter{}, actr)
 	assert.Equal(t, 1, mp.count)
 }
+
+func TestRegistrationUnregister(t *testing.T) {
+	globalMeterProvider := &meterProvider{}
+	m := globalMeterProvider.Meter("go.opentelemetry.io/otel/metric/internal/global/meter_test")
+
+	actr, err := m.AsyncFloat64().Counter("test_Async_Counter")
+	require.NoError(t, err)
+
+	var before, after, kept int
+	regBefore, err := m.RegisterCallback([]instrument.Asynchronous{actr}, func(ctx context.Context) error {
+		before++
+		return nil
+	})
+	require.NoError(t, err)
+	regAfter, err := m.RegisterCallback([]instrument.Asynchronous{actr}, func(ctx context.Context) error {
+		after++
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = m.RegisterCallback([]instrument.Asynchronous{actr}, func(ctx context.Context) error {
+		kept++
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Unregistered before the delegate is set, the callback is never
+	// registered with the delegate.
+	require.NoError(t, regBefore.Unregister())
+
+	globalMeterProvider.setDelegate(&testMeterProvider{})
+	testCollect(t, m)
+	assert.Equal(t, 0, before)
+	assert.Equal(t, 1, after)
+	assert.Equal(t, 1, kept)
+
+	// Unregistered after the delegate is set, the callback is
+	// unregistered from the delegate.
+	require.NoError(t, regAfter.Unregister())
+	testCollect(t, m)
+	assert.Equal(t, 0, before)
+	assert.Equal(t, 1, after)
+	assert.Equal(t, 2, kept)
+}
@@ -41,7 +41,7 @@ type testMeter struct {
 	sfCount int
 	siCount int
 
-	callbacks []func(context.Context)
+	callbacks map[*testRegistration]metric.Callback
 }
 
 // AsyncInt64 is the namespace for the Asynchronous Integer instruments.
@@ -64,8 +64,21 @@ func (m *testMeter) AsyncFloat64() asyncfloat64.InstrumentProvider {
 //
 // It is only valid to call Observe within the scope of the passed function,
 // and only on the instruments that were registered with this call.
-func (m *testMeter) RegisterCallback(insts []instrument.Asynchronous, function func(context.Context)) error {
-	m.callbacks = append(m.callbacks, function)
+func (m *testMeter) RegisterCallback(insts []instrument.Asynchronous, function metric.Callback) (metric.Registration, error) {
+	if m.callbacks == nil {
+		m.callbacks = map[*testRegistration]metric.Callback{}
+	}
+	reg := &testRegistration{meter: m}
+	m.callbacks[reg] = function
+	return reg, nil
+}
+
+type testRegistration struct {
+	meter *testMeter
+}
+
+func (r *testRegistration) Unregister() error {
+	delete(r.meter.callbacks, r)
 	return nil
 }
 
@@ -85,7 +98,7 @@ func (m *testMeter) SyncFloat64() syncfloat64.InstrumentProvider {
 func (m *testMeter) collect() {
 	ctx := context.Background()
 	for _, f := range m.callbacks {
-		f(ctx)
+		_ = f(ctx)
 	}
 }
 
@@ -51,10 +51,30 @@ type Meter interface {
 	//
 	// It is only valid to call Observe within the scope of the passed function,
 	// and only on the instruments that were registered with this call.
-	RegisterCallback(insts []instrument.Asynchronous, function func(context.Context)) error
+	//
+	// The returned Registration can be used to unregister the function.
+	RegisterCallback(insts []instrument.Asynchronous, function Callback) (Registration, error)
 
 	// SyncInt64 is the namespace for the Synchronous Integer instruments
 	SyncInt64() syncint64.InstrumentProvider
 	// SyncFloat64 is the namespace for the Synchronous Float instruments
 	SyncFloat64() syncfloat64.InstrumentProvider
 }
+
+// Callback is a function registered with a Meter that makes observations for
+// the set of instruments it is registered with.
+//
+// The function needs to complete in a finite amount of time and the deadline
+// of the passed context is expected to be honored. An error returned by the
+// function is reported by the collection that called it.
+type Callback func(context.Context) error
+
+// Registration is a token representing the unique registration of a callback
+// for a set of instruments with a Meter.
+type Registration interface {
+	// Unregister removes the callback registration from a Meter, so that
+	// the callback is no longer called during Collect.
+	//
+	// This method needs to be safe to call concurrently.
+	Unregister() error
+}
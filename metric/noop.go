@@ -64,7 +64,14 @@ func (noopMeter) SyncFloat64() syncfloat64.InstrumentProvider {
 }
 
 // RegisterCallback creates a register callback that does not record any metrics.
-func (noopMeter) RegisterCallback([]instrument.Asynchronous, func(context.Context)) error {
+func (noopMeter) RegisterCallback([]instrument.Asynchronous, Callback) (Registration, error) {
+	return noopRegistration{}, nil
+}
+
+type noopRegistration struct{}
+
+// Unregister does nothing.
+func (noopRegistration) Unregister() error {
 	return nil
 }
 
@@ -22,4 +22,10 @@ const (
 	Dimensionless Unit = "1"
 	Bytes         Unit = "By"
 	Milliseconds  Unit = "ms"
+	Seconds       Unit = "s"
+
+	// Nanoseconds is the unit of the int64 instruments measuring
+	// durations, recording them as the integral nanoseconds of a
+	// time.Duration.
+	Nanoseconds Unit = "ns"
 )
@@ -94,14 +94,20 @@ func NewInconsistentAggregatorError(a1, a2 Aggregator) error {
 }
 
 // RangeTest is a common routine for testing for valid input values.
-// This rejects NaN values.  This rejects negative values when the
-// metric instrument does not support negative values, including
-// monotonic counter metrics and absolute Histogram metrics.
+// This rejects NaN and infinite values, which would make the sums and
+// histograms they are added to undefined or infinite for good.  This
+// rejects negative values when the metric instrument does not support
+// negative values, including monotonic counter metrics and absolute
+// Histogram metrics.
 func RangeTest(num number.Number, descriptor *sdkapi.Descriptor) error {
 	numberKind := descriptor.NumberKind()
 
-	if numberKind == number.Float64Kind && math.IsNaN(num.AsFloat64()) {
-		return aggregation.ErrNaNInput
+	if numberKind == number.Float64Kind {
+		if v := num.AsFloat64(); math.IsNaN(v) {
+			return aggregation.ErrNaNInput
+		} else if math.IsInf(v, 0) {
+			return aggregation.ErrInfInput
+		}
 	}
 
 	switch descriptor.InstrumentKind() {
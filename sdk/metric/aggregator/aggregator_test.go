@@ -52,6 +52,17 @@ func testRangeNaN(t *testing.T, desc *sdkapi.Descriptor) {
 	}
 }
 
+func testRangeInf(t *testing.T, desc *sdkapi.Descriptor) {
+	// Int64 numbers have no infinities.
+	if desc.NumberKind() != number.Float64Kind {
+		return
+	}
+	for _, sign := range []int{+1, -1} {
+		inf := number.NewFloat64Number(math.Inf(sign))
+		require.Equal(t, aggregation.ErrInfInput, aggregator.RangeTest(inf, desc))
+	}
+}
+
 func testRangeNegative(t *testing.T, desc *sdkapi.Descriptor) {
 	var neg, pos number.Number
 
@@ -98,6 +109,7 @@ func TestNaNTest(t *testing.T) {
 					nkind,
 				)
 				testRangeNaN(t, &desc)
+				testRangeInf(t, &desc)
 			}
 		})
 	}
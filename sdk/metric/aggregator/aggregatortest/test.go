@@ -256,6 +256,16 @@ func SynchronizedMoveResetTest(t *testing.T, mkind sdkapi.InstrumentKind, nf fun
 				require.Error(t, err)
 				require.True(t, errors.Is(err, aggregation.ErrNoData))
 			}
+
+			if min, ok := agg.(aggregation.Min); ok {
+				_, err := min.Min()
+				require.ErrorIs(t, err, aggregation.ErrNoData)
+			}
+
+			if max, ok := agg.(aggregation.Max); ok {
+				_, err := max.Max()
+				require.ErrorIs(t, err, aggregation.ErrNoData)
+			}
 		})
 	})
 
@@ -302,6 +312,18 @@ func SynchronizedMoveResetTest(t *testing.T, mkind sdkapi.InstrumentKind, nf fun
 				require.Equal(t, input, v)
 				require.NoError(t, err)
 			}
+
+			if min, ok := agg.(aggregation.Min); ok {
+				v, err := min.Min()
+				require.Equal(t, input, v)
+				require.NoError(t, err)
+			}
+
+			if max, ok := agg.(aggregation.Max); ok {
+				v, err := max.Max()
+				require.Equal(t, input, v)
+				require.NoError(t, err)
+			}
 		})
 	})
 }
@@ -0,0 +1,146 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package exemplar samples the measurements made in sampled spans, for
+// the aggregators that support aggregation.Exemplars.
+package exemplar // import "go.opentelemetry.io/otel/sdk/metric/aggregator/exemplar"
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// decisionKey is the Context key of the decision set with
+// ContextWithExemplarDecision.
+type decisionKey struct{}
+
+// ContextWithExemplarDecision returns a copy of ctx that overrides the
+// sampling of the exemplars of the measurements made with it, e.g., to
+// keep the exemplars of known-interesting requests.  When keep is true,
+// Sample returns an exemplar even without a sampled span; otherwise, it
+// returns none.
+func ContextWithExemplarDecision(ctx context.Context, keep bool) context.Context {
+	return context.WithValue(ctx, decisionKey{}, keep)
+}
+
+// ExemplarDecisionFromContext returns the decision set in ctx with
+// ContextWithExemplarDecision, if any.
+func ExemplarDecisionFromContext(ctx context.Context) (keep, ok bool) {
+	keep, ok = ctx.Value(decisionKey{}).(bool)
+	return keep, ok
+}
+
+// filteredKey is the Context key of the attributes set with
+// ContextWithFilteredAttributes.
+type filteredKey struct{}
+
+// ContextWithFilteredAttributes returns a copy of ctx holding the
+// attributes that the attribute filter of an instrument removed from the
+// series of a measurement made with it, which its exemplar keeps, see
+// aggregation.Exemplar.
+func ContextWithFilteredAttributes(ctx context.Context, kvs []attribute.KeyValue) context.Context {
+	return context.WithValue(ctx, filteredKey{}, kvs)
+}
+
+// Sampled returns whether Sample returns the exemplars of the
+// measurements made in ctx.
+func Sampled(ctx context.Context) bool {
+	if keep, ok := ExemplarDecisionFromContext(ctx); ok {
+		return keep
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	return sc.IsValid() && sc.IsSampled()
+}
+
+// Sample returns the exemplar of the measurement n made in ctx.  Unless
+// ctx holds a decision, see ContextWithExemplarDecision, it returns false
+// when ctx holds no sampled span, as the exemplar would not link to a
+// recorded trace.
+func Sample(ctx context.Context, n number.Number) (aggregation.Exemplar, bool) {
+	if !Sampled(ctx) {
+		return aggregation.Exemplar{}, false
+	}
+	filtered, _ := ctx.Value(filteredKey{}).([]attribute.KeyValue)
+	return aggregation.Exemplar{
+		Value:              n,
+		Time:               time.Now(),
+		SpanContext:        trace.SpanContextFromContext(ctx),
+		FilteredAttributes: filtered,
+	}, true
+}
+
+// Reservoir keeps a uniform sample of at most a fixed number of the
+// exemplars offered to it, using reservoir sampling.  A Reservoir is not
+// safe for concurrent use.
+type Reservoir struct {
+	exemplars []aggregation.Exemplar
+	size      int
+
+	// seen is the number of exemplars offered since the last
+	// Reset.
+	seen int64
+}
+
+// NewReservoir returns a Reservoir of at most size exemplars.
+func NewReservoir(size int) *Reservoir {
+	if size < 1 {
+		size = 1
+	}
+	return &Reservoir{
+		exemplars: make([]aggregation.Exemplar, 0, size),
+		size:      size,
+	}
+}
+
+// Offer adds e to the sample with a probability of the size of the
+// reservoir over the number of exemplars offered.
+func (r *Reservoir) Offer(e aggregation.Exemplar) {
+	r.seen++
+	if len(r.exemplars) < r.size {
+		r.exemplars = append(r.exemplars, e)
+		return
+	}
+	if i := rand.Int63n(r.seen); i < int64(r.size) {
+		r.exemplars[i] = e
+	}
+}
+
+// Merge offers the exemplars of o to r.  The result is not a uniform
+// sample of both: the exemplars of o are underweighted when o saw more
+// exemplars than it kept.
+func (r *Reservoir) Merge(o *Reservoir) {
+	for _, e := range o.exemplars {
+		r.Offer(e)
+	}
+}
+
+// Exemplars returns a copy of the sampled exemplars.
+func (r *Reservoir) Exemplars() []aggregation.Exemplar {
+	if len(r.exemplars) == 0 {
+		return nil
+	}
+	return append([]aggregation.Exemplar(nil), r.exemplars...)
+}
+
+// Reset empties r.
+func (r *Reservoir) Reset() {
+	r.exemplars = r.exemplars[:0]
+	r.seen = 0
+}
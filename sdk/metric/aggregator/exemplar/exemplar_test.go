@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exemplar_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/exemplar"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSample(t *testing.T) {
+	ctx := context.Background()
+	n := number.NewInt64Number(7)
+
+	_, ok := exemplar.Sample(ctx, n)
+	require.False(t, ok, "no span")
+
+	unsampled := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	_, ok = exemplar.Sample(trace.ContextWithSpanContext(ctx, unsampled), n)
+	require.False(t, ok, "unsampled span")
+
+	sampled := unsampled.WithTraceFlags(trace.FlagsSampled)
+	e, ok := exemplar.Sample(trace.ContextWithSpanContext(ctx, sampled), n)
+	require.True(t, ok)
+	require.Equal(t, n, e.Value)
+	require.Equal(t, sampled, e.SpanContext)
+	require.False(t, e.Time.IsZero())
+}
+
+func TestExemplarDecision(t *testing.T) {
+	ctx := context.Background()
+	n := number.NewInt64Number(7)
+	sampled := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	_, ok := exemplar.ExemplarDecisionFromContext(ctx)
+	require.False(t, ok)
+
+	// A kept measurement has an exemplar, with its span if any.
+	e, ok := exemplar.Sample(exemplar.ContextWithExemplarDecision(ctx, true), n)
+	require.True(t, ok)
+	require.Equal(t, n, e.Value)
+	require.False(t, e.SpanContext.IsValid())
+	e, ok = exemplar.Sample(exemplar.ContextWithExemplarDecision(trace.ContextWithSpanContext(ctx, sampled), true), n)
+	require.True(t, ok)
+	require.Equal(t, sampled, e.SpanContext)
+
+	// A dropped measurement has none, even in a sampled span.
+	_, ok = exemplar.Sample(exemplar.ContextWithExemplarDecision(trace.ContextWithSpanContext(ctx, sampled), false), n)
+	require.False(t, ok)
+}
+
+func TestFilteredAttributes(t *testing.T) {
+	ctx := exemplar.ContextWithExemplarDecision(context.Background(), true)
+	n := number.NewInt64Number(7)
+	filtered := []attribute.KeyValue{attribute.String("user", "u-42")}
+
+	require.True(t, exemplar.Sampled(ctx))
+	e, ok := exemplar.Sample(ctx, n)
+	require.True(t, ok)
+	require.Empty(t, e.FilteredAttributes)
+
+	e, ok = exemplar.Sample(exemplar.ContextWithFilteredAttributes(ctx, filtered), n)
+	require.True(t, ok)
+	require.Equal(t, filtered, e.FilteredAttributes)
+
+	// Unsampled measurements have no exemplar to keep them.
+	ctx = context.Background()
+	require.False(t, exemplar.Sampled(ctx))
+	_, ok = exemplar.Sample(exemplar.ContextWithFilteredAttributes(ctx, filtered), n)
+	require.False(t, ok)
+}
+
+func TestReservoir(t *testing.T) {
+	r := exemplar.NewReservoir(3)
+	require.Empty(t, r.Exemplars())
+
+	offered := map[int64]bool{}
+	for i := int64(0); i < 100; i++ {
+		offered[i] = true
+		r.Offer(aggregation.Exemplar{Value: number.NewInt64Number(i)})
+	}
+	exemplars := r.Exemplars()
+	require.Len(t, exemplars, 3)
+	for _, e := range exemplars {
+		require.True(t, offered[e.Value.AsInt64()])
+	}
+
+	// Exemplars returns a copy.
+	exemplars[0].Value = number.NewInt64Number(-1)
+	require.NotEqual(t, exemplars[0], r.Exemplars()[0])
+
+	o := exemplar.NewReservoir(3)
+	o.Offer(aggregation.Exemplar{Value: number.NewInt64Number(100)})
+	r.Reset()
+	require.Empty(t, r.Exemplars())
+	r.Merge(o)
+	require.Equal(t, o.Exemplars(), r.Exemplars())
+}
+
+func TestReservoirUniform(t *testing.T) {
+	// Every exemplar is kept with probability 1/10.
+	const (
+		offers = 10
+		rounds = 10000
+	)
+	kept := make([]int, offers)
+	r := exemplar.NewReservoir(1)
+	for i := 0; i < rounds; i++ {
+		r.Reset()
+		for j := 0; j < offers; j++ {
+			r.Offer(aggregation.Exemplar{Value: number.NewInt64Number(int64(j))})
+		}
+		kept[r.Exemplars()[0].Value.AsInt64()]++
+	}
+	for j, n := range kept {
+		require.InDelta(t, rounds/offers, n, rounds/offers/2, "exemplar %d", j)
+	}
+}
@@ -0,0 +1,433 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exponential // import "go.opentelemetry.io/otel/sdk/metric/aggregator/exponential"
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/exponential/mapping"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/exponential/mapping/exponent"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/exponential/mapping/logarithm"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+const (
+	// DefaultMaxSize is the default maximum number of buckets of
+	// each sign.
+	DefaultMaxSize int32 = 160
+
+	// MinSize is the smallest maximum number of buckets.  Two
+	// buckets of the smallest scale hold every normal float64.
+	MinSize int32 = 2
+
+	// MaxScale is the scale of an empty histogram, the finest
+	// resolution it starts from.
+	MaxScale = logarithm.MaxScale
+
+	// MinScale is the coarsest scale of a histogram.
+	MinScale = exponent.MinScale
+)
+
+type (
+	// Aggregator observes events and counts them in exponential
+	// buckets, see the ExponentialHistogram data model.  The scale
+	// of the buckets starts at MaxScale and is reduced as needed to
+	// keep the number of buckets of each sign below the maximum
+	// size.  It also calculates the sum and count of all events.
+	Aggregator struct {
+		lock    sync.Mutex
+		maxSize int32
+		kind    number.Kind
+		state   *state
+	}
+
+	// config describes how the histogram is aggregated.
+	config struct {
+		maxSize int32
+	}
+
+	// Option configures an exponential histogram config.
+	Option interface {
+		// apply sets one or more config fields.
+		apply(*config)
+	}
+
+	// state represents the state of an exponential histogram.
+	state struct {
+		sum       number.Number
+		count     uint64
+		zeroCount uint64
+
+		// mapping maps values to the bucket indexes of its
+		// scale.
+		mapping  mapping.Mapping
+		positive buckets
+		negative buckets
+	}
+
+	// buckets are the counts of consecutive buckets of one sign.
+	buckets struct {
+		// offset is the index of counts[0].
+		offset int32
+		counts []uint64
+	}
+)
+
+// WithMaxSize sets the maximum number of buckets of each sign, which is
+// DefaultMaxSize by default.  Sizes below MinSize are raised to MinSize.
+func WithMaxSize(size int32) Option {
+	return maxSizeOption(size)
+}
+
+type maxSizeOption int32
+
+func (o maxSizeOption) apply(config *config) {
+	config.maxSize = int32(o)
+}
+
+var _ aggregator.Aggregator = &Aggregator{}
+var _ aggregation.Sum = &Aggregator{}
+var _ aggregation.Count = &Aggregator{}
+var _ aggregation.ExponentialHistogram = &Aggregator{}
+
+// New returns a new aggregator for computing exponential histograms.
+func New(cnt int, desc *sdkapi.Descriptor, opts ...Option) []Aggregator {
+	cfg := config{maxSize: DefaultMaxSize}
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+	if cfg.maxSize < MinSize {
+		cfg.maxSize = MinSize
+	}
+
+	aggs := make([]Aggregator, cnt)
+	for i := range aggs {
+		aggs[i] = Aggregator{
+			maxSize: cfg.maxSize,
+			kind:    desc.NumberKind(),
+			state:   newState(),
+		}
+	}
+	return aggs
+}
+
+// newMapping returns the mapping of scale, which is between MinScale and
+// MaxScale.
+func newMapping(scale int32) mapping.Mapping {
+	var m mapping.Mapping
+	if scale <= 0 {
+		m, _ = exponent.NewMapping(scale)
+	} else {
+		m, _ = logarithm.NewMapping(scale)
+	}
+	return m
+}
+
+func newState() *state {
+	return &state{mapping: newMapping(MaxScale)}
+}
+
+// Aggregation returns an interface for reading the state of this aggregator.
+func (c *Aggregator) Aggregation() aggregation.Aggregation {
+	return c
+}
+
+// Kind returns aggregation.ExponentialHistogramKind.
+func (c *Aggregator) Kind() aggregation.Kind {
+	return aggregation.ExponentialHistogramKind
+}
+
+// Sum returns the sum of all values in the checkpoint.
+func (c *Aggregator) Sum() (number.Number, error) {
+	return c.state.sum, nil
+}
+
+// Count returns the number of values in the checkpoint.
+func (c *Aggregator) Count() (uint64, error) {
+	return c.state.count, nil
+}
+
+// ExponentialHistogram returns the count of events in exponential
+// buckets.
+func (c *Aggregator) ExponentialHistogram() (aggregation.ExponentialBuckets, error) {
+	return aggregation.ExponentialBuckets{
+		Scale:     c.state.mapping.Scale(),
+		ZeroCount: c.state.zeroCount,
+		Positive:  c.state.positive.export(),
+		Negative:  c.state.negative.export(),
+	}, nil
+}
+
+// SynchronizedMove saves the current state into oa and resets the current
+// state to the empty set, at MaxScale.
+func (c *Aggregator) SynchronizedMove(oa aggregator.Aggregator, desc *sdkapi.Descriptor) error {
+	o, _ := oa.(*Aggregator)
+
+	if oa != nil && o == nil {
+		return aggregator.NewInconsistentAggregatorError(c, oa)
+	}
+
+	if o != nil {
+		// Reset the target state before swapping it under
+		// the lock below, see histogram.Aggregator.
+		o.state.clear()
+	}
+
+	c.lock.Lock()
+	if o != nil {
+		c.state, o.state = o.state, c.state
+	} else {
+		c.state.clear()
+	}
+	c.lock.Unlock()
+
+	return nil
+}
+
+// Update adds the recorded measurement to the current data set.
+func (c *Aggregator) Update(_ context.Context, n number.Number, desc *sdkapi.Descriptor) error {
+	kind := desc.NumberKind()
+	asFloat := n.CoerceToFloat64(kind)
+
+	// NaN and infinite values have no bucket and would make the sum
+	// meaningless; they are rejected without modifying the state.
+	if math.IsNaN(asFloat) {
+		return aggregation.ErrNaNInput
+	}
+	if math.IsInf(asFloat, 0) {
+		return aggregation.ErrInfInput
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.state.count++
+	c.state.sum.AddNumber(kind, n)
+	c.state.update(asFloat, c.maxSize)
+	return nil
+}
+
+// Merge combines two exponential histograms into a single one, at the
+// largest scale that fits the buckets of both.
+func (c *Aggregator) Merge(oa aggregator.Aggregator, desc *sdkapi.Descriptor) error {
+	o, _ := oa.(*Aggregator)
+	if o == nil {
+		return aggregator.NewInconsistentAggregatorError(c, oa)
+	}
+
+	c.state.sum.AddNumber(desc.NumberKind(), o.state.sum)
+	c.state.count += o.state.count
+	c.state.zeroCount += o.state.zeroCount
+	c.state.merge(o.state, c.maxSize)
+	return nil
+}
+
+// clear resets s to the empty set, at MaxScale.  The bucket counts keep
+// their memory.
+func (s *state) clear() {
+	s.sum = 0
+	s.count = 0
+	s.zeroCount = 0
+	s.mapping = newMapping(MaxScale)
+	s.positive.clear()
+	s.negative.clear()
+}
+
+// scale returns the scale of s.
+func (s *state) scale() int32 {
+	return s.mapping.Scale()
+}
+
+// update counts value in its bucket, reducing the scale first when its
+// bucket does not fit in maxSize buckets.
+func (s *state) update(value float64, maxSize int32) {
+	if value == 0 {
+		s.zeroCount++
+		return
+	}
+	b := &s.positive
+	if value < 0 {
+		b = &s.negative
+		value = -value
+	}
+
+	index := s.mapping.MapToIndex(value)
+	e := extent{low: index, high: index}
+	if !b.empty() {
+		e = e.union(b.extent(0))
+	}
+	if change := e.scaleChange(maxSize); change != 0 {
+		s.downscale(change)
+		index = s.mapping.MapToIndex(value)
+	}
+	b.increment(index, 1)
+}
+
+// merge adds the buckets of o to s, reducing the scale of s first so that
+// the buckets of both fit in maxSize buckets of each sign.
+func (s *state) merge(o *state, maxSize int32) {
+	scale := s.scale()
+	if o.scale() < scale {
+		scale = o.scale()
+	}
+
+	change := s.scale() - scale
+	var extra int32
+	for _, pair := range [][2]*buckets{
+		{&s.positive, &o.positive},
+		{&s.negative, &o.negative},
+	} {
+		sb, ob := pair[0], pair[1]
+		var e extent
+		switch {
+		case sb.empty() && ob.empty():
+			continue
+		case sb.empty():
+			e = ob.extent(o.scale() - scale)
+		case ob.empty():
+			e = sb.extent(change)
+		default:
+			e = sb.extent(change).union(ob.extent(o.scale() - scale))
+		}
+		if c := e.scaleChange(maxSize); c > extra {
+			extra = c
+		}
+	}
+	s.downscale(change + extra)
+
+	shift := o.scale() - s.scale()
+	s.positive.add(&o.positive, shift)
+	s.negative.add(&o.negative, shift)
+}
+
+// downscale reduces the scale of s by change, merging the buckets of
+// each sign pairwise change times.
+func (s *state) downscale(change int32) {
+	if s.scale()-change < MinScale {
+		change = s.scale() - MinScale
+	}
+	if change <= 0 {
+		return
+	}
+	s.positive.downscale(change)
+	s.negative.downscale(change)
+	s.mapping = newMapping(s.scale() - change)
+}
+
+// extent is an inclusive range of bucket indexes.
+type extent struct {
+	low, high int32
+}
+
+// union returns the smallest extent containing e and o.
+func (e extent) union(o extent) extent {
+	if o.low < e.low {
+		e.low = o.low
+	}
+	if o.high > e.high {
+		e.high = o.high
+	}
+	return e
+}
+
+// scaleChange returns by how much the scale has to be reduced for e to
+// fit in maxSize buckets.  Reducing the scale by one maps index i to
+// i>>1.
+func (e extent) scaleChange(maxSize int32) int32 {
+	var change int32
+	for int64(e.high)-int64(e.low) >= int64(maxSize) {
+		e.low >>= 1
+		e.high >>= 1
+		change++
+	}
+	return change
+}
+
+func (b *buckets) empty() bool {
+	return len(b.counts) == 0
+}
+
+func (b *buckets) clear() {
+	b.offset = 0
+	b.counts = b.counts[:0]
+}
+
+// extent returns the range of the indexes of b after reducing its scale
+// by shift.  It is only meaningful when b is not empty.
+func (b *buckets) extent(shift int32) extent {
+	return extent{
+		low:  b.offset >> shift,
+		high: (b.offset + int32(len(b.counts)) - 1) >> shift,
+	}
+}
+
+// increment adds n to the count of the bucket index, growing b as
+// needed.
+func (b *buckets) increment(index int32, n uint64) {
+	switch {
+	case b.empty():
+		b.offset = index
+		b.counts = append(b.counts[:0], n)
+		return
+	case index < b.offset:
+		grow := int(b.offset - index)
+		counts := make([]uint64, grow+len(b.counts))
+		copy(counts[grow:], b.counts)
+		b.counts = counts
+		b.offset = index
+	case index >= b.offset+int32(len(b.counts)):
+		b.counts = append(b.counts, make([]uint64, int(index-b.offset)-len(b.counts)+1)...)
+	}
+	b.counts[index-b.offset] += n
+}
+
+// downscale merges the buckets of b that map to the same index after
+// reducing the scale by change.  Buckets only move down, so this is done
+// in place.
+func (b *buckets) downscale(change int32) {
+	if b.empty() || change <= 0 {
+		return
+	}
+	e := b.extent(change)
+	for i, n := range b.counts {
+		b.counts[i] = 0
+		b.counts[(b.offset+int32(i))>>change-e.low] += n
+	}
+	b.counts = b.counts[:e.high-e.low+1]
+	b.offset = e.low
+}
+
+// add adds the counts of o, whose scale is larger than the scale of b by
+// shift, to b.
+func (b *buckets) add(o *buckets, shift int32) {
+	for i, n := range o.counts {
+		if n != 0 {
+			b.increment((o.offset+int32(i))>>shift, n)
+		}
+	}
+}
+
+// export returns a copy of the counts of b.
+func (b *buckets) export() aggregation.ExponentialBucketCounts {
+	return aggregation.ExponentialBucketCounts{
+		Offset: b.offset,
+		Counts: append([]uint64(nil), b.counts...),
+	}
+}
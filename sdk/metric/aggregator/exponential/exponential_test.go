@@ -0,0 +1,226 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exponential_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/aggregatortest"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/exponential"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+func newFloat64(options ...exponential.Option) (*exponential.Aggregator, *sdkapi.Descriptor) {
+	desc := aggregatortest.NewAggregatorTest(sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	return &exponential.New(1, desc, options...)[0], desc
+}
+
+func update(t *testing.T, agg *exponential.Aggregator, desc *sdkapi.Descriptor, values ...float64) {
+	for _, v := range values {
+		aggregatortest.CheckedUpdate(t, agg, number.NewFloat64Number(v), desc)
+	}
+}
+
+func buckets(t *testing.T, agg *exponential.Aggregator) aggregation.ExponentialBuckets {
+	b, err := agg.ExponentialHistogram()
+	require.NoError(t, err)
+	return b
+}
+
+func TestBucketIndex(t *testing.T) {
+	// A single value is counted at the maximum scale, where bucket
+	// index i holds the values in (2^(i/2^20), 2^((i+1)/2^20)].
+	for _, test := range []struct {
+		value float64
+		index int32
+	}{
+		{1, -1},
+		{2, 1<<20 - 1},
+		{4, 2<<20 - 1},
+		{0.5, -1<<20 - 1},
+		{0.25, -2<<20 - 1},
+		{math.Nextafter(1, 2), 0},
+	} {
+		agg, desc := newFloat64()
+		update(t, agg, desc, test.value)
+
+		b := buckets(t, agg)
+		require.Equal(t, exponential.MaxScale, b.Scale, "value %v", test.value)
+		require.Equal(t, test.index, b.Positive.Offset, "value %v", test.value)
+		require.Equal(t, []uint64{1}, b.Positive.Counts, "value %v", test.value)
+	}
+}
+
+func TestScaleDown(t *testing.T) {
+	agg, desc := newFloat64(exponential.WithMaxSize(2))
+
+	// 1 and 2 are in adjacent buckets only at scale 0.
+	update(t, agg, desc, 1, 2)
+	b := buckets(t, agg)
+	require.Equal(t, int32(0), b.Scale)
+	require.Equal(t, aggregation.ExponentialBucketCounts{Offset: -1, Counts: []uint64{1, 1}}, b.Positive)
+
+	// At scale 0, 4 is in bucket 1, which leaves three buckets: at
+	// scale -1, 2 and 4 share bucket (1, 4].
+	update(t, agg, desc, 4)
+	b = buckets(t, agg)
+	require.Equal(t, int32(-1), b.Scale)
+	require.Equal(t, aggregation.ExponentialBucketCounts{Offset: -1, Counts: []uint64{1, 2}}, b.Positive)
+
+	count, err := agg.Count()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), count)
+	sum, err := agg.Sum()
+	require.NoError(t, err)
+	require.Equal(t, 7.0, sum.AsFloat64())
+}
+
+func TestScaleDownMaxSize(t *testing.T) {
+	agg, desc := newFloat64(exponential.WithMaxSize(4))
+
+	// The powers of two are in buckets -1..3 at scale 0, one more
+	// than fits.
+	update(t, agg, desc, 1, 2, 4, 8, 16)
+	b := buckets(t, agg)
+	require.Equal(t, int32(-1), b.Scale)
+	require.Equal(t, aggregation.ExponentialBucketCounts{Offset: -1, Counts: []uint64{1, 2, 2}}, b.Positive)
+}
+
+func TestMinScale(t *testing.T) {
+	agg, desc := newFloat64(exponential.WithMaxSize(2))
+
+	update(t, agg, desc, math.SmallestNonzeroFloat64, math.MaxFloat64)
+	b := buckets(t, agg)
+	require.Equal(t, exponential.MinScale, b.Scale)
+	require.Equal(t, aggregation.ExponentialBucketCounts{Offset: -1, Counts: []uint64{1, 1}}, b.Positive)
+}
+
+func TestZeroAndNegative(t *testing.T) {
+	agg, desc := newFloat64(exponential.WithMaxSize(2))
+
+	update(t, agg, desc, 0, -1, -2, 1, 0)
+	b := buckets(t, agg)
+	require.Equal(t, int32(0), b.Scale)
+	require.Equal(t, uint64(2), b.ZeroCount)
+	require.Equal(t, aggregation.ExponentialBucketCounts{Offset: -1, Counts: []uint64{1}}, b.Positive)
+	require.Equal(t, aggregation.ExponentialBucketCounts{Offset: -1, Counts: []uint64{1, 1}}, b.Negative)
+
+	count, err := agg.Count()
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), count)
+	sum, err := agg.Sum()
+	require.NoError(t, err)
+	require.Equal(t, -2.0, sum.AsFloat64())
+}
+
+func TestInvalidValues(t *testing.T) {
+	agg, desc := newFloat64()
+	ctx := context.Background()
+
+	require.ErrorIs(t, agg.Update(ctx, number.NewFloat64Number(math.NaN()), desc), aggregation.ErrNaNInput)
+	require.ErrorIs(t, agg.Update(ctx, number.NewFloat64Number(math.Inf(+1)), desc), aggregation.ErrInfInput)
+	require.ErrorIs(t, agg.Update(ctx, number.NewFloat64Number(math.Inf(-1)), desc), aggregation.ErrInfInput)
+
+	count, err := agg.Count()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), count)
+}
+
+func TestMerge(t *testing.T) {
+	desc := aggregatortest.NewAggregatorTest(sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	aggs := exponential.New(2, desc, exponential.WithMaxSize(2))
+	agg1, agg2 := &aggs[0], &aggs[1]
+
+	update(t, agg1, desc, 1, 2, 0)
+	update(t, agg2, desc, 1, 4, -1)
+	require.Equal(t, int32(0), buckets(t, agg1).Scale)
+	require.Equal(t, int32(-1), buckets(t, agg2).Scale)
+
+	aggregatortest.CheckedMerge(t, agg1, agg2, desc)
+
+	b := buckets(t, agg1)
+	require.Equal(t, int32(-1), b.Scale)
+	require.Equal(t, uint64(1), b.ZeroCount)
+	require.Equal(t, aggregation.ExponentialBucketCounts{Offset: -1, Counts: []uint64{2, 2}}, b.Positive)
+	require.Equal(t, aggregation.ExponentialBucketCounts{Offset: -1, Counts: []uint64{1}}, b.Negative)
+
+	count, err := agg1.Count()
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), count)
+	sum, err := agg1.Sum()
+	require.NoError(t, err)
+	require.Equal(t, 7.0, sum.AsFloat64())
+
+	// The merged aggregator is unchanged.
+	b = buckets(t, agg2)
+	require.Equal(t, int32(-1), b.Scale)
+	require.Equal(t, aggregation.ExponentialBucketCounts{Offset: -1, Counts: []uint64{1, 1}}, b.Positive)
+}
+
+func TestMergeScaleDown(t *testing.T) {
+	desc := aggregatortest.NewAggregatorTest(sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	aggs := exponential.New(2, desc, exponential.WithMaxSize(2))
+	agg1, agg2 := &aggs[0], &aggs[1]
+
+	// Both are at scale 0 but together span buckets -1..1.
+	update(t, agg1, desc, 1, 2)
+	update(t, agg2, desc, 3)
+
+	aggregatortest.CheckedMerge(t, agg1, agg2, desc)
+
+	b := buckets(t, agg1)
+	require.Equal(t, int32(-1), b.Scale)
+	require.Equal(t, aggregation.ExponentialBucketCounts{Offset: -1, Counts: []uint64{1, 2}}, b.Positive)
+}
+
+func TestSynchronizedMove(t *testing.T) {
+	desc := aggregatortest.NewAggregatorTest(sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	aggs := exponential.New(2, desc, exponential.WithMaxSize(2))
+	agg, ckpt := &aggs[0], &aggs[1]
+
+	update(t, agg, desc, 1, 2, 4)
+	require.NoError(t, agg.SynchronizedMove(ckpt, desc))
+
+	b := buckets(t, ckpt)
+	require.Equal(t, int32(-1), b.Scale)
+	require.Equal(t, aggregation.ExponentialBucketCounts{Offset: -1, Counts: []uint64{1, 2}}, b.Positive)
+
+	// The moved-from aggregator starts over at the maximum scale.
+	b = buckets(t, agg)
+	require.Equal(t, exponential.MaxScale, b.Scale)
+	require.Empty(t, b.Positive.Counts)
+
+	update(t, agg, desc, 1)
+	b = buckets(t, agg)
+	require.Equal(t, exponential.MaxScale, b.Scale)
+	require.Equal(t, aggregation.ExponentialBucketCounts{Offset: -1, Counts: []uint64{1}}, b.Positive)
+}
+
+func TestSynchronizedMoveReset(t *testing.T) {
+	aggregatortest.SynchronizedMoveResetTest(
+		t,
+		sdkapi.HistogramInstrumentKind,
+		func(desc *sdkapi.Descriptor) aggregator.Aggregator {
+			return &exponential.New(1, desc)[0]
+		},
+	)
+}
@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogram // import "go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
+
+import (
+	"fmt"
+	"math"
+)
+
+// ErrInvalidBounds is returned when histogram boundaries cannot be
+// generated from the requested parameters, or are not valid, see
+// ValidateBounds.
+var ErrInvalidBounds = fmt.Errorf("invalid histogram boundaries")
+
+// ExponentialBounds returns count boundaries, starting with start and
+// multiplying each boundary by factor to compute the next one, i.e.,
+// start*factor^i for i in [0, count).  The result can be passed to
+// WithExplicitBoundaries.
+//
+// The start must be positive and finite, the factor greater than one,
+// and count positive.  Because the boundaries grow exponentially, a
+// large count overflows the float64 range; in that case, and in any
+// other case where the boundaries would not be finite and strictly
+// increasing, an error wrapping ErrInvalidBounds is returned instead of
+// a partial result.
+func ExponentialBounds(start, factor float64, count int) ([]float64, error) {
+	if count < 1 || !(start > 0) || math.IsInf(start, 0) || !(factor > 1) || math.IsInf(factor, 0) {
+		return nil, fmt.Errorf("%w: exponential start=%v factor=%v count=%d", ErrInvalidBounds, start, factor, count)
+	}
+	return generateBounds(count, func(i int, prev float64) float64 {
+		if i == 0 {
+			return start
+		}
+		return prev * factor
+	})
+}
+
+// LinearBounds returns count boundaries, starting with start and adding
+// step to compute each next boundary, i.e., start+i*step for i in
+// [0, count).  The result can be passed to WithExplicitBoundaries.
+//
+// The start and step must be finite, the step positive, and count
+// positive.  Each boundary is computed independently, so rounding error
+// does not accumulate.  When a large count overflows the float64 range,
+// or when the step is too small relative to the boundaries to produce
+// distinct values, an error wrapping ErrInvalidBounds is returned
+// instead of a partial result.
+func LinearBounds(start, step float64, count int) ([]float64, error) {
+	if count < 1 || math.IsNaN(start) || math.IsInf(start, 0) || !(step > 0) || math.IsInf(step, 0) {
+		return nil, fmt.Errorf("%w: linear start=%v step=%v count=%d", ErrInvalidBounds, start, step, count)
+	}
+	return generateBounds(count, func(i int, _ float64) float64 {
+		return start + float64(i)*step
+	})
+}
+
+// generateBounds returns the count boundaries computed by bound from
+// their index and the previous boundary.  The boundaries are validated
+// before the result is allocated, so that an invalid count does not
+// allocate.
+func generateBounds(count int, bound func(i int, prev float64) float64) ([]float64, error) {
+	var prev float64
+	for i := 0; i < count; i++ {
+		b := bound(i, prev)
+		if err := validateBound(i, b, prev); err != nil {
+			return nil, err
+		}
+		prev = b
+	}
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = bound(i, prev)
+		prev = bounds[i]
+	}
+	return bounds, nil
+}
+
+// ValidateBounds returns an error wrapping ErrInvalidBounds unless bounds
+// are finite and strictly increasing, as WithExplicitBoundaries expects.
+func ValidateBounds(bounds []float64) error {
+	var prev float64
+	for i, b := range bounds {
+		if err := validateBound(i, b, prev); err != nil {
+			return err
+		}
+		prev = b
+	}
+	return nil
+}
+
+// validateBound returns an error unless the boundary b of index i is
+// finite and, unless it is the first, greater than the previous boundary
+// prev.
+func validateBound(i int, b, prev float64) error {
+	if math.IsNaN(b) || math.IsInf(b, 0) {
+		return fmt.Errorf("%w: boundary %d is not finite", ErrInvalidBounds, i)
+	}
+	if i > 0 && !(b > prev) {
+		return fmt.Errorf("%w: boundary %d (%v) is not greater than boundary %d", ErrInvalidBounds, i, b, i-1)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogram_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+func TestExponentialBounds(t *testing.T) {
+	bounds, err := histogram.ExponentialBounds(1, 2, 5)
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 4, 8, 16}, bounds)
+
+	bounds, err = histogram.ExponentialBounds(0.001, 10, 4)
+	require.NoError(t, err)
+	require.InDeltaSlice(t, []float64{0.001, 0.01, 0.1, 1}, bounds, 1e-12)
+
+	for _, tc := range []struct {
+		name          string
+		start, factor float64
+		count         int
+	}{
+		{"zero count", 1, 2, 0},
+		{"zero start", 0, 2, 3},
+		{"negative start", -1, 2, 3},
+		{"infinite start", math.Inf(1), 2, 3},
+		{"NaN start", math.NaN(), 2, 3},
+		{"unit factor", 1, 1, 3},
+		{"infinite factor", 1, math.Inf(1), 3},
+		{"overflow", 1, 2, 2000},
+		{"huge count", 1, 2, math.MaxInt},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := histogram.ExponentialBounds(tc.start, tc.factor, tc.count)
+			require.ErrorIs(t, err, histogram.ErrInvalidBounds)
+		})
+	}
+}
+
+func TestLinearBounds(t *testing.T) {
+	bounds, err := histogram.LinearBounds(-10, 5, 5)
+	require.NoError(t, err)
+	require.Equal(t, []float64{-10, -5, 0, 5, 10}, bounds)
+
+	// Rounding error does not accumulate.
+	bounds, err = histogram.LinearBounds(0, 0.1, 1001)
+	require.NoError(t, err)
+	require.Equal(t, 100.0, bounds[1000])
+
+	for _, tc := range []struct {
+		name        string
+		start, step float64
+		count       int
+	}{
+		{"zero count", 0, 1, 0},
+		{"zero step", 0, 0, 3},
+		{"negative step", 0, -1, 3},
+		{"NaN start", math.NaN(), 1, 3},
+		{"infinite step", 0, math.Inf(1), 3},
+		{"indistinct", 1e20, 1, 3},
+		{"overflow", math.MaxFloat64 / 2, math.MaxFloat64 / 2, 4},
+		{"huge count", math.MaxFloat64 / 2, math.MaxFloat64 / 2, math.MaxInt},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := histogram.LinearBounds(tc.start, tc.step, tc.count)
+			require.ErrorIs(t, err, histogram.ErrInvalidBounds)
+		})
+	}
+}
+
+func TestValidateBounds(t *testing.T) {
+	require.NoError(t, histogram.ValidateBounds(nil))
+	require.NoError(t, histogram.ValidateBounds([]float64{-1, 0, 0.5}))
+	for _, bounds := range [][]float64{
+		{1, 1},
+		{2, 1},
+		{math.NaN()},
+		{0, math.Inf(1)},
+		{math.Inf(-1), 0},
+	} {
+		require.ErrorIs(t, histogram.ValidateBounds(bounds), histogram.ErrInvalidBounds, "%v", bounds)
+	}
+}
+
+func TestGeneratedBoundsInHistogram(t *testing.T) {
+	bounds, err := histogram.ExponentialBounds(1, 10, 3)
+	require.NoError(t, err)
+
+	desc := sdkapi.NewDescriptor("name", sdkapi.HistogramInstrumentKind, number.Float64Kind, "", "")
+	agg, ckpt := new2(&desc, histogram.WithExplicitBoundaries(bounds))
+
+	for _, v := range []float64{0.5, 5, 50, 500} {
+		require.NoError(t, agg.Update(context.Background(), number.NewFloat64Number(v), &desc))
+	}
+	require.NoError(t, agg.SynchronizedMove(ckpt, &desc))
+
+	buckets, err := ckpt.Histogram()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 10, 100}, buckets.Boundaries)
+	require.Equal(t, []uint64{1, 1, 1, 1}, buckets.Counts)
+}
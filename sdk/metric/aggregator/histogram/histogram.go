@@ -16,10 +16,12 @@ package histogram // import "go.opentelemetry.io/otel/sdk/metric/aggregator/hist
 
 import (
 	"context"
+	"math"
 	"sort"
 	"sync"
 
 	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/exemplar"
 	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
 	"go.opentelemetry.io/otel/sdk/metric/number"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
@@ -37,6 +39,7 @@ type (
 		lock       sync.Mutex
 		boundaries []float64
 		kind       number.Kind
+		exemplars  bool
 		state      *state
 	}
 
@@ -45,6 +48,9 @@ type (
 		// explicitBoundaries support arbitrary bucketing schemes.  This
 		// is the general case.
 		explicitBoundaries []float64
+
+		// exemplars enables the exemplar of each bucket.
+		exemplars bool
 	}
 
 	// Option configures a histogram config.
@@ -60,6 +66,10 @@ type (
 		bucketCounts []uint64
 		sum          number.Number
 		count        uint64
+
+		// exemplars holds the last exemplar of each bucket, if
+		// enabled.  Exemplars with a zero Time are unset.
+		exemplars []aggregation.Exemplar
 	}
 )
 
@@ -76,6 +86,18 @@ func (o explicitBoundariesOption) apply(config *config) {
 	config.explicitBoundaries = o.boundaries
 }
 
+// WithExemplars makes the histogram keep the last measurement made in a
+// sampled span in each bucket, see aggregation.Exemplars.
+func WithExemplars() Option {
+	return exemplarsOption{}
+}
+
+type exemplarsOption struct{}
+
+func (exemplarsOption) apply(config *config) {
+	config.exemplars = true
+}
+
 // defaultExplicitBoundaries have been copied from prometheus.DefBuckets.
 //
 // Note we anticipate the use of a high-precision histogram sketch as
@@ -100,6 +122,7 @@ var _ aggregator.Aggregator = &Aggregator{}
 var _ aggregation.Sum = &Aggregator{}
 var _ aggregation.Count = &Aggregator{}
 var _ aggregation.Histogram = &Aggregator{}
+var _ aggregation.Exemplars = &Aggregator{}
 
 // New returns a new aggregator for computing Histograms.
 //
@@ -135,6 +158,7 @@ func New(cnt int, desc *sdkapi.Descriptor, opts ...Option) []Aggregator {
 		aggs[i] = Aggregator{
 			kind:       desc.NumberKind(),
 			boundaries: sortedBoundaries,
+			exemplars:  cfg.exemplars,
 		}
 		aggs[i].state = aggs[i].newState()
 	}
@@ -161,14 +185,29 @@ func (c *Aggregator) Count() (uint64, error) {
 	return c.state.count, nil
 }
 
-// Histogram returns the count of events in pre-determined buckets.
+// Histogram returns the count of events in pre-determined buckets, and
+// their exemplars when the histogram was configured WithExemplars.
 func (c *Aggregator) Histogram() (aggregation.Buckets, error) {
 	return aggregation.Buckets{
 		Boundaries: c.boundaries,
 		Counts:     c.state.bucketCounts,
+		Exemplars:  c.state.exemplars,
 	}, nil
 }
 
+// Exemplars returns the exemplars in the checkpoint, at most one per
+// bucket in the order of the buckets.  There are none unless the
+// histogram was configured WithExemplars.
+func (c *Aggregator) Exemplars() ([]aggregation.Exemplar, error) {
+	var exemplars []aggregation.Exemplar
+	for _, e := range c.state.exemplars {
+		if !e.Time.IsZero() {
+			exemplars = append(exemplars, e)
+		}
+	}
+	return exemplars, nil
+}
+
 // SynchronizedMove saves the current state into oa and resets the current state to
 // the empty set.  Since no locks are taken, there is a chance that
 // the independent Sum, Count and Bucket Count are not consistent with each
@@ -205,24 +244,40 @@ func (c *Aggregator) SynchronizedMove(oa aggregator.Aggregator, desc *sdkapi.Des
 }
 
 func (c *Aggregator) newState() *state {
-	return &state{
+	s := &state{
 		bucketCounts: make([]uint64, len(c.boundaries)+1),
 	}
+	if c.exemplars {
+		s.exemplars = make([]aggregation.Exemplar, len(s.bucketCounts))
+	}
+	return s
 }
 
 func (c *Aggregator) clearState() {
 	for i := range c.state.bucketCounts {
 		c.state.bucketCounts[i] = 0
 	}
+	for i := range c.state.exemplars {
+		c.state.exemplars[i] = aggregation.Exemplar{}
+	}
 	c.state.sum = 0
 	c.state.count = 0
 }
 
 // Update adds the recorded measurement to the current data set.
-func (c *Aggregator) Update(_ context.Context, n number.Number, desc *sdkapi.Descriptor) error {
+func (c *Aggregator) Update(ctx context.Context, n number.Number, desc *sdkapi.Descriptor) error {
 	kind := desc.NumberKind()
 	asFloat := n.CoerceToFloat64(kind)
 
+	// NaN and infinite values have no bucket and would make the sum
+	// meaningless; they are rejected without modifying the state.
+	if math.IsNaN(asFloat) {
+		return aggregation.ErrNaNInput
+	}
+	if math.IsInf(asFloat, 0) {
+		return aggregation.ErrInfInput
+	}
+
 	bucketID := len(c.boundaries)
 	for i, boundary := range c.boundaries {
 		if asFloat < boundary {
@@ -242,12 +297,21 @@ func (c *Aggregator) Update(_ context.Context, n number.Number, desc *sdkapi.Des
 	// 256 and 512 elements, which is a relatively large histogram, so we
 	// continue to prefer linear search.
 
+	var e aggregation.Exemplar
+	sampled := false
+	if c.exemplars {
+		e, sampled = exemplar.Sample(ctx, n)
+	}
+
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
 	c.state.count++
 	c.state.sum.AddNumber(kind, n)
 	c.state.bucketCounts[bucketID]++
+	if sampled {
+		c.state.exemplars[bucketID] = e
+	}
 
 	return nil
 }
@@ -265,5 +329,14 @@ func (c *Aggregator) Merge(oa aggregator.Aggregator, desc *sdkapi.Descriptor) er
 	for i := 0; i < len(c.state.bucketCounts); i++ {
 		c.state.bucketCounts[i] += o.state.bucketCounts[i]
 	}
+	if c.state.exemplars != nil {
+		// Keep the last exemplar of each bucket, that of o
+		// when they are as recent.
+		for i, e := range o.state.exemplars {
+			if !e.Time.IsZero() && !e.Time.Before(c.state.exemplars[i].Time) {
+				c.state.exemplars[i] = e
+			}
+		}
+	}
 	return nil
 }
@@ -26,8 +26,10 @@ import (
 	"go.opentelemetry.io/otel/sdk/metric/aggregator"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/aggregatortest"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
 	"go.opentelemetry.io/otel/sdk/metric/number"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const count = 100
@@ -293,3 +295,99 @@ func TestHistogramDefaultBoundaries(t *testing.T) {
 		require.EqualValues(t, expect, bucks.Counts)
 	})
 }
+
+func TestHistogramInvalidInput(t *testing.T) {
+	ctx := context.Background()
+	descriptor := aggregatortest.NewAggregatorTest(sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	agg, ckpt := new2(descriptor, histogram.WithExplicitBoundaries(testBoundaries))
+
+	require.NoError(t, agg.Update(ctx, number.NewFloat64Number(100), descriptor))
+	require.ErrorIs(t, agg.Update(ctx, number.NewFloat64Number(math.NaN()), descriptor), aggregation.ErrNaNInput)
+	require.ErrorIs(t, agg.Update(ctx, number.NewFloat64Number(math.Inf(+1)), descriptor), aggregation.ErrInfInput)
+	require.ErrorIs(t, agg.Update(ctx, number.NewFloat64Number(math.Inf(-1)), descriptor), aggregation.ErrInfInput)
+	require.NoError(t, agg.Update(ctx, number.NewFloat64Number(1000), descriptor))
+
+	require.NoError(t, agg.SynchronizedMove(ckpt, descriptor))
+
+	sum, err := ckpt.Sum()
+	require.NoError(t, err)
+	require.Equal(t, 1100.0, sum.AsFloat64())
+
+	count, err := ckpt.Count()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), count)
+
+	buckets, err := ckpt.Histogram()
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 0, 0, 1}, buckets.Counts)
+}
+
+func TestHistogramExemplars(t *testing.T) {
+	ctx := context.Background()
+	descriptor := aggregatortest.NewAggregatorTest(sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	agg, ckpt := new2(descriptor, histogram.WithExplicitBoundaries(testBoundaries), histogram.WithExemplars())
+
+	spanContext := func(id byte, sampled bool) trace.SpanContext {
+		cfg := trace.SpanContextConfig{
+			TraceID: trace.TraceID{id},
+			SpanID:  trace.SpanID{id},
+		}
+		if sampled {
+			cfg.TraceFlags = trace.FlagsSampled
+		}
+		return trace.NewSpanContext(cfg)
+	}
+	record := func(agg *histogram.Aggregator, v float64, sc trace.SpanContext) {
+		require.NoError(t, agg.Update(trace.ContextWithSpanContext(ctx, sc), number.NewFloat64Number(v), descriptor))
+	}
+
+	// Buckets: (-Inf, 250), [250, 500), [500, 750), [750, +Inf).
+	record(agg, 100, spanContext(1, true))
+	record(agg, 200, spanContext(2, true))
+	record(agg, 300, spanContext(3, false))
+	record(agg, 800, spanContext(4, true))
+	require.NoError(t, agg.Update(ctx, number.NewFloat64Number(600), descriptor))
+	require.NoError(t, agg.SynchronizedMove(ckpt, descriptor))
+
+	exemplars, err := ckpt.Exemplars()
+	require.NoError(t, err)
+	require.Len(t, exemplars, 2)
+	require.Equal(t, 200.0, exemplars[0].Value.AsFloat64())
+	require.Equal(t, spanContext(2, true), exemplars[0].SpanContext)
+	require.Equal(t, 800.0, exemplars[1].Value.AsFloat64())
+	require.Equal(t, spanContext(4, true), exemplars[1].SpanContext)
+
+	// The buckets hold their exemplar.
+	buckets, err := ckpt.Histogram()
+	require.NoError(t, err)
+	require.Len(t, buckets.Exemplars, len(buckets.Counts))
+	require.Equal(t, spanContext(2, true), buckets.Exemplars[0].SpanContext)
+	require.True(t, buckets.Exemplars[1].Time.IsZero())
+	require.True(t, buckets.Exemplars[2].Time.IsZero())
+	require.Equal(t, spanContext(4, true), buckets.Exemplars[3].SpanContext)
+
+	exemplars, err = agg.Exemplars()
+	require.NoError(t, err)
+	require.Empty(t, exemplars)
+
+	// Merging keeps the last exemplar of each bucket.
+	record(agg, 700, spanContext(5, true))
+	record(agg, 900, spanContext(6, true))
+	require.NoError(t, ckpt.Merge(agg, descriptor))
+	exemplars, err = ckpt.Exemplars()
+	require.NoError(t, err)
+	require.Len(t, exemplars, 3)
+	require.Equal(t, spanContext(2, true), exemplars[0].SpanContext)
+	require.Equal(t, spanContext(5, true), exemplars[1].SpanContext)
+	require.Equal(t, spanContext(6, true), exemplars[2].SpanContext)
+
+	// Exemplars are disabled by default.
+	plain, _ := new2(descriptor)
+	record(plain, 100, spanContext(1, true))
+	exemplars, err = plain.Exemplars()
+	require.NoError(t, err)
+	require.Empty(t, exemplars)
+	buckets, err = plain.Histogram()
+	require.NoError(t, err)
+	require.Nil(t, buckets.Exemplars)
+}
@@ -0,0 +1,166 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minmaxsumcount_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/aggregatortest"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/minmaxsumcount"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+type summary struct {
+	min, max, sum number.Number
+	count         uint64
+}
+
+func check(t *testing.T, agg *minmaxsumcount.Aggregator, expect summary) {
+	min, err := agg.Min()
+	require.NoError(t, err)
+	require.Equal(t, expect.min, min, "min")
+	max, err := agg.Max()
+	require.NoError(t, err)
+	require.Equal(t, expect.max, max, "max")
+	sum, err := agg.Sum()
+	require.NoError(t, err)
+	require.Equal(t, expect.sum, sum, "sum")
+	count, err := agg.Count()
+	require.NoError(t, err)
+	require.Equal(t, expect.count, count, "count")
+}
+
+func TestInt64(t *testing.T) {
+	desc := aggregatortest.NewAggregatorTest(sdkapi.HistogramInstrumentKind, number.Int64Kind)
+	for _, tc := range []struct {
+		name   string
+		values []int64
+		expect summary
+	}{
+		{
+			name:   "positive",
+			values: []int64{5, 3, 9, 7},
+			expect: summary{number.NewInt64Number(3), number.NewInt64Number(9), number.NewInt64Number(24), 4},
+		},
+		{
+			name:   "negative",
+			values: []int64{-5, -3, -9, -7},
+			expect: summary{number.NewInt64Number(-9), number.NewInt64Number(-3), number.NewInt64Number(-24), 4},
+		},
+		{
+			name:   "mixed",
+			values: []int64{2, -4, 0},
+			expect: summary{number.NewInt64Number(-4), number.NewInt64Number(2), number.NewInt64Number(-2), 3},
+		},
+		{
+			name:   "single",
+			values: []int64{-1},
+			expect: summary{number.NewInt64Number(-1), number.NewInt64Number(-1), number.NewInt64Number(-1), 1},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			aggs := minmaxsumcount.New(2, desc)
+			agg, ckpt := &aggs[0], &aggs[1]
+			for _, v := range tc.values {
+				aggregatortest.CheckedUpdate(t, agg, number.NewInt64Number(v), desc)
+			}
+			require.NoError(t, agg.SynchronizedMove(ckpt, desc))
+			check(t, ckpt, tc.expect)
+		})
+	}
+}
+
+func TestFloat64(t *testing.T) {
+	desc := aggregatortest.NewAggregatorTest(sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	for _, tc := range []struct {
+		name   string
+		values []float64
+		expect summary
+	}{
+		{
+			name:   "positive",
+			values: []float64{0.5, 2.25, 1},
+			expect: summary{number.NewFloat64Number(0.5), number.NewFloat64Number(2.25), number.NewFloat64Number(3.75), 3},
+		},
+		{
+			name:   "negative",
+			values: []float64{-0.5, -2.25, -1},
+			expect: summary{number.NewFloat64Number(-2.25), number.NewFloat64Number(-0.5), number.NewFloat64Number(-3.75), 3},
+		},
+		{
+			name:   "mixed",
+			values: []float64{-1.5, 0, 4},
+			expect: summary{number.NewFloat64Number(-1.5), number.NewFloat64Number(4), number.NewFloat64Number(2.5), 3},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			aggs := minmaxsumcount.New(2, desc)
+			agg, ckpt := &aggs[0], &aggs[1]
+			for _, v := range tc.values {
+				aggregatortest.CheckedUpdate(t, agg, number.NewFloat64Number(v), desc)
+			}
+			require.NoError(t, agg.SynchronizedMove(ckpt, desc))
+			check(t, ckpt, tc.expect)
+		})
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	desc := aggregatortest.NewAggregatorTest(sdkapi.HistogramInstrumentKind, number.Int64Kind)
+	agg := &minmaxsumcount.New(1, desc)[0]
+
+	_, err := agg.Min()
+	require.ErrorIs(t, err, aggregation.ErrNoData)
+	_, err = agg.Max()
+	require.ErrorIs(t, err, aggregation.ErrNoData)
+	count, err := agg.Count()
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestMerge(t *testing.T) {
+	desc := aggregatortest.NewAggregatorTest(sdkapi.HistogramInstrumentKind, number.Int64Kind)
+	aggs := minmaxsumcount.New(3, desc)
+	agg1, agg2, empty := &aggs[0], &aggs[1], &aggs[2]
+
+	for _, v := range []int64{-2, -8} {
+		aggregatortest.CheckedUpdate(t, agg1, number.NewInt64Number(v), desc)
+	}
+	for _, v := range []int64{-5, -1} {
+		aggregatortest.CheckedUpdate(t, agg2, number.NewInt64Number(v), desc)
+	}
+
+	aggregatortest.CheckedMerge(t, agg1, agg2, desc)
+	check(t, agg1, summary{number.NewInt64Number(-8), number.NewInt64Number(-1), number.NewInt64Number(-16), 4})
+
+	// Merging no values changes nothing.
+	aggregatortest.CheckedMerge(t, agg1, empty, desc)
+	check(t, agg1, summary{number.NewInt64Number(-8), number.NewInt64Number(-1), number.NewInt64Number(-16), 4})
+}
+
+func TestSynchronizedMoveReset(t *testing.T) {
+	aggregatortest.SynchronizedMoveResetTest(
+		t,
+		sdkapi.HistogramInstrumentKind,
+		func(desc *sdkapi.Descriptor) aggregator.Aggregator {
+			return &minmaxsumcount.New(1, desc)[0]
+		},
+	)
+}
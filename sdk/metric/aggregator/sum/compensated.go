@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sum // import "go.opentelemetry.io/otel/sdk/metric/aggregator/sum"
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+// CompensatedAggregator aggregates counter events using compensated
+// (Kahan-Babuška-Neumaier) summation for floating point instruments.
+//
+// The error of a naive floating point sum grows with the number of
+// values added, and depends on the order they are added in.  The error of
+// a compensated sum is bounded independently of the number of values,
+// which makes results reproducible up to the last few bits.  The cost is
+// a lock around each update, where the Aggregator uses a single atomic
+// operation, and twice the state per record.  Integer instruments are
+// summed exactly, and saturate on overflow, as by the Aggregator.
+type CompensatedAggregator struct {
+	lock sync.Mutex
+	kind number.Kind
+
+	// value holds the sum for integer instruments.
+	value number.Number
+
+	// sum and compensation hold the sum for floating point
+	// instruments: the running sum and the low-order bits lost
+	// while computing it.
+	sum          float64
+	compensation float64
+
+	// overflowed is set once the integer sum saturated.
+	overflowed bool
+}
+
+var _ aggregator.Aggregator = &CompensatedAggregator{}
+var _ aggregation.Sum = &CompensatedAggregator{}
+
+// NewCompensated returns cnt new compensated sum aggregators for the
+// instrument described by desc.  These aggregators implement the
+// aggregation.Sum export interface.
+func NewCompensated(cnt int, desc *sdkapi.Descriptor) []CompensatedAggregator {
+	aggs := make([]CompensatedAggregator, cnt)
+	for i := range aggs {
+		aggs[i].kind = desc.NumberKind()
+	}
+	return aggs
+}
+
+// Aggregation returns an interface for reading the state of this aggregator.
+func (c *CompensatedAggregator) Aggregation() aggregation.Aggregation {
+	return c
+}
+
+// Kind returns aggregation.SumKind.
+func (c *CompensatedAggregator) Kind() aggregation.Kind {
+	return aggregation.SumKind
+}
+
+// Sum returns the last-checkpointed sum.  This will never return an
+// error.
+func (c *CompensatedAggregator) Sum() (number.Number, error) {
+	if c.kind == number.Float64Kind {
+		return number.NewFloat64Number(c.sum + c.compensation), nil
+	}
+	return c.value, nil
+}
+
+// SynchronizedMove saves the current value into oa and resets the current
+// sum to zero.
+func (c *CompensatedAggregator) SynchronizedMove(oa aggregator.Aggregator, _ *sdkapi.Descriptor) error {
+	var o *CompensatedAggregator
+	if oa != nil {
+		o, _ = oa.(*CompensatedAggregator)
+		if o == nil {
+			return aggregator.NewInconsistentAggregatorError(c, oa)
+		}
+	}
+
+	c.lock.Lock()
+	if o != nil {
+		o.value, o.sum, o.compensation = c.value, c.sum, c.compensation
+	}
+	c.value, c.sum, c.compensation = 0, 0, 0
+	c.lock.Unlock()
+
+	return nil
+}
+
+// Update adds num to the current value.
+func (c *CompensatedAggregator) Update(_ context.Context, num number.Number, desc *sdkapi.Descriptor) error {
+	c.lock.Lock()
+	if desc.NumberKind() != number.Float64Kind {
+		c.addInt64(num.AsInt64(), desc)
+	} else {
+		c.addFloat64(num.AsFloat64())
+	}
+	c.lock.Unlock()
+	return nil
+}
+
+// Merge combines two counters by adding their sums.
+func (c *CompensatedAggregator) Merge(oa aggregator.Aggregator, desc *sdkapi.Descriptor) error {
+	o, _ := oa.(*CompensatedAggregator)
+	if o == nil {
+		return aggregator.NewInconsistentAggregatorError(c, oa)
+	}
+	if desc.NumberKind() != number.Float64Kind {
+		c.addInt64(o.value.AsInt64(), desc)
+		return nil
+	}
+	c.addFloat64(o.sum)
+	c.addFloat64(o.compensation)
+	return nil
+}
+
+// addInt64 adds i to the integer sum, saturating it on overflow.
+func (c *CompensatedAggregator) addInt64(i int64, desc *sdkapi.Descriptor) {
+	sum, ok := addInt64(c.value.AsInt64(), i)
+	c.value.SetInt64(sum)
+	if !ok && !c.overflowed {
+		c.overflowed = true
+		reportOverflow(desc)
+	}
+}
+
+// addFloat64 adds x to the sum using the Neumaier variant of Kahan
+// summation, which also compensates when x is larger than the sum.
+func (c *CompensatedAggregator) addFloat64(x float64) {
+	t := c.sum + x
+	if math.Abs(c.sum) >= math.Abs(x) {
+		c.compensation += (c.sum - t) + x
+	} else {
+		c.compensation += (x - t) + c.sum
+	}
+	c.sum = t
+}
@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sum
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/aggregatortest"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+func TestCompensatedSum(t *testing.T) {
+	aggregatortest.RunProfiles(t, func(t *testing.T, profile aggregatortest.Profile) {
+		descriptor := aggregatortest.NewAggregatorTest(sdkapi.CounterInstrumentKind, profile.NumberKind)
+		aggs := NewCompensated(3, descriptor)
+		agg, ckpt, merged := &aggs[0], &aggs[1], &aggs[2]
+
+		sum := number.Number(0)
+		for i := 0; i < count; i++ {
+			x := profile.Random(+1)
+			sum.AddNumber(profile.NumberKind, x)
+			aggregatortest.CheckedUpdate(t, agg, x, descriptor)
+		}
+
+		require.NoError(t, agg.SynchronizedMove(ckpt, descriptor))
+		require.NoError(t, merged.Merge(ckpt, descriptor))
+		require.NoError(t, merged.Merge(ckpt, descriptor))
+
+		asum, err := agg.Sum()
+		require.NoError(t, err)
+		require.Equal(t, profile.NumberKind.Zero(), asum)
+
+		csum, err := ckpt.Sum()
+		require.NoError(t, err)
+		require.InEpsilon(t, sum.CoerceToFloat64(profile.NumberKind), csum.CoerceToFloat64(profile.NumberKind), 0.000000001)
+
+		msum, err := merged.Sum()
+		require.NoError(t, err)
+		require.InEpsilon(t, 2*sum.CoerceToFloat64(profile.NumberKind), msum.CoerceToFloat64(profile.NumberKind), 0.000000001)
+
+		require.NoError(t, ckpt.SynchronizedMove(nil, descriptor))
+		csum, err = ckpt.Sum()
+		require.NoError(t, err)
+		require.Equal(t, profile.NumberKind.Zero(), csum)
+	})
+}
+
+func TestCompensatedSumError(t *testing.T) {
+	const (
+		n    = 1000000
+		tiny = 0.1
+	)
+	ctx := context.Background()
+	descriptor := aggregatortest.NewAggregatorTest(sdkapi.CounterInstrumentKind, number.Float64Kind)
+	naive := &New(1)[0]
+	comp := &NewCompensated(1, descriptor)[0]
+
+	// A large first value makes each tiny value lose most of its
+	// bits in a naive sum.
+	for _, agg := range []interface {
+		Update(context.Context, number.Number, *sdkapi.Descriptor) error
+	}{naive, comp} {
+		require.NoError(t, agg.Update(ctx, number.NewFloat64Number(1e9), descriptor))
+		for i := 0; i < n; i++ {
+			require.NoError(t, agg.Update(ctx, number.NewFloat64Number(tiny), descriptor))
+		}
+	}
+
+	const expect = 1e9 + n*tiny
+	naiveSum, err := naive.Sum()
+	require.NoError(t, err)
+	compSum, err := comp.Sum()
+	require.NoError(t, err)
+
+	naiveErr := math.Abs(naiveSum.AsFloat64() - expect)
+	compErr := math.Abs(compSum.AsFloat64() - expect)
+
+	// The compensated sum is correctly rounded, within one ulp.
+	require.LessOrEqual(t, compErr, math.Nextafter(expect, math.Inf(1))-expect)
+	require.Greater(t, naiveErr, 1000*compErr+1e-3)
+}
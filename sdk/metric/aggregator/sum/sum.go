@@ -16,22 +16,50 @@ package sum // import "go.opentelemetry.io/otel/sdk/metric/aggregator/sum"
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
 
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/exemplar"
 	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
 	"go.opentelemetry.io/otel/sdk/metric/number"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
 )
 
 // Aggregator aggregates counter events.
+//
+// The sum of an integer instrument saturates at the minimum or maximum
+// int64 instead of wrapping around, e.g., when a long-lived process sums
+// bytes, and the overflow is reported once per Aggregator to the global
+// error handler with aggregation.ErrSumOverflow.  The sum keeps its
+// number kind, so that exporters are passed the saturated int64.
 type Aggregator struct {
 	// current holds current increments to this counter record
 	// current needs to be aligned for 64-bit atomic operations.
 	value number.Number
+
+	// exemplars, if not nil, samples the measurements made in
+	// sampled spans, see NewWithExemplars.
+	exemplars *exemplars
+
+	// overflowed is set to one, atomically, once the sum
+	// saturated.
+	overflowed int32
+}
+
+// exemplars is the exemplar reservoir of an Aggregator.  Unlike the
+// sum, it is updated under a lock.
+type exemplars struct {
+	lock      sync.Mutex
+	reservoir *exemplar.Reservoir
 }
 
 var _ aggregator.Aggregator = &Aggregator{}
 var _ aggregation.Sum = &Aggregator{}
+var _ aggregation.Exemplars = &Aggregator{}
 
 // New returns a new counter aggregator implemented by atomic
 // operations.  This aggregator implements the aggregation.Sum
@@ -40,6 +68,17 @@ func New(cnt int) []Aggregator {
 	return make([]Aggregator, cnt)
 }
 
+// NewWithExemplars returns new counter aggregators like New that also
+// keep a sample of at most size of the measurements made in sampled
+// spans, see aggregation.Exemplars.
+func NewWithExemplars(cnt int, size int) []Aggregator {
+	aggs := New(cnt)
+	for i := range aggs {
+		aggs[i].exemplars = &exemplars{reservoir: exemplar.NewReservoir(size)}
+	}
+	return aggs
+}
+
 // Aggregation returns an interface for reading the state of this aggregator.
 func (c *Aggregator) Aggregation() aggregation.Aggregation {
 	return c
@@ -56,11 +95,26 @@ func (c *Aggregator) Sum() (number.Number, error) {
 	return c.value, nil
 }
 
+// Exemplars returns the last-checkpointed exemplars, if the aggregator
+// was created by NewWithExemplars.  This will never return an error.
+func (c *Aggregator) Exemplars() ([]aggregation.Exemplar, error) {
+	if c.exemplars == nil {
+		return nil, nil
+	}
+	return c.exemplars.reservoir.Exemplars(), nil
+}
+
 // SynchronizedMove atomically saves the current value into oa and resets the
-// current sum to zero.
+// current sum to zero.  The exemplars are moved separately, under a
+// lock.
 func (c *Aggregator) SynchronizedMove(oa aggregator.Aggregator, _ *sdkapi.Descriptor) error {
 	if oa == nil {
 		c.value.SetRawAtomic(0)
+		if c.exemplars != nil {
+			c.exemplars.lock.Lock()
+			c.exemplars.reservoir.Reset()
+			c.exemplars.lock.Unlock()
+		}
 		return nil
 	}
 	o, _ := oa.(*Aggregator)
@@ -68,12 +122,29 @@ func (c *Aggregator) SynchronizedMove(oa aggregator.Aggregator, _ *sdkapi.Descri
 		return aggregator.NewInconsistentAggregatorError(c, oa)
 	}
 	o.value = c.value.SwapNumberAtomic(number.Number(0))
+	if c.exemplars != nil && o.exemplars != nil {
+		o.exemplars.reservoir.Reset()
+		c.exemplars.lock.Lock()
+		c.exemplars.reservoir, o.exemplars.reservoir = o.exemplars.reservoir, c.exemplars.reservoir
+		c.exemplars.lock.Unlock()
+	}
 	return nil
 }
 
 // Update atomically adds to the current value.
-func (c *Aggregator) Update(_ context.Context, num number.Number, desc *sdkapi.Descriptor) error {
-	c.value.AddNumberAtomic(desc.NumberKind(), num)
+func (c *Aggregator) Update(ctx context.Context, num number.Number, desc *sdkapi.Descriptor) error {
+	if desc.NumberKind() == number.Int64Kind {
+		c.addInt64Atomic(num.AsInt64(), desc)
+	} else {
+		c.value.AddNumberAtomic(desc.NumberKind(), num)
+	}
+	if c.exemplars != nil {
+		if e, ok := exemplar.Sample(ctx, num); ok {
+			c.exemplars.lock.Lock()
+			c.exemplars.reservoir.Offer(e)
+			c.exemplars.lock.Unlock()
+		}
+	}
 	return nil
 }
 
@@ -83,6 +154,59 @@ func (c *Aggregator) Merge(oa aggregator.Aggregator, desc *sdkapi.Descriptor) er
 	if o == nil {
 		return aggregator.NewInconsistentAggregatorError(c, oa)
 	}
-	c.value.AddNumber(desc.NumberKind(), o.value)
+	if desc.NumberKind() == number.Int64Kind {
+		sum, ok := addInt64(c.value.AsInt64(), o.value.AsInt64())
+		c.value.SetInt64(sum)
+		if !ok {
+			c.overflow(desc)
+		}
+	} else {
+		c.value.AddNumber(desc.NumberKind(), o.value)
+	}
+	if c.exemplars != nil && o.exemplars != nil {
+		c.exemplars.reservoir.Merge(o.exemplars.reservoir)
+	}
 	return nil
 }
+
+// addInt64Atomic atomically adds i to the current value, saturating it
+// on overflow.  The overflow is detected from the signs of i and of the
+// change of the value, so that the common case is a single atomic add;
+// concurrent readers may briefly see the wrapped sum before it is
+// saturated.
+func (c *Aggregator) addInt64Atomic(i int64, desc *sdkapi.Descriptor) {
+	sum := atomic.AddInt64(c.value.AsInt64Ptr(), i)
+	switch old := sum - i; {
+	case i > 0 && sum < old:
+		c.value.SetInt64Atomic(math.MaxInt64)
+	case i < 0 && sum > old:
+		c.value.SetInt64Atomic(math.MinInt64)
+	default:
+		return
+	}
+	c.overflow(desc)
+}
+
+// overflow reports the first overflow of c.
+func (c *Aggregator) overflow(desc *sdkapi.Descriptor) {
+	if atomic.CompareAndSwapInt32(&c.overflowed, 0, 1) {
+		reportOverflow(desc)
+	}
+}
+
+// addInt64 returns a+b, saturated at the minimum or maximum int64, and
+// false when the sum overflowed.
+func addInt64(a, b int64) (int64, bool) {
+	sum := a + b
+	switch {
+	case b > 0 && sum < a:
+		return math.MaxInt64, false
+	case b < 0 && sum > a:
+		return math.MinInt64, false
+	}
+	return sum, true
+}
+
+func reportOverflow(desc *sdkapi.Descriptor) {
+	otel.Handle(fmt.Errorf("%s: %w", desc.Name(), aggregation.ErrSumOverflow))
+}
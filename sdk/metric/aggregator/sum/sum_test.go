@@ -15,17 +15,22 @@
 package sum
 
 import (
+	"context"
+	"math"
 	"os"
 	"testing"
 	"unsafe"
 
 	"github.com/stretchr/testify/require"
 
+	"go.opentelemetry.io/otel"
 	ottest "go.opentelemetry.io/otel/internal/internaltest"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/aggregatortest"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
 	"go.opentelemetry.io/otel/sdk/metric/number"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const count = 100
@@ -152,3 +157,123 @@ func TestSynchronizedMoveReset(t *testing.T) {
 		},
 	)
 }
+
+func TestExemplars(t *testing.T) {
+	descriptor := aggregatortest.NewAggregatorTest(sdkapi.CounterInstrumentKind, number.Int64Kind)
+	aggs := NewWithExemplars(2, 2)
+	agg, ckpt := &aggs[0], &aggs[1]
+
+	sampled := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	unsampled := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{3},
+		SpanID:  trace.SpanID{4},
+	})
+	ctx := context.Background()
+
+	require.NoError(t, agg.Update(ctx, number.NewInt64Number(1), descriptor))
+	require.NoError(t, agg.Update(trace.ContextWithSpanContext(ctx, unsampled), number.NewInt64Number(2), descriptor))
+	require.NoError(t, agg.Update(trace.ContextWithSpanContext(ctx, sampled), number.NewInt64Number(3), descriptor))
+	require.NoError(t, agg.SynchronizedMove(ckpt, descriptor))
+
+	exemplars, err := ckpt.Exemplars()
+	require.NoError(t, err)
+	require.Len(t, exemplars, 1)
+	require.Equal(t, number.NewInt64Number(3), exemplars[0].Value)
+	require.Equal(t, sampled, exemplars[0].SpanContext)
+	require.False(t, exemplars[0].Time.IsZero())
+
+	exemplars, err = agg.Exemplars()
+	require.NoError(t, err)
+	require.Empty(t, exemplars)
+
+	// The reservoir keeps at most 2 exemplars.
+	for i := 0; i < 10; i++ {
+		require.NoError(t, agg.Update(trace.ContextWithSpanContext(ctx, sampled), number.NewInt64Number(1), descriptor))
+	}
+	require.NoError(t, ckpt.Merge(agg, descriptor))
+	exemplars, err = ckpt.Exemplars()
+	require.NoError(t, err)
+	require.Len(t, exemplars, 2)
+
+	// Without NewWithExemplars, there are none.
+	plain, _ := new2()
+	require.NoError(t, plain.Update(trace.ContextWithSpanContext(ctx, sampled), number.NewInt64Number(1), descriptor))
+	exemplars, err = plain.Exemplars()
+	require.NoError(t, err)
+	require.Empty(t, exemplars)
+}
+
+func TestInt64Overflow(t *testing.T) {
+	var handled []error
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		handled = append(handled, err)
+	}))
+	defer otel.SetErrorHandler(otel.ErrorHandlerFunc(func(error) {}))
+
+	ctx := context.Background()
+	descriptor := aggregatortest.NewAggregatorTest(sdkapi.UpDownCounterInstrumentKind, number.Int64Kind)
+	sumOf := func(agg aggregation.Sum) int64 {
+		s, err := agg.Sum()
+		require.NoError(t, err)
+		return s.AsInt64()
+	}
+
+	for _, agg := range []interface {
+		aggregator.Aggregator
+		aggregation.Sum
+	}{
+		&New(1)[0],
+		&NewCompensated(1, descriptor)[0],
+	} {
+		handled = nil
+
+		// Updates saturate at the maximum, and the overflow is
+		// reported once.
+		require.NoError(t, agg.Update(ctx, number.NewInt64Number(math.MaxInt64-1), descriptor))
+		require.NoError(t, agg.Update(ctx, number.NewInt64Number(1), descriptor))
+		require.Equal(t, int64(math.MaxInt64), sumOf(agg))
+		require.Empty(t, handled)
+		require.NoError(t, agg.Update(ctx, number.NewInt64Number(2), descriptor))
+		require.NoError(t, agg.Update(ctx, number.NewInt64Number(math.MaxInt64), descriptor))
+		require.Equal(t, int64(math.MaxInt64), sumOf(agg))
+		require.Len(t, handled, 1)
+		require.ErrorIs(t, handled[0], aggregation.ErrSumOverflow)
+
+		// A saturated sum still decreases.
+		require.NoError(t, agg.Update(ctx, number.NewInt64Number(-7), descriptor))
+		require.Equal(t, int64(math.MaxInt64-7), sumOf(agg))
+
+		// Sums saturate at the minimum too.
+		require.NoError(t, agg.SynchronizedMove(nil, descriptor))
+		require.NoError(t, agg.Update(ctx, number.NewInt64Number(math.MinInt64+1), descriptor))
+		require.NoError(t, agg.Update(ctx, number.NewInt64Number(-5), descriptor))
+		require.Equal(t, int64(math.MinInt64), sumOf(agg))
+		require.Len(t, handled, 1)
+	}
+
+	// Merging saturates, e.g., when a processor accumulates deltas
+	// into a cumulative sum.
+	handled = nil
+	cumulative, delta := new2()
+	require.NoError(t, cumulative.Update(ctx, number.NewInt64Number(math.MaxInt64-10), descriptor))
+	require.NoError(t, delta.Update(ctx, number.NewInt64Number(6), descriptor))
+	require.NoError(t, cumulative.Merge(delta, descriptor))
+	require.Equal(t, int64(math.MaxInt64-4), sumOf(cumulative))
+	require.Empty(t, handled)
+	require.NoError(t, cumulative.Merge(delta, descriptor))
+	require.Equal(t, int64(math.MaxInt64), sumOf(cumulative))
+	require.Len(t, handled, 1)
+	require.ErrorIs(t, handled[0], aggregation.ErrSumOverflow)
+	require.Contains(t, handled[0].Error(), descriptor.Name())
+
+	comps := NewCompensated(2, descriptor)
+	require.NoError(t, comps[0].Update(ctx, number.NewInt64Number(math.MinInt64+1), descriptor))
+	require.NoError(t, comps[1].Update(ctx, number.NewInt64Number(-2), descriptor))
+	require.NoError(t, comps[0].Merge(&comps[1], descriptor))
+	require.Equal(t, int64(math.MinInt64), sumOf(&comps[0]))
+	require.Len(t, handled, 2)
+}
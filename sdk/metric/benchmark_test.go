@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"math/rand"
 	"testing"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
@@ -28,6 +29,7 @@ import (
 	"go.opentelemetry.io/otel/metric/instrument/syncint64"
 	sdk "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/number"
 	"go.opentelemetry.io/otel/sdk/metric/processor/processortest"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
 )
@@ -39,14 +41,14 @@ type benchFixture struct {
 	export.AggregatorSelector
 }
 
-func newFixture(b *testing.B) *benchFixture {
+func newFixture(b *testing.B, opts ...sdk.Option) *benchFixture {
 	b.ReportAllocs()
 	bf := &benchFixture{
 		B:                  b,
 		AggregatorSelector: processortest.AggregatorSelector(),
 	}
 
-	bf.accumulator = sdk.NewAccumulator(bf)
+	bf.accumulator = sdk.NewAccumulator(bf, opts...)
 	bf.meter = sdkapi.WrapMeterImpl(bf.accumulator)
 	return bf
 }
@@ -157,6 +159,39 @@ func BenchmarkInt64CounterAddWithAttrs_16(b *testing.B) {
 	benchmarkAttrs(b, 16)
 }
 
+// benchmarkMultiAttrs records into three Accumulators, like the
+// Controllers of a MeterProvider, two of which keep every attribute.
+func benchmarkMultiAttrs(b *testing.B, n int) {
+	ctx := context.Background()
+	fix := newFixture(b)
+	labs := makeAttrs(n)
+	meter := sdkapi.WrapMeterImpl(sdk.NewMultiMeterImpl(
+		sdk.NewAccumulator(fix),
+		sdk.NewAccumulator(fix),
+		sdk.NewAccumulator(fix, sdk.WithAttributeFilter(func(*sdkapi.Descriptor) attribute.Filter {
+			return func(kv attribute.KeyValue) bool { return kv.Key == labs[0].Key }
+		})),
+	))
+	cnt, err := meter.SyncInt64().Counter("int64.sum")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		cnt.Add(ctx, 1, labs...)
+	}
+}
+
+func BenchmarkMultiInt64CounterAddWithAttrs_4(b *testing.B) {
+	benchmarkMultiAttrs(b, 4)
+}
+
+func BenchmarkMultiInt64CounterAddWithAttrs_16(b *testing.B) {
+	benchmarkMultiAttrs(b, 16)
+}
+
 // Note: performance does not depend on attribute set size for the benchmarks
 // below--all are benchmarked for a single attribute.
 
@@ -344,7 +379,7 @@ func BenchmarkObserverRegistration(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		ctr, _ := fix.meter.AsyncInt64().Counter(names[i])
-		_ = fix.meter.RegisterCallback([]instrument.Asynchronous{ctr}, func(context.Context) {})
+		_, _ = fix.meter.RegisterCallback([]instrument.Asynchronous{ctr}, func(context.Context) error { return nil })
 	}
 }
 
@@ -353,10 +388,11 @@ func BenchmarkGaugeObserverObservationInt64(b *testing.B) {
 	fix := newFixture(b)
 	labs := makeAttrs(1)
 	ctr, _ := fix.meter.AsyncInt64().Counter("test.lastvalue")
-	err := fix.meter.RegisterCallback([]instrument.Asynchronous{ctr}, func(ctx context.Context) {
+	_, err := fix.meter.RegisterCallback([]instrument.Asynchronous{ctr}, func(ctx context.Context) error {
 		for i := 0; i < b.N; i++ {
 			ctr.Observe(ctx, (int64)(i), labs...)
 		}
+		return nil
 	})
 	if err != nil {
 		b.Errorf("could not register callback: %v", err)
@@ -373,10 +409,11 @@ func BenchmarkGaugeObserverObservationFloat64(b *testing.B) {
 	fix := newFixture(b)
 	labs := makeAttrs(1)
 	ctr, _ := fix.meter.AsyncFloat64().Counter("test.lastvalue")
-	err := fix.meter.RegisterCallback([]instrument.Asynchronous{ctr}, func(ctx context.Context) {
+	_, err := fix.meter.RegisterCallback([]instrument.Asynchronous{ctr}, func(ctx context.Context) error {
 		for i := 0; i < b.N; i++ {
 			ctr.Observe(ctx, (float64)(i), labs...)
 		}
+		return nil
 	})
 	if err != nil {
 		b.Errorf("could not register callback: %v", err)
@@ -388,6 +425,189 @@ func BenchmarkGaugeObserverObservationFloat64(b *testing.B) {
 	fix.accumulator.Collect(ctx)
 }
 
+// benchmarkConcurrentObservations observes the same attribute sets of a
+// gauge from concurrent callbacks, each collection creating new records
+// for them.
+func benchmarkConcurrentObservations(b *testing.B, concurrency int) {
+	const (
+		numCallbacks = 16
+		numSets      = 100
+	)
+	ctx := context.Background()
+	fix := newFixture(b, sdk.WithCallbackConcurrency(concurrency))
+	sets := make([][]attribute.KeyValue, numSets)
+	for i := range sets {
+		sets[i] = makeAttrs(4)
+	}
+	gauge, _ := fix.meter.AsyncInt64().Gauge("test.lastvalue")
+	for i := 0; i < numCallbacks; i++ {
+		_, err := fix.meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+			for j, kvs := range sets {
+				gauge.Observe(ctx, int64(j), kvs...)
+			}
+			return nil
+		})
+		if err != nil {
+			b.Errorf("could not register callback: %v", err)
+			b.FailNow()
+		}
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fix.accumulator.Collect(ctx)
+	}
+}
+
+func BenchmarkConcurrentObservations_1(b *testing.B) {
+	benchmarkConcurrentObservations(b, 1)
+}
+
+func BenchmarkConcurrentObservations_4(b *testing.B) {
+	benchmarkConcurrentObservations(b, 4)
+}
+
+func BenchmarkConcurrentObservations_16(b *testing.B) {
+	benchmarkConcurrentObservations(b, 16)
+}
+
+// benchmarkObserveBatch observes numSets attribute sets of a gauge from
+// a callback, one at a time or in a batch.
+func benchmarkObserveBatch(b *testing.B, batch bool, opts ...sdk.Option) {
+	const numSets = 100
+	ctx := context.Background()
+	fix := newFixture(b, opts...)
+	observations := make([]sdk.Observation, numSets)
+	for i := range observations {
+		observations[i] = sdk.Observation{
+			Number:     number.NewInt64Number(int64(i)),
+			Attributes: makeAttrs(4),
+		}
+	}
+	gauge, _ := fix.meter.AsyncInt64().Gauge("test.lastvalue")
+	_, err := fix.meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		if batch {
+			sdk.ObserveBatch(ctx, gauge, observations)
+			return nil
+		}
+		for _, o := range observations {
+			gauge.Observe(ctx, o.Number.AsInt64(), o.Attributes...)
+		}
+		return nil
+	})
+	if err != nil {
+		b.Errorf("could not register callback: %v", err)
+		b.FailNow()
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fix.accumulator.Collect(ctx)
+	}
+}
+
+func BenchmarkObserveIndividual(b *testing.B) {
+	benchmarkObserveBatch(b, false)
+}
+
+func BenchmarkObserveBatch(b *testing.B) {
+	benchmarkObserveBatch(b, true)
+}
+
+func BenchmarkObserveIndividualBuffered(b *testing.B) {
+	benchmarkObserveBatch(b, false, sdk.WithCallbackRetries(1, time.Millisecond))
+}
+
+func BenchmarkObserveBatchBuffered(b *testing.B) {
+	benchmarkObserveBatch(b, true, sdk.WithCallbackRetries(1, time.Millisecond))
+}
+
+// benchmarkObserveSet observes numSets wide attribute sets of a gauge
+// from a callback, passing the attributes or their precomputed set.
+func benchmarkObserveSet(b *testing.B, precomputed bool) {
+	const numSets = 100
+	ctx := context.Background()
+	fix := newFixture(b)
+	attrs := make([][]attribute.KeyValue, numSets)
+	sets := make([]attribute.Set, numSets)
+	for i := range attrs {
+		attrs[i] = makeAttrs(16)
+		sets[i] = attribute.NewSet(attrs[i]...)
+	}
+	gauge, _ := fix.meter.AsyncInt64().Gauge("test.lastvalue")
+	_, err := fix.meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		for i := range attrs {
+			if precomputed {
+				sdk.ObserveSet(ctx, gauge, number.NewInt64Number(int64(i)), sets[i])
+			} else {
+				gauge.Observe(ctx, int64(i), attrs[i]...)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Errorf("could not register callback: %v", err)
+		b.FailNow()
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fix.accumulator.Collect(ctx)
+	}
+}
+
+func BenchmarkObserveAttributes(b *testing.B) {
+	benchmarkObserveSet(b, false)
+}
+
+func BenchmarkObserveSet(b *testing.B) {
+	benchmarkObserveSet(b, true)
+}
+
+// benchmarkInstrumentsBySets adds to numSets attribute sets of numInst
+// counters from parallel goroutines, collecting every so often so that
+// records are created again.
+func benchmarkInstrumentsBySets(b *testing.B, numInst, numSets int) {
+	ctx := context.Background()
+	fix := newFixture(b)
+	counters := make([]syncint64.Counter, numInst)
+	for i := range counters {
+		counters[i] = fix.iCounter(fmt.Sprintf("int64.%d.sum", i))
+	}
+	sets := make([][]attribute.KeyValue, numSets)
+	for i := range sets {
+		sets[i] = makeAttrs(2)
+	}
+
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.Int()
+		for pb.Next() {
+			i++
+			counters[i%numInst].Add(ctx, 1, sets[(i/numInst)%numSets]...)
+			if i%(numInst*numSets) == 0 {
+				fix.accumulator.Collect(ctx)
+			}
+		}
+	})
+}
+
+func BenchmarkInstrumentsBySets_1x1000(b *testing.B) {
+	benchmarkInstrumentsBySets(b, 1, 1000)
+}
+
+func BenchmarkInstrumentsBySets_10x100(b *testing.B) {
+	benchmarkInstrumentsBySets(b, 10, 100)
+}
+
+func BenchmarkInstrumentsBySets_100x10(b *testing.B) {
+	benchmarkInstrumentsBySets(b, 100, 10)
+}
+
 // BatchRecord
 
 func benchmarkBatchRecord8Attrs(b *testing.B, numInst int) {
@@ -442,3 +662,34 @@ func BenchmarkRepeatedDirectCalls(b *testing.B) {
 		fix.accumulator.Collect(ctx)
 	}
 }
+
+// Interning
+
+// benchmarkRepeatedAttributes records a few constant attribute sets and
+// collects every so often, so that records are created again.
+func benchmarkRepeatedAttributes(b *testing.B, opts ...sdk.Option) {
+	ctx := context.Background()
+	fix := newFixture(b, opts...)
+	cnt := fix.iCounter("int64.sum")
+	sets := make([][]attribute.KeyValue, 4)
+	for i := range sets {
+		sets[i] = makeAttrs(4)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		cnt.Add(ctx, 1, sets[i%len(sets)]...)
+		if i%100 == 0 {
+			fix.accumulator.Collect(ctx)
+		}
+	}
+}
+
+func BenchmarkRepeatedAttributes(b *testing.B) {
+	benchmarkRepeatedAttributes(b)
+}
+
+func BenchmarkRepeatedAttributesInterned(b *testing.B) {
+	benchmarkRepeatedAttributes(b, sdk.WithAttributeInterning(64))
+}
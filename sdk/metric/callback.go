@@ -0,0 +1,600 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric // import "go.opentelemetry.io/otel/sdk/metric"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/exemplar"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+// Registration is a callback registered with an Accumulator, see
+// Accumulator.RegisterCallback.
+type Registration struct {
+	accumulator *Accumulator
+
+	// callback is nil when the callback was not registered
+	// because all its instruments are excluded.
+	callback *callback
+}
+
+// Unregister unregisters the callback, which is not called by later
+// collections, nor by the collection in progress if it has not been
+// called yet.  It is safe to call concurrently with Collect, and from the
+// callback itself.  Unregister returns ErrUnknownCallback when the
+// callback is no longer registered, e.g., when it was already
+// unregistered, except for a callback that was never registered because
+// all its instruments are excluded.
+func (r *Registration) Unregister() error {
+	if r == nil || r.accumulator == nil {
+		return ErrUnknownCallback
+	}
+	if r.callback == nil {
+		return nil
+	}
+	m := r.accumulator
+	m.callbackLock.Lock()
+	defer m.callbackLock.Unlock()
+	if _, ok := m.callbacks[r.callback]; !ok {
+		return ErrUnknownCallback
+	}
+	delete(m.callbacks, r.callback)
+	return nil
+}
+
+// TagCallback tags the callback of reg with tags, in addition to its
+// previous tags, so that Accumulator.CollectTagged runs it when passed
+// one of them.  reg is a Registration returned by the RegisterCallback
+// method of a Meter of this SDK.  TagCallback returns ErrUnknownCallback
+// when the callback is not registered, or reg is not a Registration of
+// this SDK.
+func TagCallback(reg metric.Registration, tags ...string) error {
+	switch r := reg.(type) {
+	case *Registration:
+		return r.tag(tags)
+	case multiRegistration:
+		for _, sub := range r {
+			if err := TagCallback(sub, tags...); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return ErrUnknownCallback
+}
+
+func (r *Registration) tag(tags []string) error {
+	if r == nil || r.accumulator == nil {
+		return ErrUnknownCallback
+	}
+	if r.callback == nil {
+		// Never registered, see Unregister.
+		return nil
+	}
+	m := r.accumulator
+	m.callbackLock.Lock()
+	defer m.callbackLock.Unlock()
+	if _, ok := m.callbacks[r.callback]; !ok {
+		return ErrUnknownCallback
+	}
+	r.callback.tags = append(r.callback.tags, tags...)
+	return nil
+}
+
+// tagged returns whether cb has one of tags.  The callbackLock must be
+// held.
+func (cb *callback) tagged(tags []string) bool {
+	for _, t := range cb.tags {
+		for _, tag := range tags {
+			if t == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CallbackInfo describes a registered callback, see
+// Accumulator.Inspect.
+type CallbackInfo struct {
+	// Name identifies the callback function, as in the errors of
+	// its collections.
+	Name string
+
+	// Instruments are the descriptors of the instruments the
+	// callback was registered with, in the order of their names.
+	// The excluded instruments are omitted.
+	Instruments []sdkapi.Descriptor
+}
+
+// Inspect returns the callbacks registered with m and the instruments
+// each one observes, in the order of their names, e.g., for an operator
+// to confirm that registrations took effect.  The callbacks of the same
+// name are ordered by the names of their instruments.  The result is a
+// copy, and Inspect is safe to call concurrently with Collect and with
+// the registration of callbacks.
+func (m *Accumulator) Inspect() []CallbackInfo {
+	m.callbackLock.Lock()
+	infos := make([]CallbackInfo, 0, len(m.callbacks))
+	for cb := range m.callbacks {
+		info := CallbackInfo{
+			Name:        cb.name,
+			Instruments: make([]sdkapi.Descriptor, 0, len(cb.insts)),
+		}
+		for inst := range cb.insts {
+			info.Instruments = append(info.Instruments, inst.Descriptor())
+		}
+		sort.Slice(info.Instruments, func(i, j int) bool {
+			return info.Instruments[i].Name() < info.Instruments[j].Name()
+		})
+		infos = append(infos, info)
+	}
+	m.callbackLock.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Name != infos[j].Name {
+			return infos[i].Name < infos[j].Name
+		}
+		return instrumentNames(infos[i].Instruments) < instrumentNames(infos[j].Instruments)
+	})
+	return infos
+}
+
+// instrumentNames joins the names of descs, to order the callbacks of
+// the same name in Inspect.
+func instrumentNames(descs []sdkapi.Descriptor) string {
+	names := make([]string, len(descs))
+	for i, desc := range descs {
+		names[i] = desc.Name()
+	}
+	return strings.Join(names, "\x00")
+}
+
+// callbackAttempt is one run of a callback.  When retries are
+// configured, the observations of the run are buffered until it is known
+// whether the run failed.
+type callbackAttempt struct {
+	// epoch is the epoch of the collection that runs the
+	// attempt.
+	epoch int64
+
+	// backpressure is true when the collection signals
+	// backpressure, see WithBackpressure.
+	backpressure bool
+
+	// callback is the callback that runs.
+	callback *callback
+
+	// insts are the instruments the callback may observe.
+	insts map[*asyncInstrument]struct{}
+
+	lock         sync.Mutex
+	buffered     bool
+	err          error
+	observations []observation
+
+	// abandoned is set when the callback timed out, see
+	// WithCallbackTimeout.  Its later observations are dropped.
+	abandoned int32
+
+	// returned is set once the callback returned.  Its later
+	// observations, e.g., from goroutines it started, are late
+	// even while other callbacks of the collection still run.
+	returned int32
+}
+
+type observation struct {
+	inst  *asyncInstrument
+	num   number.Number
+	attrs []attribute.KeyValue
+
+	// shared are the attributes of an observation of
+	// ObserveShared, which replace attrs.
+	shared *sharedAttributes
+
+	// bag is the baggage of the observation, kept when members
+	// are promoted to attributes, see WithBaggageAttributes.
+	bag baggage.Baggage
+
+	// decision is the exemplar decision of the observation.
+	decision exemplarDecision
+}
+
+// exemplarDecision is the exemplar decision of a buffered observation,
+// see exemplar.ContextWithExemplarDecision.
+type exemplarDecision struct {
+	keep    bool
+	decided bool
+}
+
+func exemplarDecisionOf(ctx context.Context) exemplarDecision {
+	keep, decided := exemplar.ExemplarDecisionFromContext(ctx)
+	return exemplarDecision{keep: keep, decided: decided}
+}
+
+// context returns ctx with the decision, if any.
+func (d exemplarDecision) context(ctx context.Context) context.Context {
+	if !d.decided {
+		return ctx
+	}
+	return exemplar.ContextWithExemplarDecision(ctx, d.keep)
+}
+
+// keptBaggage returns the baggage of ctx to keep with the buffered
+// observations, when baggage members are promoted to attributes.
+func (m *Accumulator) keptBaggage(ctx context.Context) baggage.Baggage {
+	if m.config.BaggageAttributes == nil {
+		return baggage.Baggage{}
+	}
+	return baggage.FromContext(ctx)
+}
+
+// buffer holds an observation of inst until the attempt succeeds.  It
+// returns false when the attempt does not buffer observations.
+func (a *callbackAttempt) buffer(ctx context.Context, inst *asyncInstrument, num number.Number, attrs []attribute.KeyValue) bool {
+	if !a.buffered {
+		return false
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.isAbandoned() {
+		return true
+	}
+	a.observations = append(a.observations, observation{
+		inst:     inst,
+		num:      num,
+		attrs:    append([]attribute.KeyValue(nil), attrs...),
+		bag:      inst.meter.keptBaggage(ctx),
+		decision: exemplarDecisionOf(ctx),
+	})
+	return true
+}
+
+// bufferBatch holds the observations of inst until the attempt
+// succeeds, like buffer.
+func (a *callbackAttempt) bufferBatch(ctx context.Context, inst *asyncInstrument, observations []Observation) bool {
+	if !a.buffered {
+		return false
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.isAbandoned() {
+		return true
+	}
+	bag := inst.meter.keptBaggage(ctx)
+	decision := exemplarDecisionOf(ctx)
+	for _, o := range observations {
+		a.observations = append(a.observations, observation{
+			inst:     inst,
+			num:      o.Number,
+			attrs:    append([]attribute.KeyValue(nil), o.Attributes...),
+			bag:      bag,
+			decision: decision,
+		})
+	}
+	return true
+}
+
+// bufferShared holds the observations of ObserveShared until the
+// attempt succeeds, like buffer.
+func (a *callbackAttempt) bufferShared(ctx context.Context, insts []*asyncInstrument, nums []number.Number, shared *sharedAttributes) bool {
+	if !a.buffered {
+		return false
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.isAbandoned() {
+		return true
+	}
+	// The set is already computed: only the attributes that
+	// enrichment may need are copied.
+	shared = &sharedAttributes{
+		kvs: append([]attribute.KeyValue(nil), shared.kvs...),
+		set: shared.set,
+	}
+	bag := insts[0].meter.keptBaggage(ctx)
+	decision := exemplarDecisionOf(ctx)
+	for i, inst := range insts {
+		a.observations = append(a.observations, observation{
+			inst:     inst,
+			num:      nums[i],
+			shared:   shared,
+			bag:      bag,
+			decision: decision,
+		})
+	}
+	return true
+}
+
+func (a *callbackAttempt) fail(err error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.err == nil {
+		a.err = err
+	}
+}
+
+// result returns the error the attempt failed with, if any.
+func (a *callbackAttempt) result() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.err
+}
+
+// abandon drops the later observations of the attempt.  Its buffered
+// observations are kept.
+func (a *callbackAttempt) abandon() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	atomic.StoreInt32(&a.abandoned, 1)
+}
+
+func (a *callbackAttempt) isAbandoned() bool {
+	return atomic.LoadInt32(&a.abandoned) != 0
+}
+
+func (a *callbackAttempt) hasReturned() bool {
+	return atomic.LoadInt32(&a.returned) != 0
+}
+
+// check returns the error an observation of inst made during the attempt
+// is dropped with, if any: inst is not declared by the callback, the
+// epoch of the attempt has ended, the callback timed out or it returned.
+// The epoch lock of the Accumulator of inst must be held.
+func (a *callbackAttempt) check(inst *asyncInstrument) error {
+	var err error
+	switch _, declared := a.insts[inst]; {
+	case !declared:
+		err = ErrUndeclaredInstrument
+	case a.epoch != inst.meter.currentEpoch:
+		err = ErrLateObservation
+	case a.isAbandoned():
+		err = ErrCallbackTimeout
+	case a.hasReturned():
+		err = ErrLateObservation
+	default:
+		return nil
+	}
+	return fmt.Errorf("%s: %w", inst.descriptor.Name(), err)
+}
+
+// observeIn captures an observation made during attempt, unless the
+// callback is not registered with a or the epoch of attempt has ended.
+// Holding the epoch lock ensures that the observation is either collected
+// in the epoch of attempt or dropped, but never collected in a later
+// epoch.
+func (a *asyncInstrument) observeIn(ctx context.Context, attempt *callbackAttempt, num number.Number, attrs []attribute.KeyValue) {
+	a.meter.epochLock.RLock()
+	defer a.meter.epochLock.RUnlock()
+	if err := attempt.check(a); err != nil {
+		otel.Handle(err)
+		return
+	}
+	if attempt.buffer(ctx, a, num, attrs) {
+		return
+	}
+	a.observe(ctx, num, attrs)
+}
+
+// observeSetIn captures an observation of ObserveSet made during
+// attempt, like observeIn.
+func (a *asyncInstrument) observeSetIn(ctx context.Context, attempt *callbackAttempt, num number.Number, shared *sharedAttributes) {
+	a.meter.epochLock.RLock()
+	defer a.meter.epochLock.RUnlock()
+	if err := attempt.check(a); err != nil {
+		otel.Handle(err)
+		return
+	}
+	if attempt.bufferShared(ctx, []*asyncInstrument{a}, []number.Number{num}, shared) {
+		return
+	}
+	a.captureShared(ctx, num, shared)
+}
+
+// observeBatchIn captures the observations made during attempt, like
+// observeIn, checking attempt and holding the epoch lock once.
+func (a *asyncInstrument) observeBatchIn(ctx context.Context, attempt *callbackAttempt, observations []Observation) {
+	a.meter.epochLock.RLock()
+	defer a.meter.epochLock.RUnlock()
+	if err := attempt.check(a); err != nil {
+		otel.Handle(err)
+		return
+	}
+	if attempt.bufferBatch(ctx, a, observations) {
+		return
+	}
+	for _, o := range observations {
+		a.observe(ctx, o.Number, o.Attributes)
+	}
+}
+
+// commit captures the buffered observations of a successful attempt.
+func (a *callbackAttempt) commit(ctx context.Context) {
+	a.lock.Lock()
+	observations := a.observations
+	a.lock.Unlock()
+	ctx = context.WithValue(ctx, asyncContextKey{}, a)
+	for _, o := range observations {
+		octx := o.decision.context(ctx)
+		if o.bag.Len() != 0 {
+			octx = baggage.ContextWithBaggage(octx, o.bag)
+		}
+		if o.shared != nil {
+			o.inst.captureShared(octx, o.num, o.shared)
+			continue
+		}
+		o.inst.observe(octx, o.num, o.attrs)
+	}
+}
+
+// FailCallback reports that the callback running with ctx, or a context
+// derived from it, failed with err, as if the callback returned err.
+// When retries are configured, see WithCallbackRetries, the observations
+// made by the callback during this run are discarded and the callback is
+// run again.  Otherwise, err is returned by Collect, and the observations
+// are kept.
+func FailCallback(ctx context.Context, err error) {
+	attempt, ok := ctx.Value(asyncContextKey{}).(*callbackAttempt)
+	if !ok {
+		otel.Handle(err)
+		return
+	}
+	attempt.fail(err)
+}
+
+// Backpressure returns whether the collection running the callback with
+// ctx, or a context derived from it, signals backpressure, see
+// WithBackpressure.  Callbacks may then skip optional or expensive
+// observations.  The signal is advisory: observations are collected
+// either way.  Backpressure returns false outside of callbacks.
+func Backpressure(ctx context.Context) bool {
+	attempt, ok := ctx.Value(asyncContextKey{}).(*callbackAttempt)
+	return ok && attempt.backpressure
+}
+
+// run calls the callback, converting a panic into an error wrapping
+// ErrCallbackPanic that names the instruments of the callback.
+func (cb *callback) run(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v (instruments: %s)", ErrCallbackPanic, r, cb.instrumentNames())
+		}
+	}()
+	return cb.f(ctx)
+}
+
+// instrumentNames returns the sorted names of the instruments of cb.
+func (cb *callback) instrumentNames() string {
+	names := make([]string, 0, len(cb.insts))
+	for inst := range cb.insts {
+		names = append(names, inst.descriptor.Name())
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// runCallback runs cb, retrying it as configured by WithCallbackRetries,
+// and returns the error of its last attempt.
+func (m *Accumulator) runCallback(ctx context.Context, cb *callback, backpressure bool) error {
+	retries := m.config.CallbackRetries
+	for i := 0; ; i++ {
+		attempt := &callbackAttempt{
+			epoch:        m.currentEpoch,
+			backpressure: backpressure,
+			callback:     cb,
+			insts:        cb.insts,
+			buffered:     retries > 0,
+		}
+		if err := m.runAttempt(ctx, cb, attempt); err != nil {
+			attempt.fail(err)
+		}
+		atomic.StoreInt32(&attempt.returned, 1)
+		err := attempt.result()
+		if err == nil {
+			attempt.commit(ctx)
+			return nil
+		}
+		if i >= retries {
+			return err
+		}
+		timer := time.NewTimer(m.config.CallbackRetryBackoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// runAttempt runs cb for attempt, abandoning it once the timeout set by
+// WithCallbackTimeout expires.  An abandoned attempt does not fail,
+// unless ctx is done too.
+func (m *Accumulator) runAttempt(ctx context.Context, cb *callback, attempt *callbackAttempt) error {
+	timeout := m.config.CallbackTimeout
+	if timeout <= 0 {
+		return cb.run(context.WithValue(ctx, asyncContextKey{}, attempt))
+	}
+	cbCtx, cancel := context.WithTimeout(context.WithValue(ctx, asyncContextKey{}, attempt), timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- cb.run(cbCtx)
+	}()
+	select {
+	case err := <-done:
+		if cbCtx.Err() == nil || ctx.Err() != nil {
+			return err
+		}
+		// The callback returned after it timed out, e.g., with
+		// the error of cbCtx.
+	case <-cbCtx.Done():
+		attempt.abandon()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	otel.Handle(fmt.Errorf("callback %s abandoned after %v: %w", cb.name, timeout, ErrCallbackTimeout))
+	return nil
+}
+
+// CallbackError is returned by Collect when callbacks fail.  errors.Is
+// and errors.As match each of its errors.
+type CallbackError struct {
+	// Errors holds the errors of the failed callbacks, each
+	// naming its callback.
+	Errors []error
+}
+
+func (e *CallbackError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d callback(s) failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Is reports whether any of the errors of e matches target.
+func (e *CallbackError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors of e that matches target.
+func (e *CallbackError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
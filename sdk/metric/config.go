@@ -0,0 +1,679 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric // import "go.opentelemetry.io/otel/sdk/metric"
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/instrument/syncfloat64"
+	"go.opentelemetry.io/otel/metric/instrument/syncint64"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+// config contains configuration for an Accumulator.
+type config struct {
+	// DeltaObservers is the set of asynchronous counter
+	// instrument names that receive observations through
+	// ObserveDelta instead of ObserveOne.
+	DeltaObservers map[string]struct{}
+
+	// CallbackDurations, if not nil, records the duration of
+	// each callback, in milliseconds, during Collect().
+	CallbackDurations syncfloat64.Histogram
+
+	// CollectConcurrency is the number of goroutines used to
+	// checkpoint records during Collect().  Values less than two
+	// checkpoint records sequentially.
+	CollectConcurrency int
+
+	// CallbackConcurrency is the number of goroutines used to
+	// run the callbacks during Collect().  Values less than two
+	// run the callbacks sequentially.
+	CallbackConcurrency int
+
+	// UnitMismatch is the action taken when the unit of a new
+	// instrument contradicts the unit suffix of its name.
+	UnitMismatch UnitMismatch
+
+	// AttributeEnrichment, if not nil, returns the attributes
+	// added to every measurement of an instrument.
+	AttributeEnrichment func(*sdkapi.Descriptor) []attribute.KeyValue
+
+	// DefaultAttributes are added to every measurement of every
+	// instrument.
+	DefaultAttributes []attribute.KeyValue
+
+	// BaggageAttributes, if not nil, returns the keys of the
+	// baggage members promoted to attributes of the observations
+	// of each asynchronous instrument.
+	BaggageAttributes func(*sdkapi.Descriptor) []string
+
+	// AttributeFilter, if not nil, returns the filter of the
+	// attributes passed with the measurements of an instrument.
+	AttributeFilter func(*sdkapi.Descriptor) attribute.Filter
+
+	// StringNormalizers maps attribute keys to the functions
+	// applied, in order, to their string values.
+	StringNormalizers map[attribute.Key][]func(string) string
+
+	// ExcludeInstrument, if not nil, returns true for the
+	// instruments that are not collected.
+	ExcludeInstrument func(*sdkapi.Descriptor) bool
+
+	// SeriesGrowthLimit is the maximum number of new attribute
+	// sets of an instrument in one collection cycle.  Values less
+	// than one disable the limit.
+	SeriesGrowthLimit int
+
+	// CardinalityLimit is the maximum number of attribute sets
+	// of an asynchronous instrument, including its overflow
+	// series.  Values less than one disable the limit.
+	CardinalityLimit int
+
+	// CardinalityLimits, if not nil, returns the cardinality
+	// limit of an asynchronous instrument, overriding
+	// CardinalityLimit when it returns true.
+	CardinalityLimits func(*sdkapi.Descriptor) (int, bool)
+
+	// SeriesKey, if not nil, returns the key identifying an
+	// attribute set in place of its attribute.Distinct.
+	SeriesKey func(*attribute.Set) interface{}
+
+	// CallbackRetries is the number of times a failed callback
+	// is run again in the same collection, waiting
+	// CallbackRetryBackoff before each retry.
+	CallbackRetries      int
+	CallbackRetryBackoff time.Duration
+
+	// CallbackTimeout is the time after which a running
+	// callback is abandoned.  Values less than or equal to zero
+	// disable the timeout.
+	CallbackTimeout time.Duration
+
+	// UnitConverters maps unit conversions to the functions
+	// converting values.
+	UnitConverters map[unitConversion]func(float64) float64
+
+	// UnitConversion, if not nil, returns the unit that the
+	// measurements of an instrument are converted to.
+	UnitConversion func(*sdkapi.Descriptor) (unit.Unit, bool)
+
+	// InvalidMeasurements, if not nil, counts the NaN and
+	// infinite measurements that are dropped.
+	InvalidMeasurements syncint64.Counter
+
+	// DroppedMeasurements, if not nil, counts the measurements
+	// dropped by the series limits.
+	DroppedMeasurements syncint64.Counter
+
+	// Aliases maps instrument names to the other names their
+	// measurements are also recorded under.
+	Aliases map[string][]string
+
+	// Renames maps instrument names to the names their data is
+	// exported under.
+	Renames map[string]string
+
+	// IgnoreMissingCallbacks disables the report of the
+	// asynchronous instruments without a callback.
+	IgnoreMissingCallbacks bool
+
+	// Backpressure, if not nil, returns whether the callbacks of
+	// a collection are signaled backpressure.
+	Backpressure func() bool
+
+	// SeedAttributes, if not nil, returns the attribute sets of
+	// an adding instrument that are collected with a zero value
+	// before they are measured.
+	SeedAttributes func(*sdkapi.Descriptor) []attribute.Set
+
+	// AttributeInterning is the number of attribute sets cached
+	// to be reused by the measurements with the same attributes.
+	// Values less than one disable the cache.
+	AttributeInterning int
+}
+
+// Option is the interface that applies the value to a configuration option.
+type Option interface {
+	// apply sets the Option value of a config.
+	apply(config) config
+}
+
+func newConfig(opts ...Option) config {
+	var cfg config
+	for _, opt := range opts {
+		cfg = opt.apply(cfg)
+	}
+	return cfg
+}
+
+// WithDeltaObservers sets the asynchronous counter instruments, by name,
+// that are observed using ObserveDelta.  The observations of these
+// instruments are added to a running total maintained by the
+// Accumulator, which is exported as the cumulative value.
+func WithDeltaObservers(names ...string) Option {
+	return deltaObserversOption(names)
+}
+
+type deltaObserversOption []string
+
+func (o deltaObserversOption) apply(cfg config) config {
+	if cfg.DeltaObservers == nil {
+		cfg.DeltaObservers = map[string]struct{}{}
+	}
+	for _, name := range o {
+		cfg.DeltaObservers[name] = struct{}{}
+	}
+	return cfg
+}
+
+// WithCallbackDurations sets a histogram that records the duration of
+// each callback execution, in milliseconds, during Collect().  Each
+// measurement has a "callback" attribute naming the callback function.
+func WithCallbackDurations(histogram syncfloat64.Histogram) Option {
+	return callbackDurationsOption{histogram}
+}
+
+type callbackDurationsOption struct {
+	histogram syncfloat64.Histogram
+}
+
+func (o callbackDurationsOption) apply(cfg config) config {
+	cfg.CallbackDurations = o.histogram
+	return cfg
+}
+
+// WithCollectConcurrency sets the number of goroutines used to
+// checkpoint records during Collect().  This can reduce the duration of
+// a collection when there are many instruments with high cardinality.
+// The records are still passed to the Processor one at a time.
+func WithCollectConcurrency(n int) Option {
+	return collectConcurrencyOption(n)
+}
+
+type collectConcurrencyOption int
+
+func (o collectConcurrencyOption) apply(cfg config) config {
+	cfg.CollectConcurrency = int(o)
+	return cfg
+}
+
+// WithCallbackConcurrency sets the number of goroutines used to run the
+// callbacks during Collect().  This can reduce the duration of a
+// collection when callbacks block, e.g., on I/O.  All the callbacks
+// complete before the records are checkpointed.  Callbacks that run
+// concurrently must not depend on each other, and must synchronize the
+// state they share.  By default, the callbacks run sequentially.
+func WithCallbackConcurrency(n int) Option {
+	return callbackConcurrencyOption(n)
+}
+
+type callbackConcurrencyOption int
+
+func (o callbackConcurrencyOption) apply(cfg config) config {
+	cfg.CallbackConcurrency = int(o)
+	return cfg
+}
+
+// WithUnitMismatch sets the action taken when a new instrument has a unit
+// that contradicts the unit suffix of its name, e.g., an instrument named
+// "request.duration.bytes" with unit "ms".  By default, the instrument is
+// created without a check.
+func WithUnitMismatch(action UnitMismatch) Option {
+	return unitMismatchOption(action)
+}
+
+type unitMismatchOption UnitMismatch
+
+func (o unitMismatchOption) apply(cfg config) config {
+	cfg.UnitMismatch = UnitMismatch(o)
+	return cfg
+}
+
+// WithAttributeEnrichment sets a function that returns, for each new
+// instrument, attributes that are added to every measurement of the
+// instrument.  Attributes passed with a measurement take precedence over
+// the added attributes with the same key.
+func WithAttributeEnrichment(f func(*sdkapi.Descriptor) []attribute.KeyValue) Option {
+	return attributeEnrichmentOption(f)
+}
+
+type attributeEnrichmentOption func(*sdkapi.Descriptor) []attribute.KeyValue
+
+func (o attributeEnrichmentOption) apply(cfg config) config {
+	cfg.AttributeEnrichment = o
+	return cfg
+}
+
+// WithAttributeFilter sets a function that returns, for each new
+// instrument, the filter of the attributes passed with its measurements,
+// or nil to keep every attribute, e.g., to keep only an allowlist of keys
+// and reduce the cardinality of the instrument.  Attributes are filtered
+// before the attribute set of a measurement is computed, so measurements
+// whose attributes are equal after filtering are aggregated together.
+// This includes the observations of an asynchronous instrument in one
+// collection, which are then added up instead of the last observation
+// winning.  The attributes added with WithAttributeEnrichment and
+// WithDefaultAttributes are not filtered.
+func WithAttributeFilter(f func(*sdkapi.Descriptor) attribute.Filter) Option {
+	return attributeFilterOption(f)
+}
+
+type attributeFilterOption func(*sdkapi.Descriptor) attribute.Filter
+
+func (o attributeFilterOption) apply(cfg config) config {
+	cfg.AttributeFilter = o
+	return cfg
+}
+
+// WithBaggageAttributes sets a function that returns, for each new
+// asynchronous instrument, the keys of the baggage members that are
+// promoted to attributes of its observations.  The baggage is read from
+// the context passed to Observe, and the members that are missing are
+// omitted.  The attributes passed with an observation take precedence
+// over the promoted members with the same key, which take precedence
+// over the attributes set with WithAttributeEnrichment and
+// WithDefaultAttributes.  The promoted members are filtered like the
+// other attributes, see WithAttributeFilter.
+func WithBaggageAttributes(f func(*sdkapi.Descriptor) []string) Option {
+	return baggageAttributesOption(f)
+}
+
+type baggageAttributesOption func(*sdkapi.Descriptor) []string
+
+func (o baggageAttributesOption) apply(cfg config) config {
+	cfg.BaggageAttributes = o
+	return cfg
+}
+
+// WithDefaultAttributes adds attributes to every measurement of every
+// instrument, e.g., the version of the instrumented library.  Attributes
+// passed with a measurement take precedence over the attributes set with
+// WithAttributeEnrichment, which take precedence over the default
+// attributes with the same key.  Multiple calls are combined; a later
+// default attribute takes precedence over an earlier one with the same
+// key.
+func WithDefaultAttributes(kvs ...attribute.KeyValue) Option {
+	return defaultAttributesOption(kvs)
+}
+
+type defaultAttributesOption []attribute.KeyValue
+
+func (o defaultAttributesOption) apply(cfg config) config {
+	cfg.DefaultAttributes = append(cfg.DefaultAttributes[:len(cfg.DefaultAttributes):len(cfg.DefaultAttributes)], o...)
+	return cfg
+}
+
+// WithStringNormalization sets a function that canonicalizes the string
+// values of the attributes with the given keys, e.g., strings.ToLower or
+// strings.TrimSpace.  Values are normalized before the attribute set of a
+// measurement is computed, so measurements whose values normalize to the
+// same string are aggregated together.  Attributes of other types are not
+// changed.  Multiple calls are combined; the functions for a key are
+// applied in the order they were configured.
+func WithStringNormalization(normalize func(string) string, keys ...attribute.Key) Option {
+	return stringNormalizationOption{normalize: normalize, keys: keys}
+}
+
+type stringNormalizationOption struct {
+	normalize func(string) string
+	keys      []attribute.Key
+}
+
+func (o stringNormalizationOption) apply(cfg config) config {
+	if cfg.StringNormalizers == nil {
+		cfg.StringNormalizers = map[attribute.Key][]func(string) string{}
+	}
+	for _, key := range o.keys {
+		cfg.StringNormalizers[key] = append(cfg.StringNormalizers[key], o.normalize)
+	}
+	return cfg
+}
+
+// WithExcludedInstruments sets a function that selects the instruments
+// excluded from collection.  The measurements of an excluded instrument
+// are discarded without allocating any state, and callbacks registered
+// only for excluded instruments are not run.
+func WithExcludedInstruments(exclude func(*sdkapi.Descriptor) bool) Option {
+	return excludedInstrumentsOption(exclude)
+}
+
+type excludedInstrumentsOption func(*sdkapi.Descriptor) bool
+
+func (o excludedInstrumentsOption) apply(cfg config) config {
+	cfg.ExcludeInstrument = o
+	return cfg
+}
+
+// WithInvalidMeasurements sets a counter of the measurements dropped
+// because their value is NaN, or infinite for a histogram.  Each
+// measurement has an "instrument" attribute naming the instrument and a
+// "reason" attribute of "nan" or "inf".  By default, these measurements
+// are dropped and reported to the global error handler.
+func WithInvalidMeasurements(counter syncint64.Counter) Option {
+	return invalidMeasurementsOption{counter}
+}
+
+type invalidMeasurementsOption struct {
+	counter syncint64.Counter
+}
+
+func (o invalidMeasurementsOption) apply(cfg config) config {
+	cfg.InvalidMeasurements = o.counter
+	return cfg
+}
+
+// WithDroppedMeasurements sets a counter of the measurements dropped
+// because of the series limits of their instrument: the measurements
+// dropped by WithSeriesGrowthLimit, with a "reason" attribute of
+// "series_growth", and the measurements whose attributes are dropped
+// into the overflow series by WithCardinalityLimit, with a "reason"
+// attribute of "cardinality_limit".  Each measurement also has an
+// "instrument" attribute naming the instrument.
+func WithDroppedMeasurements(counter syncint64.Counter) Option {
+	return droppedMeasurementsOption{counter}
+}
+
+type droppedMeasurementsOption struct {
+	counter syncint64.Counter
+}
+
+func (o droppedMeasurementsOption) apply(cfg config) config {
+	cfg.DroppedMeasurements = o.counter
+	return cfg
+}
+
+// WithSeriesGrowthLimit sets the maximum number of new attribute sets
+// that an instrument can create in one collection cycle, i.e., since the
+// end of the previous Collect() and through the callbacks run by the next
+// one.  An instrument exceeding the limit, typically because of an
+// attribute value such as a timestamp or a UUID, is reported once to the
+// global error handler with an error wrapping ErrSeriesGrowth, and its
+// measurements with new attribute sets are dropped for the rest of the
+// cycle.  Measurements with existing attribute sets are not affected.
+func WithSeriesGrowthLimit(n int) Option {
+	return seriesGrowthLimitOption(n)
+}
+
+type seriesGrowthLimitOption int
+
+func (o seriesGrowthLimitOption) apply(cfg config) config {
+	cfg.SeriesGrowthLimit = int(o)
+	return cfg
+}
+
+// WithCardinalityLimit sets the maximum number of attribute sets of each
+// asynchronous instrument.  Once an instrument has limit-1 attribute
+// sets, the observations with new attribute sets are folded into a
+// single overflow series with the cardinality.OverflowKey=true attribute,
+// and the first such observation is reported to the global error handler
+// with an error wrapping ErrCardinalityLimit.  An attribute set counts
+// against the limit until it is no longer observed and its record is
+// removed at the end of a collection.  By default, there is no limit.
+func WithCardinalityLimit(limit int) Option {
+	return cardinalityLimitOption(limit)
+}
+
+type cardinalityLimitOption int
+
+func (o cardinalityLimitOption) apply(cfg config) config {
+	cfg.CardinalityLimit = int(o)
+	return cfg
+}
+
+// WithCardinalityLimits sets a function that returns the cardinality
+// limit of each asynchronous instrument, see WithCardinalityLimit.  When
+// it returns true, the limit it returns overrides the limit set by
+// WithCardinalityLimit, a limit less than one disabling it.
+func WithCardinalityLimits(limits func(*sdkapi.Descriptor) (int, bool)) Option {
+	return cardinalityLimitsOption(limits)
+}
+
+type cardinalityLimitsOption func(*sdkapi.Descriptor) (int, bool)
+
+func (o cardinalityLimitsOption) apply(cfg config) config {
+	cfg.CardinalityLimits = o
+	return cfg
+}
+
+// WithSeriesKey sets a function that returns the key identifying the
+// attribute set of a series within the Accumulator, in place of the
+// attribute.Distinct of the set.  This lets the series be keyed by the
+// identifier of an external store, e.g., a hash of the attributes that
+// is also used on export.
+//
+// The key must be comparable.  Equivalent attribute sets must have equal
+// keys, and attribute sets that are not equivalent must have different
+// keys, otherwise their measurements are aggregated together.  The
+// function is called for every measurement, so it should not allocate
+// when possible.
+func WithSeriesKey(key func(*attribute.Set) interface{}) Option {
+	return seriesKeyOption(key)
+}
+
+type seriesKeyOption func(*attribute.Set) interface{}
+
+func (o seriesKeyOption) apply(cfg config) config {
+	cfg.SeriesKey = o
+	return cfg
+}
+
+// WithCallbackRetries sets the number of times a callback that fails,
+// i.e., returns an error, panics or calls FailCallback, is run again
+// within the same collection, after waiting for backoff.  The
+// observations made by a failed run are discarded, so they are not
+// recorded twice.  When the retries are exhausted, the error of the last
+// run is returned by Collect.  By default, failed callbacks are not
+// retried.
+func WithCallbackRetries(retries int, backoff time.Duration) Option {
+	return callbackRetriesOption{retries: retries, backoff: backoff}
+}
+
+type callbackRetriesOption struct {
+	retries int
+	backoff time.Duration
+}
+
+func (o callbackRetriesOption) apply(cfg config) config {
+	cfg.CallbackRetries = o.retries
+	cfg.CallbackRetryBackoff = o.backoff
+	return cfg
+}
+
+// WithCallbackTimeout sets the time each run of a callback has to
+// return during Collect().  The callback is called with a Context whose
+// deadline expires after timeout.  A callback that has not returned by
+// then is abandoned: an error wrapping ErrCallbackTimeout is reported to
+// the global error handler, the observations it made so far are
+// collected, its later observations are dropped, and the collection
+// continues without waiting for it.  By default, callbacks have no
+// timeout.
+func WithCallbackTimeout(timeout time.Duration) Option {
+	return callbackTimeoutOption(timeout)
+}
+
+type callbackTimeoutOption time.Duration
+
+func (o callbackTimeoutOption) apply(cfg config) config {
+	cfg.CallbackTimeout = time.Duration(o)
+	return cfg
+}
+
+// WithUnitConverter registers a function that converts values from one
+// unit to another, e.g., from Celsius to Kelvin.  The function must be
+// pure; it is called for every measurement of the instruments configured
+// with WithUnitConversion to convert from the unit from to the unit to.
+func WithUnitConverter(from, to unit.Unit, convert func(float64) float64) Option {
+	return unitConverterOption{conversion: unitConversion{from: from, to: to}, convert: convert}
+}
+
+type unitConverterOption struct {
+	conversion unitConversion
+	convert    func(float64) float64
+}
+
+func (o unitConverterOption) apply(cfg config) config {
+	if cfg.UnitConverters == nil {
+		cfg.UnitConverters = map[unitConversion]func(float64) float64{}
+	}
+	cfg.UnitConverters[o.conversion] = o.convert
+	return cfg
+}
+
+// WithUnitConversion sets a function that returns, for each new
+// instrument, the unit that its measurements are converted to, and false
+// when they are not converted.  The measurements are converted using the
+// converter registered with WithUnitConverter for the unit of the
+// instrument and the returned unit, and are exported with the returned
+// unit.  When no converter is registered, the creation of the instrument
+// fails with an error wrapping ErrNoUnitConverter.
+func WithUnitConversion(to func(*sdkapi.Descriptor) (unit.Unit, bool)) Option {
+	return unitConversionOption(to)
+}
+
+type unitConversionOption func(*sdkapi.Descriptor) (unit.Unit, bool)
+
+func (o unitConversionOption) apply(cfg config) config {
+	cfg.UnitConversion = o
+	return cfg
+}
+
+// WithInstrumentAlias records the measurements of the instruments named
+// name under alias too, as if they were also made on an instrument named
+// alias with the same kind, description and unit.  This lets an
+// instrument be exported under both its old and new names while its name
+// is migrated; removing the option stops the alias without affecting the
+// instrument.
+//
+// The alias is created with the instrument, and is subject to the same
+// options as an instrument of that name, e.g., WithExcludedInstruments.
+// The option can be repeated to create several aliases.
+func WithInstrumentAlias(name, alias string) Option {
+	return aliasOption{name: name, alias: alias}
+}
+
+type aliasOption struct {
+	name, alias string
+}
+
+func (o aliasOption) apply(cfg config) config {
+	aliases := make(map[string][]string, len(cfg.Aliases)+1)
+	for name, names := range cfg.Aliases {
+		aliases[name] = names
+	}
+	aliases[o.name] = append(append([]string(nil), aliases[o.name]...), o.alias)
+	cfg.Aliases = aliases
+	return cfg
+}
+
+// WithInstrumentRename exports the data of the instruments named name
+// under newName, without changing the instrumentation.  The other
+// options still select the instrument by its name, and instruments are
+// still registered under their name, so that duplicate registrations
+// are detected as usual.  When two instruments are renamed to the same
+// name, an error wrapping ErrRenameConflict is handled.
+func WithInstrumentRename(name, newName string) Option {
+	return renameOption{name: name, newName: newName}
+}
+
+type renameOption struct {
+	name, newName string
+}
+
+func (o renameOption) apply(cfg config) config {
+	renames := make(map[string]string, len(cfg.Renames)+1)
+	for name, newName := range cfg.Renames {
+		renames[name] = newName
+	}
+	renames[o.name] = o.newName
+	cfg.Renames = renames
+	return cfg
+}
+
+// WithBackpressure sets a function that is called once per Collect,
+// before the callbacks run.  When it returns true, the callbacks of the
+// collection are signaled backpressure, see Backpressure, e.g., because
+// the export pipeline is congested.  The signal is advisory.
+func WithBackpressure(signal func() bool) Option {
+	return backpressureOption(signal)
+}
+
+type backpressureOption func() bool
+
+func (o backpressureOption) apply(cfg config) config {
+	cfg.Backpressure = o
+	return cfg
+}
+
+// WithMissingCallbackWarnings sets whether the asynchronous instruments
+// that are neither registered with a callback nor observed outside of
+// callbacks when they are first collected are reported to the global
+// error handler, with an error wrapping ErrNoCallback.  Each instrument
+// is checked once.  By default, they are reported.
+func WithMissingCallbackWarnings(enabled bool) Option {
+	return missingCallbackWarningsOption(enabled)
+}
+
+type missingCallbackWarningsOption bool
+
+func (o missingCallbackWarningsOption) apply(cfg config) config {
+	cfg.IgnoreMissingCallbacks = !bool(o)
+	return cfg
+}
+
+// WithAttributeInterning caches up to size attribute sets, so that the
+// measurements passing the same attributes in the same order reuse their
+// attribute set instead of sorting the attributes and building it again,
+// e.g., for instruments recorded in hot paths with a few constant
+// attribute sets.  The attributes are hashed to select one of size
+// slots, which holds the last set built for the attributes of that
+// hash, and compared with the attributes of the cached set, so that
+// distinct attribute sets are never confused.  Values less than one
+// disable the cache, which is the default.
+func WithAttributeInterning(size int) Option {
+	return attributeInterningOption(size)
+}
+
+type attributeInterningOption int
+
+func (o attributeInterningOption) apply(cfg config) config {
+	cfg.AttributeInterning = int(o)
+	return cfg
+}
+
+// WithSeedAttributes sets a function that returns, for each adding
+// instrument, an explicit list of attribute sets that are collected with
+// a zero value from the first collection after the instrument is
+// created, before any measurement of these sets, e.g., so that the rate
+// of a cumulative Counter does not start with a gap.  The sets are
+// seeded once, when the instrument is created: with a cumulative
+// temporality and a Processor with memory, their zero points are then
+// exported every collection.
+// The seeded sets count against the limits of the instrument, and are
+// filtered and enriched like measured attributes.  The Histograms and
+// GaugeObservers, whose zero points would be misleading, are not
+// seeded.
+func WithSeedAttributes(f func(*sdkapi.Descriptor) []attribute.Set) Option {
+	return seedAttributesOption(f)
+}
+
+type seedAttributesOption func(*sdkapi.Descriptor) []attribute.Set
+
+func (o seedAttributesOption) apply(cfg config) config {
+	cfg.SeedAttributes = o
+	return cfg
+}
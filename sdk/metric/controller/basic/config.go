@@ -18,7 +18,12 @@ import (
 	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdk "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/registry"
 	"go.opentelemetry.io/otel/sdk/resource"
 )
 
@@ -47,6 +52,13 @@ type config struct {
 	// Default value is 10s.  If zero, no Collect timeout is applied.
 	CollectTimeout time.Duration
 
+	// NearTimeoutThreshold is the fraction of CollectTimeout
+	// beyond which a collection is reported as near its timeout
+	// by LastCollection().
+	//
+	// Default value is 0.8.
+	NearTimeoutThreshold float64
+
 	// Exporter is used for exporting metric data.
 	//
 	// Note: Exporters such as Prometheus that pull data do not implement
@@ -57,6 +69,100 @@ type config struct {
 	//
 	// Default value is 10s.  If zero, no Export timeout is applied.
 	PushTimeout time.Duration
+
+	// Selectors restrict the metric data visited through
+	// ForEach, and therefore exported, to the data matched by at
+	// least one Selector.  Aggregation state is unaffected.
+	//
+	// Default value is empty, in which case all data is visited.
+	Selectors []Selector
+
+	// AccumulatorOptions configure the Accumulator created for
+	// each Meter.
+	AccumulatorOptions []sdk.Option
+
+	// Scheduler, if not nil, serializes the collections of this
+	// Controller with those of the other Controllers using the
+	// same Scheduler, in order of Priority.
+	//
+	// Default value is nil.
+	Scheduler *Scheduler
+
+	// Priority is the priority of the collections of this
+	// Controller in the Scheduler.  Higher values collect first.
+	Priority int
+
+	// ResourceAttributes lists the resource attributes that are
+	// copied to the measurements of selected instruments.
+	ResourceAttributes []resourceAttributes
+
+	// DefaultAttributes are added to the measurements of every
+	// instrument of every Meter.
+	DefaultAttributes []attribute.KeyValue
+
+	// Exclusions select the instruments that are not collected
+	// by this Controller.
+	Exclusions []Selector
+
+	// Inclusions, when not empty, select the only instruments
+	// that are collected by this Controller.
+	Inclusions []Selector
+
+	// UnitConversions select the instruments whose measurements
+	// are converted to another unit.
+	UnitConversions []unitConversion
+
+	// CardinalityLimits limit the number of attribute sets of the
+	// asynchronous instruments matched by a selector.
+	CardinalityLimits []cardinalityLimit
+
+	// AttributeKeys select the instruments whose measurements
+	// keep only an allowlist of attribute keys.
+	AttributeKeys []attributeKeys
+
+	// AttributeDrops select the instruments whose measurements
+	// drop the attributes matched by a predicate.
+	AttributeDrops []attributeDrop
+
+	// BaggageAttributes select the asynchronous instruments whose
+	// observations promote baggage members to attributes.
+	BaggageAttributes []baggageAttributes
+
+	// SeedAttributes select the adding instruments whose
+	// enumerated attribute sets are collected with a zero value
+	// before they are measured.
+	SeedAttributes []seedAttributes
+
+	// MetadataListener, if not nil, is called with the metadata
+	// of the instruments registered with the Controller.
+	MetadataListener func(instrumentation.Scope, registry.MetadataEvent)
+
+	// Producers are the sources of metric data from outside the
+	// SDK that are collected with the SDK instruments.
+	Producers []export.Producer
+
+	// CallbackDurations enables a histogram instrument that
+	// records the duration of each asynchronous instrument
+	// callback.
+	//
+	// Default value is false.
+	CallbackDurations bool
+
+	// InvalidMeasurements enables a counter instrument that
+	// counts the NaN and infinite measurements that are dropped.
+	//
+	// Default value is false.
+	InvalidMeasurements bool
+
+	// SelfMetrics enables the instruments that report on the
+	// Accumulators of the Controller: the callback durations
+	// and invalid measurements, as CallbackDurations and
+	// InvalidMeasurements do, the number of attribute sets of
+	// each instrument, and the measurements dropped by the
+	// series limits.
+	//
+	// Default value is false.
+	SelfMetrics bool
 }
 
 // Option is the interface that applies the value to a configuration option.
@@ -106,6 +212,19 @@ func (o collectTimeoutOption) apply(cfg config) config {
 	return cfg
 }
 
+// WithNearTimeoutThreshold sets the NearTimeoutThreshold configuration
+// option of a Config.
+func WithNearTimeoutThreshold(fraction float64) Option {
+	return nearTimeoutThresholdOption(fraction)
+}
+
+type nearTimeoutThresholdOption float64
+
+func (o nearTimeoutThresholdOption) apply(cfg config) config {
+	cfg.NearTimeoutThreshold = float64(o)
+	return cfg
+}
+
 // WithExporter sets the exporter configuration option of a Config.
 func WithExporter(exporter export.Exporter) Option {
 	return exporterOption{exporter}
@@ -129,3 +248,361 @@ func (o pushTimeoutOption) apply(cfg config) config {
 	cfg.PushTimeout = time.Duration(o)
 	return cfg
 }
+
+// WithSelectors sets the Selectors configuration option of a Config.
+// Multiple calls append to the list of selectors.
+func WithSelectors(selectors ...Selector) Option {
+	return selectorsOption(selectors)
+}
+
+type selectorsOption []Selector
+
+func (o selectorsOption) apply(cfg config) config {
+	cfg.Selectors = append(cfg.Selectors, o...)
+	return cfg
+}
+
+// WithAccumulatorOptions sets the options used to construct the
+// Accumulator of each Meter.  Multiple calls append to the list of
+// options.
+func WithAccumulatorOptions(opts ...sdk.Option) Option {
+	return accumulatorOptions(opts)
+}
+
+type accumulatorOptions []sdk.Option
+
+func (o accumulatorOptions) apply(cfg config) config {
+	cfg.AccumulatorOptions = append(cfg.AccumulatorOptions, o...)
+	return cfg
+}
+
+// WithCallbackDurations sets the CallbackDurations configuration option of a
+// Config.
+func WithCallbackDurations(enabled bool) Option {
+	return callbackDurationsOption(enabled)
+}
+
+type callbackDurationsOption bool
+
+func (o callbackDurationsOption) apply(cfg config) config {
+	cfg.CallbackDurations = bool(o)
+	return cfg
+}
+
+// WithDefaultAttributes adds to the DefaultAttributes configuration option
+// of a Config.  Attributes passed with a measurement take precedence over
+// the resource attributes set with WithResourceAttributes, which take
+// precedence over the default attributes with the same key.
+func WithDefaultAttributes(kvs ...attribute.KeyValue) Option {
+	return defaultAttributesOption(kvs)
+}
+
+type defaultAttributesOption []attribute.KeyValue
+
+func (o defaultAttributesOption) apply(cfg config) config {
+	cfg.DefaultAttributes = append(cfg.DefaultAttributes, o...)
+	return cfg
+}
+
+// WithInvalidMeasurements sets the InvalidMeasurements configuration
+// option of a Config.
+func WithInvalidMeasurements(enabled bool) Option {
+	return invalidMeasurementsOption(enabled)
+}
+
+type invalidMeasurementsOption bool
+
+func (o invalidMeasurementsOption) apply(cfg config) config {
+	cfg.InvalidMeasurements = bool(o)
+	return cfg
+}
+
+// WithSelfMetrics sets the SelfMetrics configuration option of a Config.
+func WithSelfMetrics(enabled bool) Option {
+	return selfMetricsOption(enabled)
+}
+
+type selfMetricsOption bool
+
+func (o selfMetricsOption) apply(cfg config) config {
+	cfg.SelfMetrics = bool(o)
+	return cfg
+}
+
+// WithScheduler sets the Scheduler and Priority configuration options of a
+// Config.  Controllers that share a Scheduler do not collect at the same
+// time; when several are waiting, the one with the highest priority
+// collects first.
+func WithScheduler(scheduler *Scheduler, priority int) Option {
+	return schedulerOption{scheduler: scheduler, priority: priority}
+}
+
+type schedulerOption struct {
+	scheduler *Scheduler
+	priority  int
+}
+
+func (o schedulerOption) apply(cfg config) config {
+	cfg.Scheduler = o.scheduler
+	cfg.Priority = o.priority
+	return cfg
+}
+
+// WithResourceAttributes copies the resource attributes with the given keys
+// to every measurement of the instruments matched by selector, so that
+// they become data point attributes.  Attributes passed with a
+// measurement take precedence.  Multiple calls are combined.
+func WithResourceAttributes(selector Selector, keys ...attribute.Key) Option {
+	return resourceAttributesOption{
+		selector: selector,
+		keys:     keys,
+	}
+}
+
+// resourceAttributes copies the resource attributes with the listed
+// keys to the instruments matched by a selector.
+type resourceAttributes struct {
+	selector Selector
+	keys     []attribute.Key
+}
+
+type resourceAttributesOption resourceAttributes
+
+func (o resourceAttributesOption) apply(cfg config) config {
+	cfg.ResourceAttributes = append(cfg.ResourceAttributes, resourceAttributes(o))
+	return cfg
+}
+
+// WithExclusions sets the Exclusions configuration option of a Config.
+// The instruments matched by any of the selectors are not collected by
+// the Controller: their measurements are discarded without allocating
+// any state.  Multiple calls append to the list of exclusions.
+func WithExclusions(selectors ...Selector) Option {
+	return exclusionsOption(selectors)
+}
+
+type exclusionsOption []Selector
+
+func (o exclusionsOption) apply(cfg config) config {
+	cfg.Exclusions = append(cfg.Exclusions, o...)
+	return cfg
+}
+
+// WithInclusions sets the Inclusions configuration option of a Config.
+// When inclusions are configured, only the instruments matched by one of
+// the selectors are collected by the Controller, e.g., by instrument
+// name prefix with InstrumentName "http.*" or by ScopeName, and the
+// others are excluded as with WithExclusions.  This includes the
+// instruments of WithSelfMetrics.  Multiple calls append to the list of
+// inclusions.
+func WithInclusions(selectors ...Selector) Option {
+	return inclusionsOption(selectors)
+}
+
+type inclusionsOption []Selector
+
+func (o inclusionsOption) apply(cfg config) config {
+	cfg.Inclusions = append(cfg.Inclusions, o...)
+	return cfg
+}
+
+// WithUnitConverter registers a function that converts values from one
+// unit to another, for use by WithUnitConversion, see
+// sdk.WithUnitConverter.
+func WithUnitConverter(from, to unit.Unit, convert func(float64) float64) Option {
+	return accumulatorOptions{sdk.WithUnitConverter(from, to, convert)}
+}
+
+// WithUnitConversion converts the measurements of the instruments matched
+// by selector to the unit to, using the converter registered with
+// WithUnitConverter, and exports them with that unit.  Creating a matched
+// instrument fails with an error wrapping sdk.ErrNoUnitConverter when no
+// converter is registered from its unit.  When several selectors match
+// an instrument, the first one applies.
+func WithUnitConversion(selector Selector, to unit.Unit) Option {
+	return unitConversionOption{
+		selector: selector,
+		to:       to,
+	}
+}
+
+// unitConversion converts the measurements of the instruments matched
+// by a selector to a unit.
+type unitConversion struct {
+	selector Selector
+	to       unit.Unit
+}
+
+type unitConversionOption unitConversion
+
+func (o unitConversionOption) apply(cfg config) config {
+	cfg.UnitConversions = append(cfg.UnitConversions, unitConversion(o))
+	return cfg
+}
+
+// WithCardinalityLimit limits the number of attribute sets of the
+// asynchronous instruments matched by selector, overriding the limit set
+// with sdk.WithCardinalityLimit, see sdk.WithCardinalityLimits.  A limit
+// less than one disables the limit of the matched instruments.  When
+// several selectors match an instrument, the first one applies.
+func WithCardinalityLimit(selector Selector, limit int) Option {
+	return cardinalityLimitOption{
+		selector: selector,
+		limit:    limit,
+	}
+}
+
+// cardinalityLimit limits the number of attribute sets of the
+// instruments matched by a selector.
+type cardinalityLimit struct {
+	selector Selector
+	limit    int
+}
+
+type cardinalityLimitOption cardinalityLimit
+
+func (o cardinalityLimitOption) apply(cfg config) config {
+	cfg.CardinalityLimits = append(cfg.CardinalityLimits, cardinalityLimit(o))
+	return cfg
+}
+
+// WithAttributeKeys keeps only the attributes with the given keys in the
+// measurements of the instruments matched by selector, see
+// sdk.WithAttributeFilter.  Measurements whose kept attributes are equal
+// are aggregated together, which reduces the cardinality of the matched
+// instruments.  When several selectors match an instrument, the first one
+// applies.
+func WithAttributeKeys(selector Selector, keys ...attribute.Key) Option {
+	return attributeKeysOption{
+		selector: selector,
+		keys:     keys,
+	}
+}
+
+// attributeKeys keeps only the attributes with one of keys in the
+// measurements of the instruments matched by a selector.
+type attributeKeys struct {
+	selector Selector
+	keys     []attribute.Key
+}
+
+type attributeKeysOption attributeKeys
+
+func (o attributeKeysOption) apply(cfg config) config {
+	cfg.AttributeKeys = append(cfg.AttributeKeys, attributeKeys(o))
+	return cfg
+}
+
+// WithAttributeDropIf drops the attributes for which drop returns true
+// from the measurements of the instruments matched by selector, e.g., to
+// drop a high-cardinality attribute such as a user identifier.  Like
+// WithAttributeKeys, attributes are dropped before the attribute set of a
+// measurement is computed, so measurements whose remaining attributes are
+// equal are aggregated together.  Every selector that matches an
+// instrument applies, together with the first WithAttributeKeys selector
+// that matches it.
+func WithAttributeDropIf(selector Selector, drop func(attribute.KeyValue) bool) Option {
+	return attributeDropOption{
+		selector: selector,
+		drop:     drop,
+	}
+}
+
+// attributeDrop drops the attributes for which drop returns true from
+// the measurements of the instruments matched by a selector.
+type attributeDrop struct {
+	selector Selector
+	drop     func(attribute.KeyValue) bool
+}
+
+type attributeDropOption attributeDrop
+
+func (o attributeDropOption) apply(cfg config) config {
+	cfg.AttributeDrops = append(cfg.AttributeDrops, attributeDrop(o))
+	return cfg
+}
+
+// WithBaggageAttributes promotes the baggage members with the given keys
+// to attributes of the observations of the asynchronous instruments
+// matched by selector, see sdk.WithBaggageAttributes.  When several
+// selectors match an instrument, the first one applies.
+func WithBaggageAttributes(selector Selector, keys ...string) Option {
+	return baggageAttributesOption{
+		selector: selector,
+		keys:     keys,
+	}
+}
+
+// baggageAttributes promotes the baggage members with one of keys to
+// attributes of the observations of the instruments matched by a
+// selector.
+type baggageAttributes struct {
+	selector Selector
+	keys     []string
+}
+
+type baggageAttributesOption baggageAttributes
+
+func (o baggageAttributesOption) apply(cfg config) config {
+	cfg.BaggageAttributes = append(cfg.BaggageAttributes, baggageAttributes(o))
+	return cfg
+}
+
+// WithSeedAttributes collects the given attribute sets of the adding
+// instruments matched by selector with a zero value, from the first
+// collection after each instrument is created and before these sets are
+// measured, see sdk.WithSeedAttributes.  The sets must be enumerated
+// explicitly.  The sets of every selector that matches an instrument are
+// seeded.
+func WithSeedAttributes(selector Selector, sets ...attribute.Set) Option {
+	return seedAttributesOption{
+		selector: selector,
+		sets:     sets,
+	}
+}
+
+// seedAttributes seeds the attribute sets sets of the instruments
+// matched by a selector.
+type seedAttributes struct {
+	selector Selector
+	sets     []attribute.Set
+}
+
+type seedAttributesOption seedAttributes
+
+func (o seedAttributesOption) apply(cfg config) config {
+	cfg.SeedAttributes = append(cfg.SeedAttributes, seedAttributes(o))
+	return cfg
+}
+
+// WithMetadataListener sets the MetadataListener configuration option of a
+// Config.  The function is called when an instrument is registered, and
+// when an instrument that is already registered is requested with a
+// different description or unit, see registry.WithMetadataListener.
+func WithMetadataListener(f func(instrumentation.Scope, registry.MetadataEvent)) Option {
+	return metadataListenerOption(f)
+}
+
+type metadataListenerOption func(instrumentation.Scope, registry.MetadataEvent)
+
+func (o metadataListenerOption) apply(cfg config) config {
+	cfg.MetadataListener = o
+	return cfg
+}
+
+// WithProducer adds a source of metric data from outside the SDK to the
+// Controller.  Each collection calls its Produce method, and ForEach
+// visits the produced records after those of the SDK instruments of the
+// same instrumentation scope.  The produced delta Sums are visited as
+// the running totals of their series when cumulative temporality is
+// selected.  Multiple calls append to the list of producers.
+func WithProducer(producer export.Producer) Option {
+	return producerOption{producer}
+}
+
+type producerOption struct{ producer export.Producer }
+
+func (o producerOption) apply(cfg config) config {
+	cfg.Producers = append(cfg.Producers, o.producer)
+	return cfg
+}
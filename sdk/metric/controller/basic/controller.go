@@ -16,21 +16,32 @@ package basic // import "go.opentelemetry.io/otel/sdk/metric/controller/basic"
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/unit"
 	"go.opentelemetry.io/otel/sdk/instrumentation"
 	sdk "go.opentelemetry.io/otel/sdk/metric"
 	controllerTime "go.opentelemetry.io/otel/sdk/metric/controller/time"
 	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/export/snapshot"
 	"go.opentelemetry.io/otel/sdk/metric/registry"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
 	"go.opentelemetry.io/otel/sdk/resource"
 )
 
+// instrumentationName is the name of the instrumentation scope of
+// the instruments that measure the SDK itself.
+const instrumentationName = "go.opentelemetry.io/otel/sdk/metric"
+
 // DefaultPeriod is used for:
 //
 // - the minimum time between calls to Collect()
@@ -38,10 +49,22 @@ import (
 // - the timeout for Collect().
 const DefaultPeriod = 10 * time.Second
 
+// DefaultNearTimeoutThreshold is the default fraction of the collection
+// timeout beyond which a collection is reported as near its timeout.
+const DefaultNearTimeoutThreshold = 0.8
+
 // ErrControllerStarted indicates that a controller was started more
 // than once.
 var ErrControllerStarted = fmt.Errorf("controller already started")
 
+// ErrControllerShutdown is returned by the methods of a controller that
+// was shut down, see Shutdown.
+var ErrControllerShutdown = fmt.Errorf("controller is shut down")
+
+// ErrCollectInProgress is returned by TryCollect and TryForceFlush when
+// another collection is in progress.
+var ErrCollectInProgress = fmt.Errorf("collection already in progress")
+
 // Controller organizes and synchronizes collection of metric data in
 // both "pull" and "push" configurations.  This supports two distinct
 // modes:
@@ -57,8 +80,9 @@ var ErrControllerStarted = fmt.Errorf("controller already started")
 // using the export.Reader RWLock interface.  Collection will
 // be blocked by a pull request in the basic controller.
 type Controller struct {
-	// lock synchronizes Start() and Stop().
+	// lock synchronizes Start(), Stop() and Shutdown().
 	lock                sync.Mutex
+	shutdown            bool
 	scopes              sync.Map
 	checkpointerFactory export.CheckpointerFactory
 
@@ -71,11 +95,70 @@ type Controller struct {
 
 	collectPeriod  time.Duration
 	collectTimeout time.Duration
+	nearTimeout    time.Duration
 	pushTimeout    time.Duration
 
+	selectors          []Selector
+	accumulatorOptions []sdk.Option
+	scheduler          *Scheduler
+	priority           int
+	resourceAttributes []resourceAttributes
+	exclusions         []Selector
+	inclusions         []Selector
+	unitConversions    []unitConversion
+	cardinalityLimits  []cardinalityLimit
+	attributeKeys      []attributeKeys
+	attributeDrops     []attributeDrop
+	baggageAttributes  []baggageAttributes
+	seedAttributes     []seedAttributes
+	metadataListener   func(instrumentation.Scope, registry.MetadataEvent)
+	producers          []export.Producer
+
+	// producedLock protects produced, the records of the
+	// Producers in the last collection, conflicts, the produced
+	// metric names that were reported to conflict, and totals,
+	// the running totals of the produced delta sums.
+	producedLock sync.RWMutex
+	produced     []producedScope
+	conflicts    map[producedName]struct{}
+	totals       map[seriesKey]*series
+
 	// collectedTime is used only in configurations with no
 	// exporter, when ticker != nil.
 	collectedTime time.Time
+
+	// collecting holds a value while Collect(), TryCollect(),
+	// ForceFlush() or a collection of the background goroutine is
+	// in progress.
+	collecting chan struct{}
+
+	// inProgress is the number of collections in progress,
+	// accessed atomically, see IsCollecting.
+	inProgress int32
+
+	// errLock protects lastErr and lastErrTime, the outcome of
+	// the most recent failed collection, and lastCollection.
+	errLock        sync.Mutex
+	lastErr        error
+	lastErrTime    time.Time
+	lastCollection CollectionInfo
+}
+
+// CollectionInfo describes the timing of a collection.
+type CollectionInfo struct {
+	// Time is the time the collection started.
+	Time time.Time
+
+	// Duration is the time spent collecting, not including the
+	// export.
+	Duration time.Duration
+
+	// NearTimeout is true when the collection of an
+	// instrumentation scope timed out or took longer than the
+	// configured fraction of the collection timeout, see
+	// WithNearTimeoutThreshold.  This indicates that the
+	// collection period should be lengthened.
+	NearTimeout bool
 }
 
 var _ export.InstrumentationLibraryReader = &Controller{}
@@ -83,7 +166,23 @@ var _ metric.MeterProvider = &Controller{}
 
 // Meter returns a new Meter defined by instrumentationName and configured
 // with opts.
+// The instrumentation scope, i.e., the name, version and schema URL of the
+// Meter, has its own Accumulator and instrument registry: instruments of
+// the same name in different scopes do not conflict, and ForEach groups
+// their records by scope.  The Meters of the same scope share their
+// instruments.
+// After Shutdown, the returned Meter is a no-op.
 func (c *Controller) Meter(instrumentationName string, opts ...metric.MeterOption) metric.Meter {
+	if c.isShutdown() {
+		return metric.NewNoopMeter()
+	}
+	return sdkapi.WrapMeterImpl(c.meterImpl(instrumentationName, opts...))
+}
+
+// meterImpl returns the instrument registry of the Accumulator of the
+// scope defined by instrumentationName and opts, creating them when
+// needed.
+func (c *Controller) meterImpl(instrumentationName string, opts ...metric.MeterOption) *registry.UniqueInstrumentMeterImpl {
 	cfg := metric.NewMeterConfig(opts...)
 	scope := instrumentation.Scope{
 		Name:      instrumentationName,
@@ -97,12 +196,306 @@ func (c *Controller) Meter(instrumentationName string, opts ...metric.MeterOptio
 		m, _ = c.scopes.LoadOrStore(
 			scope,
 			registry.NewUniqueInstrumentMeterImpl(&accumulatorCheckpointer{
-				Accumulator:  sdk.NewAccumulator(checkpointer),
+				Accumulator:  sdk.NewAccumulator(checkpointer, c.accumulatorOptionsFor(scope)...),
 				checkpointer: checkpointer,
 				scope:        scope,
-			}))
+			}, c.registryOptionsFor(scope)...))
+	}
+	return m.(*registry.UniqueInstrumentMeterImpl)
+}
+
+// MeterProvider provides the Meters of several Controllers at once, see
+// NewMeterProvider.
+type MeterProvider struct {
+	controllers []*Controller
+}
+
+var _ metric.MeterProvider = &MeterProvider{}
+
+// NewMeterProvider returns a MeterProvider whose instruments record
+// their measurements in every one of controllers, see
+// sdk.NewMultiMeterImpl.  Each Controller aggregates them with its own
+// Selectors and Checkpointer, and exports them with its own Exporter,
+// e.g., cumulative explicit-bucket histograms for Prometheus and delta
+// exponential histograms for OTLP.
+func NewMeterProvider(controllers ...*Controller) *MeterProvider {
+	return &MeterProvider{
+		controllers: append([]*Controller(nil), controllers...),
+	}
+}
+
+// Meter implements metric.MeterProvider.  The Controllers that are shut
+// down are left out.
+func (p *MeterProvider) Meter(instrumentationName string, opts ...metric.MeterOption) metric.Meter {
+	impls := make([]sdkapi.MeterImpl, 0, len(p.controllers))
+	for _, c := range p.controllers {
+		if c.isShutdown() {
+			continue
+		}
+		impls = append(impls, c.meterImpl(instrumentationName, opts...))
+	}
+	if len(impls) == 0 {
+		return metric.NewNoopMeter()
+	}
+	return sdkapi.WrapMeterImpl(sdk.NewMultiMeterImpl(impls...))
+}
+
+// accumulatorOptionsFor returns the options of the Accumulator of scope.
+func (c *Controller) accumulatorOptionsFor(scope instrumentation.Scope) []sdk.Option {
+	opts := c.accumulatorOptions[:len(c.accumulatorOptions):len(c.accumulatorOptions)]
+	if enrichment := c.enrichment(scope); enrichment != nil {
+		opts = append(opts, sdk.WithAttributeEnrichment(enrichment))
+	}
+	if exclude := c.exclusion(scope); exclude != nil {
+		opts = append(opts, sdk.WithExcludedInstruments(exclude))
+	}
+	if conversion := c.unitConversion(scope); conversion != nil {
+		opts = append(opts, sdk.WithUnitConversion(conversion))
+	}
+	if limits := c.cardinalityLimit(scope); limits != nil {
+		opts = append(opts, sdk.WithCardinalityLimits(limits))
+	}
+	if filter := c.attributeFilter(scope); filter != nil {
+		opts = append(opts, sdk.WithAttributeFilter(filter))
+	}
+	if keys := c.baggageKeys(scope); keys != nil {
+		opts = append(opts, sdk.WithBaggageAttributes(keys))
+	}
+	if seeds := c.seeds(scope); seeds != nil {
+		opts = append(opts, sdk.WithSeedAttributes(seeds))
+	}
+	if reporter, ok := c.exporter.(export.CongestionReporter); ok {
+		opts = append(opts, sdk.WithBackpressure(reporter.Congested))
+	}
+	return opts
+}
+
+// unitConversion returns the function that selects the unit the
+// measurements of each instrument of scope are converted to, or nil when
+// none can be converted.
+func (c *Controller) unitConversion(scope instrumentation.Scope) func(*sdkapi.Descriptor) (unit.Unit, bool) {
+	var scoped []unitConversion
+	for _, uc := range c.unitConversions {
+		if uc.selector.matchScope(scope) {
+			scoped = append(scoped, uc)
+		}
+	}
+	if len(scoped) == 0 {
+		return nil
+	}
+	return func(desc *sdkapi.Descriptor) (unit.Unit, bool) {
+		for _, uc := range scoped {
+			if uc.selector.matchDescriptor(desc) {
+				return uc.to, true
+			}
+		}
+		return "", false
+	}
+}
+
+// cardinalityLimit returns the function that selects the cardinality
+// limit of each instrument of scope, or nil when none is limited.
+func (c *Controller) cardinalityLimit(scope instrumentation.Scope) func(*sdkapi.Descriptor) (int, bool) {
+	var scoped []cardinalityLimit
+	for _, cl := range c.cardinalityLimits {
+		if cl.selector.matchScope(scope) {
+			scoped = append(scoped, cl)
+		}
+	}
+	if len(scoped) == 0 {
+		return nil
+	}
+	return func(desc *sdkapi.Descriptor) (int, bool) {
+		for _, cl := range scoped {
+			if cl.selector.matchDescriptor(desc) {
+				return cl.limit, true
+			}
+		}
+		return 0, false
+	}
+}
+
+// attributeFilter returns the function that selects the attribute filter
+// of each instrument of scope, or nil when no WithAttributeKeys or
+// WithAttributeDropIf selector matches the scope.
+func (c *Controller) attributeFilter(scope instrumentation.Scope) func(*sdkapi.Descriptor) attribute.Filter {
+	var scoped []attributeKeys
+	for _, ak := range c.attributeKeys {
+		if ak.selector.matchScope(scope) {
+			scoped = append(scoped, ak)
+		}
+	}
+	var drops []attributeDrop
+	for _, ad := range c.attributeDrops {
+		if ad.selector.matchScope(scope) {
+			drops = append(drops, ad)
+		}
+	}
+	if len(scoped) == 0 && len(drops) == 0 {
+		return nil
+	}
+	return func(desc *sdkapi.Descriptor) attribute.Filter {
+		var keys map[attribute.Key]struct{}
+		for _, ak := range scoped {
+			if !ak.selector.matchDescriptor(desc) {
+				continue
+			}
+			keys = make(map[attribute.Key]struct{}, len(ak.keys))
+			for _, key := range ak.keys {
+				keys[key] = struct{}{}
+			}
+			break
+		}
+		var matched []func(attribute.KeyValue) bool
+		for _, ad := range drops {
+			if ad.selector.matchDescriptor(desc) {
+				matched = append(matched, ad.drop)
+			}
+		}
+		if keys == nil && len(matched) == 0 {
+			return nil
+		}
+		return func(kv attribute.KeyValue) bool {
+			if keys != nil {
+				if _, ok := keys[kv.Key]; !ok {
+					return false
+				}
+			}
+			for _, drop := range matched {
+				if drop(kv) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+}
+
+// baggageKeys returns the function that selects the keys of the baggage
+// members promoted to attributes for each instrument of scope, or nil
+// when no WithBaggageAttributes selector matches the scope.
+func (c *Controller) baggageKeys(scope instrumentation.Scope) func(*sdkapi.Descriptor) []string {
+	var scoped []baggageAttributes
+	for _, ba := range c.baggageAttributes {
+		if ba.selector.matchScope(scope) {
+			scoped = append(scoped, ba)
+		}
+	}
+	if len(scoped) == 0 {
+		return nil
+	}
+	return func(desc *sdkapi.Descriptor) []string {
+		for _, ba := range scoped {
+			if ba.selector.matchDescriptor(desc) {
+				return ba.keys
+			}
+		}
+		return nil
+	}
+}
+
+// seeds returns the function that selects the attribute sets seeded for
+// each instrument of scope, or nil when no WithSeedAttributes selector
+// matches the scope.
+func (c *Controller) seeds(scope instrumentation.Scope) func(*sdkapi.Descriptor) []attribute.Set {
+	var scoped []seedAttributes
+	for _, sa := range c.seedAttributes {
+		if sa.selector.matchScope(scope) {
+			scoped = append(scoped, sa)
+		}
+	}
+	if len(scoped) == 0 {
+		return nil
+	}
+	return func(desc *sdkapi.Descriptor) []attribute.Set {
+		var sets []attribute.Set
+		for _, sa := range scoped {
+			if sa.selector.matchDescriptor(desc) {
+				sets = append(sets, sa.sets...)
+			}
+		}
+		return sets
+	}
+}
+
+// registryOptionsFor returns the options of the instrument registry of
+// scope.
+func (c *Controller) registryOptionsFor(scope instrumentation.Scope) []registry.Option {
+	if c.metadataListener == nil {
+		return nil
+	}
+	return []registry.Option{
+		registry.WithMetadataListener(func(event registry.MetadataEvent) {
+			c.metadataListener(scope, event)
+		}),
+	}
+}
+
+// exclusion returns the function that selects the instruments of scope
+// that are excluded from collection, or nil when none can be.  When
+// inclusions are configured, the instruments that no inclusion matches
+// are excluded as well.
+func (c *Controller) exclusion(scope instrumentation.Scope) func(*sdkapi.Descriptor) bool {
+	var scoped, included []Selector
+	for _, s := range c.exclusions {
+		if s.matchScope(scope) {
+			scoped = append(scoped, s)
+		}
+	}
+	for _, s := range c.inclusions {
+		if s.matchScope(scope) {
+			included = append(included, s)
+		}
+	}
+	if len(scoped) == 0 && len(c.inclusions) == 0 {
+		return nil
+	}
+	return func(desc *sdkapi.Descriptor) bool {
+		for _, s := range scoped {
+			if s.matchDescriptor(desc) {
+				return true
+			}
+		}
+		if len(c.inclusions) == 0 {
+			return false
+		}
+		for _, s := range included {
+			if s.matchDescriptor(desc) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// enrichment returns the function that computes the resource attributes
+// copied to the measurements of each instrument of scope, or nil when
+// none can be copied.
+func (c *Controller) enrichment(scope instrumentation.Scope) func(*sdkapi.Descriptor) []attribute.KeyValue {
+	var scoped []resourceAttributes
+	for _, ra := range c.resourceAttributes {
+		if ra.selector.matchScope(scope) {
+			scoped = append(scoped, ra)
+		}
+	}
+	if len(scoped) == 0 {
+		return nil
+	}
+	set := c.resource.Set()
+	return func(desc *sdkapi.Descriptor) []attribute.KeyValue {
+		var kvs []attribute.KeyValue
+		for _, ra := range scoped {
+			if !ra.selector.matchDescriptor(desc) {
+				continue
+			}
+			for _, key := range ra.keys {
+				if value, ok := set.Value(key); ok {
+					kvs = append(kvs, attribute.KeyValue{Key: key, Value: value})
+				}
+			}
+		}
+		return kvs
 	}
-	return sdkapi.WrapMeterImpl(m.(*registry.UniqueInstrumentMeterImpl))
 }
 
 type accumulatorCheckpointer struct {
@@ -121,6 +514,8 @@ func New(checkpointerFactory export.CheckpointerFactory, opts ...Option) *Contro
 		CollectPeriod:  DefaultPeriod,
 		CollectTimeout: DefaultPeriod,
 		PushTimeout:    DefaultPeriod,
+
+		NearTimeoutThreshold: DefaultNearTimeoutThreshold,
 	}
 	for _, opt := range opts {
 		c = opt.apply(c)
@@ -134,16 +529,120 @@ func New(checkpointerFactory export.CheckpointerFactory, opts ...Option) *Contro
 			otel.Handle(err)
 		}
 	}
-	return &Controller{
+	selectors := append(c.Selectors[:len(c.Selectors):len(c.Selectors)], c.Exclusions...)
+	for _, s := range append(selectors, c.Inclusions...) {
+		if err := s.validate(); err != nil {
+			otel.Handle(err)
+		}
+	}
+	for _, uc := range c.UnitConversions {
+		if err := uc.selector.validate(); err != nil {
+			otel.Handle(err)
+		}
+	}
+	cont := &Controller{
 		checkpointerFactory: checkpointerFactory,
 		exporter:            c.Exporter,
 		resource:            c.Resource,
 		stopCh:              nil,
 		clock:               controllerTime.RealClock{},
+		collecting:          make(chan struct{}, 1),
 
-		collectPeriod:  c.CollectPeriod,
-		collectTimeout: c.CollectTimeout,
-		pushTimeout:    c.PushTimeout,
+		collectPeriod:      c.CollectPeriod,
+		collectTimeout:     c.CollectTimeout,
+		nearTimeout:        time.Duration(c.NearTimeoutThreshold * float64(c.CollectTimeout)),
+		pushTimeout:        c.PushTimeout,
+		selectors:          c.Selectors,
+		accumulatorOptions: c.AccumulatorOptions,
+		scheduler:          c.Scheduler,
+		priority:           c.Priority,
+		resourceAttributes: c.ResourceAttributes,
+		exclusions:         c.Exclusions,
+		inclusions:         c.Inclusions,
+		unitConversions:    c.UnitConversions,
+		cardinalityLimits:  c.CardinalityLimits,
+		attributeKeys:      c.AttributeKeys,
+		attributeDrops:     c.AttributeDrops,
+		baggageAttributes:  c.BaggageAttributes,
+		seedAttributes:     c.SeedAttributes,
+		metadataListener:   c.MetadataListener,
+		producers:          c.Producers,
+	}
+	if len(c.DefaultAttributes) != 0 {
+		cont.accumulatorOptions = append(cont.accumulatorOptions, sdk.WithDefaultAttributes(c.DefaultAttributes...))
+	}
+	if c.CallbackDurations || c.SelfMetrics {
+		hist, err := cont.Meter(instrumentationName).SyncFloat64().Histogram(
+			"otel.sdk.metric.callback.duration",
+			instrument.WithUnit(unit.Milliseconds),
+			instrument.WithDescription("Duration of asynchronous instrument callbacks"),
+		)
+		if err != nil {
+			otel.Handle(err)
+		} else {
+			cont.accumulatorOptions = append(cont.accumulatorOptions, sdk.WithCallbackDurations(hist))
+		}
+	}
+	if c.InvalidMeasurements || c.SelfMetrics {
+		counter, err := cont.Meter(instrumentationName).SyncInt64().Counter(
+			"otel.sdk.metric.invalid_measurements",
+			instrument.WithUnit(unit.Dimensionless),
+			instrument.WithDescription("Measurements dropped because their value is NaN or infinite"),
+		)
+		if err != nil {
+			otel.Handle(err)
+		} else {
+			cont.accumulatorOptions = append(cont.accumulatorOptions, sdk.WithInvalidMeasurements(counter))
+		}
+	}
+	if c.SelfMetrics {
+		cont.registerSelfMetrics()
+	}
+	return cont
+}
+
+// registerSelfMetrics registers the instruments enabled by SelfMetrics
+// that are not already registered.  They are created before the
+// Accumulator options are extended, so that the Accumulator of their own
+// scope does not report on itself.
+func (c *Controller) registerSelfMetrics() {
+	meter := c.Meter(instrumentationName)
+	counter, err := meter.SyncInt64().Counter(
+		"otel.sdk.metric.dropped_measurements",
+		instrument.WithUnit(unit.Dimensionless),
+		instrument.WithDescription("Measurements dropped by the series limits"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	} else {
+		c.accumulatorOptions = append(c.accumulatorOptions, sdk.WithDroppedMeasurements(counter))
+	}
+
+	series, err := meter.AsyncInt64().Gauge(
+		"otel.sdk.metric.series",
+		instrument.WithUnit(unit.Dimensionless),
+		instrument.WithDescription("Number of attribute sets of each instrument"),
+	)
+	if err != nil {
+		otel.Handle(err)
+		return
+	}
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{series}, func(ctx context.Context) error {
+		for _, acc := range c.accumulatorList() {
+			if acc.scope.Name == instrumentationName {
+				continue
+			}
+			for name, n := range acc.SeriesCounts() {
+				series.Observe(ctx, int64(n),
+					attribute.String("scope", acc.scope.Name),
+					attribute.String("instrument", name),
+				)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		otel.Handle(err)
 	}
 }
 
@@ -177,6 +676,9 @@ func (c *Controller) Start(ctx context.Context) error {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	if c.shutdown {
+		return ErrControllerShutdown
+	}
 	if c.stopCh != nil {
 		return ErrControllerStarted
 	}
@@ -195,24 +697,70 @@ func (c *Controller) Start(ctx context.Context) error {
 //
 // Note that Stop() will not cancel an ongoing collection or export.
 func (c *Controller) Stop(ctx context.Context) error {
-	if lastCollection := func() bool {
-		c.lock.Lock()
-		defer c.lock.Unlock()
+	if !c.stopTicker() {
+		return nil
+	}
+	return c.collect(ctx)
+}
 
-		if c.stopCh == nil {
-			return false
-		}
+// stopTicker waits for the background goroutine to return, and reports
+// whether the controller was started.
+func (c *Controller) stopTicker() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-		close(c.stopCh)
-		c.stopCh = nil
-		c.wg.Wait()
-		c.ticker.Stop()
-		c.ticker = nil
-		return true
-	}(); !lastCollection {
+	if c.stopCh == nil {
+		return false
+	}
+
+	close(c.stopCh)
+	c.stopCh = nil
+	c.wg.Wait()
+	c.ticker.Stop()
+	c.ticker = nil
+	return true
+}
+
+// Shutdown stops the controller for good.  The background goroutine, if
+// any, is stopped, metrics are collected and exported one last time when
+// an Exporter is configured, and the accumulators of every
+// instrumentation scope are released: the instruments created before
+// Shutdown drop their measurements.  Afterwards, Start, Collect,
+// TryCollect and ForceFlush return ErrControllerShutdown and Meter
+// returns no-op Meters.  Calling Shutdown again has no effect.
+func (c *Controller) Shutdown(ctx context.Context) error {
+	c.lock.Lock()
+	if c.shutdown {
+		c.lock.Unlock()
 		return nil
 	}
-	return c.collect(ctx)
+	c.shutdown = true
+	c.lock.Unlock()
+
+	c.stopTicker()
+
+	var err error
+	if c.exporter != nil {
+		err = c.collect(ctx)
+	}
+
+	// The instruments keep their Accumulator, which is no
+	// longer collected.
+	for _, acc := range c.accumulatorList() {
+		acc.Shutdown()
+	}
+	c.scopes.Range(func(key, _ interface{}) bool {
+		c.scopes.Delete(key)
+		return true
+	})
+	return err
+}
+
+// isShutdown returns whether Shutdown was called.
+func (c *Controller) isShutdown() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.shutdown
 }
 
 // runTicker collection on ticker events until the stop channel is closed.
@@ -230,18 +778,158 @@ func (c *Controller) runTicker(ctx context.Context, stopCh chan struct{}) {
 	}
 }
 
+// ForceFlush collects and exports metrics immediately, without waiting
+// for the next tick of the collection period.  Calls to ForceFlush are
+// serialized with each other and with the collections of the background
+// goroutine.  ForceFlush returns nil without collecting when no Exporter
+// is configured, see WithExporter.
+func (c *Controller) ForceFlush(ctx context.Context) error {
+	if c.isShutdown() {
+		return ErrControllerShutdown
+	}
+	if c.exporter == nil {
+		return nil
+	}
+	return c.collect(ctx)
+}
+
+// TryForceFlush is like ForceFlush, except that it returns
+// ErrCollectInProgress immediately instead of waiting when another
+// collection is in progress, including one of the background goroutine
+// after Start().  This lets latency sensitive callers of a running
+// Controller skip a flush, or retry it later.
+func (c *Controller) TryForceFlush(ctx context.Context) error {
+	if c.isShutdown() {
+		return ErrControllerShutdown
+	}
+	if c.exporter == nil {
+		return nil
+	}
+	select {
+	case c.collecting <- struct{}{}:
+	default:
+		return ErrCollectInProgress
+	}
+	defer func() { <-c.collecting }()
+	return c.flush(ctx)
+}
+
 // collect computes a checkpoint and optionally exports it.
 func (c *Controller) collect(ctx context.Context) error {
-	if err := c.checkpoint(ctx); err != nil {
+	c.collecting <- struct{}{}
+	defer func() { <-c.collecting }()
+	return c.flush(ctx)
+}
+
+// flush performs the collection and export requested by collect() or
+// TryForceFlush().
+func (c *Controller) flush(ctx context.Context) error {
+	err := c.scheduled(ctx, c.collectAndExport)
+	c.setLastError(err)
+	return err
+}
+
+// scheduled calls f when the Scheduler, if any, grants this Controller
+// its turn to collect.
+func (c *Controller) scheduled(ctx context.Context, f func(context.Context) error) error {
+	if c.scheduler != nil {
+		if err := c.scheduler.acquire(ctx, c.priority); err != nil {
+			return err
+		}
+		defer c.scheduler.release()
+	}
+	atomic.AddInt32(&c.inProgress, 1)
+	defer atomic.AddInt32(&c.inProgress, -1)
+	return f(ctx)
+}
+
+// IsCollecting returns true while the Controller is collecting, either
+// in the background after Start() or in a call to Collect().  A
+// collection in the background includes its export.  This is safe to
+// call from any goroutine, e.g., to diagnose overlapping or stuck
+// collections together with LastError() and LastCollection().
+func (c *Controller) IsCollecting() bool {
+	return atomic.LoadInt32(&c.inProgress) != 0
+}
+
+func (c *Controller) collectAndExport(ctx context.Context) error {
+	// Failed callbacks do not prevent exporting the checkpoint,
+	// their error is returned after the export.
+	err := c.checkpoint(ctx)
+	if _, ok := err.(*sdk.CallbackError); err != nil && !ok {
 		return err
 	}
 	if c.exporter == nil {
-		return nil
+		c.acknowledge()
+		return err
 	}
 
 	// Note: this is not subject to collectTimeout.  This blocks the next
 	// collection despite collectTimeout because it holds a lock.
-	return c.export(ctx)
+	if eerr := c.export(ctx); eerr != nil {
+		return eerr
+	}
+	c.acknowledge()
+	return err
+}
+
+// acknowledge acknowledges the last collection of each checkpointer that
+// supports acknowledgement, see export.Acknowledger.
+func (c *Controller) acknowledge() {
+	for _, ac := range c.accumulatorList() {
+		a, ok := ac.checkpointer.(export.Acknowledger)
+		if !ok {
+			continue
+		}
+		ckpt := ac.checkpointer.Reader()
+		ckpt.Lock()
+		a.Acknowledge()
+		ckpt.Unlock()
+	}
+}
+
+// setLastError records the outcome of a collection.  A nil error
+// clears the last error.
+func (c *Controller) setLastError(err error) {
+	c.errLock.Lock()
+	defer c.errLock.Unlock()
+	c.lastErr = err
+	if err == nil {
+		c.lastErrTime = time.Time{}
+	} else {
+		c.lastErrTime = c.clock.Now()
+	}
+}
+
+// LastError returns the error of the most recent collection, including
+// its export, and the time it happened.  This returns a zero time and
+// a nil error when the most recent collection succeeded.
+//
+// This allows the failures of collections made in the background, after
+// Start() is called, to be observed.
+func (c *Controller) LastError() (time.Time, error) {
+	c.errLock.Lock()
+	defer c.errLock.Unlock()
+	return c.lastErrTime, c.lastErr
+}
+
+// setLastCollection records the timing of a collection.
+func (c *Controller) setLastCollection(info CollectionInfo) {
+	c.errLock.Lock()
+	defer c.errLock.Unlock()
+	c.lastCollection = info
+}
+
+// LastCollection returns the timing of the most recent collection,
+// whether it succeeded or not.  This returns a zero CollectionInfo
+// before the first collection.
+//
+// This allows a scheduler to adapt the collection period to the time
+// collections take, see WithNearTimeoutThreshold.
+func (c *Controller) LastCollection() CollectionInfo {
+	c.errLock.Lock()
+	defer c.errLock.Unlock()
+	return c.lastCollection
 }
 
 // accumulatorList returns a snapshot of current accumulators
@@ -261,21 +949,70 @@ func (c *Controller) accumulatorList() []*accumulatorCheckpointer {
 // checkpoint calls the Accumulator and Checkpointer interfaces to
 // compute the Reader.  This applies the configured collection
 // timeout.  Note that this does not try to cancel a Collect or Export
-// when Stop() is called.
-func (c *Controller) checkpoint(ctx context.Context) error {
+// when Stop() is called.  The errors of failed callbacks do not stop
+// the checkpoint, they are returned together in a *sdk.CallbackError
+// once every scope is checkpointed.
+func (c *Controller) checkpoint(ctx context.Context) (err error) {
+	start := c.clock.Now()
+	var longest time.Duration
+	defer func() {
+		c.setLastCollection(CollectionInfo{
+			Time:     start,
+			Duration: c.clock.Now().Sub(start),
+			NearTimeout: errors.Is(err, context.DeadlineExceeded) ||
+				(c.collectTimeout > 0 && longest >= c.nearTimeout),
+		})
+	}()
+	var callbackErrs []error
 	for _, impl := range c.accumulatorList() {
-		if err := c.checkpointSingleAccumulator(ctx, impl); err != nil {
+		begin := c.clock.Now()
+		var cbErrs []error
+		cbErrs, err = c.checkpointSingleAccumulator(ctx, impl, (*sdk.Accumulator).Collect)
+		if d := c.clock.Now().Sub(begin); d > longest {
+			longest = d
+		}
+		if err != nil {
 			return err
 		}
+		callbackErrs = append(callbackErrs, cbErrs...)
+	}
+	if err = c.produce(ctx); err != nil {
+		return err
+	}
+	if len(callbackErrs) != 0 {
+		return &sdk.CallbackError{Errors: callbackErrs}
 	}
 	return nil
 }
 
+// checkpointTagged is like checkpoint, but it collects each accumulator
+// with CollectTagged, and does not call the Producers.
+func (c *Controller) checkpointTagged(tags []string) func(context.Context) error {
+	collect := func(a *sdk.Accumulator, ctx context.Context) (int, error) {
+		return a.CollectTagged(ctx, tags...)
+	}
+	return func(ctx context.Context) error {
+		var callbackErrs []error
+		for _, impl := range c.accumulatorList() {
+			cbErrs, err := c.checkpointSingleAccumulator(ctx, impl, collect)
+			if err != nil {
+				return err
+			}
+			callbackErrs = append(callbackErrs, cbErrs...)
+		}
+		if len(callbackErrs) != 0 {
+			return &sdk.CallbackError{Errors: callbackErrs}
+		}
+		return nil
+	}
+}
+
 // checkpointSingleAccumulator checkpoints a single instrumentation
 // scope's accumulator, which involves calling
-// checkpointer.StartCollection, accumulator.Collect, and
-// checkpointer.FinishCollection in sequence.
-func (c *Controller) checkpointSingleAccumulator(ctx context.Context, ac *accumulatorCheckpointer) error {
+// checkpointer.StartCollection, collect, and
+// checkpointer.FinishCollection in sequence.  It returns the errors of
+// the callbacks that failed separately.
+func (c *Controller) checkpointSingleAccumulator(ctx context.Context, ac *accumulatorCheckpointer, collect func(*sdk.Accumulator, context.Context) (int, error)) ([]error, error) {
 	ckpt := ac.checkpointer.Reader()
 	ckpt.Lock()
 	defer ckpt.Unlock()
@@ -288,7 +1025,13 @@ func (c *Controller) checkpointSingleAccumulator(ctx context.Context, ac *accumu
 		defer cancel()
 	}
 
-	_ = ac.Accumulator.Collect(ctx)
+	var callbackErrs []error
+	if _, cerr := collect(ac.Accumulator, ctx); cerr != nil {
+		var cbErr *sdk.CallbackError
+		if errors.As(cerr, &cbErr) {
+			callbackErrs = cbErr.Errors
+		}
+	}
 
 	var err error
 	select {
@@ -307,7 +1050,7 @@ func (c *Controller) checkpointSingleAccumulator(ctx context.Context, ac *accumu
 		}
 	}
 
-	return err
+	return callbackErrs, err
 }
 
 // export calls the exporter with a read lock on the Reader,
@@ -322,19 +1065,46 @@ func (c *Controller) export(ctx context.Context) error { // nolint:revive  // me
 	return c.exporter.Export(ctx, c.resource, c)
 }
 
-// ForEach implements export.InstrumentationLibraryReader.
+// ForEach implements export.InstrumentationLibraryReader.  When the
+// controller is configured with Selectors, only the matching records
+// are visited.  The records of the Producers follow those of the SDK
+// instruments of their instrumentation scope.
 func (c *Controller) ForEach(readerFunc func(l instrumentation.Library, r export.Reader) error) error {
+	c.producedLock.RLock()
+	defer c.producedLock.RUnlock()
+
+	merged := map[instrumentation.Scope]bool{}
 	for _, acPair := range c.accumulatorList() {
-		reader := acPair.checkpointer.Reader()
+		var reader export.Reader = acPair.checkpointer.Reader()
+		if records := c.producedFor(acPair.scope); len(records) != 0 {
+			reader = producedReader{Reader: reader, records: records}
+			merged[acPair.scope] = true
+		}
+		selected, ok := selectReader(reader, acPair.scope, c.selectors)
+		if !ok {
+			continue
+		}
 		// TODO: We should not fail fast; instead accumulate errors.
 		if err := func() error {
 			reader.RLock()
 			defer reader.RUnlock()
-			return readerFunc(acPair.scope, reader)
+			return readerFunc(acPair.scope, selected)
 		}(); err != nil {
 			return err
 		}
 	}
+	for _, ps := range c.produced {
+		if merged[ps.scope] {
+			continue
+		}
+		selected, ok := selectReader(producedReader{records: ps.records}, ps.scope, c.selectors)
+		if !ok {
+			continue
+		}
+		if err := readerFunc(ps.scope, selected); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -349,18 +1119,113 @@ func (c *Controller) IsRunning() bool {
 
 // Collect requests a collection.  The collection will be skipped if
 // the last collection is aged less than the configured collection
-// period.
+// period.  Concurrent calls wait for each other, see TryCollect.
+//
+// When callbacks fail, the collection completes and a *sdk.CallbackError
+// holding their errors is returned.
 func (c *Controller) Collect(ctx context.Context) error {
+	if c.isShutdown() {
+		return ErrControllerShutdown
+	}
 	if c.IsRunning() {
 		// When there's a non-nil ticker, there's a goroutine
 		// computing checkpoints with the collection period.
 		return ErrControllerStarted
 	}
+	c.collecting <- struct{}{}
+	defer func() { <-c.collecting }()
+	return c.pull(ctx)
+}
+
+// CollectSnapshot is like Collect, but it also returns a copy of the
+// collected metric data with the temporality chosen by tempSelector, see
+// snapshot.New.  The Snapshot does not depend on the state of the
+// Controller: it can be kept after later collections, and passed to an
+// Exporter.  ForEach remains the way to read the collected data in
+// place, without copying it.  Snapshots do not keep exemplars: their
+// records do not implement aggregation.Exemplars.
+//
+// When callbacks fail, the Snapshot of the completed collection is
+// returned with the *sdk.CallbackError holding their errors.
+func (c *Controller) CollectSnapshot(ctx context.Context, tempSelector aggregation.TemporalitySelector) (*snapshot.Snapshot, error) {
+	if c.isShutdown() {
+		return nil, ErrControllerShutdown
+	}
+	if c.IsRunning() {
+		return nil, ErrControllerStarted
+	}
+	c.collecting <- struct{}{}
+	defer func() { <-c.collecting }()
+
+	err := c.pull(ctx)
+	var cbErr *sdk.CallbackError
+	if err != nil && !errors.As(err, &cbErr) {
+		return nil, err
+	}
+	s, serr := snapshot.New(c, tempSelector)
+	if serr != nil {
+		return nil, serr
+	}
+	return s, err
+}
+
+// TryCollect is like Collect, except that it returns
+// ErrCollectInProgress immediately instead of waiting when another call
+// to Collect() or TryCollect() is in progress.  This lets latency
+// sensitive callers skip a collection, or retry it later.
+func (c *Controller) TryCollect(ctx context.Context) error {
+	if c.isShutdown() {
+		return ErrControllerShutdown
+	}
+	if c.IsRunning() {
+		return ErrControllerStarted
+	}
+	select {
+	case c.collecting <- struct{}{}:
+	default:
+		return ErrCollectInProgress
+	}
+	defer func() { <-c.collecting }()
+	return c.pull(ctx)
+}
+
+// CollectTagged is like Collect, but it collects each scope with
+// sdk.Accumulator.CollectTagged: only the callbacks tagged with one of
+// tags run, see sdk.TagCallback, and only the records of their
+// instruments are checkpointed, e.g., to refresh the observations of one
+// subsystem without running every callback.  The collection period is
+// not applied, and the Producers are not called.  With a processor that
+// does not keep memory, ForEach then visits the records of the tagged
+// instruments only.
+func (c *Controller) CollectTagged(ctx context.Context, tags ...string) error {
+	if c.isShutdown() {
+		return ErrControllerShutdown
+	}
+	if c.IsRunning() {
+		return ErrControllerStarted
+	}
+	c.collecting <- struct{}{}
+	defer func() { <-c.collecting }()
+
+	c.acknowledge()
+	err := c.scheduled(ctx, c.checkpointTagged(tags))
+	c.setLastError(err)
+	return err
+}
+
+// pull performs the collection requested by Collect() or TryCollect().
+func (c *Controller) pull(ctx context.Context) error {
 	if !c.shouldCollect() {
 		return nil
 	}
 
-	return c.checkpoint(ctx)
+	// The data of the prior collection was read by the caller,
+	// which acknowledges it.
+	c.acknowledge()
+
+	err := c.scheduled(ctx, c.checkpoint)
+	c.setLastError(err)
+	return err
 }
 
 // shouldCollect returns true if the collector should collect now,
@@ -18,23 +18,39 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
 	ottest "go.opentelemetry.io/otel/internal/internaltest"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/instrument/asyncint64"
+	"go.opentelemetry.io/otel/metric/instrument/syncint64"
+	"go.opentelemetry.io/otel/metric/unit"
 	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/exemplar"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/sum"
 	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
 	"go.opentelemetry.io/otel/sdk/metric/controller/controllertest"
 	"go.opentelemetry.io/otel/sdk/metric/export"
 	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/export/snapshot"
+	"go.opentelemetry.io/otel/sdk/metric/number"
 	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
 	"go.opentelemetry.io/otel/sdk/metric/processor/processortest"
+	"go.opentelemetry.io/otel/sdk/metric/registry"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
 	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const envVar = "OTEL_RESOURCE_ATTRIBUTES"
@@ -159,10 +175,11 @@ func TestStartNoExporter(t *testing.T) {
 	counterObserver, err := meter.AsyncInt64().Counter("calls.lastvalue")
 	require.NoError(t, err)
 
-	err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) {
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) error {
 		calls++
 		checkTestContext(t, ctx)
 		counterObserver.Observe(ctx, calls, attribute.String("A", "B"))
+		return nil
 	})
 	require.NoError(t, err)
 
@@ -230,10 +247,11 @@ func TestObserverCanceled(t *testing.T) {
 	counterObserver, err := meter.AsyncInt64().Counter("done.lastvalue")
 	require.NoError(t, err)
 
-	err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) {
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) error {
 		<-ctx.Done()
 		calls++
 		counterObserver.Observe(ctx, calls)
+		return nil
 	})
 	require.NoError(t, err)
 
@@ -263,10 +281,11 @@ func TestObserverContext(t *testing.T) {
 	counterObserver, err := meter.AsyncInt64().Counter("done.lastvalue")
 	require.NoError(t, err)
 
-	err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) {
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) error {
 		time.Sleep(10 * time.Millisecond)
 		checkTestContext(t, ctx)
 		counterObserver.Observe(ctx, 1)
+		return nil
 	})
 	require.NoError(t, err)
 
@@ -331,9 +350,10 @@ func TestExportTimeout(t *testing.T) {
 	counterObserver, err := meter.AsyncInt64().Counter("one.lastvalue")
 	require.NoError(t, err)
 
-	err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) {
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) error {
 		calls++
 		counterObserver.Observe(ctx, calls)
+		return nil
 	})
 	require.NoError(t, err)
 
@@ -389,9 +409,10 @@ func TestCollectAfterStopThenStartAgain(t *testing.T) {
 	counterObserver, err := meter.AsyncInt64().Counter("one.lastvalue")
 	require.NoError(t, err)
 
-	err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) {
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) error {
 		calls++
 		counterObserver.Observe(ctx, int64(calls))
+		return nil
 	})
 	require.NoError(t, err)
 
@@ -491,3 +512,1641 @@ func TestRegistryFunction(t *testing.T) {
 		"counter.sum//": 20,
 	}, exp.Values())
 }
+
+func TestCallbackDurations(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			simple.NewWithHistogramDistribution(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithCallbackDurations(true),
+	)
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#CallbackDurations")
+
+	gauge, err := meter.AsyncInt64().Gauge("gauge")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		gauge.Observe(ctx, 1)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The durations of a collection are exported by the next one
+	// when the SDK's own meter is collected first.
+	ctx := context.Background()
+	require.NoError(t, cont.Collect(ctx))
+	require.NoError(t, cont.Collect(ctx))
+
+	var found bool
+	require.NoError(t, cont.ForEach(
+		func(_ instrumentation.Scope, reader export.Reader) error {
+			return reader.ForEach(
+				aggregation.CumulativeTemporalitySelector(),
+				func(record export.Record) error {
+					if record.Descriptor().Name() != "otel.sdk.metric.callback.duration" {
+						return nil
+					}
+					name, _ := record.Attributes().Value("callback")
+					found = strings.HasPrefix(name.AsString(), "go.opentelemetry.io/otel/sdk/metric/controller/basic_test.TestCallbackDurations")
+					return nil
+				},
+			)
+		}))
+	require.True(t, found)
+}
+
+func TestSelfMetrics(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			simple.NewWithHistogramDistribution(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithSelfMetrics(true),
+		controller.WithCardinalityLimit(controller.Selector{InstrumentName: "counter"}, 2),
+	)
+	const scope = "go.opentelemetry.io/otel/sdk/metric/controller/basic_test#SelfMetrics"
+	meter := cont.Meter(scope)
+
+	counter, err := meter.AsyncInt64().Counter("counter")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{counter}, func(ctx context.Context) error {
+		for id := 0; id < 4; id++ {
+			counter.Observe(ctx, 1, attribute.Int("id", id))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The self-metrics of a collection are exported by the next
+	// one when the SDK's own meter is collected first.
+	ctx := context.Background()
+	require.NoError(t, cont.Collect(ctx))
+	require.NoError(t, cont.Collect(ctx))
+	require.ErrorIs(t, testHandler.Flush(), sdk.ErrCardinalityLimit)
+
+	values := map[string]int64{}
+	require.NoError(t, cont.ForEach(
+		func(_ instrumentation.Scope, reader export.Reader) error {
+			return reader.ForEach(
+				aggregation.CumulativeTemporalitySelector(),
+				func(record export.Record) error {
+					enc := record.Attributes().Encoded(attribute.DefaultEncoder())
+					key := record.Descriptor().Name() + "/" + enc
+					switch agg := record.Aggregation().(type) {
+					case aggregation.Sum:
+						sum, err := agg.Sum()
+						require.NoError(t, err)
+						values[key] = sum.AsInt64()
+					case aggregation.LastValue:
+						last, _, err := agg.LastValue()
+						require.NoError(t, err)
+						values[key] = last.AsInt64()
+					}
+					return nil
+				},
+			)
+		}))
+
+	// Three attribute sets are dropped into the overflow series by
+	// each collection.
+	dropped := values["otel.sdk.metric.dropped_measurements/instrument=counter,reason=cardinality_limit"]
+	require.GreaterOrEqual(t, dropped, int64(3))
+	require.Zero(t, dropped%3)
+	// The counter has one attribute set and the overflow series.
+	require.Equal(t, int64(2), values["otel.sdk.metric.series/instrument=counter,scope="+scope])
+}
+
+func TestResourceAttributes(t *testing.T) {
+	res := resource.NewSchemaless(
+		attribute.String("pod", "p1"),
+		attribute.String("node", "n1"),
+	)
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(res),
+		controller.WithResourceAttributes(
+			controller.Selector{InstrumentName: "key.*"},
+			"pod", "missing",
+		),
+		controller.WithResourceAttributes(
+			controller.Selector{ScopeName: "other"},
+			"node",
+		),
+	)
+	ctx := context.Background()
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#ResourceAttributes")
+
+	key, err := meter.SyncInt64().Counter("key.sum")
+	require.NoError(t, err)
+	other, err := meter.SyncInt64().Counter("other.sum")
+	require.NoError(t, err)
+
+	key.Add(ctx, 1, attribute.String("A", "B"))
+	key.Add(ctx, 2, attribute.String("pod", "override"))
+	other.Add(ctx, 3)
+
+	require.NoError(t, cont.Collect(ctx))
+	require.EqualValues(t, map[string]float64{
+		"key.sum/A=B,pod=p1/":   1,
+		"key.sum/pod=override/": 2,
+		"other.sum//":           3,
+	}, getMap(t, cont))
+}
+
+func TestDefaultAttributes(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.NewSchemaless(attribute.String("pod", "p1"))),
+		controller.WithDefaultAttributes(
+			attribute.String("library.version", "v1"),
+			attribute.String("pod", "default"),
+		),
+		controller.WithResourceAttributes(controller.Selector{InstrumentName: "pod.*"}, "pod"),
+	)
+	ctx := context.Background()
+
+	first, err := cont.Meter("first").SyncInt64().Counter("pod.sum")
+	require.NoError(t, err)
+	second, err := cont.Meter("second").SyncInt64().Counter("plain.sum")
+	require.NoError(t, err)
+
+	first.Add(ctx, 1)
+	second.Add(ctx, 2)
+	second.Add(ctx, 3, attribute.String("library.version", "override"))
+
+	require.NoError(t, cont.Collect(ctx))
+	require.EqualValues(t, map[string]float64{
+		"pod.sum/library.version=v1,pod=p1/":              1,
+		"plain.sum/library.version=v1,pod=default/":       2,
+		"plain.sum/library.version=override,pod=default/": 3,
+	}, getMap(t, cont))
+}
+
+func TestExclusions(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithExclusions(controller.Selector{InstrumentName: "wasteful.*"}),
+	)
+	ctx := context.Background()
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#Exclusions")
+
+	kept, err := meter.SyncInt64().Counter("kept.sum")
+	require.NoError(t, err)
+	wasteful, err := meter.SyncInt64().Counter("wasteful.sum")
+	require.NoError(t, err)
+	observer, err := meter.AsyncInt64().Gauge("wasteful.lastvalue")
+	require.NoError(t, err)
+
+	var calls int
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{observer}, func(ctx context.Context) error {
+		calls++
+		observer.Observe(ctx, 1)
+		return nil
+	})
+	require.NoError(t, err)
+
+	kept.Add(ctx, 1)
+	wasteful.Add(ctx, 2)
+
+	require.NoError(t, cont.Collect(ctx))
+	require.EqualValues(t, map[string]float64{
+		"kept.sum//": 1,
+	}, getMap(t, cont))
+	require.Equal(t, 0, calls)
+}
+
+func TestInclusions(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithInclusions(controller.Selector{InstrumentName: "http.*"}),
+	)
+	ctx := context.Background()
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#Inclusions")
+
+	requests, err := meter.SyncInt64().Counter("http.requests.sum")
+	require.NoError(t, err)
+	queries, err := meter.SyncInt64().Counter("db.queries.sum")
+	require.NoError(t, err)
+	observer, err := meter.AsyncInt64().Gauge("db.connections.lastvalue")
+	require.NoError(t, err)
+
+	var calls int
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{observer}, func(ctx context.Context) error {
+		calls++
+		observer.Observe(ctx, 1)
+		return nil
+	})
+	require.NoError(t, err)
+
+	requests.Add(ctx, 1)
+	queries.Add(ctx, 2)
+
+	require.NoError(t, cont.Collect(ctx))
+	require.EqualValues(t, map[string]float64{
+		"http.requests.sum//": 1,
+	}, getMap(t, cont))
+	require.Equal(t, 0, calls)
+
+	// The instruments of other scopes are not matched by a selector
+	// of ScopeName.
+	cont = controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithInclusions(controller.Selector{ScopeName: "included"}),
+	)
+	for _, name := range []string{"included", "other"} {
+		counter, err := cont.Meter(name).SyncInt64().Counter(name + ".sum")
+		require.NoError(t, err)
+		counter.Add(ctx, 1)
+	}
+	require.NoError(t, cont.Collect(ctx))
+	require.EqualValues(t, map[string]float64{
+		"included.sum//": 1,
+	}, getMap(t, cont))
+}
+
+func TestSeedAttributes(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+			processor.WithMemory(true),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithSeedAttributes(
+			controller.Selector{InstrumentName: "requests.*"},
+			attribute.NewSet(attribute.String("code", "200")),
+			attribute.NewSet(attribute.String("code", "500")),
+		),
+	)
+	ctx := context.Background()
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#SeedAttributes")
+
+	requests, err := meter.SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+	_, err = meter.SyncInt64().Counter("errors.sum")
+	require.NoError(t, err)
+
+	// The seed sets are exported with a zero value before any
+	// measurement, in every collection.
+	for i := 0; i < 2; i++ {
+		require.NoError(t, cont.Collect(ctx))
+		require.EqualValues(t, map[string]float64{
+			"requests.sum/code=200/": 0,
+			"requests.sum/code=500/": 0,
+		}, getMap(t, cont))
+	}
+
+	requests.Add(ctx, 2, attribute.String("code", "200"))
+	require.NoError(t, cont.Collect(ctx))
+	require.EqualValues(t, map[string]float64{
+		"requests.sum/code=200/": 2,
+		"requests.sum/code=500/": 0,
+	}, getMap(t, cont))
+}
+
+func TestMetadataListener(t *testing.T) {
+	var scopes []string
+	var names []string
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithMetadataListener(func(scope instrumentation.Scope, event registry.MetadataEvent) {
+			scopes = append(scopes, scope.Name)
+			names = append(names, event.Registered.Name()+"/"+event.Requested.Description())
+		}),
+	)
+
+	_, err := cont.Meter("a").SyncInt64().Counter("counter.sum", instrument.WithDescription("first"))
+	require.NoError(t, err)
+	_, err = cont.Meter("a").SyncInt64().Counter("counter.sum", instrument.WithDescription("second"))
+	require.NoError(t, err)
+	_, err = cont.Meter("b").SyncInt64().Counter("counter.sum", instrument.WithDescription("second"))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"a", "a", "b"}, scopes)
+	require.Equal(t, []string{"counter.sum/first", "counter.sum/second", "counter.sum/second"}, names)
+}
+
+func TestLastCollection(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithCollectTimeout(10*time.Second),
+		controller.WithNearTimeoutThreshold(0.5),
+		controller.WithResource(resource.Empty()),
+	)
+	mock := controllertest.NewMockClock()
+	cont.SetClock(mock)
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#LastCollection")
+
+	require.Equal(t, controller.CollectionInfo{}, cont.LastCollection())
+
+	// The callback takes as long as the clock is advanced.
+	var elapsed time.Duration
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		mock.Add(elapsed)
+		gauge.Observe(ctx, 1)
+		return nil
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	elapsed = 2 * time.Second
+	start := mock.Now()
+	require.NoError(t, cont.Collect(ctx))
+	require.Equal(t, controller.CollectionInfo{
+		Time:     start,
+		Duration: 2 * time.Second,
+	}, cont.LastCollection())
+
+	elapsed = 6 * time.Second
+	start = mock.Now()
+	require.NoError(t, cont.Collect(ctx))
+	require.Equal(t, controller.CollectionInfo{
+		Time:        start,
+		Duration:    6 * time.Second,
+		NearTimeout: true,
+	}, cont.LastCollection())
+}
+
+func TestTryCollect(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+	)
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#TryCollect")
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var block bool
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		if block {
+			entered <- struct{}{}
+			<-release
+		}
+		gauge.Observe(ctx, 1)
+		return nil
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.False(t, cont.IsCollecting())
+	require.NoError(t, cont.TryCollect(ctx))
+
+	block = true
+	done := make(chan error)
+	go func() {
+		done <- cont.Collect(ctx)
+	}()
+	<-entered
+	require.True(t, cont.IsCollecting())
+	require.ErrorIs(t, cont.TryCollect(ctx), controller.ErrCollectInProgress)
+
+	block = false
+	close(release)
+	require.NoError(t, <-done)
+	require.False(t, cont.IsCollecting())
+	require.NoError(t, cont.TryCollect(ctx))
+	require.EqualValues(t, map[string]float64{
+		"gauge.lastvalue//": 1,
+	}, getMap(t, cont))
+}
+
+func TestTryForceFlush(t *testing.T) {
+	exp := processortest.New(aggregation.CumulativeTemporalitySelector(), attribute.DefaultEncoder())
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithExporter(exp),
+	)
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#TryForceFlush")
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var block bool
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		if block {
+			entered <- struct{}{}
+			<-release
+		}
+		gauge.Observe(ctx, 1)
+		return nil
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, cont.TryForceFlush(ctx))
+	require.Equal(t, 1, exp.ExportCount())
+
+	// A flush in progress, like those of the background goroutine,
+	// is not waited for.
+	block = true
+	done := make(chan error)
+	go func() {
+		done <- cont.ForceFlush(ctx)
+	}()
+	<-entered
+	require.ErrorIs(t, cont.TryForceFlush(ctx), controller.ErrCollectInProgress)
+
+	block = false
+	close(release)
+	require.NoError(t, <-done)
+	require.Equal(t, 2, exp.ExportCount())
+	require.NoError(t, cont.TryForceFlush(ctx))
+	require.Equal(t, 3, exp.ExportCount())
+
+	require.NoError(t, cont.Shutdown(ctx))
+	require.ErrorIs(t, cont.TryForceFlush(ctx), controller.ErrControllerShutdown)
+}
+
+func TestUnitConversion(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithUnitConverter("Cel", "K", func(v float64) float64 { return v + 273.15 }),
+		controller.WithUnitConversion(controller.Selector{InstrumentName: "temperature.*"}, "K"),
+	)
+	ctx := context.Background()
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#UnitConversion")
+
+	temperature, err := meter.SyncFloat64().Histogram("temperature.histogram", instrument.WithUnit("Cel"))
+	require.NoError(t, err)
+	temperature.Record(ctx, 20)
+
+	// The matched instruments must have a registered converter.
+	_, err = meter.SyncFloat64().Histogram("temperature.fahrenheit.histogram", instrument.WithUnit("[degF]"))
+	require.ErrorIs(t, err, sdk.ErrNoUnitConverter)
+
+	require.NoError(t, cont.Collect(ctx))
+	require.EqualValues(t, map[string]float64{
+		"temperature.histogram//": 293.15,
+	}, getMap(t, cont))
+
+	require.NoError(t, cont.ForEach(func(_ instrumentation.Library, reader export.Reader) error {
+		return reader.ForEach(aggregation.CumulativeTemporalitySelector(), func(rec export.Record) error {
+			require.Equal(t, unit.Unit("K"), rec.Descriptor().Unit())
+			return nil
+		})
+	}))
+}
+
+func TestBaggageAttributes(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithBaggageAttributes(controller.Selector{InstrumentName: "tenant.*"}, "tenant"),
+	)
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#BaggageAttributes")
+
+	member, err := baggage.NewMember("tenant", "t1")
+	require.NoError(t, err)
+	bag, err := baggage.New(member)
+	require.NoError(t, err)
+
+	tenant, err := meter.AsyncInt64().Gauge("tenant.lastvalue")
+	require.NoError(t, err)
+	other, err := meter.AsyncInt64().Gauge("other.lastvalue")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{tenant, other}, func(ctx context.Context) error {
+		tenant.Observe(ctx, 1)
+		other.Observe(ctx, 2)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, cont.Collect(baggage.ContextWithBaggage(context.Background(), bag)))
+	require.EqualValues(t, map[string]float64{
+		"tenant.lastvalue/tenant=t1/": 1,
+		"other.lastvalue//":           2,
+	}, getMap(t, cont))
+}
+
+func TestCardinalityLimit(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithAccumulatorOptions(sdk.WithCardinalityLimit(3)),
+		controller.WithCardinalityLimit(controller.Selector{InstrumentName: "small.*"}, 2),
+		controller.WithCardinalityLimit(controller.Selector{InstrumentName: "unlimited.*"}, 0),
+	)
+	ctx := context.Background()
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#CardinalityLimit")
+
+	var insts []instrument.Asynchronous
+	var counters []asyncint64.Counter
+	for _, name := range []string{"limited.sum", "small.sum", "unlimited.sum"} {
+		counter, err := meter.AsyncInt64().Counter(name)
+		require.NoError(t, err)
+		insts = append(insts, counter)
+		counters = append(counters, counter)
+	}
+	_, err := meter.RegisterCallback(insts, func(ctx context.Context) error {
+		for _, counter := range counters {
+			for id := 0; id < 4; id++ {
+				counter.Observe(ctx, 1, attribute.Int("id", id))
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, cont.Collect(ctx))
+	require.EqualValues(t, map[string]float64{
+		"limited.sum/id=0/":                      1,
+		"limited.sum/id=1/":                      1,
+		"limited.sum/otel.metric.overflow=true/": 2,
+		"small.sum/id=0/":                        1,
+		"small.sum/otel.metric.overflow=true/":   3,
+		"unlimited.sum/id=0/":                    1,
+		"unlimited.sum/id=1/":                    1,
+		"unlimited.sum/id=2/":                    1,
+		"unlimited.sum/id=3/":                    1,
+	}, getMap(t, cont))
+	require.ErrorIs(t, testHandler.Flush(), sdk.ErrCardinalityLimit)
+}
+
+type congestedExporter struct {
+	*processortest.Exporter
+	congested bool
+}
+
+func (e *congestedExporter) Congested() bool { return e.congested }
+
+func TestBackpressure(t *testing.T) {
+	exp := &congestedExporter{
+		Exporter: processortest.New(aggregation.CumulativeTemporalitySelector(), attribute.DefaultEncoder()),
+	}
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithExporter(exp),
+	)
+	ctx := context.Background()
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#Backpressure")
+
+	essential, err := meter.AsyncInt64().Gauge("essential.lastvalue")
+	require.NoError(t, err)
+	optional, err := meter.AsyncInt64().Gauge("optional.lastvalue")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{essential, optional}, func(ctx context.Context) error {
+		essential.Observe(ctx, 1)
+		if !sdk.Backpressure(ctx) {
+			optional.Observe(ctx, 2)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, cont.Collect(ctx))
+	require.EqualValues(t, map[string]float64{
+		"essential.lastvalue//": 1,
+		"optional.lastvalue//":  2,
+	}, getMap(t, cont))
+
+	// While the exporter is congested, the callback skips its
+	// optional observations.
+	exp.congested = true
+	require.NoError(t, cont.Collect(ctx))
+	require.EqualValues(t, map[string]float64{
+		"essential.lastvalue//": 1,
+	}, getMap(t, cont))
+}
+
+func TestCallbackErrors(t *testing.T) {
+	exp := processortest.New(aggregation.CumulativeTemporalitySelector(), attribute.DefaultEncoder())
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(time.Hour),
+		controller.WithResource(resource.Empty()),
+		controller.WithExporter(exp),
+	)
+	ctx := context.Background()
+
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+	for i, err := range []error{errFirst, nil, errSecond} {
+		err := err
+		meter := cont.Meter(fmt.Sprint("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#CallbackErrors", i))
+		gauge, gerr := meter.AsyncInt64().Gauge(fmt.Sprint("gauge", i, ".lastvalue"))
+		require.NoError(t, gerr)
+		_, gerr = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+			gauge.Observe(ctx, 1)
+			return err
+		})
+		require.NoError(t, gerr)
+	}
+
+	// The errors of the callbacks of every scope are returned
+	// together, and every scope is collected.
+	err := cont.Collect(ctx)
+	var cbErr *sdk.CallbackError
+	require.ErrorAs(t, err, &cbErr)
+	require.Len(t, cbErr.Errors, 2)
+	require.ErrorIs(t, err, errFirst)
+	require.ErrorIs(t, err, errSecond)
+	_, lastErr := cont.LastError()
+	require.Equal(t, err, lastErr)
+	require.EqualValues(t, map[string]float64{
+		"gauge0.lastvalue//": 1,
+		"gauge1.lastvalue//": 1,
+		"gauge2.lastvalue//": 1,
+	}, getMap(t, cont))
+
+	// The checkpoint is exported despite the errors.
+	require.NoError(t, cont.Start(ctx))
+	err = cont.Stop(ctx)
+	require.ErrorIs(t, err, errFirst)
+	require.ErrorIs(t, err, errSecond)
+	require.Equal(t, 1, exp.ExportCount())
+	require.EqualValues(t, map[string]float64{
+		"gauge0.lastvalue//": 1,
+		"gauge1.lastvalue//": 1,
+		"gauge2.lastvalue//": 1,
+	}, exp.Values())
+}
+
+func TestShutdown(t *testing.T) {
+	ctx := context.Background()
+	exp := processortest.New(
+		aggregation.CumulativeTemporalitySelector(),
+		attribute.DefaultEncoder(),
+	)
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			exp,
+		),
+		controller.WithCollectPeriod(time.Second),
+		controller.WithExporter(exp),
+		controller.WithResource(resource.Empty()),
+	)
+	cont.SetClock(controllertest.NewMockClock())
+
+	scopes := func() int {
+		n := 0
+		require.NoError(t, cont.ForEach(func(instrumentation.Library, export.Reader) error {
+			n++
+			return nil
+		}))
+		return n
+	}
+
+	for _, name := range []string{"a", "b"} {
+		counter, err := cont.Meter(name).SyncInt64().Counter(name + ".sum")
+		require.NoError(t, err)
+		counter.Add(ctx, 1)
+	}
+	require.NoError(t, cont.Start(ctx))
+	require.Equal(t, 2, scopes())
+
+	// Shutdown exports one last time and releases the scopes.
+	require.NoError(t, cont.Shutdown(ctx))
+	require.Equal(t, 1, exp.ExportCount())
+	require.EqualValues(t, map[string]float64{
+		"a.sum//": 1,
+		"b.sum//": 1,
+	}, exp.Values())
+	require.Equal(t, 0, scopes())
+	require.False(t, cont.IsRunning())
+
+	require.NoError(t, cont.Shutdown(ctx))
+	require.Equal(t, 1, exp.ExportCount())
+
+	require.ErrorIs(t, cont.Collect(ctx), controller.ErrControllerShutdown)
+	require.ErrorIs(t, cont.TryCollect(ctx), controller.ErrControllerShutdown)
+	require.ErrorIs(t, cont.ForceFlush(ctx), controller.ErrControllerShutdown)
+	require.ErrorIs(t, cont.Start(ctx), controller.ErrControllerShutdown)
+
+	// Meters of a shut down controller do not create scopes.
+	counter, err := cont.Meter("c").SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+	counter.Add(ctx, 1)
+	require.Equal(t, 0, scopes())
+}
+
+// countingSelector counts the aggregators it selects, one per new
+// series.
+type countingSelector struct {
+	export.AggregatorSelector
+	count *int64
+}
+
+func (s countingSelector) AggregatorFor(desc *sdkapi.Descriptor, aggPtrs ...*aggregator.Aggregator) {
+	atomic.AddInt64(s.count, 1)
+	s.AggregatorSelector.AggregatorFor(desc, aggPtrs...)
+}
+
+func TestRecordAfterShutdown(t *testing.T) {
+	ctx := context.Background()
+	var series int64
+	cont := controller.New(
+		processor.NewFactory(
+			countingSelector{AggregatorSelector: processortest.AggregatorSelector(), count: &series},
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+	)
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#RecordAfterShutdown")
+	counter, err := meter.SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+
+	counter.Add(ctx, 1, attribute.Int("i", 0))
+	require.NoError(t, cont.Collect(ctx))
+	before := atomic.LoadInt64(&series)
+	require.NotZero(t, before)
+
+	// The instruments created before Shutdown drop their
+	// measurements instead of creating series that would never be
+	// collected.
+	require.NoError(t, cont.Shutdown(ctx))
+	for i := 1; i <= 10; i++ {
+		counter.Add(ctx, 1, attribute.Int("i", i))
+		gauge.Observe(ctx, 1, attribute.Int("i", i))
+	}
+	require.Equal(t, before, atomic.LoadInt64(&series))
+}
+
+func TestAttributeKeys(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithAttributeKeys(controller.Selector{InstrumentName: "filtered.*"}, "a"),
+	)
+	ctx := context.Background()
+	meter := cont.Meter("test")
+
+	filtered, err := meter.SyncInt64().Counter("filtered.sum")
+	require.NoError(t, err)
+	plain, err := meter.SyncInt64().Counter("plain.sum")
+	require.NoError(t, err)
+
+	for _, counter := range []syncint64.Counter{filtered, plain} {
+		counter.Add(ctx, 1, attribute.String("a", "1"), attribute.String("b", "2"))
+		counter.Add(ctx, 2, attribute.String("a", "1"), attribute.String("c", "3"))
+	}
+
+	require.NoError(t, cont.Collect(ctx))
+	require.EqualValues(t, map[string]float64{
+		"filtered.sum/a=1/":  3,
+		"plain.sum/a=1,b=2/": 1,
+		"plain.sum/a=1,c=3/": 2,
+	}, getMap(t, cont))
+}
+
+func TestAttributeDropIf(t *testing.T) {
+	dropUser := func(kv attribute.KeyValue) bool {
+		return strings.HasPrefix(string(kv.Key), "user.")
+	}
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithAttributeDropIf(controller.Selector{InstrumentName: "requests.*"}, dropUser),
+		controller.WithAttributeDropIf(controller.Selector{InstrumentName: "keyed.*"}, dropUser),
+		controller.WithAttributeKeys(controller.Selector{InstrumentName: "keyed.*"}, "http.route", "user.id"),
+		// The selectors match the instrument before it is renamed.
+		controller.WithAccumulatorOptions(sdk.WithInstrumentRename("requests.sum", "renamed.sum")),
+	)
+	ctx := context.Background()
+	meter := cont.Meter("test")
+
+	requests, err := meter.SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+	keyed, err := meter.SyncInt64().Counter("keyed.sum")
+	require.NoError(t, err)
+	plain, err := meter.SyncInt64().Counter("plain.sum")
+	require.NoError(t, err)
+
+	for _, counter := range []syncint64.Counter{requests, keyed, plain} {
+		for id := 0; id < 3; id++ {
+			counter.Add(ctx, 1,
+				attribute.String("http.route", "/a"),
+				attribute.String("http.method", "GET"),
+				attribute.Int("user.id", id),
+				attribute.String("user.name", fmt.Sprint("u", id)),
+			)
+		}
+		counter.Add(ctx, 10, attribute.String("http.route", "/b"), attribute.Int("user.id", 0))
+	}
+
+	require.NoError(t, cont.Collect(ctx))
+	out := getMap(t, cont)
+	series := map[string]int{}
+	for key := range out {
+		series[strings.SplitN(key, "/", 2)[0]]++
+	}
+	require.Equal(t, map[string]int{
+		"renamed.sum": 2,
+		"keyed.sum":   2,
+		"plain.sum":   4,
+	}, series)
+	require.Equal(t, 3.0, out["renamed.sum/http.method=GET,http.route=/a/"])
+	require.Equal(t, 10.0, out["renamed.sum/http.route=/b/"])
+	require.Equal(t, 3.0, out["keyed.sum/http.route=/a/"])
+	require.Equal(t, 10.0, out["keyed.sum/http.route=/b/"])
+}
+
+func TestMeterProvider(t *testing.T) {
+	cumulative := controller.New(
+		processor.NewFactory(
+			simple.NewWithHistogramDistribution(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+	)
+	delta := controller.New(
+		processor.NewFactory(
+			simple.NewWithExponentialHistogramDistribution(),
+			aggregation.DeltaTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithAttributeKeys(controller.Selector{InstrumentName: "latency"}, "route"),
+	)
+	_ = testHandler.Flush()
+	meter := controller.NewMeterProvider(cumulative, delta).Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#MeterProvider")
+
+	latency, err := meter.SyncFloat64().Histogram("latency")
+	require.NoError(t, err)
+	gauge, err := meter.AsyncInt64().Gauge("gauge")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		gauge.Observe(ctx, 7)
+		return nil
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	latency.Record(ctx, 1, attribute.String("route", "/a"), attribute.String("method", "GET"))
+	latency.Record(ctx, 2, attribute.String("route", "/a"), attribute.String("method", "PUT"))
+
+	kinds := func(cont *controller.Controller, temporality aggregation.TemporalitySelector) map[string]aggregation.Kind {
+		require.NoError(t, cont.Collect(ctx))
+		out := map[string]aggregation.Kind{}
+		require.NoError(t, cont.ForEach(
+			func(_ instrumentation.Scope, reader export.Reader) error {
+				return reader.ForEach(temporality, func(record export.Record) error {
+					enc := record.Attributes().Encoded(attribute.DefaultEncoder())
+					out[record.Descriptor().Name()+"/"+enc] = record.Aggregation().Kind()
+					if record.Descriptor().Name() == "gauge" {
+						last, _, err := record.Aggregation().(aggregation.LastValue).LastValue()
+						require.NoError(t, err)
+						require.Equal(t, int64(7), last.AsInt64())
+					}
+					return nil
+				})
+			}))
+		return out
+	}
+
+	// The same instruments are aggregated according to the
+	// selectors of each Controller.
+	require.Equal(t, map[string]aggregation.Kind{
+		"latency/method=GET,route=/a": aggregation.HistogramKind,
+		"latency/method=PUT,route=/a": aggregation.HistogramKind,
+		"gauge/":                      aggregation.LastValueKind,
+	}, kinds(cumulative, aggregation.CumulativeTemporalitySelector()))
+	require.Equal(t, map[string]aggregation.Kind{
+		"latency/route=/a": aggregation.ExponentialHistogramKind,
+		"gauge/":           aggregation.LastValueKind,
+	}, kinds(delta, aggregation.DeltaTemporalitySelector()))
+
+	// Each Controller runs the callback with its own instrument.
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestMeterProviderAttributes(t *testing.T) {
+	ctx := context.Background()
+	newController := func(opts ...controller.Option) *controller.Controller {
+		return controller.New(
+			processor.NewFactory(
+				processortest.AggregatorSelector(),
+				aggregation.CumulativeTemporalitySelector(),
+			),
+			append([]controller.Option{
+				controller.WithCollectPeriod(0),
+				controller.WithResource(resource.Empty()),
+			}, opts...)...,
+		)
+	}
+	full := newController()
+	alsoFull := newController()
+	normalized := newController(controller.WithAccumulatorOptions(sdk.WithStringNormalization(strings.ToLower, "method")))
+	filtered := newController(controller.WithAttributeKeys(controller.Selector{InstrumentName: "requests.sum"}, "route"))
+	meter := controller.NewMeterProvider(full, alsoFull, normalized, filtered).Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#MeterProviderAttributes")
+
+	counter, err := meter.SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+	counter.Add(ctx, 1, attribute.String("method", "GET"), attribute.String("route", "/a"), attribute.String("method", "PUT"))
+	counter.Add(ctx, 2, attribute.String("route", "/a"), attribute.String("method", "put"))
+
+	// Each Controller reduces the attributes of the measurements
+	// with its own options, the last value of a key winning.
+	for _, tc := range []struct {
+		cont     *controller.Controller
+		expected map[string]float64
+	}{
+		{full, map[string]float64{
+			"requests.sum/method=PUT,route=/a/": 1,
+			"requests.sum/method=put,route=/a/": 2,
+		}},
+		{alsoFull, map[string]float64{
+			"requests.sum/method=PUT,route=/a/": 1,
+			"requests.sum/method=put,route=/a/": 2,
+		}},
+		{normalized, map[string]float64{
+			"requests.sum/method=put,route=/a/": 3,
+		}},
+		{filtered, map[string]float64{
+			"requests.sum/route=/a/": 3,
+		}},
+	} {
+		require.NoError(t, tc.cont.Collect(ctx))
+		require.EqualValues(t, tc.expected, getMap(t, tc.cont))
+	}
+}
+
+func TestMeterProviderDiscard(t *testing.T) {
+	ctx := context.Background()
+	newController := func() *controller.Controller {
+		return controller.New(
+			processor.NewFactory(
+				processortest.AggregatorSelector(),
+				aggregation.CumulativeTemporalitySelector(),
+			),
+			controller.WithCollectPeriod(0),
+			controller.WithResource(resource.Empty()),
+		)
+	}
+	first, second := newController(), newController()
+	const name = "go.opentelemetry.io/otel/sdk/metric/controller/basic_test#MeterProviderDiscard"
+	_, err := second.Meter(name).SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+	_ = testHandler.Flush()
+	meter := controller.NewMeterProvider(first, second).Meter(name)
+
+	// The gauge conflicts with the counter of the second
+	// Controller, so it is discarded from the first one, which
+	// does not report it as lacking a callback.
+	_, err = meter.AsyncInt64().Gauge("requests.sum")
+	require.ErrorIs(t, err, registry.ErrMetricKindMismatch)
+	require.NoError(t, first.Collect(ctx))
+	require.NoError(t, testHandler.Flush())
+
+	// The name is free again in the first Controller.
+	_, err = meter.SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+}
+
+func TestMeterProviderObserve(t *testing.T) {
+	ctx := context.Background()
+	newController := func() *controller.Controller {
+		return controller.New(
+			processor.NewFactory(
+				processortest.AggregatorSelector(),
+				aggregation.CumulativeTemporalitySelector(),
+			),
+			controller.WithCollectPeriod(0),
+			controller.WithResource(resource.Empty()),
+			controller.WithAccumulatorOptions(sdk.WithDeltaObservers("delta.sum")),
+		)
+	}
+	first, second := newController(), newController()
+	_ = testHandler.Flush()
+	meter := controller.NewMeterProvider(first, second).Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#MeterProviderObserve")
+
+	batch, err := meter.AsyncInt64().Gauge("batch.lastvalue")
+	require.NoError(t, err)
+	set, err := meter.AsyncInt64().Gauge("set.lastvalue")
+	require.NoError(t, err)
+	user, err := meter.AsyncInt64().Gauge("user.lastvalue")
+	require.NoError(t, err)
+	system, err := meter.AsyncInt64().Gauge("system.lastvalue")
+	require.NoError(t, err)
+	delta, err := meter.AsyncInt64().Counter("delta.sum")
+	require.NoError(t, err)
+
+	cpu := attribute.NewSet(attribute.String("cpu", "0"))
+	_, err = meter.RegisterCallback(
+		[]instrument.Asynchronous{batch, set, user, system, delta},
+		func(ctx context.Context) error {
+			sdk.ObserveBatch(ctx, batch, []sdk.Observation{
+				{Number: number.NewInt64Number(1), Attributes: cpu.ToSlice()},
+				{Number: number.NewInt64Number(2), Attributes: []attribute.KeyValue{attribute.String("cpu", "1")}},
+			})
+			sdk.ObserveSet(ctx, set, number.NewInt64Number(3), cpu)
+			sdk.ObserveShared(ctx, cpu.ToSlice(), []sdk.SharedObservation{
+				{Instrument: user, Number: number.NewInt64Number(4)},
+				{Instrument: system, Number: number.NewInt64Number(5)},
+			})
+			sdk.ObserveDelta(ctx, delta, number.NewInt64Number(6), cpu.ToSlice()...)
+			return nil
+		})
+	require.NoError(t, err)
+
+	// Each Controller captures the observations of the callback
+	// with its own instruments, once.
+	for _, cont := range []*controller.Controller{first, second} {
+		require.NoError(t, cont.Collect(ctx))
+		require.EqualValues(t, map[string]float64{
+			"batch.lastvalue/cpu=0/":  1,
+			"batch.lastvalue/cpu=1/":  2,
+			"set.lastvalue/cpu=0/":    3,
+			"user.lastvalue/cpu=0/":   4,
+			"system.lastvalue/cpu=0/": 5,
+			"delta.sum/cpu=0/":        6,
+		}, getMap(t, cont))
+	}
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestScopedInstruments(t *testing.T) {
+	ctx := context.Background()
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+	)
+	const name = "go.opentelemetry.io/otel/sdk/metric/controller/basic_test#ScopedInstruments"
+	server := cont.Meter(name + "/server")
+	client := cont.Meter(name + "/client")
+	clientV2 := cont.Meter(name+"/client", metric.WithInstrumentationVersion("v2"))
+
+	// The same name in different scopes, with different kinds.
+	serverRequests, err := server.SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+	clientRequests, err := client.SyncFloat64().Counter("requests.sum")
+	require.NoError(t, err)
+	clientV2Requests, err := clientV2.SyncInt64().UpDownCounter("requests.sum")
+	require.NoError(t, err)
+
+	// The Meters of the same scope share their instruments.
+	_, err = cont.Meter(name + "/server").SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+	_, err = cont.Meter(name + "/server").SyncFloat64().Histogram("requests.sum")
+	require.Error(t, err)
+	require.True(t, errors.Is(err, registry.ErrMetricKindMismatch))
+
+	serverRequests.Add(ctx, 3)
+	clientRequests.Add(ctx, 1.5)
+	clientV2Requests.Add(ctx, -2)
+	require.NoError(t, cont.Collect(ctx))
+
+	type scoped struct {
+		kind sdkapi.InstrumentKind
+		sum  float64
+	}
+	out := map[instrumentation.Scope]map[string]scoped{}
+	require.NoError(t, cont.ForEach(func(scope instrumentation.Scope, reader export.Reader) error {
+		out[scope] = map[string]scoped{}
+		return reader.ForEach(aggregation.CumulativeTemporalitySelector(), func(record export.Record) error {
+			desc := record.Descriptor()
+			sum, err := record.Aggregation().(aggregation.Sum).Sum()
+			require.NoError(t, err)
+			out[scope][desc.Name()] = scoped{
+				kind: desc.InstrumentKind(),
+				sum:  sum.CoerceToFloat64(desc.NumberKind()),
+			}
+			return nil
+		})
+	}))
+	require.Equal(t, map[instrumentation.Scope]map[string]scoped{
+		{Name: name + "/server"}: {
+			"requests.sum": {kind: sdkapi.CounterInstrumentKind, sum: 3},
+		},
+		{Name: name + "/client"}: {
+			"requests.sum": {kind: sdkapi.CounterInstrumentKind, sum: 1.5},
+		},
+		{Name: name + "/client", Version: "v2"}: {
+			"requests.sum": {kind: sdkapi.UpDownCounterInstrumentKind, sum: -2},
+		},
+	}, out)
+}
+
+type producerFunc func(context.Context) ([]export.ScopeMetrics, error)
+
+func (f producerFunc) Produce(ctx context.Context) ([]export.ScopeMetrics, error) {
+	return f(ctx)
+}
+
+func TestProducer(t *testing.T) {
+	const name = "go.opentelemetry.io/otel/sdk/metric/controller/basic_test#Producer"
+	var produceErr error
+	produced := func(ctx context.Context) ([]export.ScopeMetrics, error) {
+		desc := sdkapi.NewDescriptor("requests.sum", sdkapi.CounterObserverInstrumentKind, number.Int64Kind, "", "")
+		attrs := attribute.NewSet(attribute.String("source", "producer"))
+		agg := &sum.New(1)[0]
+		if err := agg.Update(ctx, number.NewInt64Number(5), &desc); err != nil {
+			return nil, err
+		}
+		return []export.ScopeMetrics{{
+			Scope:   instrumentation.Scope{Name: name},
+			Records: []export.Record{export.NewRecord(&desc, &attrs, agg, time.Time{}, time.Now())},
+		}}, produceErr
+	}
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+			processor.WithMemory(true),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithProducer(producerFunc(produced)),
+	)
+	ctx := context.Background()
+	counter, err := cont.Meter(name).SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+	counter.Add(ctx, 1)
+
+	require.NoError(t, testHandler.Flush())
+	require.NoError(t, cont.Collect(ctx))
+	require.ErrorIs(t, testHandler.Flush(), controller.ErrProducerConflict)
+	require.EqualValues(t, map[string]float64{
+		"requests.sum//":                1,
+		"requests.sum/source=producer/": 5,
+	}, getMap(t, cont))
+
+	// Each conflict is reported once.
+	require.NoError(t, cont.Collect(ctx))
+	require.NoError(t, testHandler.Flush())
+
+	// The records of a failed producer are dropped.
+	produceErr = errors.New("produce failed")
+	err = cont.Collect(ctx)
+	require.ErrorIs(t, err, produceErr)
+	require.EqualValues(t, map[string]float64{
+		"requests.sum//": 1,
+	}, getMap(t, cont))
+}
+
+// snapshotMap returns the sum or last value of each record of s, by
+// instrument name and encoded attributes.
+func snapshotMap(t *testing.T, s *snapshot.Snapshot) map[string]float64 {
+	m := map[string]float64{}
+	require.NoError(t, s.ForEach(func(_ instrumentation.Scope, reader export.Reader) error {
+		return reader.ForEach(nil, func(rec export.Record) error {
+			var value number.Number
+			var err error
+			switch agg := rec.Aggregation().(type) {
+			case aggregation.Sum:
+				value, err = agg.Sum()
+			case aggregation.LastValue:
+				value, _, err = agg.LastValue()
+			}
+			key := rec.Descriptor().Name() + "/" + rec.Attributes().Encoded(attribute.DefaultEncoder()) + "/"
+			m[key] = value.CoerceToFloat64(rec.Descriptor().NumberKind())
+			return err
+		})
+	}))
+	return m
+}
+
+func TestCollectSnapshot(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+			processor.WithMemory(true),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+	)
+	ctx := context.Background()
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#CollectSnapshot")
+	counter, err := meter.SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+	gauge, err := meter.AsyncFloat64().Gauge("temperature.lastvalue")
+	require.NoError(t, err)
+	var temperature float64
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		gauge.Observe(ctx, temperature, attribute.String("room", "kitchen"))
+		return nil
+	})
+	require.NoError(t, err)
+
+	counter.Add(ctx, 2, attribute.String("method", "GET"))
+	temperature = 21.5
+	first, err := cont.CollectSnapshot(ctx, aggregation.CumulativeTemporalitySelector())
+	require.NoError(t, err)
+	expected := map[string]float64{
+		"requests.sum/method=GET/":            2,
+		"temperature.lastvalue/room=kitchen/": 21.5,
+	}
+	require.EqualValues(t, expected, snapshotMap(t, first))
+
+	// The snapshot is unchanged by later collections.
+	counter.Add(ctx, 3, attribute.String("method", "GET"))
+	temperature = 19
+	second, err := cont.CollectSnapshot(ctx, aggregation.CumulativeTemporalitySelector())
+	require.NoError(t, err)
+	require.EqualValues(t, expected, snapshotMap(t, first))
+	require.EqualValues(t, map[string]float64{
+		"requests.sum/method=GET/":            5,
+		"temperature.lastvalue/room=kitchen/": 19,
+	}, snapshotMap(t, second))
+	require.EqualValues(t, getMap(t, cont), snapshotMap(t, second))
+
+	require.NoError(t, cont.Shutdown(ctx))
+	_, err = cont.CollectSnapshot(ctx, aggregation.CumulativeTemporalitySelector())
+	require.ErrorIs(t, err, controller.ErrControllerShutdown)
+}
+
+func TestControllerCollectTagged(t *testing.T) {
+	ctx := context.Background()
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+	)
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#ControllerCollectTagged")
+	gcCount, err := meter.AsyncInt64().Counter("gc.count.sum")
+	require.NoError(t, err)
+	queue, err := meter.AsyncInt64().Gauge("queue.length.lastvalue")
+	require.NoError(t, err)
+	requests, err := meter.SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+
+	var gcRuns, queueRuns int
+	reg, err := meter.RegisterCallback([]instrument.Asynchronous{gcCount}, func(ctx context.Context) error {
+		gcRuns++
+		gcCount.Observe(ctx, int64(10*gcRuns))
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, sdk.TagCallback(reg, "gc"))
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{queue}, func(ctx context.Context) error {
+		queueRuns++
+		queue.Observe(ctx, 3)
+		return nil
+	})
+	require.NoError(t, err)
+	requests.Add(ctx, 5)
+
+	// The processor is passed the tagged records only, within a
+	// complete collection.
+	require.NoError(t, cont.CollectTagged(ctx, "gc"))
+	require.Equal(t, 1, gcRuns)
+	require.Equal(t, 0, queueRuns)
+	require.EqualValues(t, map[string]float64{
+		"gc.count.sum//": 10,
+	}, getMap(t, cont))
+
+	// The synchronous updates were kept for the next collection.
+	require.NoError(t, cont.Collect(ctx))
+	require.Equal(t, 2, gcRuns)
+	require.Equal(t, 1, queueRuns)
+	require.EqualValues(t, map[string]float64{
+		"gc.count.sum//":           20,
+		"queue.length.lastvalue//": 3,
+		"requests.sum//":           5,
+	}, getMap(t, cont))
+
+	require.NoError(t, cont.Shutdown(ctx))
+	require.ErrorIs(t, cont.CollectTagged(ctx, "gc"), controller.ErrControllerShutdown)
+}
+
+// aggregationValues formats the values of the aggregation of rec.
+func aggregationValues(rec export.Record) string {
+	kind := rec.Descriptor().NumberKind()
+	switch agg := rec.Aggregation().(type) {
+	case aggregation.ExponentialHistogram:
+		count, _ := agg.Count()
+		sum, _ := agg.Sum()
+		buckets, _ := agg.ExponentialHistogram()
+		return fmt.Sprintf("%s %d %s %+v", agg.Kind(), count, sum.Emit(kind), buckets)
+	case aggregation.Histogram:
+		count, _ := agg.Count()
+		sum, _ := agg.Sum()
+		buckets, _ := agg.Histogram()
+		// The exemplars of the buckets are counted by
+		// exemplarCounts.
+		return fmt.Sprintf("%s %d %s %v %v", agg.Kind(), count, sum.Emit(kind), buckets.Boundaries, buckets.Counts)
+	case aggregation.MinMaxSumCount:
+		min, _ := agg.Min()
+		max, _ := agg.Max()
+		sum, _ := agg.Sum()
+		count, _ := agg.Count()
+		return fmt.Sprintf("%s %s %s %s %d", agg.Kind(), min.Emit(kind), max.Emit(kind), sum.Emit(kind), count)
+	case aggregation.Sum:
+		sum, _ := agg.Sum()
+		return fmt.Sprintf("%s %s", agg.Kind(), sum.Emit(kind))
+	}
+	return string(rec.Aggregation().Kind())
+}
+
+// exemplarCounts returns the values of the aggregations of reader, and the
+// number of exemplars they hold.
+func exemplarCounts(t *testing.T, reader export.InstrumentationLibraryReader) ([]string, int) {
+	var values []string
+	var exemplars int
+	require.NoError(t, reader.ForEach(func(_ instrumentation.Scope, r export.Reader) error {
+		return r.ForEach(aggregation.CumulativeTemporalitySelector(), func(rec export.Record) error {
+			values = append(values, aggregationValues(rec))
+			if agg, ok := rec.Aggregation().(aggregation.Exemplars); ok {
+				e, err := agg.Exemplars()
+				exemplars += len(e)
+				return err
+			}
+			return nil
+		})
+	}))
+	return values, exemplars
+}
+
+func TestCollectSnapshotAggregations(t *testing.T) {
+	sampled := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	for _, tc := range []struct {
+		name     string
+		selector export.AggregatorSelector
+		kind     aggregation.Kind
+	}{
+		{"histogram", simple.NewWithHistogramDistribution(), aggregation.HistogramKind},
+		{"exponential", simple.NewWithExponentialHistogramDistribution(), aggregation.ExponentialHistogramKind},
+		{"minmaxsumcount", simple.NewWithMinMaxSumCountDistribution(), aggregation.MinMaxSumCountKind},
+		{"exemplars", simple.NewWithExemplars(2), aggregation.HistogramKind},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cont := controller.New(
+				processor.NewFactory(tc.selector, aggregation.CumulativeTemporalitySelector()),
+				controller.WithCollectPeriod(0),
+			)
+			meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#CollectSnapshotAggregations")
+			hist, err := meter.SyncFloat64().Histogram("latency")
+			require.NoError(t, err)
+			counter, err := meter.SyncInt64().Counter("requests")
+			require.NoError(t, err)
+			for _, v := range []float64{-2, 0, 0.5, 3, 3, 250} {
+				hist.Record(sampled, v)
+			}
+			counter.Add(sampled, 7)
+
+			s, err := cont.CollectSnapshot(context.Background(), aggregation.CumulativeTemporalitySelector())
+			require.NoError(t, err)
+			expected, exemplars := exemplarCounts(t, cont)
+			require.Len(t, expected, 2)
+			require.Contains(t, expected, "Sum 7")
+			if tc.name == "exemplars" {
+				require.NotZero(t, exemplars)
+			}
+
+			// The values are copied exactly, without the exemplars.
+			values, exemplars := exemplarCounts(t, s)
+			require.ElementsMatch(t, expected, values)
+			require.Zero(t, exemplars)
+			var kinds []aggregation.Kind
+			require.NoError(t, s.ForEach(func(_ instrumentation.Scope, r export.Reader) error {
+				return r.ForEach(nil, func(rec export.Record) error {
+					kinds = append(kinds, rec.Aggregation().Kind())
+					return nil
+				})
+			}))
+			require.ElementsMatch(t, []aggregation.Kind{tc.kind, aggregation.SumKind}, kinds)
+		})
+	}
+}
+
+func TestProducerDeltaToCumulative(t *testing.T) {
+	const name = "go.opentelemetry.io/otel/sdk/metric/controller/basic_test#ProducerDeltaToCumulative"
+	type delta struct {
+		series     string
+		value      int64
+		start, end time.Time
+	}
+	var deltas []delta
+	produced := func(ctx context.Context) ([]export.ScopeMetrics, error) {
+		desc := sdkapi.NewDescriptor("allocations", sdkapi.CounterObserverInstrumentKind, number.Int64Kind, "", "")
+		sm := export.ScopeMetrics{Scope: instrumentation.Scope{Name: name}}
+		for _, d := range deltas {
+			attrs := attribute.NewSet(attribute.String("series", d.series))
+			agg := &sum.New(1)[0]
+			if err := agg.Update(ctx, number.NewInt64Number(d.value), &desc); err != nil {
+				return nil, err
+			}
+			rec := export.NewRecord(&desc, &attrs, agg, d.start, d.end)
+			sm.Records = append(sm.Records, rec.WithTemporality(aggregation.DeltaTemporality))
+		}
+		return []export.ScopeMetrics{sm}, nil
+	}
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithProducer(producerFunc(produced)),
+	)
+	type point struct {
+		value int64
+		start time.Time
+	}
+	read := func(tempSelector aggregation.TemporalitySelector) map[string]point {
+		points := map[string]point{}
+		require.NoError(t, cont.ForEach(func(_ instrumentation.Scope, reader export.Reader) error {
+			return reader.ForEach(tempSelector, func(rec export.Record) error {
+				v, err := rec.Aggregation().(aggregation.Sum).Sum()
+				require.NoError(t, err)
+				series, _ := rec.Attributes().Value("series")
+				points[series.AsString()] = point{value: v.AsInt64(), start: rec.StartTime()}
+				return nil
+			})
+		}))
+		return points
+	}
+	ctx := context.Background()
+	t0 := time.Now()
+	at := func(i int) time.Time { return t0.Add(time.Duration(i) * time.Second) }
+
+	deltas = []delta{{"a", 1, at(0), at(1)}, {"gone", 4, at(0), at(1)}}
+	require.NoError(t, cont.Collect(ctx))
+	require.Equal(t, map[string]point{"a": {1, at(0)}, "gone": {4, at(0)}}, read(aggregation.CumulativeTemporalitySelector()))
+
+	// A new series starts mid-stream, another disappears.
+	deltas = []delta{{"a", 2, at(1), at(2)}, {"new", 5, at(1), at(2)}}
+	require.NoError(t, cont.Collect(ctx))
+	require.Equal(t, map[string]point{"a": {3, at(0)}, "new": {5, at(1)}}, read(aggregation.CumulativeTemporalitySelector()))
+
+	// The deltas are unchanged for delta exporters, and reading
+	// again does not add them twice.
+	require.Equal(t, map[string]point{"a": {2, at(1)}, "new": {5, at(1)}}, read(aggregation.DeltaTemporalitySelector()))
+	require.Equal(t, map[string]point{"a": {3, at(0)}, "new": {5, at(1)}}, read(aggregation.CumulativeTemporalitySelector()))
+
+	deltas = []delta{{"a", 3, at(2), at(3)}, {"new", 1, at(2), at(3)}, {"gone", 2, at(2), at(3)}}
+	require.NoError(t, cont.Collect(ctx))
+	require.Equal(t, map[string]point{"a": {6, at(0)}, "new": {6, at(1)}, "gone": {2, at(2)}}, read(aggregation.CumulativeTemporalitySelector()))
+
+	// Series a restarts: its start time is not the previous end time.
+	deltas = []delta{{"a", 7, at(5), at(6)}, {"new", 1, at(3), at(4)}}
+	require.NoError(t, cont.Collect(ctx))
+	require.Equal(t, map[string]point{"a": {7, at(5)}, "new": {7, at(1)}}, read(aggregation.CumulativeTemporalitySelector()))
+}
+
+func TestExemplarFilteredAttributes(t *testing.T) {
+	sampled := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	cont := controller.New(
+		processor.NewFactory(simple.NewWithExemplars(2), aggregation.CumulativeTemporalitySelector()),
+		controller.WithCollectPeriod(0),
+		controller.WithAccumulatorOptions(sdk.WithAttributeFilter(func(*sdkapi.Descriptor) attribute.Filter {
+			return func(kv attribute.KeyValue) bool { return kv.Key != "user" }
+		})),
+	)
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#ExemplarFilteredAttributes")
+
+	counter, err := meter.SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+	counter.Add(sampled, 1, attribute.String("route", "/"), attribute.String("user", "u-42"))
+
+	require.NoError(t, cont.Collect(context.Background()))
+	var exemplars []aggregation.Exemplar
+	require.NoError(t, cont.ForEach(func(_ instrumentation.Scope, r export.Reader) error {
+		return r.ForEach(aggregation.CumulativeTemporalitySelector(), func(rec export.Record) error {
+			// The series has the filtered attributes...
+			require.Equal(t, "route=/", rec.Attributes().Encoded(attribute.DefaultEncoder()))
+			e, err := rec.Aggregation().(aggregation.Exemplars).Exemplars()
+			exemplars = append(exemplars, e...)
+			return err
+		})
+	}))
+	// ... and the exemplar keeps the filtered-out ones.
+	require.Len(t, exemplars, 1)
+	require.Equal(t, []attribute.KeyValue{attribute.String("user", "u-42")}, exemplars[0].FilteredAttributes)
+}
+
+func TestExemplarDecision(t *testing.T) {
+	sampled := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	cont := controller.New(
+		processor.NewFactory(simple.NewWithExemplars(2), aggregation.CumulativeTemporalitySelector()),
+		controller.WithCollectPeriod(0),
+		// Retries buffer the observations of each attempt.
+		controller.WithAccumulatorOptions(sdk.WithCallbackRetries(1, time.Millisecond)),
+	)
+	meter := cont.Meter("go.opentelemetry.io/otel/sdk/metric/controller/basic_test#ExemplarDecision")
+
+	dropped, err := meter.SyncInt64().Counter("dropped.sum")
+	require.NoError(t, err)
+	dropped.Add(exemplar.ContextWithExemplarDecision(sampled, false), 1)
+
+	kept, err := meter.AsyncInt64().Counter("kept.sum")
+	require.NoError(t, err)
+	attempts := 0
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{kept}, func(ctx context.Context) error {
+		attempts++
+		kept.Observe(exemplar.ContextWithExemplarDecision(ctx, true), 2)
+		if attempts == 1 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, cont.Collect(context.Background()))
+	require.Equal(t, 2, attempts)
+	exemplars := map[string][]aggregation.Exemplar{}
+	require.NoError(t, cont.ForEach(func(_ instrumentation.Scope, r export.Reader) error {
+		return r.ForEach(aggregation.CumulativeTemporalitySelector(), func(rec export.Record) error {
+			e, err := rec.Aggregation().(aggregation.Exemplars).Exemplars()
+			exemplars[rec.Descriptor().Name()] = e
+			return err
+		})
+	}))
+	require.Len(t, exemplars, 2)
+	// Only the kept observation has an exemplar, without a span.
+	require.Empty(t, exemplars["dropped.sum"])
+	require.Len(t, exemplars["kept.sum"], 1)
+	require.Equal(t, int64(2), exemplars["kept.sum"][0].Value.AsInt64())
+	require.False(t, exemplars["kept.sum"][0].SpanContext.IsValid())
+}
@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic // import "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+)
+
+// seriesKey identifies the series of a produced delta Sum.
+type seriesKey struct {
+	scope instrumentation.Scope
+	name  string
+	attrs attribute.Distinct
+}
+
+// series is the running total of the deltas of a series since its
+// start.
+type series struct {
+	start time.Time
+	end   time.Time
+	total runningSum
+}
+
+// runningSum is the Sum aggregation of the running total of a series.
+type runningSum struct {
+	value number.Number
+}
+
+var _ aggregation.Sum = &runningSum{}
+
+func (*runningSum) Kind() aggregation.Kind        { return aggregation.SumKind }
+func (s *runningSum) Sum() (number.Number, error) { return s.value, nil }
+
+// accumulate adds the produced delta Sums to the running totals of their
+// series, and sets their cumulative records.  A series restarts when the
+// start time of its delta is not the end time of the previous one, e.g.,
+// after the restart of the source or a missed collection.  The series
+// that were not produced are forgotten, so that they restart when they
+// reappear.  The caller holds producedLock.
+func (c *Controller) accumulate(produced []producedScope) {
+	totals := map[seriesKey]*series{}
+	for i := range produced {
+		ps := &produced[i]
+		for j := range ps.records {
+			pr := &ps.records[j]
+			if pr.Temporality() != aggregation.DeltaTemporality {
+				continue
+			}
+			sum, ok := pr.Aggregation().(aggregation.Sum)
+			if !ok {
+				continue
+			}
+			delta, err := sum.Sum()
+			if err != nil {
+				continue
+			}
+			desc := pr.Descriptor()
+			key := seriesKey{
+				scope: ps.scope,
+				name:  desc.Name(),
+				attrs: pr.Attributes().Equivalent(),
+			}
+			s, ok := totals[key]
+			if !ok {
+				s, ok = c.totals[key]
+			}
+			if !ok || !s.end.Equal(pr.StartTime()) {
+				s = &series{start: pr.StartTime()}
+			}
+			s.total.value.AddNumber(desc.NumberKind(), delta)
+			s.end = pr.EndTime()
+			totals[key] = s
+
+			total := s.total
+			rec := export.NewRecord(desc, pr.Attributes(), &total, s.start, s.end).WithTemporality(aggregation.CumulativeTemporality)
+			pr.cumulative = &rec
+		}
+	}
+	c.totals = totals
+}
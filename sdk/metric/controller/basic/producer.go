@@ -0,0 +1,208 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic // import "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/registry"
+)
+
+// ErrProducerConflict is handled when a Producer produces a metric with
+// the name of an SDK instrument, or of a metric of another Producer, in
+// the same instrumentation scope.  Both are exported, and each conflict
+// is reported once.
+var ErrProducerConflict = fmt.Errorf("a metric was produced with the name of another metric")
+
+// producedName identifies a produced metric for the conflict reports.
+type producedName struct {
+	scope instrumentation.Scope
+	name  string
+}
+
+// producedScope holds the records produced for a scope in the last
+// collection.
+type producedScope struct {
+	scope   instrumentation.Scope
+	records []producedRecord
+}
+
+// producedRecord is a produced record and, for a delta Sum, the
+// cumulative record of the running total of its series.
+type producedRecord struct {
+	export.Record
+	cumulative *export.Record
+}
+
+// produce calls the Producers and keeps their records for ForEach.  The
+// records of a Producer that fails are dropped, and the first error is
+// returned once every Producer was called.
+func (c *Controller) produce(ctx context.Context) error {
+	if len(c.producers) == 0 {
+		return nil
+	}
+	var (
+		produced []producedScope
+		owners   = map[producedName]int{}
+		err      error
+	)
+	for i, p := range c.producers {
+		sms, perr := p.Produce(ctx)
+		if perr != nil {
+			if err == nil {
+				err = fmt.Errorf("producer: %w", perr)
+			}
+			continue
+		}
+		for _, sm := range sms {
+			for _, rec := range sm.Records {
+				name := producedName{scope: sm.Scope, name: rec.Descriptor().Name()}
+				if owner, ok := owners[name]; !ok {
+					owners[name] = i
+					if c.isRegistered(name) {
+						c.conflict(name, "an instrument")
+					}
+				} else if owner != i {
+					c.conflict(name, "another producer")
+				}
+			}
+			produced = mergeScopeMetrics(produced, sm)
+		}
+	}
+
+	c.producedLock.Lock()
+	defer c.producedLock.Unlock()
+	c.accumulate(produced)
+	c.produced = produced
+	return err
+}
+
+// isRegistered returns whether an SDK instrument of the scope of name
+// has its name.
+func (c *Controller) isRegistered(name producedName) bool {
+	m, ok := c.scopes.Load(name.scope)
+	if !ok {
+		return false
+	}
+	_, ok = m.(*registry.UniqueInstrumentMeterImpl).Lookup(name.name)
+	return ok
+}
+
+// conflict handles an ErrProducerConflict for name, unless it was
+// handled before.
+func (c *Controller) conflict(name producedName, with string) {
+	c.producedLock.Lock()
+	defer c.producedLock.Unlock()
+	if _, ok := c.conflicts[name]; ok {
+		return
+	}
+	if c.conflicts == nil {
+		c.conflicts = map[producedName]struct{}{}
+	}
+	c.conflicts[name] = struct{}{}
+	otel.Handle(fmt.Errorf("%w: %q of %q conflicts with %s", ErrProducerConflict, name.name, name.scope.Name, with))
+}
+
+// mergeScopeMetrics adds the records of sm to those of the same scope
+// in produced.
+func mergeScopeMetrics(produced []producedScope, sm export.ScopeMetrics) []producedScope {
+	i := 0
+	for i < len(produced) && produced[i].scope != sm.Scope {
+		i++
+	}
+	if i == len(produced) {
+		produced = append(produced, producedScope{scope: sm.Scope})
+	}
+	for _, rec := range sm.Records {
+		produced[i].records = append(produced[i].records, producedRecord{Record: rec})
+	}
+	return produced
+}
+
+// producedFor returns the records produced for scope.  The caller holds
+// producedLock.
+func (c *Controller) producedFor(scope instrumentation.Scope) []producedRecord {
+	for _, ps := range c.produced {
+		if ps.scope == scope {
+			return ps.records
+		}
+	}
+	return nil
+}
+
+// producedReader is an export.Reader of produced records, following the
+// records of Reader when it is not nil.  The delta Sums are visited as
+// their running totals when tempSelector selects cumulative sums.
+type producedReader struct {
+	export.Reader
+	records []producedRecord
+}
+
+// ForEach implements export.Reader.
+func (r producedReader) ForEach(tempSelector aggregation.TemporalitySelector, recordFunc func(export.Record) error) error {
+	if r.Reader != nil {
+		if err := r.Reader.ForEach(tempSelector, recordFunc); err != nil {
+			return err
+		}
+	}
+	for _, pr := range r.records {
+		rec := pr.Record
+		selected := tempSelector.TemporalityFor(rec.Descriptor(), rec.Aggregation().Kind())
+		switch {
+		case rec.Temporality() == 0:
+			rec = rec.WithTemporality(selected)
+		case pr.cumulative != nil && selected == aggregation.CumulativeTemporality:
+			rec = *pr.cumulative
+		}
+		if err := recordFunc(rec); err != nil && !errors.Is(err, aggregation.ErrNoData) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lock implements sync.Locker.
+func (r producedReader) Lock() {
+	if r.Reader != nil {
+		r.Reader.Lock()
+	}
+}
+
+// Unlock implements sync.Locker.
+func (r producedReader) Unlock() {
+	if r.Reader != nil {
+		r.Reader.Unlock()
+	}
+}
+
+// RLock implements export.Reader.
+func (r producedReader) RLock() {
+	if r.Reader != nil {
+		r.Reader.RLock()
+	}
+}
+
+// RUnlock implements export.Reader.
+func (r producedReader) RUnlock() {
+	if r.Reader != nil {
+		r.Reader.RUnlock()
+	}
+}
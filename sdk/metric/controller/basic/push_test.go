@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -150,6 +151,77 @@ func TestPushTicker(t *testing.T) {
 	require.NoError(t, p.Stop(ctx))
 }
 
+// concurrencyExporter records the largest number of concurrent exports.
+type concurrencyExporter struct {
+	*processortest.Exporter
+	inflight, max int32
+}
+
+func (e *concurrencyExporter) Export(ctx context.Context, res *resource.Resource, reader export.InstrumentationLibraryReader) error {
+	n := atomic.AddInt32(&e.inflight, 1)
+	defer atomic.AddInt32(&e.inflight, -1)
+	for {
+		prev := atomic.LoadInt32(&e.max)
+		if n <= prev || atomic.CompareAndSwapInt32(&e.max, prev, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	return e.Exporter.Export(ctx, res, reader)
+}
+
+func TestPushForceFlush(t *testing.T) {
+	exporter := &concurrencyExporter{Exporter: newExporter()}
+	p := controller.New(
+		newCheckpointerFactory(),
+		controller.WithExporter(exporter),
+		controller.WithCollectPeriod(time.Second),
+		controller.WithResource(testResource),
+	)
+	mock := controllertest.NewMockClock()
+	p.SetClock(mock)
+	meter := p.Meter("name")
+
+	ctx := context.Background()
+
+	counter, err := meter.SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+
+	require.NoError(t, p.Start(ctx))
+
+	counter.Add(ctx, 3)
+
+	// The flush exports before the first tick.
+	require.NoError(t, p.ForceFlush(ctx))
+	require.EqualValues(t, map[string]float64{
+		"counter.sum//R=V": 3,
+	}, exporter.Values())
+	require.Equal(t, 1, exporter.ExportCount())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, p.ForceFlush(ctx))
+		}()
+	}
+	mock.Add(time.Second)
+	wg.Wait()
+
+	require.NoError(t, p.Stop(ctx))
+
+	// The flushes, the tick and the final collection of Stop
+	// never export concurrently.
+	require.Equal(t, int32(1), atomic.LoadInt32(&exporter.max))
+}
+
+func TestPushForceFlushWithoutExporter(t *testing.T) {
+	p := controller.New(newCheckpointerFactory())
+	require.NoError(t, p.ForceFlush(context.Background()))
+	require.True(t, p.LastCollection().Time.IsZero())
+}
+
 func TestPushExportError(t *testing.T) {
 	injector := func(name string, e error) func(r export.Record) error {
 		return func(r export.Record) error {
@@ -228,3 +300,128 @@ func TestPushExportError(t *testing.T) {
 		})
 	}
 }
+
+func TestPushLastError(t *testing.T) {
+	errExport := fmt.Errorf("export failed")
+	var fail int32 = 1
+
+	exporter := newExporter()
+	exporter.InjectErr = func(export.Record) error {
+		if atomic.LoadInt32(&fail) != 0 {
+			return errExport
+		}
+		return nil
+	}
+	p := controller.New(
+		newCheckpointerFactory(),
+		controller.WithExporter(exporter),
+		controller.WithCollectPeriod(time.Second),
+		controller.WithResource(testResource),
+	)
+	mock := controllertest.NewMockClock()
+	p.SetClock(mock)
+
+	ctx := context.Background()
+	counter, err := p.Meter("name").SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+
+	when, err := p.LastError()
+	require.NoError(t, err)
+	require.True(t, when.IsZero())
+
+	require.NoError(t, p.Start(ctx))
+
+	counter.Add(ctx, 1)
+	mock.Add(time.Second)
+	runtime.Gosched()
+
+	require.Equal(t, 1, exporter.ExportCount())
+	when, err = p.LastError()
+	require.ErrorIs(t, err, errExport)
+	require.Equal(t, mock.Now(), when)
+	require.ErrorIs(t, testHandler.Flush(), errExport)
+
+	// The next successful collection clears the error.
+	atomic.StoreInt32(&fail, 0)
+	counter.Add(ctx, 1)
+	mock.Add(time.Second)
+	runtime.Gosched()
+
+	require.Equal(t, 2, exporter.ExportCount())
+	when, err = p.LastError()
+	require.NoError(t, err)
+	require.True(t, when.IsZero())
+
+	require.NoError(t, p.Stop(ctx))
+}
+
+func TestPushRetainUnacknowledged(t *testing.T) {
+	errExport := fmt.Errorf("export failed")
+	var fail int32 = 1
+
+	exporter := newExporter()
+	exporter.InjectErr = func(export.Record) error {
+		if atomic.LoadInt32(&fail) != 0 {
+			return errExport
+		}
+		return nil
+	}
+	p := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.DeltaTemporalitySelector(),
+			processor.WithRetainUnacknowledged(true),
+		),
+		controller.WithExporter(exporter),
+		controller.WithCollectPeriod(time.Second),
+		controller.WithResource(testResource),
+	)
+	mock := controllertest.NewMockClock()
+	p.SetClock(mock)
+
+	ctx := context.Background()
+	meter := p.Meter("name")
+	counter, err := meter.SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+	other, err := meter.SyncInt64().Counter("other.sum")
+	require.NoError(t, err)
+
+	require.NoError(t, p.Start(ctx))
+
+	// The first export fails.
+	counter.Add(ctx, 3)
+	other.Add(ctx, 5)
+	mock.Add(time.Second)
+	runtime.Gosched()
+
+	require.Equal(t, 1, exporter.ExportCount())
+	require.ErrorIs(t, testHandler.Flush(), errExport)
+	exporter.Reset()
+
+	// The next export includes the deltas of the failed one,
+	// including those of series that were not updated since.
+	atomic.StoreInt32(&fail, 0)
+	counter.Add(ctx, 4)
+	mock.Add(time.Second)
+	runtime.Gosched()
+
+	require.Equal(t, 1, exporter.ExportCount())
+	require.EqualValues(t, map[string]float64{
+		"counter.sum//R=V": 7,
+		"other.sum//R=V":   5,
+	}, exporter.Values())
+	require.NoError(t, testHandler.Flush())
+	exporter.Reset()
+
+	// Once acknowledged, the deltas are not exported again.
+	counter.Add(ctx, 1)
+	mock.Add(time.Second)
+	runtime.Gosched()
+
+	require.Equal(t, 1, exporter.ExportCount())
+	require.EqualValues(t, map[string]float64{
+		"counter.sum//R=V": 1,
+	}, exporter.Values())
+
+	require.NoError(t, p.Stop(ctx))
+}
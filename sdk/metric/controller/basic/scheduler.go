@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic // import "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+
+import (
+	"context"
+	"sync"
+)
+
+// Scheduler serializes the collections of the Controllers that share it,
+// see WithScheduler.  While a collection is in progress, the other
+// Controllers wait, and the waiting Controller with the highest priority
+// collects next.  Controllers with equal priority collect in the order
+// they started waiting.
+type Scheduler struct {
+	lock    sync.Mutex
+	busy    bool
+	waiting []*schedulerWaiter
+}
+
+type schedulerWaiter struct {
+	priority int
+	ready    chan struct{}
+}
+
+// NewScheduler returns a new Scheduler.
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// acquire blocks until no other collection is in progress and no waiting
+// collection has a higher priority.  It returns the context error if ctx
+// is done first, in which case release must not be called.
+func (s *Scheduler) acquire(ctx context.Context, priority int) error {
+	s.lock.Lock()
+	if !s.busy {
+		s.busy = true
+		s.lock.Unlock()
+		return nil
+	}
+	w := &schedulerWaiter{
+		priority: priority,
+		ready:    make(chan struct{}),
+	}
+	s.waiting = append(s.waiting, w)
+	s.lock.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.lock.Lock()
+	for i, other := range s.waiting {
+		if other == w {
+			s.waiting = append(s.waiting[:i], s.waiting[i+1:]...)
+			s.lock.Unlock()
+			return ctx.Err()
+		}
+	}
+	s.lock.Unlock()
+
+	// The turn was handed to w concurrently with the
+	// cancellation, pass it on.
+	s.release()
+	return ctx.Err()
+}
+
+// release ends the current collection and hands the turn to the waiting
+// collection with the highest priority, if any.
+func (s *Scheduler) release() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.waiting) == 0 {
+		s.busy = false
+		return
+	}
+	next := 0
+	for i, w := range s.waiting[1:] {
+		if w.priority > s.waiting[next].priority {
+			next = i + 1
+		}
+	}
+	w := s.waiting[next]
+	s.waiting = append(s.waiting[:next], s.waiting[next+1:]...)
+	close(w.ready)
+}
@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// waitForWaiters blocks until n collections are waiting in s.
+func waitForWaiters(t *testing.T, s *Scheduler, n int) {
+	require.Eventually(t, func() bool {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		return len(s.waiting) == n
+	}, time.Second, time.Millisecond)
+}
+
+func TestSchedulerPriority(t *testing.T) {
+	ctx := context.Background()
+	s := NewScheduler()
+	require.NoError(t, s.acquire(ctx, 0))
+
+	var (
+		lock  sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+	)
+	for i, priority := range []int{1, 5, 1, 3} {
+		wg.Add(1)
+		go func(priority int) {
+			defer wg.Done()
+			require.NoError(t, s.acquire(ctx, priority))
+			lock.Lock()
+			order = append(order, priority)
+			lock.Unlock()
+			s.release()
+		}(priority)
+		waitForWaiters(t, s, i+1)
+	}
+
+	s.release()
+	wg.Wait()
+	require.Equal(t, []int{5, 3, 1, 1}, order)
+
+	// The scheduler is idle again.
+	require.NoError(t, s.acquire(ctx, 0))
+	s.release()
+}
+
+func TestSchedulerCanceled(t *testing.T) {
+	s := NewScheduler()
+	require.NoError(t, s.acquire(context.Background(), 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		errCh <- s.acquire(ctx, 10)
+	}()
+	waitForWaiters(t, s, 1)
+	cancel()
+	require.ErrorIs(t, <-errCh, context.Canceled)
+	waitForWaiters(t, s, 0)
+
+	s.release()
+	require.NoError(t, s.acquire(context.Background(), 0))
+	s.release()
+}
+
+func TestControllerScheduled(t *testing.T) {
+	ctx := context.Background()
+	s := NewScheduler()
+	cont := New(nil, WithScheduler(s, 0))
+
+	require.NoError(t, s.acquire(ctx, 0))
+	done := make(chan error)
+	go func() {
+		done <- cont.Collect(ctx)
+	}()
+	waitForWaiters(t, s, 1)
+	s.release()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic // import "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+
+import (
+	"fmt"
+	"path"
+
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+// Selector identifies a subset of the metric data collected by a
+// Controller.  Each field narrows the selection; the zero value of a
+// field matches everything.
+type Selector struct {
+	// InstrumentName is a glob pattern, in the syntax of
+	// path.Match, matched against the instrument name.
+	InstrumentName string
+
+	// InstrumentKinds lists the instrument kinds that are
+	// matched.
+	InstrumentKinds []sdkapi.InstrumentKind
+
+	// ScopeName is matched exactly against the name of the
+	// instrumentation scope that created the instrument.
+	ScopeName string
+}
+
+// validate returns an error if the InstrumentName pattern is malformed.
+func (s Selector) validate() error {
+	if _, err := path.Match(s.InstrumentName, ""); err != nil {
+		return fmt.Errorf("invalid selector %q: %w", s.InstrumentName, err)
+	}
+	return nil
+}
+
+// matchScope returns whether s could match any instrument of scope.
+func (s Selector) matchScope(scope instrumentation.Scope) bool {
+	return s.ScopeName == "" || s.ScopeName == scope.Name
+}
+
+// matchDescriptor returns whether s matches desc.  The scope is not
+// considered.
+func (s Selector) matchDescriptor(desc *sdkapi.Descriptor) bool {
+	if s.InstrumentName != "" {
+		// Malformed patterns were reported by New(), they
+		// simply fail to match here.
+		if ok, _ := path.Match(s.InstrumentName, desc.Name()); !ok {
+			return false
+		}
+	}
+	if len(s.InstrumentKinds) == 0 {
+		return true
+	}
+	for _, k := range s.InstrumentKinds {
+		if k == desc.InstrumentKind() {
+			return true
+		}
+	}
+	return false
+}
+
+// selectedReader is an export.Reader that only visits the records
+// matched by at least one of its selectors.  The underlying
+// Processor state is not modified.
+type selectedReader struct {
+	export.Reader
+	selectors []Selector
+}
+
+var _ export.Reader = selectedReader{}
+
+// selectReader returns a reader that filters r according to the
+// selectors that apply to scope, or r itself when no selection is
+// configured.  The boolean result is false when no record of scope
+// can be selected.
+func selectReader(r export.Reader, scope instrumentation.Scope, selectors []Selector) (export.Reader, bool) {
+	if len(selectors) == 0 {
+		return r, true
+	}
+	var scoped []Selector
+	for _, s := range selectors {
+		if s.matchScope(scope) {
+			scoped = append(scoped, s)
+		}
+	}
+	if len(scoped) == 0 {
+		return nil, false
+	}
+	return selectedReader{
+		Reader:    r,
+		selectors: scoped,
+	}, true
+}
+
+// ForEach implements export.Reader.
+func (r selectedReader) ForEach(tempSelector aggregation.TemporalitySelector, recordFunc func(export.Record) error) error {
+	return r.Reader.ForEach(tempSelector, func(rec export.Record) error {
+		for _, s := range r.selectors {
+			if s.matchDescriptor(rec.Descriptor()) {
+				return recordFunc(rec)
+			}
+		}
+		return nil
+	})
+}
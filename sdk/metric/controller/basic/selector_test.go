@@ -0,0 +1,161 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/attribute"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	"go.opentelemetry.io/otel/sdk/metric/controller/controllertest"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/metric/processor/processortest"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestSelectors(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		selectors []controller.Selector
+		expect    map[string]float64
+	}{
+		{
+			name: "no selectors",
+			expect: map[string]float64{
+				"http.requests.sum//":      1,
+				"http.latency.histogram//": 2,
+				"rpc.requests.sum//":       3,
+			},
+		},
+		{
+			name: "name glob",
+			selectors: []controller.Selector{
+				{InstrumentName: "http.*"},
+			},
+			expect: map[string]float64{
+				"http.requests.sum//":      1,
+				"http.latency.histogram//": 2,
+			},
+		},
+		{
+			name: "instrument kind",
+			selectors: []controller.Selector{
+				{InstrumentKinds: []sdkapi.InstrumentKind{sdkapi.CounterInstrumentKind}},
+			},
+			expect: map[string]float64{
+				"http.requests.sum//": 1,
+				"rpc.requests.sum//":  3,
+			},
+		},
+		{
+			name: "scope",
+			selectors: []controller.Selector{
+				{ScopeName: "rpc"},
+			},
+			expect: map[string]float64{
+				"rpc.requests.sum//": 3,
+			},
+		},
+		{
+			name: "union of selectors",
+			selectors: []controller.Selector{
+				{ScopeName: "rpc"},
+				{
+					InstrumentName:  "http.*",
+					InstrumentKinds: []sdkapi.InstrumentKind{sdkapi.HistogramInstrumentKind},
+				},
+			},
+			expect: map[string]float64{
+				"http.latency.histogram//": 2,
+				"rpc.requests.sum//":       3,
+			},
+		},
+		{
+			name: "no match",
+			selectors: []controller.Selector{
+				{InstrumentName: "db.*"},
+			},
+			expect: map[string]float64{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cont := controller.New(
+				processor.NewFactory(
+					processortest.AggregatorSelector(),
+					aggregation.CumulativeTemporalitySelector(),
+				),
+				controller.WithCollectPeriod(0),
+				controller.WithResource(resource.Empty()),
+				controller.WithSelectors(tc.selectors...),
+			)
+			ctx := context.Background()
+
+			httpMeter := cont.Meter("http")
+			counter, err := httpMeter.SyncInt64().Counter("http.requests.sum")
+			require.NoError(t, err)
+			histo, err := httpMeter.SyncFloat64().Histogram("http.latency.histogram")
+			require.NoError(t, err)
+			rpcCounter, err := cont.Meter("rpc").SyncInt64().Counter("rpc.requests.sum")
+			require.NoError(t, err)
+
+			counter.Add(ctx, 1)
+			histo.Record(ctx, 2)
+			rpcCounter.Add(ctx, 3)
+
+			require.NoError(t, cont.Collect(ctx))
+			records := processortest.NewOutput(attribute.DefaultEncoder())
+			require.NoError(t, controllertest.ReadAll(cont, aggregation.CumulativeTemporalitySelector(), records.AddInstrumentationLibraryRecord))
+			require.EqualValues(t, tc.expect, records.Map())
+		})
+	}
+}
+
+func TestSelectorsPreserveAggregation(t *testing.T) {
+	cont := controller.New(
+		processor.NewFactory(
+			processortest.AggregatorSelector(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithSelectors(controller.Selector{InstrumentName: "selected.*"}),
+	)
+	ctx := context.Background()
+	meter := cont.Meter("test")
+	selected, err := meter.SyncInt64().Counter("selected.sum")
+	require.NoError(t, err)
+	dropped, err := meter.SyncInt64().Counter("dropped.sum")
+	require.NoError(t, err)
+
+	for i := 1; i <= 3; i++ {
+		selected.Add(ctx, 1, attribute.String("A", "B"))
+		dropped.Add(ctx, 1, attribute.String("A", "B"))
+
+		require.NoError(t, cont.Collect(ctx))
+		records := processortest.NewOutput(attribute.DefaultEncoder())
+		require.NoError(t, controllertest.ReadAll(cont, aggregation.CumulativeTemporalitySelector(), records.AddInstrumentationLibraryRecord))
+
+		// The cumulative sum keeps accumulating across
+		// collections even though other records are dropped.
+		require.EqualValues(t, map[string]float64{
+			"selected.sum/A=B/": float64(i),
+		}, records.Map())
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic // import "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+// Instrument identifies an instrument that ValidateSelectors matches
+// Selectors against.
+type Instrument struct {
+	Scope      instrumentation.Scope
+	Descriptor sdkapi.Descriptor
+}
+
+// SelectorMatch is a Selector passed to an Option, with the instruments
+// it matches.
+type SelectorMatch struct {
+	// Option names the Option the Selector was passed to, e.g.,
+	// "WithExclusions".
+	Option   string
+	Selector Selector
+
+	// Err is not nil when the InstrumentName pattern of the
+	// Selector is malformed.  Such a Selector matches nothing.
+	Err error
+
+	// Instruments are the instruments matched by the Selector.
+	Instruments []Instrument
+}
+
+// SelectorConflict is an instrument that the Selectors of several
+// Options configure inconsistently.
+type SelectorConflict struct {
+	Instrument Instrument
+
+	// Matches are the indexes in SelectorReport.Matches of the
+	// conflicting Selectors.
+	Matches []int
+
+	// Reason describes the conflict.
+	Reason string
+}
+
+// SelectorReport describes how the Selectors of a configuration apply to
+// a set of instruments, see ValidateSelectors.
+type SelectorReport struct {
+	// Matches holds one entry per Selector, grouped by Option in
+	// the order WithSelectors, WithExclusions, WithInclusions,
+	// WithResourceAttributes, WithUnitConversion, and in the order
+	// they were passed within each group.
+	Matches []SelectorMatch
+
+	// Conflicts are the instruments configured inconsistently, in
+	// the order of the instruments.
+	Conflicts []SelectorConflict
+}
+
+// Unmatched returns the Selectors of r that match no instrument,
+// including the malformed ones.
+func (r SelectorReport) Unmatched() []SelectorMatch {
+	var unmatched []SelectorMatch
+	for _, m := range r.Matches {
+		if len(m.Instruments) == 0 {
+			unmatched = append(unmatched, m)
+		}
+	}
+	return unmatched
+}
+
+// Valid returns whether r has no malformed Selector, no Selector that
+// matches nothing and no conflict.
+func (r SelectorReport) Valid() bool {
+	return len(r.Unmatched()) == 0 && len(r.Conflicts) == 0
+}
+
+// ValidateSelectors matches the Selectors of the Options opts against
+// instruments, without creating a Controller, e.g., to check a
+// configuration against a known inventory of instruments before using
+// it.  The Options other than WithSelectors, WithExclusions,
+// WithInclusions, WithResourceAttributes, WithUnitConversion,
+// WithCardinalityLimit, WithAttributeKeys, WithAttributeDropIf,
+// WithBaggageAttributes and WithSeedAttributes are ignored.
+//
+// The report lists the instruments each Selector matches, and the
+// instruments that are excluded by WithExclusions but also matched by
+// another Option, which then has no effect, or that are converted to
+// different units by several WithUnitConversion Selectors, of which only
+// the first applies.
+func ValidateSelectors(instruments []Instrument, opts ...Option) SelectorReport {
+	var cfg config
+	for _, opt := range opts {
+		cfg = opt.apply(cfg)
+	}
+
+	var report SelectorReport
+	add := func(option string, s Selector) {
+		report.Matches = append(report.Matches, SelectorMatch{
+			Option:   option,
+			Selector: s,
+			Err:      s.validate(),
+		})
+	}
+	for _, s := range cfg.Selectors {
+		add("WithSelectors", s)
+	}
+	for _, s := range cfg.Exclusions {
+		add("WithExclusions", s)
+	}
+	for _, s := range cfg.Inclusions {
+		add("WithInclusions", s)
+	}
+	for _, ra := range cfg.ResourceAttributes {
+		add("WithResourceAttributes", ra.selector)
+	}
+	// units holds the unit of each WithUnitConversion match.
+	units := map[int]string{}
+	for _, uc := range cfg.UnitConversions {
+		units[len(report.Matches)] = string(uc.to)
+		add("WithUnitConversion", uc.selector)
+	}
+	for _, cl := range cfg.CardinalityLimits {
+		add("WithCardinalityLimit", cl.selector)
+	}
+	for _, ak := range cfg.AttributeKeys {
+		add("WithAttributeKeys", ak.selector)
+	}
+	for _, ad := range cfg.AttributeDrops {
+		add("WithAttributeDropIf", ad.selector)
+	}
+	for _, ba := range cfg.BaggageAttributes {
+		add("WithBaggageAttributes", ba.selector)
+	}
+	for _, sa := range cfg.SeedAttributes {
+		add("WithSeedAttributes", sa.selector)
+	}
+
+	for _, inst := range instruments {
+		var matched []int
+		for i := range report.Matches {
+			m := &report.Matches[i]
+			if m.Err != nil || !m.Selector.matchScope(inst.Scope) || !m.Selector.matchDescriptor(&inst.Descriptor) {
+				continue
+			}
+			m.Instruments = append(m.Instruments, inst)
+			matched = append(matched, i)
+		}
+		report.Conflicts = append(report.Conflicts, conflicts(inst, report.Matches, matched, units)...)
+	}
+	return report
+}
+
+// conflicts returns the conflicts of inst, matched by the Selectors at
+// the indexes matched of matches.
+func conflicts(inst Instrument, matches []SelectorMatch, matched []int, units map[int]string) []SelectorConflict {
+	var excluded, configured, converted []int
+	for _, i := range matched {
+		switch matches[i].Option {
+		case "WithExclusions":
+			excluded = append(excluded, i)
+		case "WithUnitConversion":
+			converted = append(converted, i)
+			configured = append(configured, i)
+		default:
+			configured = append(configured, i)
+		}
+	}
+
+	var result []SelectorConflict
+	if len(excluded) != 0 && len(configured) != 0 {
+		result = append(result, SelectorConflict{
+			Instrument: inst,
+			Matches:    append(excluded[:len(excluded):len(excluded)], configured...),
+			Reason:     fmt.Sprintf("%s is excluded, but also matched by %s", inst.Descriptor.Name(), matches[configured[0]].Option),
+		})
+	}
+	for _, i := range converted {
+		if units[i] != units[converted[0]] {
+			result = append(result, SelectorConflict{
+				Instrument: inst,
+				Matches:    converted,
+				Reason:     fmt.Sprintf("%s is converted to several units, only %q applies", inst.Descriptor.Name(), units[converted[0]]),
+			})
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basic_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+func TestValidateSelectors(t *testing.T) {
+	requests := controller.Instrument{
+		Scope:      instrumentation.Scope{Name: "http"},
+		Descriptor: sdkapi.NewDescriptor("http.requests", sdkapi.CounterInstrumentKind, number.Int64Kind, "", ""),
+	}
+	latency := controller.Instrument{
+		Scope:      instrumentation.Scope{Name: "http"},
+		Descriptor: sdkapi.NewDescriptor("http.latency", sdkapi.HistogramInstrumentKind, number.Float64Kind, "", unit.Milliseconds),
+	}
+	queue := controller.Instrument{
+		Scope:      instrumentation.Scope{Name: "queue"},
+		Descriptor: sdkapi.NewDescriptor("queue.length", sdkapi.GaugeObserverInstrumentKind, number.Int64Kind, "", ""),
+	}
+	instruments := []controller.Instrument{requests, latency, queue}
+
+	t.Run("valid", func(t *testing.T) {
+		report := controller.ValidateSelectors(instruments,
+			controller.WithSelectors(controller.Selector{ScopeName: "http"}),
+			controller.WithExclusions(controller.Selector{InstrumentName: "queue.*"}),
+			controller.WithUnitConversion(controller.Selector{InstrumentName: "*.latency"}, unit.Unit("s")),
+			controller.WithCardinalityLimit(controller.Selector{InstrumentName: "http.requests"}, 100),
+			controller.WithAttributeKeys(controller.Selector{InstrumentName: "http.*"}, "http.method"),
+			controller.WithCollectPeriod(0),
+		)
+		require.True(t, report.Valid())
+		require.Empty(t, report.Conflicts)
+		require.Len(t, report.Matches, 5)
+
+		require.Equal(t, "WithSelectors", report.Matches[0].Option)
+		require.Equal(t, []controller.Instrument{requests, latency}, report.Matches[0].Instruments)
+		require.Equal(t, "WithExclusions", report.Matches[1].Option)
+		require.Equal(t, []controller.Instrument{queue}, report.Matches[1].Instruments)
+		require.Equal(t, "WithUnitConversion", report.Matches[2].Option)
+		require.Equal(t, []controller.Instrument{latency}, report.Matches[2].Instruments)
+		require.Equal(t, "WithCardinalityLimit", report.Matches[3].Option)
+		require.Equal(t, []controller.Instrument{requests}, report.Matches[3].Instruments)
+		require.Equal(t, "WithAttributeKeys", report.Matches[4].Option)
+		require.Equal(t, []controller.Instrument{requests, latency}, report.Matches[4].Instruments)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		report := controller.ValidateSelectors(instruments,
+			controller.WithSelectors(controller.Selector{InstrumentName: "["}),
+			controller.WithExclusions(controller.Selector{InstrumentName: "http.*", InstrumentKinds: []sdkapi.InstrumentKind{sdkapi.HistogramInstrumentKind}}),
+			controller.WithResourceAttributes(controller.Selector{ScopeName: "http"}, "host.name"),
+			controller.WithUnitConversion(controller.Selector{ScopeName: "missing"}, unit.Unit("s")),
+			controller.WithUnitConversion(controller.Selector{InstrumentName: "queue.*"}, unit.Unit("s")),
+			controller.WithUnitConversion(controller.Selector{ScopeName: "queue"}, unit.Milliseconds),
+		)
+		require.False(t, report.Valid())
+
+		// The malformed selector and the selector of a
+		// missing scope match nothing.
+		unmatched := report.Unmatched()
+		require.Len(t, unmatched, 2)
+		require.Error(t, unmatched[0].Err)
+		require.Equal(t, "WithSelectors", unmatched[0].Option)
+		require.NoError(t, unmatched[1].Err)
+		require.Equal(t, controller.Selector{ScopeName: "missing"}, unmatched[1].Selector)
+
+		require.Len(t, report.Conflicts, 2)
+		require.Equal(t, latency, report.Conflicts[0].Instrument)
+		require.Equal(t, []int{1, 2}, report.Conflicts[0].Matches)
+		require.Equal(t, queue, report.Conflicts[1].Instrument)
+		require.Equal(t, []int{4, 5}, report.Conflicts[1].Matches)
+	})
+}
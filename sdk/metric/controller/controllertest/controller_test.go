@@ -49,7 +49,7 @@ func TestEndToEnd(t *testing.T) {
 	meter := global.Meter("go.opentelemetry.io/otel/sdk/metric/controller/controllertest_EndToEnd")
 	gauge, err := meter.AsyncInt64().Gauge("test")
 	require.NoError(t, err)
-	err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(context.Context) {})
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(context.Context) error { return nil })
 	require.NoError(t, err)
 
 	c := controller.New(basic.NewFactory(simple.NewWithInexpensiveDistribution(), aggregation.CumulativeTemporalitySelector()))
@@ -58,7 +58,7 @@ func TestEndToEnd(t *testing.T) {
 
 	gauge, err = meter.AsyncInt64().Gauge("test2")
 	require.NoError(t, err)
-	err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(context.Context) {})
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(context.Context) error { return nil })
 	require.NoError(t, err)
 
 	h.lock.Lock()
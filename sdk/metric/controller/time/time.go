@@ -27,6 +27,22 @@ type Clock interface {
 	Ticker(duration time.Duration) Ticker
 }
 
+// MonotonicClock is a Clock that also exposes the readings it is derived
+// from.  Its Now method returns the anchor, a single wall clock reading,
+// plus the Monotonic reading, so that the times it returns are ordered
+// even when the system wall clock is adjusted.
+type MonotonicClock interface {
+	Clock
+
+	// Wall returns the current wall clock time, which is subject
+	// to adjustments of the system clock.
+	Wall() time.Time
+
+	// Monotonic returns the time elapsed since the anchor,
+	// which is not subject to adjustments of the system clock.
+	Monotonic() time.Duration
+}
+
 // Ticker signals time intervals.
 type Ticker interface {
 	Stop()
@@ -43,8 +59,15 @@ type RealTicker struct {
 	ticker *time.Ticker
 }
 
+// AnchoredClock is a MonotonicClock using the system time, anchored to the
+// wall clock time at which it was created.
+type AnchoredClock struct {
+	anchor time.Time
+}
+
 var _ Clock = RealClock{}
 var _ Ticker = RealTicker{}
+var _ MonotonicClock = AnchoredClock{}
 
 // Now returns the current time.
 func (RealClock) Now() time.Time {
@@ -65,3 +88,28 @@ func (t RealTicker) Stop() {
 func (t RealTicker) C() <-chan time.Time {
 	return t.ticker.C
 }
+
+// NewAnchoredClock returns an AnchoredClock anchored to the current time.
+func NewAnchoredClock() AnchoredClock {
+	return AnchoredClock{anchor: time.Now()}
+}
+
+// Now returns the anchor plus the monotonic time elapsed since.
+func (c AnchoredClock) Now() time.Time {
+	return c.anchor.Add(c.Monotonic()).Round(0)
+}
+
+// Wall returns the current wall clock time.
+func (AnchoredClock) Wall() time.Time {
+	return time.Now().Round(0)
+}
+
+// Monotonic returns the monotonic time elapsed since the anchor.
+func (c AnchoredClock) Monotonic() time.Duration {
+	return time.Since(c.anchor)
+}
+
+// Ticker creates a new RealTicker that will tick with period.
+func (AnchoredClock) Ticker(period time.Duration) Ticker {
+	return RealTicker{time.NewTicker(period)}
+}
@@ -0,0 +1,40 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package time
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnchoredClock(t *testing.T) {
+	before := time.Now()
+	c := NewAnchoredClock()
+
+	prev := c.Now()
+	require.False(t, prev.Before(before.Round(0)))
+	for i := 0; i < 100; i++ {
+		now := c.Now()
+		require.False(t, now.Before(prev))
+		prev = now
+	}
+
+	// Without adjustments of the system clock, the anchored
+	// and the wall readings agree.
+	require.True(t, c.Monotonic() > 0)
+	require.WithinDuration(t, c.Wall(), c.Now(), time.Second)
+}
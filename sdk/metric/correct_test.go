@@ -18,22 +18,31 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/instrument"
 	"go.opentelemetry.io/otel/metric/instrument/asyncint64"
+	"go.opentelemetry.io/otel/metric/instrument/syncfloat64"
+	"go.opentelemetry.io/otel/metric/unit"
 	metricsdk "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator"
 	"go.opentelemetry.io/otel/sdk/metric/export"
 	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
 	"go.opentelemetry.io/otel/sdk/metric/processor/processortest"
+	"go.opentelemetry.io/otel/sdk/metric/registry"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
 )
 
 type handler struct {
@@ -70,12 +79,12 @@ func init() {
 
 type testSelector struct {
 	selector    export.AggregatorSelector
-	newAggCount int
+	newAggCount int64
 }
 
 func (ts *testSelector) AggregatorFor(desc *sdkapi.Descriptor, aggPtrs ...*aggregator.Aggregator) {
-	ts.newAggCount += len(aggPtrs)
-	processortest.AggregatorSelector().AggregatorFor(desc, aggPtrs...)
+	atomic.AddInt64(&ts.newAggCount, int64(len(aggPtrs)))
+	ts.selector.AggregatorFor(desc, aggPtrs...)
 }
 
 func newSDK(t *testing.T) (metric.Meter, *metricsdk.Accumulator, *testSelector, *processortest.Processor) {
@@ -92,6 +101,14 @@ func newSDK(t *testing.T) (metric.Meter, *metricsdk.Accumulator, *testSelector,
 	return meter, accum, testSelector, processor
 }
 
+// collect collects sdk, requiring that no callback fails, and returns
+// the number of records that were checkpointed.
+func collect(t *testing.T, ctx context.Context, sdk *metricsdk.Accumulator) int {
+	n, err := sdk.Collect(ctx)
+	require.NoError(t, err)
+	return n
+}
+
 func TestInputRangeCounter(t *testing.T) {
 	ctx := context.Background()
 	meter, sdk, _, processor := newSDK(t)
@@ -102,12 +119,12 @@ func TestInputRangeCounter(t *testing.T) {
 	counter.Add(ctx, -1)
 	require.Equal(t, aggregation.ErrNegativeInput, testHandler.Flush())
 
-	checkpointed := sdk.Collect(ctx)
+	checkpointed, _ := sdk.Collect(ctx)
 	require.Equal(t, 0, checkpointed)
 
 	processor.Reset()
 	counter.Add(ctx, 1)
-	checkpointed = sdk.Collect(ctx)
+	checkpointed, _ = sdk.Collect(ctx)
 	require.Equal(t, map[string]float64{
 		"name.sum//": 1,
 	}, processor.Values())
@@ -127,7 +144,7 @@ func TestInputRangeUpDownCounter(t *testing.T) {
 	counter.Add(ctx, 2)
 	counter.Add(ctx, 1)
 
-	checkpointed := sdk.Collect(ctx)
+	checkpointed, _ := sdk.Collect(ctx)
 	require.Equal(t, map[string]float64{
 		"name.sum//": 1,
 	}, processor.Values())
@@ -143,16 +160,18 @@ func TestInputRangeHistogram(t *testing.T) {
 	require.NoError(t, err)
 
 	histogram.Record(ctx, math.NaN())
-	require.Equal(t, aggregation.ErrNaNInput, testHandler.Flush())
+	err = testHandler.Flush()
+	require.ErrorIs(t, err, aggregation.ErrNaNInput)
+	require.Contains(t, err.Error(), "name.histogram")
 
-	checkpointed := sdk.Collect(ctx)
+	checkpointed, _ := sdk.Collect(ctx)
 	require.Equal(t, 0, checkpointed)
 
 	histogram.Record(ctx, 1)
 	histogram.Record(ctx, 2)
 
 	processor.Reset()
-	checkpointed = sdk.Collect(ctx)
+	checkpointed, _ = sdk.Collect(ctx)
 
 	require.Equal(t, map[string]float64{
 		"name.histogram//": 3,
@@ -169,12 +188,179 @@ func TestDisabledInstrument(t *testing.T) {
 	require.NoError(t, err)
 
 	histogram.Record(ctx, -1)
-	checkpointed := sdk.Collect(ctx)
+	checkpointed, _ := sdk.Collect(ctx)
 
 	require.Equal(t, 0, checkpointed)
 	require.Equal(t, map[string]float64{}, processor.Values())
 }
 
+func TestDroppedInstrument(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	selector := &testSelector{selector: simple.NewWithAggregationKinds(func(ikind sdkapi.InstrumentKind) aggregation.Kind {
+		if ikind == sdkapi.CounterInstrumentKind {
+			return aggregation.DropKind
+		}
+		return ""
+	})}
+	processor := processortest.NewProcessor(selector, attribute.DefaultEncoder())
+	sdk := metricsdk.NewAccumulator(processor)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	counter, err := meter.SyncInt64().Counter("dropped.sum")
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		counter.Add(ctx, 1, attribute.Int("i", i))
+	}
+
+	// The aggregators are selected once, and no record is
+	// created for the attribute sets of the instrument.
+	require.Equal(t, int64(2), atomic.LoadInt64(&selector.newAggCount))
+	require.Equal(t, 0, collect(t, ctx, sdk))
+	require.Equal(t, map[string]float64{}, processor.Values())
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestSynchronousLastValue(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	selector := &testSelector{selector: simple.NewWithAggregationKinds(func(ikind sdkapi.InstrumentKind) aggregation.Kind {
+		if ikind.Synchronous() {
+			return aggregation.LastValueKind
+		}
+		return ""
+	})}
+	processor := processortest.NewProcessor(selector, attribute.DefaultEncoder())
+	sdk := metricsdk.NewAccumulator(processor)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	counter, err := meter.SyncInt64().UpDownCounter("temperature.lastvalue")
+	require.NoError(t, err)
+
+	const (
+		goroutines = 8
+		updates    = 1000
+	)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < updates; i++ {
+				counter.Add(ctx, int64(g*updates+i))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// The value is one of the recorded values, not their sum.
+	require.Equal(t, 1, collect(t, ctx, sdk))
+	last := processor.Values()["temperature.lastvalue//"]
+	require.GreaterOrEqual(t, last, 0.0)
+	require.Less(t, last, float64(goroutines*updates))
+	require.Equal(t, last, float64(int64(last)))
+
+	// The next value recorded replaces it.
+	processor.Reset()
+	counter.Add(ctx, -5)
+	require.Equal(t, 1, collect(t, ctx, sdk))
+	require.Equal(t, map[string]float64{"temperature.lastvalue//": -5}, processor.Values())
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestAttributeInterning(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+
+	record := func(opts ...metricsdk.Option) map[string]float64 {
+		processor := processortest.NewProcessor(processortest.AggregatorSelector(), attribute.DefaultEncoder())
+		sdk := metricsdk.NewAccumulator(processor, opts...)
+		meter := sdkapi.WrapMeterImpl(sdk)
+
+		counter, err := meter.SyncInt64().Counter("counter.sum")
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			counter.Add(ctx, 1, attribute.Int("a", 1))
+			counter.Add(ctx, 2, attribute.Int("a", 2))
+			counter.Add(ctx, 3, attribute.Int64("b", 1))
+			counter.Add(ctx, 4, attribute.String("a", "x"))
+			counter.Add(ctx, 5, attribute.Bool("a", true))
+			counter.Add(ctx, 6)
+			// The same set in another order.
+			counter.Add(ctx, 7, attribute.Int("a", 1), attribute.Int("b", 2))
+			counter.Add(ctx, 8, attribute.Int("b", 2), attribute.Int("a", 1))
+		}
+		// The attributes passed are sorted, and then reused.
+		attrs := []attribute.KeyValue{attribute.Int("b", 2), attribute.Int("a", 1)}
+		counter.Add(ctx, 9, attrs...)
+		counter.Add(ctx, 9, attrs...)
+
+		collect(t, ctx, sdk)
+		return processor.Values()
+	}
+
+	expected := map[string]float64{
+		"counter.sum/a=1/":     2,
+		"counter.sum/a=2/":     4,
+		"counter.sum/b=1/":     6,
+		"counter.sum/a=x/":     8,
+		"counter.sum/a=true/":  10,
+		"counter.sum//":        12,
+		"counter.sum/a=1,b=2/": 48,
+	}
+	require.EqualValues(t, expected, record())
+	// A single slot, for which every attribute set collides.
+	require.EqualValues(t, expected, record(metricsdk.WithAttributeInterning(1)))
+	require.EqualValues(t, expected, record(metricsdk.WithAttributeInterning(64)))
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestSeedAttributes(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(processortest.AggregatorSelector(), attribute.DefaultEncoder())
+	sdk := metricsdk.NewAccumulator(processor, metricsdk.WithSeedAttributes(func(*sdkapi.Descriptor) []attribute.Set {
+		return []attribute.Set{
+			attribute.NewSet(attribute.String("code", "200")),
+			attribute.NewSet(attribute.String("code", "500")),
+		}
+	}))
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	counter, err := meter.SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+	histogram, err := meter.SyncInt64().Histogram("latency.histogram")
+	require.NoError(t, err)
+	observer, err := meter.AsyncInt64().Counter("bytes.sum")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{observer}, func(ctx context.Context) error {
+		observer.Observe(ctx, 10, attribute.String("code", "200"))
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The seed sets are collected before any measurement, except
+	// for the Histogram.
+	require.Equal(t, 4, collect(t, ctx, sdk))
+	require.EqualValues(t, map[string]float64{
+		"requests.sum/code=200/": 0,
+		"requests.sum/code=500/": 0,
+		"bytes.sum/code=200/":    10,
+		"bytes.sum/code=500/":    0,
+	}, processor.Values())
+
+	processor.Reset()
+	counter.Add(ctx, 1, attribute.String("code", "200"))
+	histogram.Record(ctx, 1, attribute.String("code", "200"))
+	collect(t, ctx, sdk)
+	require.EqualValues(t, map[string]float64{
+		"requests.sum/code=200/":      1,
+		"latency.histogram/code=200/": 1,
+		"bytes.sum/code=200/":         10,
+	}, processor.Values())
+	require.NoError(t, testHandler.Flush())
+}
+
 func TestRecordNaN(t *testing.T) {
 	ctx := context.Background()
 	meter, _, _, _ := newSDK(t)
@@ -283,89 +469,98 @@ func TestObserverCollection(t *testing.T) {
 
 	gaugeF, err := meter.AsyncFloat64().Gauge("float.gauge.lastvalue")
 	require.NoError(t, err)
-	err = meter.RegisterCallback([]instrument.Asynchronous{
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{
 		gaugeF,
-	}, func(ctx context.Context) {
+	}, func(ctx context.Context) error {
 		gaugeF.Observe(ctx, float64(mult), attribute.String("A", "B"))
 		// last value wins
 		gaugeF.Observe(ctx, float64(-mult), attribute.String("A", "B"))
 		gaugeF.Observe(ctx, float64(-mult), attribute.String("C", "D"))
+		return nil
 	})
 	require.NoError(t, err)
 
 	gaugeI, err := meter.AsyncInt64().Gauge("int.gauge.lastvalue")
 	require.NoError(t, err)
-	err = meter.RegisterCallback([]instrument.Asynchronous{
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{
 		gaugeI,
-	}, func(ctx context.Context) {
+	}, func(ctx context.Context) error {
 		gaugeI.Observe(ctx, int64(-mult), attribute.String("A", "B"))
 		gaugeI.Observe(ctx, int64(mult))
 		// last value wins
 		gaugeI.Observe(ctx, int64(mult), attribute.String("A", "B"))
 		gaugeI.Observe(ctx, int64(mult))
+		return nil
 	})
 	require.NoError(t, err)
 
 	counterF, err := meter.AsyncFloat64().Counter("float.counterobserver.sum")
 	require.NoError(t, err)
-	err = meter.RegisterCallback([]instrument.Asynchronous{
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{
 		counterF,
-	}, func(ctx context.Context) {
+	}, func(ctx context.Context) error {
 		counterF.Observe(ctx, float64(mult), attribute.String("A", "B"))
+		// last value wins
 		counterF.Observe(ctx, float64(2*mult), attribute.String("A", "B"))
 		counterF.Observe(ctx, float64(mult), attribute.String("C", "D"))
+		return nil
 	})
 	require.NoError(t, err)
 
 	counterI, err := meter.AsyncInt64().Counter("int.counterobserver.sum")
 	require.NoError(t, err)
-	err = meter.RegisterCallback([]instrument.Asynchronous{
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{
 		counterI,
-	}, func(ctx context.Context) {
+	}, func(ctx context.Context) error {
 		counterI.Observe(ctx, int64(2*mult), attribute.String("A", "B"))
 		counterI.Observe(ctx, int64(mult))
 		// last value wins
 		counterI.Observe(ctx, int64(mult), attribute.String("A", "B"))
 		counterI.Observe(ctx, int64(mult))
+		return nil
 	})
 	require.NoError(t, err)
 
 	updowncounterF, err := meter.AsyncFloat64().UpDownCounter("float.updowncounterobserver.sum")
 	require.NoError(t, err)
-	err = meter.RegisterCallback([]instrument.Asynchronous{
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{
 		updowncounterF,
-	}, func(ctx context.Context) {
+	}, func(ctx context.Context) error {
 		updowncounterF.Observe(ctx, float64(mult), attribute.String("A", "B"))
+		// last value wins
 		updowncounterF.Observe(ctx, float64(-2*mult), attribute.String("A", "B"))
 		updowncounterF.Observe(ctx, float64(mult), attribute.String("C", "D"))
+		return nil
 	})
 	require.NoError(t, err)
 
 	updowncounterI, err := meter.AsyncInt64().UpDownCounter("int.updowncounterobserver.sum")
 	require.NoError(t, err)
-	err = meter.RegisterCallback([]instrument.Asynchronous{
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{
 		updowncounterI,
-	}, func(ctx context.Context) {
+	}, func(ctx context.Context) error {
 		updowncounterI.Observe(ctx, int64(2*mult), attribute.String("A", "B"))
 		updowncounterI.Observe(ctx, int64(mult))
 		// last value wins
 		updowncounterI.Observe(ctx, int64(mult), attribute.String("A", "B"))
 		updowncounterI.Observe(ctx, int64(-mult))
+		return nil
 	})
 	require.NoError(t, err)
 
 	unused, err := meter.AsyncInt64().Gauge("empty.gauge.sum")
 	require.NoError(t, err)
-	err = meter.RegisterCallback([]instrument.Asynchronous{
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{
 		unused,
-	}, func(ctx context.Context) {
+	}, func(ctx context.Context) error {
+		return nil
 	})
 	require.NoError(t, err)
 
 	for mult = 0; mult < 3; mult++ {
 		processor.Reset()
 
-		collected := sdk.Collect(ctx)
+		collected, _ := sdk.Collect(ctx)
 		require.Equal(t, collected, len(processor.Values()))
 
 		mult := float64(mult)
@@ -375,15 +570,52 @@ func TestObserverCollection(t *testing.T) {
 			"int.gauge.lastvalue//":      mult,
 			"int.gauge.lastvalue/A=B/":   mult,
 
-			"float.counterobserver.sum/A=B/": 3 * mult,
+			"float.counterobserver.sum/A=B/": 2 * mult,
 			"float.counterobserver.sum/C=D/": mult,
-			"int.counterobserver.sum//":      2 * mult,
-			"int.counterobserver.sum/A=B/":   3 * mult,
+			"int.counterobserver.sum//":      mult,
+			"int.counterobserver.sum/A=B/":   mult,
 
-			"float.updowncounterobserver.sum/A=B/": -mult,
+			"float.updowncounterobserver.sum/A=B/": -2 * mult,
 			"float.updowncounterobserver.sum/C=D/": mult,
-			"int.updowncounterobserver.sum//":      0,
-			"int.updowncounterobserver.sum/A=B/":   3 * mult,
+			"int.updowncounterobserver.sum//":      -mult,
+			"int.updowncounterobserver.sum/A=B/":   mult,
+		}, processor.Values())
+	}
+}
+
+func TestObserveTwice(t *testing.T) {
+	ctx := context.Background()
+	meter, sdk, _, processor := newSDK(t)
+	a, b := attribute.String("A", "a"), attribute.String("B", "b")
+
+	round := int64(0)
+	counter, err := meter.AsyncInt64().Counter("observer.sum")
+	require.NoError(t, err)
+	gauge, err := meter.AsyncInt64().Gauge("observer.lastvalue")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{counter, gauge}, func(ctx context.Context) error {
+		// The same attribute set twice: the last value wins.
+		counter.Observe(ctx, 10*round, a)
+		counter.Observe(ctx, 20*round, a)
+		gauge.Observe(ctx, 10*round, a)
+		gauge.Observe(ctx, 20*round, a)
+
+		// Different attribute sets are kept apart.
+		counter.Observe(ctx, 1*round, a, b)
+		counter.Observe(ctx, 2*round, b)
+		return nil
+	})
+	require.NoError(t, err)
+
+	for round = 1; round <= 2; round++ {
+		processor.Reset()
+		require.Equal(t, 4, collect(t, ctx, sdk))
+
+		require.EqualValues(t, map[string]float64{
+			"observer.sum/A=a/":       float64(20 * round),
+			"observer.sum/A=a,B=b/":   float64(round),
+			"observer.sum/B=b/":       float64(2 * round),
+			"observer.lastvalue/A=a/": float64(20 * round),
 		}, processor.Values())
 	}
 }
@@ -394,27 +626,29 @@ func TestCounterObserverInputRange(t *testing.T) {
 
 	// TODO: these tests are testing for negative values, not for _descending values_. Fix.
 	counterF, _ := meter.AsyncFloat64().Counter("float.counterobserver.sum")
-	err := meter.RegisterCallback([]instrument.Asynchronous{
+	_, err := meter.RegisterCallback([]instrument.Asynchronous{
 		counterF,
-	}, func(ctx context.Context) {
+	}, func(ctx context.Context) error {
 		counterF.Observe(ctx, -2, attribute.String("A", "B"))
 		require.Equal(t, aggregation.ErrNegativeInput, testHandler.Flush())
 		counterF.Observe(ctx, -1, attribute.String("C", "D"))
 		require.Equal(t, aggregation.ErrNegativeInput, testHandler.Flush())
+		return nil
 	})
 	require.NoError(t, err)
 	counterI, _ := meter.AsyncInt64().Counter("int.counterobserver.sum")
-	err = meter.RegisterCallback([]instrument.Asynchronous{
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{
 		counterI,
-	}, func(ctx context.Context) {
+	}, func(ctx context.Context) error {
 		counterI.Observe(ctx, -1, attribute.String("A", "B"))
 		require.Equal(t, aggregation.ErrNegativeInput, testHandler.Flush())
 		counterI.Observe(ctx, -1)
 		require.Equal(t, aggregation.ErrNegativeInput, testHandler.Flush())
+		return nil
 	})
 	require.NoError(t, err)
 
-	collected := sdk.Collect(ctx)
+	collected, _ := sdk.Collect(ctx)
 
 	require.Equal(t, 0, collected)
 	require.EqualValues(t, map[string]float64{}, processor.Values())
@@ -434,14 +668,14 @@ func TestObserverBatch(t *testing.T) {
 	floatUpDownCounterObs, _ := meter.AsyncFloat64().UpDownCounter("float.updowncounterobserver.sum")
 	intUpDownCounterObs, _ := meter.AsyncInt64().UpDownCounter("int.updowncounterobserver.sum")
 
-	err := meter.RegisterCallback([]instrument.Asynchronous{
+	_, err := meter.RegisterCallback([]instrument.Asynchronous{
 		floatGaugeObs,
 		intGaugeObs,
 		floatCounterObs,
 		intCounterObs,
 		floatUpDownCounterObs,
 		intUpDownCounterObs,
-	}, func(ctx context.Context) {
+	}, func(ctx context.Context) error {
 		ab := attribute.String("A", "B")
 		floatGaugeObs.Observe(ctx, 1, ab)
 		floatGaugeObs.Observe(ctx, -1, ab)
@@ -462,10 +696,11 @@ func TestObserverBatch(t *testing.T) {
 		intCounterObs.Observe(ctx, 10)
 		floatCounterObs.Observe(ctx, 1.1)
 		intUpDownCounterObs.Observe(ctx, 10)
+		return nil
 	})
 	require.NoError(t, err)
 
-	collected := sdk.Collect(ctx)
+	collected, _ := sdk.Collect(ctx)
 
 	require.Equal(t, collected, len(processor.Values()))
 
@@ -504,7 +739,7 @@ func TestRecordPersistence(t *testing.T) {
 		sdk.Collect(ctx)
 	}
 
-	require.Equal(t, 2, selector.newAggCount)
+	require.Equal(t, int64(2), atomic.LoadInt64(&selector.newAggCount))
 }
 
 func TestIncorrectInstruments(t *testing.T) {
@@ -516,14 +751,15 @@ func TestIncorrectInstruments(t *testing.T) {
 	meter, sdk, _, processor := newSDK(t)
 
 	// Now try with uninitialized instruments.
-	err := meter.RegisterCallback([]instrument.Asynchronous{
+	_, err := meter.RegisterCallback([]instrument.Asynchronous{
 		observer,
-	}, func(ctx context.Context) {
+	}, func(ctx context.Context) error {
 		observer.Observe(ctx, 1)
+		return nil
 	})
 	require.ErrorIs(t, err, metricsdk.ErrBadInstrument)
 
-	collected := sdk.Collect(ctx)
+	collected, _ := sdk.Collect(ctx)
 	err = testHandler.Flush()
 	require.NoError(t, err)
 	require.Equal(t, 0, collected)
@@ -532,15 +768,16 @@ func TestIncorrectInstruments(t *testing.T) {
 	noopMeter := metric.NewNoopMeter()
 	observer, _ = noopMeter.AsyncInt64().Gauge("observer")
 
-	err = meter.RegisterCallback(
+	_, err = meter.RegisterCallback(
 		[]instrument.Asynchronous{observer},
-		func(ctx context.Context) {
+		func(ctx context.Context) error {
 			observer.Observe(ctx, 1)
+			return nil
 		},
 	)
 	require.ErrorIs(t, err, metricsdk.ErrBadInstrument)
 
-	collected = sdk.Collect(ctx)
+	collected, _ = sdk.Collect(ctx)
 	require.Equal(t, 0, collected)
 	require.EqualValues(t, map[string]float64{}, processor.Values())
 
@@ -555,11 +792,12 @@ func TestSyncInAsync(t *testing.T) {
 	counter, _ := meter.SyncFloat64().Counter("counter.sum")
 	gauge, _ := meter.AsyncInt64().Gauge("observer.lastvalue")
 
-	err := meter.RegisterCallback([]instrument.Asynchronous{
+	_, err := meter.RegisterCallback([]instrument.Asynchronous{
 		gauge,
-	}, func(ctx context.Context) {
+	}, func(ctx context.Context) error {
 		gauge.Observe(ctx, 10)
 		counter.Add(ctx, 100)
+		return nil
 	})
 	require.NoError(t, err)
 
@@ -570,3 +808,2317 @@ func TestSyncInAsync(t *testing.T) {
 		"observer.lastvalue//": 10,
 	}, processor.Values())
 }
+
+func TestObserveDelta(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(
+		processor,
+		metricsdk.WithDeltaObservers("delta.counterobserver.sum", "delta.gauge.lastvalue"),
+		// The gauge is never observed.
+		metricsdk.WithMissingCallbackWarnings(false),
+	)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	deltaCounter, err := meter.AsyncInt64().Counter("delta.counterobserver.sum")
+	require.NoError(t, err)
+	counter, err := meter.AsyncInt64().Counter("int.counterobserver.sum")
+	require.NoError(t, err)
+
+	// Delta observation is not supported for gauges.
+	_, err = meter.AsyncInt64().Gauge("delta.gauge.lastvalue")
+	require.NoError(t, err)
+	require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrDeltaObservation)
+
+	var delta int64
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{
+		deltaCounter,
+		counter,
+	}, func(ctx context.Context) error {
+		if delta != 0 {
+			metricsdk.ObserveDelta(ctx, deltaCounter, number.NewInt64Number(delta), attribute.String("A", "B"))
+		}
+		counter.Observe(ctx, 10, attribute.String("A", "B"))
+		return nil
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		delta  int64
+		expect float64
+	}{
+		{delta: 1, expect: 1},
+		{delta: 2, expect: 3},
+		// Without an observation, the total is unchanged.
+		{delta: 0, expect: 3},
+		{delta: 3, expect: 6},
+	} {
+		delta = tc.delta
+		processor.Reset()
+		sdk.Collect(ctx)
+
+		require.EqualValues(t, map[string]float64{
+			"delta.counterobserver.sum/A=B/": tc.expect,
+			"int.counterobserver.sum/A=B/":   10,
+		}, processor.Values())
+		require.NoError(t, testHandler.Flush())
+	}
+}
+
+func TestObserveDeltaMismatch(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(
+		processor,
+		metricsdk.WithDeltaObservers("delta.counterobserver.sum"),
+	)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	deltaCounter, err := meter.AsyncInt64().Counter("delta.counterobserver.sum")
+	require.NoError(t, err)
+	counter, err := meter.AsyncInt64().Counter("int.counterobserver.sum")
+	require.NoError(t, err)
+
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{
+		deltaCounter,
+		counter,
+	}, func(ctx context.Context) error {
+		deltaCounter.Observe(ctx, 1)
+		require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrDeltaObservation)
+
+		metricsdk.ObserveDelta(ctx, counter, number.NewInt64Number(1))
+		require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrDeltaObservation)
+		return nil
+	})
+	require.NoError(t, err)
+
+	collected, _ := sdk.Collect(ctx)
+	require.Equal(t, 0, collected)
+	require.EqualValues(t, map[string]float64{}, processor.Values())
+}
+
+func TestObserveBatch(t *testing.T) {
+	ctx := context.Background()
+	for _, opts := range [][]metricsdk.Option{
+		nil,
+		// Retries buffer the observations of each attempt.
+		{metricsdk.WithCallbackRetries(1, time.Millisecond)},
+	} {
+		testHandler.Reset()
+		single := processortest.NewProcessor(processortest.AggregatorSelector(), attribute.DefaultEncoder())
+		batched := processortest.NewProcessor(processortest.AggregatorSelector(), attribute.DefaultEncoder())
+
+		observations := []metricsdk.Observation{
+			{Number: number.NewInt64Number(1), Attributes: []attribute.KeyValue{attribute.String("A", "B")}},
+			{Number: number.NewInt64Number(2), Attributes: []attribute.KeyValue{attribute.String("C", "D")}},
+			// The last observation of a set wins.
+			{Number: number.NewInt64Number(3), Attributes: []attribute.KeyValue{attribute.String("A", "B")}},
+			{Number: number.NewInt64Number(4)},
+		}
+		for _, processor := range []*processortest.Processor{single, batched} {
+			sdk := metricsdk.NewAccumulator(processor, opts...)
+			meter := sdkapi.WrapMeterImpl(sdk)
+			gauge, err := meter.AsyncInt64().Gauge("int.gauge.lastvalue")
+			require.NoError(t, err)
+
+			batch := processor == batched
+			_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+				if batch {
+					metricsdk.ObserveBatch(ctx, gauge, observations)
+					return nil
+				}
+				for _, o := range observations {
+					gauge.Observe(ctx, o.Number.AsInt64(), o.Attributes...)
+				}
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, 3, collect(t, ctx, sdk))
+		}
+
+		require.EqualValues(t, map[string]float64{
+			"int.gauge.lastvalue/A=B/": 3,
+			"int.gauge.lastvalue/C=D/": 2,
+			"int.gauge.lastvalue//":    4,
+		}, batched.Values())
+		require.Equal(t, single.Values(), batched.Values())
+	}
+}
+
+func TestObserveBatchErrors(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(
+		processor,
+		metricsdk.WithDeltaObservers("delta.counterobserver.sum"),
+	)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	declared, err := meter.AsyncInt64().Gauge("declared.lastvalue")
+	require.NoError(t, err)
+	foreign, err := meter.AsyncInt64().Gauge("foreign.lastvalue")
+	require.NoError(t, err)
+	delta, err := meter.AsyncInt64().Counter("delta.counterobserver.sum")
+	require.NoError(t, err)
+
+	one := []metricsdk.Observation{{Number: number.NewInt64Number(1)}}
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{declared, delta}, func(ctx context.Context) error {
+		metricsdk.ObserveBatch(ctx, foreign, one)
+		require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrUndeclaredInstrument)
+
+		metricsdk.ObserveBatch(ctx, delta, one)
+		require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrDeltaObservation)
+
+		metricsdk.ObserveBatch(ctx, declared, one)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 1, collect(t, ctx, sdk))
+	require.NoError(t, testHandler.Flush())
+	require.EqualValues(t, map[string]float64{
+		"declared.lastvalue//": 1,
+	}, processor.Values())
+}
+
+func observeQueue(context.Context) error { return nil }
+
+func observeMemory(context.Context) error { return nil }
+
+func TestInspect(t *testing.T) {
+	meter, sdk, _, _ := newSDK(t)
+	require.Empty(t, sdk.Inspect())
+
+	length, err := meter.AsyncInt64().Gauge("queue.length")
+	require.NoError(t, err)
+	age, err := meter.AsyncFloat64().Gauge("queue.age", instrument.WithUnit(unit.Milliseconds))
+	require.NoError(t, err)
+	heap, err := meter.AsyncInt64().UpDownCounter("memory.heap")
+	require.NoError(t, err)
+
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{length, age}, observeQueue)
+	require.NoError(t, err)
+	reg, err := meter.RegisterCallback([]instrument.Asynchronous{heap}, observeMemory)
+	require.NoError(t, err)
+
+	infos := sdk.Inspect()
+	require.Equal(t, []metricsdk.CallbackInfo{
+		{
+			Name: "go.opentelemetry.io/otel/sdk/metric_test.observeMemory",
+			Instruments: []sdkapi.Descriptor{
+				sdkapi.NewDescriptor("memory.heap", sdkapi.UpDownCounterObserverInstrumentKind, number.Int64Kind, "", ""),
+			},
+		},
+		{
+			Name: "go.opentelemetry.io/otel/sdk/metric_test.observeQueue",
+			Instruments: []sdkapi.Descriptor{
+				sdkapi.NewDescriptor("queue.age", sdkapi.GaugeObserverInstrumentKind, number.Float64Kind, "", unit.Milliseconds),
+				sdkapi.NewDescriptor("queue.length", sdkapi.GaugeObserverInstrumentKind, number.Int64Kind, "", ""),
+			},
+		},
+	}, infos)
+
+	// The result is a copy.
+	infos[0].Instruments[0] = sdkapi.Descriptor{}
+	require.Equal(t, "memory.heap", sdk.Inspect()[0].Instruments[0].Name())
+
+	require.NoError(t, reg.Unregister())
+	infos = sdk.Inspect()
+	require.Len(t, infos, 1)
+	require.Equal(t, "go.opentelemetry.io/otel/sdk/metric_test.observeQueue", infos[0].Name)
+}
+
+func TestCollectTagged(t *testing.T) {
+	ctx := context.Background()
+	meter, sdk, _, processor := newSDK(t)
+
+	gcCount, err := meter.AsyncInt64().Counter("gc.count.sum")
+	require.NoError(t, err)
+	gcPause, err := meter.AsyncFloat64().Gauge("gc.pause.lastvalue")
+	require.NoError(t, err)
+	queue, err := meter.AsyncInt64().Gauge("queue.length.lastvalue")
+	require.NoError(t, err)
+	requests, err := meter.SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+
+	var gcRuns, queueRuns int
+	gcReg, err := meter.RegisterCallback([]instrument.Asynchronous{gcCount, gcPause}, func(ctx context.Context) error {
+		gcRuns++
+		gcCount.Observe(ctx, int64(10*gcRuns))
+		gcPause.Observe(ctx, 1.5)
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, metricsdk.TagCallback(gcReg, "gc", "runtime"))
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{queue}, func(ctx context.Context) error {
+		queueRuns++
+		queue.Observe(ctx, 3)
+		return nil
+	})
+	require.NoError(t, err)
+	requests.Add(ctx, 5)
+
+	// Only the tagged callback runs, and only its instruments are
+	// collected.
+	n, err := sdk.CollectTagged(ctx, "gc")
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, 1, gcRuns)
+	require.Equal(t, 0, queueRuns)
+	require.EqualValues(t, map[string]float64{
+		"gc.count.sum//":       10,
+		"gc.pause.lastvalue//": 1.5,
+	}, processor.Values())
+
+	// No callback matches.
+	processor.Reset()
+	n, err = sdk.CollectTagged(ctx, "network")
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+	require.Equal(t, 1, gcRuns)
+	require.Empty(t, processor.Values())
+
+	// A full collection runs every callback, and collects the
+	// synchronous update the tagged collections left.
+	processor.Reset()
+	require.Equal(t, 4, collect(t, ctx, sdk))
+	require.Equal(t, 2, gcRuns)
+	require.Equal(t, 1, queueRuns)
+	require.EqualValues(t, map[string]float64{
+		"gc.count.sum//":           20,
+		"gc.pause.lastvalue//":     1.5,
+		"queue.length.lastvalue//": 3,
+		"requests.sum//":           5,
+	}, processor.Values())
+
+	require.NoError(t, gcReg.Unregister())
+	require.ErrorIs(t, metricsdk.TagCallback(gcReg, "gc"), metricsdk.ErrUnknownCallback)
+	require.ErrorIs(t, metricsdk.TagCallback(nil, "gc"), metricsdk.ErrUnknownCallback)
+}
+
+func TestMissingCallback(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	meter, sdk, _, _ := newSDK(t)
+
+	_, err := meter.AsyncInt64().Gauge("forgotten.lastvalue")
+	require.NoError(t, err)
+	registered, err := meter.AsyncInt64().Gauge("registered.lastvalue")
+	require.NoError(t, err)
+	outside, err := meter.AsyncInt64().Gauge("outside.lastvalue")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{registered}, func(ctx context.Context) error {
+		registered.Observe(ctx, 1)
+		return nil
+	})
+	require.NoError(t, err)
+	outside.Observe(ctx, 2)
+
+	// The instrument is reported by its first collection only.
+	collect(t, ctx, sdk)
+	err = testHandler.Flush()
+	require.ErrorIs(t, err, metricsdk.ErrNoCallback)
+	require.Equal(t, "forgotten.lastvalue: "+metricsdk.ErrNoCallback.Error(), err.Error())
+	for i := 0; i < 3; i++ {
+		collect(t, ctx, sdk)
+		require.NoError(t, testHandler.Flush())
+	}
+
+	// The report can be disabled.
+	quiet := metricsdk.NewAccumulator(
+		processortest.NewProcessor(processortest.AggregatorSelector(), attribute.DefaultEncoder()),
+		metricsdk.WithMissingCallbackWarnings(false),
+	)
+	_, err = sdkapi.WrapMeterImpl(quiet).AsyncInt64().Gauge("forgotten.lastvalue")
+	require.NoError(t, err)
+	collect(t, ctx, quiet)
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestObserveShared(t *testing.T) {
+	ctx := context.Background()
+	for _, opts := range [][]metricsdk.Option{
+		nil,
+		// Retries buffer the observations of each attempt.
+		{metricsdk.WithCallbackRetries(1, time.Millisecond)},
+	} {
+		testHandler.Reset()
+		normalized := 0
+		processor := processortest.NewProcessor(processortest.AggregatorSelector(), attribute.DefaultEncoder())
+		sdk := metricsdk.NewAccumulator(processor, append(opts, metricsdk.WithStringNormalization(func(s string) string {
+			normalized++
+			return strings.TrimSpace(s)
+		}, "cpu"))...)
+		meter := sdkapi.WrapMeterImpl(sdk)
+
+		user, err := meter.AsyncInt64().Counter("cpu.user.sum")
+		require.NoError(t, err)
+		system, err := meter.AsyncInt64().Counter("cpu.system.sum")
+		require.NoError(t, err)
+		idle, err := meter.AsyncInt64().Counter("cpu.idle.sum")
+		require.NoError(t, err)
+		foreign, err := meter.AsyncInt64().Counter("foreign.sum")
+		require.NoError(t, err)
+
+		_, err = meter.RegisterCallback([]instrument.Asynchronous{user, system, idle}, func(ctx context.Context) error {
+			metricsdk.ObserveShared(ctx, []attribute.KeyValue{attribute.String("cpu", " 0 ")}, []metricsdk.SharedObservation{
+				{Instrument: user, Number: number.NewInt64Number(10)},
+				{Instrument: system, Number: number.NewInt64Number(20)},
+				{Instrument: foreign, Number: number.NewInt64Number(40)},
+				{Instrument: idle, Number: number.NewInt64Number(30)},
+			})
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 3, collect(t, ctx, sdk))
+		require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrUndeclaredInstrument)
+		// The attributes are normalized once for the three
+		// instruments.
+		require.Equal(t, 1, normalized)
+		require.EqualValues(t, map[string]float64{
+			"cpu.user.sum/cpu=0/":   10,
+			"cpu.system.sum/cpu=0/": 20,
+			"cpu.idle.sum/cpu=0/":   30,
+		}, processor.Values())
+	}
+}
+
+func TestObserveSharedAttributes(t *testing.T) {
+	ctx := context.Background()
+	processor := processortest.NewProcessor(processortest.AggregatorSelector(), attribute.DefaultEncoder())
+	sdk := metricsdk.NewAccumulator(
+		processor,
+		metricsdk.WithAttributeFilter(func(desc *sdkapi.Descriptor) attribute.Filter {
+			if desc.Name() != "filtered.lastvalue" {
+				return nil
+			}
+			return func(kv attribute.KeyValue) bool { return kv.Key == "A" }
+		}),
+		metricsdk.WithAttributeEnrichment(func(desc *sdkapi.Descriptor) []attribute.KeyValue {
+			if desc.Name() != "enriched.lastvalue" {
+				return nil
+			}
+			return []attribute.KeyValue{attribute.String("E", "F")}
+		}),
+	)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	plain, err := meter.AsyncInt64().Gauge("plain.lastvalue")
+	require.NoError(t, err)
+	filtered, err := meter.AsyncInt64().Gauge("filtered.lastvalue")
+	require.NoError(t, err)
+	enriched, err := meter.AsyncInt64().Gauge("enriched.lastvalue")
+	require.NoError(t, err)
+
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{plain, filtered, enriched}, func(ctx context.Context) error {
+		metricsdk.ObserveShared(ctx, []attribute.KeyValue{attribute.String("C", "D"), attribute.String("A", "B")}, []metricsdk.SharedObservation{
+			{Instrument: plain, Number: number.NewInt64Number(1)},
+			{Instrument: filtered, Number: number.NewInt64Number(2)},
+			{Instrument: enriched, Number: number.NewInt64Number(3)},
+		})
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 3, collect(t, ctx, sdk))
+	require.EqualValues(t, map[string]float64{
+		"plain.lastvalue/A=B,C=D/":        1,
+		"filtered.lastvalue/A=B/":         2,
+		"enriched.lastvalue/A=B,C=D,E=F/": 3,
+	}, processor.Values())
+}
+
+func TestObserveSet(t *testing.T) {
+	ctx := context.Background()
+	attrs := []attribute.KeyValue{attribute.String("C", "d"), attribute.String("A", "B")}
+	set := attribute.NewSet(attrs...)
+
+	for _, opts := range [][]metricsdk.Option{
+		nil,
+		// Retries buffer the observations of each attempt.
+		{metricsdk.WithCallbackRetries(1, time.Millisecond)},
+		{
+			metricsdk.WithStringNormalization(strings.ToUpper, "C"),
+			metricsdk.WithAttributeFilter(func(desc *sdkapi.Descriptor) attribute.Filter {
+				if desc.Name() != "filtered.lastvalue" {
+					return nil
+				}
+				return func(kv attribute.KeyValue) bool { return kv.Key == "A" }
+			}),
+			metricsdk.WithAttributeEnrichment(func(desc *sdkapi.Descriptor) []attribute.KeyValue {
+				if desc.Name() != "enriched.lastvalue" {
+					return nil
+				}
+				return []attribute.KeyValue{attribute.String("E", "F")}
+			}),
+		},
+	} {
+		record := func(observeSet bool) map[string]float64 {
+			testHandler.Reset()
+			processor := processortest.NewProcessor(processortest.AggregatorSelector(), attribute.DefaultEncoder())
+			sdk := metricsdk.NewAccumulator(processor, opts...)
+			meter := sdkapi.WrapMeterImpl(sdk)
+
+			var insts []asyncint64.Gauge
+			var declared []instrument.Asynchronous
+			for _, name := range []string{"plain.lastvalue", "filtered.lastvalue", "enriched.lastvalue"} {
+				inst, err := meter.AsyncInt64().Gauge(name)
+				require.NoError(t, err)
+				insts = append(insts, inst)
+				declared = append(declared, inst)
+			}
+			outside, err := meter.AsyncInt64().Gauge("outside.lastvalue")
+			require.NoError(t, err)
+
+			_, err = meter.RegisterCallback(declared, func(ctx context.Context) error {
+				for i, inst := range insts {
+					if observeSet {
+						metricsdk.ObserveSet(ctx, inst, number.NewInt64Number(int64(i+1)), set)
+					} else {
+						inst.Observe(ctx, int64(i+1), attrs...)
+					}
+				}
+				return nil
+			})
+			require.NoError(t, err)
+			if observeSet {
+				metricsdk.ObserveSet(ctx, outside, number.NewInt64Number(4), set)
+			} else {
+				outside.Observe(ctx, 4, attrs...)
+			}
+
+			collect(t, ctx, sdk)
+			require.NoError(t, testHandler.Flush())
+			return processor.Values()
+		}
+
+		observed := record(false)
+		require.Len(t, observed, 4)
+		require.Equal(t, observed, record(true))
+	}
+}
+
+func TestBaggageAttributes(t *testing.T) {
+	ctx := context.Background()
+	tenant, err := baggage.NewMember("tenant", "t1")
+	require.NoError(t, err)
+	region, err := baggage.NewMember("region", "eu")
+	require.NoError(t, err)
+	other, err := baggage.NewMember("other", "x")
+	require.NoError(t, err)
+	bag, err := baggage.New(tenant, region, other)
+	require.NoError(t, err)
+	partial, err := baggage.New(tenant)
+	require.NoError(t, err)
+
+	for _, opts := range [][]metricsdk.Option{
+		nil,
+		// Retries buffer the observations of each attempt.
+		{metricsdk.WithCallbackRetries(1, time.Millisecond)},
+	} {
+		processor := processortest.NewProcessor(processortest.AggregatorSelector(), attribute.DefaultEncoder())
+		sdk := metricsdk.NewAccumulator(processor, append(opts,
+			metricsdk.WithBaggageAttributes(func(desc *sdkapi.Descriptor) []string {
+				if desc.Name() == "plain.lastvalue" {
+					return nil
+				}
+				return []string{"tenant", "region"}
+			}),
+		)...)
+		meter := sdkapi.WrapMeterImpl(sdk)
+
+		gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+		require.NoError(t, err)
+		plain, err := meter.AsyncInt64().Gauge("plain.lastvalue")
+		require.NoError(t, err)
+
+		_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge, plain}, func(ctx context.Context) error {
+			// Without baggage, only the explicit attributes
+			// are kept.
+			gauge.Observe(ctx, 1, attribute.String("A", "B"))
+
+			// The explicit attributes take precedence over
+			// the baggage members, and the members that are
+			// missing are omitted.
+			withBag := baggage.ContextWithBaggage(ctx, bag)
+			gauge.Observe(withBag, 2, attribute.String("region", "us"))
+			gauge.Observe(baggage.ContextWithBaggage(ctx, partial), 3)
+			plain.Observe(withBag, 4)
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 4, collect(t, ctx, sdk))
+		require.EqualValues(t, map[string]float64{
+			"gauge.lastvalue/A=B/":                 1,
+			"gauge.lastvalue/region=us,tenant=t1/": 2,
+			"gauge.lastvalue/tenant=t1/":           3,
+			"plain.lastvalue//":                    4,
+		}, processor.Values())
+	}
+}
+
+func TestCallbackDurations(t *testing.T) {
+	ctx := context.Background()
+	durationMeter, durationSDK, _, durationProcessor := newSDK(t)
+	durations, err := durationMeter.SyncFloat64().Histogram("callback.histogram")
+	require.NoError(t, err)
+
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(processor, metricsdk.WithCallbackDurations(durations))
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback(
+		[]instrument.Asynchronous{gauge},
+		observeGauge(gauge),
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, collect(t, ctx, sdk))
+	require.Equal(t, 1, collect(t, ctx, sdk))
+	require.Equal(t, 1, collect(t, ctx, durationSDK))
+	require.NoError(t, testHandler.Flush())
+
+	values := durationProcessor.Values()
+	require.Len(t, values, 1)
+	for key, sum := range values {
+		require.Contains(t, key, "callback.histogram/callback=go.opentelemetry.io/otel/sdk/metric_test.observeGauge")
+		require.GreaterOrEqual(t, sum, 0.0)
+	}
+}
+
+func observeGauge(gauge asyncint64.Gauge) metric.Callback {
+	return func(ctx context.Context) error {
+		gauge.Observe(ctx, 1)
+		return nil
+	}
+}
+
+func TestCollectConcurrency(t *testing.T) {
+	ctx := context.Background()
+
+	collect := func(opts ...metricsdk.Option) (int, map[string]float64) {
+		testHandler.Reset()
+		processor := processortest.NewProcessor(
+			processortest.AggregatorSelector(),
+			attribute.DefaultEncoder(),
+		)
+		sdk := metricsdk.NewAccumulator(processor, opts...)
+		meter := sdkapi.WrapMeterImpl(sdk)
+
+		for i := 0; i < 10; i++ {
+			counter, err := meter.SyncInt64().Counter(fmt.Sprint("counter", i, ".sum"))
+			require.NoError(t, err)
+			histogram, err := meter.SyncFloat64().Histogram(fmt.Sprint("histogram", i, ".histogram"))
+			require.NoError(t, err)
+			for j := 0; j < 20; j++ {
+				counter.Add(ctx, int64(j), attribute.Int("j", j))
+				histogram.Record(ctx, float64(j), attribute.Int("j", j%7))
+			}
+		}
+		collected, _ := sdk.Collect(ctx)
+		require.NoError(t, testHandler.Flush())
+		return collected, processor.Values()
+	}
+
+	expectCollected, expectValues := collect()
+	require.Equal(t, 10*20+10*7, expectCollected)
+
+	for _, n := range []int{2, 3, 64} {
+		collected, values := collect(metricsdk.WithCollectConcurrency(n))
+		require.Equal(t, expectCollected, collected, n)
+		require.Equal(t, expectValues, values, n)
+	}
+}
+
+func TestCallbackConcurrency(t *testing.T) {
+	ctx := context.Background()
+
+	collect := func(opts ...metricsdk.Option) (int64, map[string]float64) {
+		testHandler.Reset()
+		processor := processortest.NewProcessor(
+			processortest.AggregatorSelector(),
+			attribute.DefaultEncoder(),
+		)
+		sdk := metricsdk.NewAccumulator(processor, append(opts, metricsdk.WithDeltaObservers("delta.sum"))...)
+		meter := sdkapi.WrapMeterImpl(sdk)
+
+		gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+		require.NoError(t, err)
+		delta, err := meter.AsyncInt64().Counter("delta.sum")
+		require.NoError(t, err)
+
+		// The callbacks block for a while, and share the
+		// instruments and their attribute sets.
+		var running, maxRunning int64
+		for i := 0; i < 8; i++ {
+			i := i
+			_, err := meter.RegisterCallback([]instrument.Asynchronous{gauge, delta}, func(ctx context.Context) error {
+				n := atomic.AddInt64(&running, 1)
+				defer atomic.AddInt64(&running, -1)
+				for {
+					prev := atomic.LoadInt64(&maxRunning)
+					if n <= prev || atomic.CompareAndSwapInt64(&maxRunning, prev, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				gauge.Observe(ctx, int64(i), attribute.Int("i", i))
+				metricsdk.ObserveDelta(ctx, delta, number.NewInt64Number(1))
+				return nil
+			})
+			require.NoError(t, err)
+		}
+		collected, err := sdk.Collect(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 9, collected)
+		require.NoError(t, testHandler.Flush())
+		return maxRunning, processor.Values()
+	}
+
+	// By default, the callbacks run sequentially.
+	maxRunning, expectValues := collect()
+	require.EqualValues(t, 1, maxRunning)
+	require.Equal(t, float64(8), expectValues["delta.sum//"])
+
+	for _, n := range []int{2, 4, 64} {
+		maxRunning, values := collect(metricsdk.WithCallbackConcurrency(n))
+		require.Greater(t, maxRunning, int64(1), n)
+		require.LessOrEqual(t, maxRunning, int64(n), n)
+		require.Equal(t, expectValues, values, n)
+	}
+}
+
+func TestConcurrentAcquire(t *testing.T) {
+	ctx := context.Background()
+	meter, sdk, _, processor := newSDK(t)
+
+	counter, err := meter.SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+
+	// Goroutines race to create and reuse the records of the same
+	// attribute sets, while collections unmap them.
+	const (
+		goroutines = 8
+		adds       = 1000
+		sets       = 10
+	)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < adds; j++ {
+				counter.Add(ctx, 1, attribute.Int("j", j%sets), attribute.String("k", "v"))
+			}
+		}()
+	}
+	done := make(chan struct{})
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_, _ = sdk.Collect(ctx)
+			}
+		}
+	}()
+	wg.Wait()
+	close(done)
+	<-collected
+	collect(t, ctx, sdk)
+
+	values := processor.Values()
+	require.Len(t, values, sets)
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	require.Equal(t, float64(goroutines*adds), total)
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestInstrumentNames(t *testing.T) {
+	meter, _, _, _ := newSDK(t)
+
+	for _, name := range []string{
+		"a",
+		"requests",
+		"http.server.duration",
+		"process.runtime.go_gc-count",
+		"Z9",
+		strings.Repeat("a", 63),
+	} {
+		_, err := meter.SyncInt64().Counter(name)
+		require.NoError(t, err, name)
+	}
+
+	for _, name := range []string{
+		"",
+		"9lives",
+		"_private",
+		".dot",
+		"with space",
+		"slash/name",
+		"colon:name",
+		"été",
+		strings.Repeat("a", 64),
+	} {
+		_, err := meter.SyncInt64().Counter(name)
+		require.ErrorIs(t, err, metricsdk.ErrInvalidInstrumentName, name)
+		_, err = meter.AsyncFloat64().Gauge(name)
+		require.ErrorIs(t, err, metricsdk.ErrInvalidInstrumentName, name)
+	}
+
+	_, err := meter.SyncInt64().Counter("unit", instrument.WithUnit("\xff"))
+	require.ErrorIs(t, err, metricsdk.ErrInvalidUnit)
+	_, err = meter.SyncInt64().Counter("description", instrument.WithDescription("\xffdescription"))
+	require.ErrorIs(t, err, metricsdk.ErrInvalidDescription)
+	_, err = meter.SyncInt64().Counter("valid", instrument.WithUnit("µs"), instrument.WithDescription("Durée"))
+	require.NoError(t, err)
+}
+
+func TestUnitMismatch(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		unit     unit.Unit
+		mismatch bool
+	}{
+		{name: "latency.seconds", unit: "s"},
+		{name: "latency_seconds", unit: unit.Milliseconds, mismatch: true},
+		{name: "payload.bytes", unit: unit.Milliseconds, mismatch: true},
+		{name: "payload_Bytes", unit: unit.Bytes},
+		{name: "payload.bytes"},
+		{name: "cpu.utilization", unit: unit.Bytes, mismatch: true},
+		{name: "requests", unit: unit.Bytes},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, action := range []metricsdk.UnitMismatch{
+				metricsdk.UnitMismatchIgnore,
+				metricsdk.UnitMismatchWarn,
+				metricsdk.UnitMismatchError,
+			} {
+				testHandler.Reset()
+				sdk := metricsdk.NewAccumulator(
+					processortest.NewProcessor(processortest.AggregatorSelector(), attribute.DefaultEncoder()),
+					metricsdk.WithUnitMismatch(action),
+				)
+				meter := sdkapi.WrapMeterImpl(sdk)
+
+				_, err := meter.SyncFloat64().Histogram(tc.name, instrument.WithUnit(tc.unit))
+				handled := testHandler.Flush()
+
+				switch {
+				case !tc.mismatch || action == metricsdk.UnitMismatchIgnore:
+					require.NoError(t, err)
+					require.NoError(t, handled)
+				case action == metricsdk.UnitMismatchWarn:
+					require.NoError(t, err)
+					require.ErrorIs(t, handled, metricsdk.ErrUnitMismatch)
+				default:
+					require.ErrorIs(t, err, metricsdk.ErrUnitMismatch)
+					require.NoError(t, handled)
+				}
+			}
+		})
+	}
+}
+
+func TestAttributeEnrichment(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(processor, metricsdk.WithAttributeEnrichment(
+		func(desc *sdkapi.Descriptor) []attribute.KeyValue {
+			if desc.Name() == "plain.sum" {
+				return nil
+			}
+			return []attribute.KeyValue{attribute.String("pod", "p1")}
+		},
+	))
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	enriched, err := meter.SyncInt64().Counter("enriched.sum")
+	require.NoError(t, err)
+	plain, err := meter.SyncInt64().Counter("plain.sum")
+	require.NoError(t, err)
+	observed, err := meter.AsyncInt64().Gauge("observed.lastvalue")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{observed}, func(ctx context.Context) error {
+		observed.Observe(ctx, 10)
+		return nil
+	})
+	require.NoError(t, err)
+
+	enriched.Add(ctx, 1)
+	enriched.Add(ctx, 2, attribute.String("pod", "override"))
+	plain.Add(ctx, 3)
+
+	require.Equal(t, 4, collect(t, ctx, sdk))
+	require.NoError(t, testHandler.Flush())
+	require.EqualValues(t, map[string]float64{
+		"enriched.sum/pod=p1/":       1,
+		"enriched.sum/pod=override/": 2,
+		"plain.sum//":                3,
+		"observed.lastvalue/pod=p1/": 10,
+	}, processor.Values())
+}
+
+func TestAttributeFilter(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(processor, metricsdk.WithAttributeFilter(
+		func(desc *sdkapi.Descriptor) attribute.Filter {
+			if desc.Name() == "plain.sum" {
+				return nil
+			}
+			return func(kv attribute.KeyValue) bool {
+				return kv.Key == "a"
+			}
+		},
+	))
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	counter, err := meter.SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+	plain, err := meter.SyncInt64().Counter("plain.sum")
+	require.NoError(t, err)
+	observer, err := meter.AsyncInt64().Counter("observer.sum")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{observer}, func(ctx context.Context) error {
+		observer.Observe(ctx, 10, attribute.String("a", "1"), attribute.String("b", "2"))
+		observer.Observe(ctx, 20, attribute.String("a", "1"), attribute.String("c", "3"))
+		return nil
+	})
+	require.NoError(t, err)
+
+	ab := []attribute.KeyValue{attribute.String("a", "1"), attribute.String("b", "2")}
+	ac := []attribute.KeyValue{attribute.String("a", "1"), attribute.String("c", "3")}
+	counter.Add(ctx, 1, ab...)
+	counter.Add(ctx, 2, ac...)
+	plain.Add(ctx, 1, ab...)
+	plain.Add(ctx, 2, ac...)
+
+	// The attributes passed with a measurement are not modified.
+	require.Equal(t, attribute.String("b", "2"), ab[1])
+
+	require.Equal(t, 4, collect(t, ctx, sdk))
+	require.NoError(t, testHandler.Flush())
+	require.EqualValues(t, map[string]float64{
+		"counter.sum/a=1/":   3,
+		"observer.sum/a=1/":  30,
+		"plain.sum/a=1,b=2/": 1,
+		"plain.sum/a=1,c=3/": 2,
+	}, processor.Values())
+}
+
+func TestDefaultAttributes(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(processor,
+		metricsdk.WithDefaultAttributes(attribute.String("A", "a"), attribute.String("B", "b")),
+		metricsdk.WithDefaultAttributes(attribute.String("B", "c")),
+		metricsdk.WithAttributeEnrichment(func(*sdkapi.Descriptor) []attribute.KeyValue {
+			return []attribute.KeyValue{attribute.String("A", "enriched")}
+		}),
+	)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	counter, err := meter.SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+	counter.Add(ctx, 1)
+	counter.Add(ctx, 2, attribute.String("B", "measured"))
+
+	require.Equal(t, 2, collect(t, ctx, sdk))
+	require.NoError(t, testHandler.Flush())
+	require.EqualValues(t, map[string]float64{
+		"counter.sum/A=enriched,B=c/":        1,
+		"counter.sum/A=enriched,B=measured/": 2,
+	}, processor.Values())
+}
+
+func TestStringNormalization(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(processor,
+		metricsdk.WithStringNormalization(strings.TrimSpace, "method", "path"),
+		metricsdk.WithStringNormalization(strings.ToUpper, "method"),
+	)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	counter, err := meter.SyncInt64().Counter("requests.sum")
+	require.NoError(t, err)
+
+	input := []attribute.KeyValue{attribute.String("method", " get ")}
+	counter.Add(ctx, 1, attribute.String("method", "GET"))
+	counter.Add(ctx, 2, attribute.String("method", "get"))
+	counter.Add(ctx, 4, input...)
+	counter.Add(ctx, 8, attribute.String("method", "Get"), attribute.String("path", "/A "))
+	counter.Add(ctx, 16, attribute.String("method", "POST"), attribute.String("other", " x "))
+	counter.Add(ctx, 32, attribute.Bool("method", true))
+
+	// The caller's attributes are not modified.
+	require.Equal(t, " get ", input[0].Value.AsString())
+
+	require.Equal(t, 4, collect(t, ctx, sdk))
+	require.NoError(t, testHandler.Flush())
+	require.EqualValues(t, map[string]float64{
+		"requests.sum/method=GET/":            7,
+		"requests.sum/method=GET,path=/A/":    8,
+		"requests.sum/method=POST,other= x /": 16,
+		"requests.sum/method=true/":           32,
+	}, processor.Values())
+}
+
+func TestExcludedInstruments(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(processor, metricsdk.WithExcludedInstruments(
+		func(desc *sdkapi.Descriptor) bool {
+			return strings.HasPrefix(desc.Name(), "excluded.")
+		},
+	))
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	counter, err := meter.SyncInt64().Counter("excluded.sum")
+	require.NoError(t, err)
+	excluded, err := meter.AsyncInt64().Gauge("excluded.lastvalue")
+	require.NoError(t, err)
+	included, err := meter.AsyncInt64().Gauge("included.lastvalue")
+	require.NoError(t, err)
+
+	var calls int
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{excluded}, func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{excluded, included}, func(ctx context.Context) error {
+		excluded.Observe(ctx, 1)
+		included.Observe(ctx, 2)
+		return nil
+	})
+	require.NoError(t, err)
+
+	counter.Add(ctx, 1)
+
+	require.Equal(t, 1, collect(t, ctx, sdk))
+	require.NoError(t, testHandler.Flush())
+	require.Equal(t, 0, calls)
+	require.EqualValues(t, map[string]float64{
+		"included.lastvalue//": 2,
+	}, processor.Values())
+}
+
+func TestInvalidMeasurements(t *testing.T) {
+	ctx := context.Background()
+
+	// By default, invalid measurements are dropped and reported.
+	meter, sdk, _, processor := newSDK(t)
+	hist, err := meter.SyncFloat64().Histogram("hist.histogram")
+	require.NoError(t, err)
+
+	hist.Record(ctx, math.NaN())
+	require.ErrorIs(t, testHandler.Flush(), aggregation.ErrNaNInput)
+	hist.Record(ctx, math.Inf(+1))
+	require.ErrorIs(t, testHandler.Flush(), aggregation.ErrInfInput)
+	hist.Record(ctx, 2)
+
+	sdk.Collect(ctx)
+	require.EqualValues(t, map[string]float64{
+		"hist.histogram//": 2,
+	}, processor.Values())
+
+	// With a counter, invalid measurements are counted instead.
+	counterMeter, counterSDK, _, counterProcessor := newSDK(t)
+	counter, err := counterMeter.SyncInt64().Counter("invalid.sum")
+	require.NoError(t, err)
+
+	processor = processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk = metricsdk.NewAccumulator(processor, metricsdk.WithInvalidMeasurements(counter))
+	meter = sdkapi.WrapMeterImpl(sdk)
+	hist, err = meter.SyncFloat64().Histogram("hist.histogram")
+	require.NoError(t, err)
+
+	hist.Record(ctx, math.NaN())
+	hist.Record(ctx, math.Inf(-1))
+	hist.Record(ctx, math.Inf(+1))
+	hist.Record(ctx, 3)
+	require.NoError(t, testHandler.Flush())
+
+	sdk.Collect(ctx)
+	require.EqualValues(t, map[string]float64{
+		"hist.histogram//": 3,
+	}, processor.Values())
+
+	counterSDK.Collect(ctx)
+	require.EqualValues(t, map[string]float64{
+		"invalid.sum/instrument=hist.histogram,reason=inf/": 2,
+		"invalid.sum/instrument=hist.histogram,reason=nan/": 1,
+	}, counterProcessor.Values())
+}
+
+func TestNonFiniteMeasurements(t *testing.T) {
+	ctx := context.Background()
+	meter, sdk, _, processor := newSDK(t)
+
+	counter, err := meter.SyncFloat64().Counter("counter.sum")
+	require.NoError(t, err)
+	hist, err := meter.SyncFloat64().Histogram("hist.histogram")
+	require.NoError(t, err)
+	observer, err := meter.AsyncFloat64().Counter("observer.sum")
+	require.NoError(t, err)
+
+	nonFinite := []struct {
+		value float64
+		err   error
+	}{
+		{math.NaN(), aggregation.ErrNaNInput},
+		{math.Inf(+1), aggregation.ErrInfInput},
+		{math.Inf(-1), aggregation.ErrInfInput},
+	}
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{observer}, func(ctx context.Context) error {
+		for _, test := range nonFinite {
+			observer.Observe(ctx, test.value, attribute.String("A", "B"))
+			require.ErrorIs(t, testHandler.Flush(), test.err)
+		}
+		observer.Observe(ctx, 3, attribute.String("A", "B"))
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The non-finite measurements are dropped and reported with
+	// the name of their instrument.
+	for _, test := range nonFinite {
+		counter.Add(ctx, test.value)
+		err := testHandler.Flush()
+		require.ErrorIs(t, err, test.err)
+		require.Contains(t, err.Error(), "counter.sum")
+
+		hist.Record(ctx, test.value)
+		err = testHandler.Flush()
+		require.ErrorIs(t, err, test.err)
+		require.Contains(t, err.Error(), "hist.histogram")
+	}
+	counter.Add(ctx, 1)
+	hist.Record(ctx, 2)
+
+	require.Equal(t, 3, collect(t, ctx, sdk))
+	require.EqualValues(t, map[string]float64{
+		"counter.sum//":     1,
+		"hist.histogram//":  2,
+		"observer.sum/A=B/": 3,
+	}, processor.Values())
+}
+
+func TestCardinalityLimit(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(processor,
+		metricsdk.WithCardinalityLimit(3),
+		metricsdk.WithCardinalityLimits(func(desc *sdkapi.Descriptor) (int, bool) {
+			switch desc.Name() {
+			case "unlimited.sum":
+				return 0, true
+			case "small.sum":
+				return 2, true
+			}
+			return 0, false
+		}),
+	)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	limited, err := meter.AsyncInt64().Counter("limited.sum")
+	require.NoError(t, err)
+	small, err := meter.AsyncInt64().Counter("small.sum")
+	require.NoError(t, err)
+	unlimited, err := meter.AsyncInt64().Counter("unlimited.sum")
+	require.NoError(t, err)
+
+	ids := []int{0, 1, 2, 3, 4}
+	_, err = meter.RegisterCallback(
+		[]instrument.Asynchronous{limited, small, unlimited},
+		func(ctx context.Context) error {
+			for _, id := range ids {
+				limited.Observe(ctx, int64(id), attribute.Int("id", id))
+				small.Observe(ctx, int64(id), attribute.Int("id", id))
+				unlimited.Observe(ctx, int64(id), attribute.Int("id", id))
+			}
+			return nil
+		},
+	)
+	require.NoError(t, err)
+
+	// The attribute sets past the limit are folded into the
+	// overflow series, which counts against the limit.
+	expect := map[string]float64{
+		"limited.sum/id=0/":                      0,
+		"limited.sum/id=1/":                      1,
+		"limited.sum/otel.metric.overflow=true/": 2 + 3 + 4,
+		"small.sum/id=0/":                        0,
+		"small.sum/otel.metric.overflow=true/":   1 + 2 + 3 + 4,
+		"unlimited.sum/id=0/":                    0,
+		"unlimited.sum/id=1/":                    1,
+		"unlimited.sum/id=2/":                    2,
+		"unlimited.sum/id=3/":                    3,
+		"unlimited.sum/id=4/":                    4,
+	}
+	require.Equal(t, len(expect), collect(t, ctx, sdk))
+	err = testHandler.Flush()
+	require.ErrorIs(t, err, metricsdk.ErrCardinalityLimit)
+	require.EqualValues(t, expect, processor.Values())
+
+	// The overflow is reported once.
+	processor.Reset()
+	require.Equal(t, len(expect), collect(t, ctx, sdk))
+	require.NoError(t, testHandler.Flush())
+	require.EqualValues(t, expect, processor.Values())
+
+	// The attribute sets that are no longer observed stop
+	// counting against the limit once collected.
+	ids = nil
+	collect(t, ctx, sdk)
+	ids = []int{4, 3}
+	processor.Reset()
+	collect(t, ctx, sdk)
+	require.EqualValues(t, map[string]float64{
+		"limited.sum/id=4/":                    4,
+		"limited.sum/id=3/":                    3,
+		"small.sum/id=4/":                      4,
+		"small.sum/otel.metric.overflow=true/": 3,
+		"unlimited.sum/id=4/":                  4,
+		"unlimited.sum/id=3/":                  3,
+	}, processor.Values())
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestDroppedMeasurements(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	counterMeter, counterSDK, _, counterProcessor := newSDK(t)
+	counter, err := counterMeter.SyncInt64().Counter("dropped.sum")
+	require.NoError(t, err)
+
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(processor,
+		metricsdk.WithDroppedMeasurements(counter),
+		metricsdk.WithCardinalityLimits(func(desc *sdkapi.Descriptor) (int, bool) {
+			return 2, desc.Name() == "limited.sum"
+		}),
+		metricsdk.WithSeriesGrowthLimit(3),
+	)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	limited, err := meter.AsyncInt64().Counter("limited.sum")
+	require.NoError(t, err)
+	exploding, err := meter.AsyncInt64().Gauge("exploding.lastvalue")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback(
+		[]instrument.Asynchronous{limited, exploding},
+		func(ctx context.Context) error {
+			for id := 0; id < 5; id++ {
+				limited.Observe(ctx, 1, attribute.Int("id", id))
+				exploding.Observe(ctx, 1, attribute.Int("id", id))
+			}
+			return nil
+		},
+	)
+	require.NoError(t, err)
+
+	collect(t, ctx, sdk)
+	require.Error(t, testHandler.Flush())
+	require.EqualValues(t, map[string]int{
+		"limited.sum":         2,
+		"exploding.lastvalue": 3,
+	}, sdk.SeriesCounts())
+
+	counterSDK.Collect(ctx)
+	require.EqualValues(t, map[string]float64{
+		"dropped.sum/instrument=limited.sum,reason=cardinality_limit/":     4,
+		"dropped.sum/instrument=exploding.lastvalue,reason=series_growth/": 2,
+	}, counterProcessor.Values())
+
+	// The overflowing measurements are counted again by the next
+	// collection, while the series growth limit lets the two
+	// remaining attribute sets in.
+	collect(t, ctx, sdk)
+	counterSDK.Collect(ctx)
+	require.EqualValues(t, map[string]float64{
+		"dropped.sum/instrument=limited.sum,reason=cardinality_limit/":     8,
+		"dropped.sum/instrument=exploding.lastvalue,reason=series_growth/": 2,
+	}, counterProcessor.Values())
+	require.Equal(t, 5, sdk.SeriesCounts()["exploding.lastvalue"])
+}
+
+func TestSeriesGrowthLimit(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(processor, metricsdk.WithSeriesGrowthLimit(3))
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	exploding, err := meter.AsyncInt64().Gauge("exploding.lastvalue")
+	require.NoError(t, err)
+	steady, err := meter.AsyncInt64().Gauge("steady.lastvalue")
+	require.NoError(t, err)
+
+	var next int
+	_, err = meter.RegisterCallback(
+		[]instrument.Asynchronous{exploding, steady},
+		func(ctx context.Context) error {
+			for i := 0; i < 10; i++ {
+				exploding.Observe(ctx, 1, attribute.Int("id", next))
+				next++
+			}
+			steady.Observe(ctx, 2, attribute.String("A", "B"))
+			return nil
+		},
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, 4, collect(t, ctx, sdk))
+	err = testHandler.Flush()
+	require.ErrorIs(t, err, metricsdk.ErrSeriesGrowth)
+	require.Contains(t, err.Error(), "exploding.lastvalue")
+	require.EqualValues(t, map[string]float64{
+		"exploding.lastvalue/id=0/": 1,
+		"exploding.lastvalue/id=1/": 1,
+		"exploding.lastvalue/id=2/": 1,
+		"steady.lastvalue/A=B/":     2,
+	}, processor.Values())
+
+	// The limit applies again to the next cycle.
+	processor.Reset()
+	require.Equal(t, 4, collect(t, ctx, sdk))
+	require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrSeriesGrowth)
+	require.EqualValues(t, map[string]float64{
+		"exploding.lastvalue/id=10/": 1,
+		"exploding.lastvalue/id=11/": 1,
+		"exploding.lastvalue/id=12/": 1,
+		"steady.lastvalue/A=B/":      2,
+	}, processor.Values())
+}
+
+func TestSeriesKey(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+
+	// The key is the value of the "id" attribute, as assigned by an
+	// external store.
+	var calls int
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(processor, metricsdk.WithSeriesKey(func(attrs *attribute.Set) interface{} {
+		calls++
+		id, _ := attrs.Value("id")
+		return id.AsInt64()
+	}))
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	counter, err := meter.SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+	other, err := meter.SyncInt64().Counter("other.sum")
+	require.NoError(t, err)
+
+	counter.Add(ctx, 1, attribute.Int("id", 1), attribute.String("A", "B"))
+	counter.Add(ctx, 2, attribute.String("A", "B"), attribute.Int("id", 1))
+	counter.Add(ctx, 3, attribute.Int("id", 2), attribute.String("A", "C"))
+	other.Add(ctx, 4, attribute.Int("id", 1), attribute.String("A", "B"))
+	require.Equal(t, 4, calls)
+
+	require.Equal(t, 3, collect(t, ctx, sdk))
+	require.NoError(t, testHandler.Flush())
+	require.EqualValues(t, map[string]float64{
+		"counter.sum/A=B,id=1/": 3,
+		"counter.sum/A=C,id=2/": 3,
+		"other.sum/A=B,id=1/":   4,
+	}, processor.Values())
+
+	// Unused records are removed using the same key.
+	require.Equal(t, 0, collect(t, ctx, sdk))
+	counter.Add(ctx, 5, attribute.Int("id", 1), attribute.String("A", "B"))
+	processor.Reset()
+	require.Equal(t, 1, collect(t, ctx, sdk))
+	require.EqualValues(t, map[string]float64{
+		"counter.sum/A=B,id=1/": 5,
+	}, processor.Values())
+}
+
+func TestCallbackRetries(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(processor,
+		metricsdk.WithDeltaObservers("delta.sum"),
+		metricsdk.WithCallbackRetries(2, time.Millisecond),
+	)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+	delta, err := meter.AsyncInt64().Counter("delta.sum")
+	require.NoError(t, err)
+
+	errTransient := fmt.Errorf("transient")
+	var runs, failures int
+	_, err = meter.RegisterCallback(
+		[]instrument.Asynchronous{gauge, delta},
+		func(ctx context.Context) error {
+			runs++
+			gauge.Observe(ctx, int64(runs))
+			metricsdk.ObserveDelta(ctx, delta, number.NewInt64Number(5))
+			if failures > 0 {
+				failures--
+				metricsdk.FailCallback(ctx, errTransient)
+			}
+			return nil
+		},
+	)
+	require.NoError(t, err)
+
+	// The first run fails, its observations are discarded.
+	failures = 1
+	require.Equal(t, 2, collect(t, ctx, sdk))
+	require.NoError(t, testHandler.Flush())
+	require.Equal(t, 2, runs)
+	require.EqualValues(t, map[string]float64{
+		"gauge.lastvalue//": 2,
+		"delta.sum//":       5,
+	}, processor.Values())
+
+	// All runs fail: nothing is observed and the error is
+	// returned.  The running total of the delta observer is
+	// still exported.
+	runs = 0
+	failures = 3
+	processor.Reset()
+	n, err := sdk.Collect(ctx)
+	require.Equal(t, 1, n)
+	require.ErrorIs(t, err, errTransient)
+	require.NoError(t, testHandler.Flush())
+	require.Equal(t, 3, runs)
+	require.EqualValues(t, map[string]float64{
+		"delta.sum//": 5,
+	}, processor.Values())
+}
+
+func TestCallbackErrors(t *testing.T) {
+	ctx := context.Background()
+	meter, sdk, _, processor := newSDK(t)
+
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+
+	errFirst := fmt.Errorf("first")
+	errSecond := fmt.Errorf("second")
+	for _, f := range []metric.Callback{
+		func(ctx context.Context) error {
+			gauge.Observe(ctx, 1, attribute.String("callback", "ok"))
+			return nil
+		},
+		func(ctx context.Context) error {
+			gauge.Observe(ctx, 2, attribute.String("callback", "first"))
+			return errFirst
+		},
+		func(ctx context.Context) error {
+			return errSecond
+		},
+		func(ctx context.Context) error {
+			panic("boom")
+		},
+		func(ctx context.Context) error {
+			gauge.Observe(ctx, 5, attribute.String("callback", "also.ok"))
+			return nil
+		},
+	} {
+		_, err := meter.RegisterCallback([]instrument.Asynchronous{gauge}, f)
+		require.NoError(t, err)
+	}
+
+	// Every failure is returned, and the observations of the
+	// callbacks, including the failed one, are collected.
+	n, err := sdk.Collect(ctx)
+	require.Equal(t, 3, n)
+	var cbErr *metricsdk.CallbackError
+	require.ErrorAs(t, err, &cbErr)
+	require.Len(t, cbErr.Errors, 3)
+	require.ErrorIs(t, err, errFirst)
+	require.ErrorIs(t, err, errSecond)
+	require.ErrorIs(t, err, metricsdk.ErrCallbackPanic)
+	require.Contains(t, err.Error(), "boom")
+	require.NoError(t, testHandler.Flush())
+	require.EqualValues(t, map[string]float64{
+		"gauge.lastvalue/callback=ok/":      1,
+		"gauge.lastvalue/callback=first/":   2,
+		"gauge.lastvalue/callback=also.ok/": 5,
+	}, processor.Values())
+}
+
+func TestCallbackPanic(t *testing.T) {
+	ctx := context.Background()
+	meter, sdk, _, processor := newSDK(t)
+
+	first, err := meter.AsyncInt64().Gauge("first.lastvalue")
+	require.NoError(t, err)
+	second, err := meter.AsyncInt64().Gauge("second.lastvalue")
+	require.NoError(t, err)
+	other, err := meter.AsyncInt64().Gauge("other.lastvalue")
+	require.NoError(t, err)
+	counter, err := meter.SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{second, first}, func(ctx context.Context) error {
+		panic("boom")
+	})
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{other}, func(ctx context.Context) error {
+		other.Observe(ctx, 1)
+		return nil
+	})
+	require.NoError(t, err)
+	counter.Add(ctx, 2)
+
+	// The panic is returned with the names of the instruments of
+	// the callback, and the other instruments are collected, at
+	// each collection.
+	for i := 0; i < 2; i++ {
+		processor.Reset()
+		n, err := sdk.Collect(ctx)
+		require.ErrorIs(t, err, metricsdk.ErrCallbackPanic)
+		require.Contains(t, err.Error(), "boom (instruments: first.lastvalue, second.lastvalue)")
+		require.Equal(t, 2-i, n)
+		expect := map[string]float64{
+			"other.lastvalue//": 1,
+		}
+		if i == 0 {
+			expect["counter.sum//"] = 2
+		}
+		require.EqualValues(t, expect, processor.Values())
+	}
+}
+
+func TestUndeclaredInstrument(t *testing.T) {
+	ctx := context.Background()
+	meter, sdk, _, processor := newSDK(t)
+
+	declared, err := meter.AsyncInt64().Gauge("declared.lastvalue")
+	require.NoError(t, err)
+	foreign, err := meter.AsyncInt64().Gauge("foreign.lastvalue")
+	require.NoError(t, err)
+
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{declared}, func(ctx context.Context) error {
+		declared.Observe(ctx, 1)
+		foreign.Observe(ctx, 2)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 1, collect(t, ctx, sdk))
+	require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrUndeclaredInstrument)
+	require.EqualValues(t, map[string]float64{
+		"declared.lastvalue//": 1,
+	}, processor.Values())
+}
+
+func TestLateObservations(t *testing.T) {
+	ctx := context.Background()
+	meter, sdk, _, processor := newSDK(t)
+
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+
+	// Each run of the callback observes the number of the run,
+	// and starts a goroutine that observes it again, concurrently
+	// with the collection.
+	var run int64
+	var wg sync.WaitGroup
+	_, err = meter.RegisterCallback(
+		[]instrument.Asynchronous{gauge},
+		func(ctx context.Context) error {
+			run++
+			value := run
+			gauge.Observe(ctx, value)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				gauge.Observe(ctx, value, attribute.Bool("late", true))
+			}()
+			return nil
+		},
+	)
+	require.NoError(t, err)
+
+	for i := int64(1); i <= 200; i++ {
+		processor.Reset()
+		sdk.Collect(ctx)
+		wg.Wait()
+
+		// The late observation of each run is collected by
+		// its own collection, or dropped, never collected by
+		// the next one.
+		values := processor.Values()
+		require.Equal(t, float64(i), values["gauge.lastvalue//"])
+		if v, ok := values["gauge.lastvalue/late=true/"]; ok {
+			require.Equal(t, float64(i), v)
+		}
+		if err := testHandler.Flush(); err != nil {
+			require.ErrorIs(t, err, metricsdk.ErrLateObservation)
+		}
+	}
+}
+
+// returnedHook is a callback duration histogram that signals the return
+// of the first callback, as durations are recorded once callbacks have
+// returned.
+type returnedHook struct {
+	syncfloat64.Histogram
+	once     sync.Once
+	returned chan struct{}
+}
+
+func (h *returnedHook) Record(context.Context, float64, ...attribute.KeyValue) {
+	h.once.Do(func() { close(h.returned) })
+}
+
+func TestObservationAfterCallbackReturned(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	hook := &returnedHook{returned: make(chan struct{})}
+	processor := processortest.NewProcessor(processortest.AggregatorSelector(), attribute.DefaultEncoder())
+	sdk := metricsdk.NewAccumulator(
+		processor,
+		metricsdk.WithCallbackConcurrency(2),
+		metricsdk.WithCallbackDurations(hook),
+	)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+	other, err := meter.AsyncInt64().Gauge("other.lastvalue")
+	require.NoError(t, err)
+
+	// The first callback returns, and its goroutine observes while
+	// the second callback still runs, i.e., before the collection
+	// ends.
+	observed := make(chan struct{})
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(cbCtx context.Context) error {
+		gauge.Observe(cbCtx, 1)
+		go func() {
+			<-hook.returned
+			gauge.Observe(cbCtx, 2, attribute.Bool("late", true))
+			close(observed)
+		}()
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{other}, func(cbCtx context.Context) error {
+		<-observed
+		other.Observe(cbCtx, 3)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 2, collect(t, ctx, sdk))
+	require.EqualValues(t, map[string]float64{
+		"gauge.lastvalue//": 1,
+		"other.lastvalue//": 3,
+	}, processor.Values())
+	require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrLateObservation)
+}
+
+// blockingProcessor blocks the collection of its first record until
+// release is closed.
+type blockingProcessor struct {
+	*processortest.Processor
+	once      sync.Once
+	collected chan struct{}
+	release   chan struct{}
+}
+
+func (p *blockingProcessor) Process(accum export.Accumulation) error {
+	p.once.Do(func() {
+		close(p.collected)
+		<-p.release
+	})
+	return p.Processor.Process(accum)
+}
+
+func TestCollectBarrier(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := &blockingProcessor{
+		Processor: processortest.NewProcessor(processortest.AggregatorSelector(), attribute.DefaultEncoder()),
+		collected: make(chan struct{}),
+		release:   make(chan struct{}),
+	}
+	sdk := metricsdk.NewAccumulator(processor)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+
+	// The first run of the callback starts goroutines that observe
+	// once the records are being collected, after the callback
+	// returned.
+	var late, outside int32
+	var wg sync.WaitGroup
+	var start sync.Once
+	startObservers := func(cbCtx context.Context) {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			<-processor.collected
+			gauge.Observe(cbCtx, 2, attribute.String("from", "late"))
+			atomic.StoreInt32(&late, 1)
+		}()
+		go func() {
+			defer wg.Done()
+			<-processor.collected
+			gauge.Observe(ctx, 3, attribute.String("from", "outside"))
+			atomic.StoreInt32(&outside, 1)
+		}()
+	}
+	_, err = meter.RegisterCallback(
+		[]instrument.Asynchronous{gauge},
+		func(cbCtx context.Context) error {
+			gauge.Observe(cbCtx, 1)
+			start.Do(func() { startObservers(cbCtx) })
+			return nil
+		},
+	)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		_, err := sdk.Collect(ctx)
+		done <- err
+	}()
+
+	// No observation inserts a record until the collection ends.
+	<-processor.collected
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, int32(0), atomic.LoadInt32(&late))
+	require.Equal(t, int32(0), atomic.LoadInt32(&outside))
+	close(processor.release)
+	require.NoError(t, <-done)
+	wg.Wait()
+
+	require.EqualValues(t, map[string]float64{
+		"gauge.lastvalue//": 1,
+	}, processor.Values())
+	require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrLateObservation)
+
+	// The observation made outside of the callback is collected
+	// next, the late one is dropped.
+	processor.Reset()
+	collect(t, ctx, sdk)
+	require.EqualValues(t, map[string]float64{
+		"gauge.lastvalue//":             1,
+		"gauge.lastvalue/from=outside/": 3,
+	}, processor.Values())
+}
+
+func TestInstrumentAlias(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+
+	run := func(opts ...metricsdk.Option) map[string]float64 {
+		processor := processortest.NewProcessor(
+			processortest.AggregatorSelector(),
+			attribute.DefaultEncoder(),
+		)
+		sdk := metricsdk.NewAccumulator(processor, opts...)
+		meter := sdkapi.WrapMeterImpl(sdk)
+
+		counter, err := meter.SyncInt64().Counter("new.sum")
+		require.NoError(t, err)
+		gauge, err := meter.AsyncFloat64().Gauge("new.lastvalue")
+		require.NoError(t, err)
+		_, err = meter.RegisterCallback(
+			[]instrument.Asynchronous{gauge},
+			func(ctx context.Context) error {
+				gauge.Observe(ctx, 1.5, attribute.String("A", "B"))
+				return nil
+			},
+		)
+		require.NoError(t, err)
+
+		counter.Add(ctx, 3, attribute.String("A", "B"))
+		sdk.Collect(ctx)
+		require.NoError(t, testHandler.Flush())
+		return processor.Values()
+	}
+
+	// One measurement populates both names.
+	require.EqualValues(t, map[string]float64{
+		"new.sum/A=B/":       3,
+		"old.sum/A=B/":       3,
+		"new.lastvalue/A=B/": 1.5,
+		"old.lastvalue/A=B/": 1.5,
+	}, run(
+		metricsdk.WithInstrumentAlias("new.sum", "old.sum"),
+		metricsdk.WithInstrumentAlias("new.lastvalue", "old.lastvalue"),
+	))
+
+	// The alias is collected even when the instrument is
+	// excluded.
+	require.EqualValues(t, map[string]float64{
+		"old.sum/A=B/":       3,
+		"new.lastvalue/A=B/": 1.5,
+	}, run(
+		metricsdk.WithInstrumentAlias("new.sum", "old.sum"),
+		metricsdk.WithExcludedInstruments(func(desc *sdkapi.Descriptor) bool {
+			return desc.Name() == "new.sum"
+		}),
+	))
+
+	// Without the alias, only the instrument is collected.
+	require.EqualValues(t, map[string]float64{
+		"new.sum/A=B/":       3,
+		"new.lastvalue/A=B/": 1.5,
+	}, run())
+}
+
+func TestNilProcessor(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	sdk := metricsdk.NewAccumulator(nil)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	// Instruments can be created, but not used.  The dropped
+	// measurements are reported once per instrument.
+	var handled []error
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		handled = append(handled, err)
+	}))
+	defer otel.SetErrorHandler(testHandler)
+	counter, err := meter.SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		counter.Add(ctx, 1)
+	}
+	require.Len(t, handled, 1)
+	require.ErrorIs(t, handled[0], metricsdk.ErrNoProcessor)
+	otel.SetErrorHandler(testHandler)
+
+	n, err := sdk.Collect(ctx)
+	require.ErrorIs(t, err, metricsdk.ErrNoProcessor)
+	require.Equal(t, 0, n)
+	n, err = sdk.CollectTagged(ctx, "tag")
+	require.ErrorIs(t, err, metricsdk.ErrNoProcessor)
+	require.Equal(t, 0, n)
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestBackpressure(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	var congested, signaled int
+	sdk := metricsdk.NewAccumulator(processor, metricsdk.WithBackpressure(func() bool {
+		signaled++
+		return congested > 0
+	}))
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+	var backpressure []bool
+	for i := 0; i < 2; i++ {
+		attr := attribute.Int("callback", i)
+		_, err := meter.RegisterCallback(
+			[]instrument.Asynchronous{gauge},
+			func(ctx context.Context) error {
+				backpressure = append(backpressure, metricsdk.Backpressure(ctx))
+				gauge.Observe(ctx, 1, attr)
+				return nil
+			},
+		)
+		require.NoError(t, err)
+	}
+
+	// The signal is sampled once per collection, for all the
+	// callbacks.
+	sdk.Collect(ctx)
+	require.Equal(t, []bool{false, false}, backpressure)
+	congested = 1
+	backpressure = nil
+	require.Equal(t, 2, collect(t, ctx, sdk))
+	require.Equal(t, []bool{true, true}, backpressure)
+	require.Equal(t, 2, signaled)
+
+	require.False(t, metricsdk.Backpressure(ctx))
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestCallbackContextDone(t *testing.T) {
+	meter, sdk, _, processor := newSDK(t)
+
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+
+	// Each callback blocks until the Context is done.
+	var calls int
+	for i := 0; i < 2; i++ {
+		_, err := meter.RegisterCallback(
+			[]instrument.Asynchronous{gauge},
+			func(ctx context.Context) error {
+				calls++
+				<-ctx.Done()
+				gauge.Observe(ctx, 1)
+				return nil
+			},
+		)
+		require.NoError(t, err)
+	}
+
+	// The first callback to run returns when the Context times
+	// out, the other one is skipped.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = sdk.Collect(ctx)
+	require.Equal(t, 1, calls)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.EqualValues(t, map[string]float64{
+		"gauge.lastvalue//": 1,
+	}, processor.Values())
+
+	// No callback runs with a canceled Context.
+	calls = 0
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = sdk.Collect(canceled)
+	require.Zero(t, calls)
+	require.ErrorIs(t, err, context.Canceled)
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestCollectContextDone(t *testing.T) {
+	meter, sdk, _, processor := newSDK(t)
+
+	counter, err := meter.SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+	const series = 2000
+	for i := 0; i < series; i++ {
+		counter.Add(context.Background(), 1, attribute.Int("i", i))
+	}
+
+	// The collection stops early, with a partial result.
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	n, err := sdk.Collect(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Greater(t, n, 0)
+	require.Less(t, n, series)
+	partial := processor.Values()
+	require.Len(t, partial, n)
+
+	// The records that were not collected are collected next time.
+	processor.Reset()
+	require.Equal(t, series-n, collect(t, context.Background(), sdk))
+	rest := processor.Values()
+	require.Len(t, rest, series-n)
+	for key := range rest {
+		_, ok := partial[key]
+		require.False(t, ok, key)
+	}
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestCallbacksShareInstrument(t *testing.T) {
+	ctx := context.Background()
+	for _, opts := range [][]metricsdk.Option{
+		nil,
+		{metricsdk.WithCallbackRetries(1, time.Millisecond)},
+	} {
+		testHandler.Reset()
+		processor := processortest.NewProcessor(
+			processortest.AggregatorSelector(),
+			attribute.DefaultEncoder(),
+		)
+		sdk := metricsdk.NewAccumulator(processor, opts...)
+		meter := sdkapi.WrapMeterImpl(sdk)
+
+		gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+		require.NoError(t, err)
+
+		// Each subsystem observes its own attribute sets.
+		disk, err := meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+			gauge.Observe(ctx, 1, attribute.String("subsystem", "disk"))
+			return nil
+		})
+		require.NoError(t, err)
+		var conflict bool
+		_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+			gauge.Observe(ctx, 2, attribute.String("subsystem", "net"))
+			if conflict {
+				gauge.Observe(ctx, 3, attribute.String("subsystem", "disk"))
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 2, collect(t, ctx, sdk))
+		require.NoError(t, testHandler.Flush())
+		require.EqualValues(t, map[string]float64{
+			"gauge.lastvalue/subsystem=disk/": 1,
+			"gauge.lastvalue/subsystem=net/":  2,
+		}, processor.Values())
+
+		// Observing the attribute set of the other callback is
+		// reported, and one of the observations wins.
+		conflict = true
+		processor.Reset()
+		require.Equal(t, 2, collect(t, ctx, sdk))
+		require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrObservationConflict)
+		require.Contains(t, []float64{1, 3}, processor.Values()["gauge.lastvalue/subsystem=disk/"])
+
+		// Unregistering a callback leaves the other one.
+		conflict = false
+		require.NoError(t, disk.Unregister())
+		processor.Reset()
+		require.Equal(t, 1, collect(t, ctx, sdk))
+		require.NoError(t, testHandler.Flush())
+		require.EqualValues(t, map[string]float64{
+			"gauge.lastvalue/subsystem=net/": 2,
+		}, processor.Values())
+	}
+}
+
+func TestCallbackTimeout(t *testing.T) {
+	ctx := context.Background()
+	for _, opts := range [][]metricsdk.Option{
+		{metricsdk.WithCallbackTimeout(10 * time.Millisecond)},
+		// The observations buffered before the timeout are kept.
+		{
+			metricsdk.WithCallbackTimeout(10 * time.Millisecond),
+			metricsdk.WithCallbackRetries(1, time.Millisecond),
+		},
+	} {
+		testHandler.Reset()
+		processor := processortest.NewProcessor(
+			processortest.AggregatorSelector(),
+			attribute.DefaultEncoder(),
+		)
+		sdk := metricsdk.NewAccumulator(processor, opts...)
+		meter := sdkapi.WrapMeterImpl(sdk)
+
+		slow, err := meter.AsyncInt64().Gauge("slow.lastvalue")
+		require.NoError(t, err)
+		fast, err := meter.AsyncInt64().Gauge("fast.lastvalue")
+		require.NoError(t, err)
+
+		// The slow callback ignores its deadline until released.
+		release := make(chan struct{})
+		finished := make(chan struct{})
+		_, err = meter.RegisterCallback([]instrument.Asynchronous{slow}, func(ctx context.Context) error {
+			defer close(finished)
+			slow.Observe(ctx, 1)
+			<-release
+			slow.Observe(ctx, 2)
+			return nil
+		})
+		require.NoError(t, err)
+		_, err = meter.RegisterCallback([]instrument.Asynchronous{fast}, func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			require.True(t, ok)
+			fast.Observe(ctx, 3)
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 2, collect(t, ctx, sdk))
+		require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrCallbackTimeout)
+		require.EqualValues(t, map[string]float64{
+			"slow.lastvalue//": 1,
+			"fast.lastvalue//": 3,
+		}, processor.Values())
+
+		// The observation made after the collection is dropped.
+		close(release)
+		<-finished
+		require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrLateObservation)
+	}
+}
+
+func TestCallbackTimeoutContextDone(t *testing.T) {
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(processor, metricsdk.WithCallbackTimeout(time.Hour))
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The collection deadline fails the callback rather than
+	// timing it out.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = sdk.Collect(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestUnregisterCallback(t *testing.T) {
+	ctx := context.Background()
+	meter, sdk, _, processor := newSDK(t)
+
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+
+	var calls int
+	reg, err := meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		calls++
+		gauge.Observe(ctx, 1)
+		return nil
+	})
+	require.NoError(t, err)
+
+	sdk.Collect(ctx)
+	require.Equal(t, 1, calls)
+
+	require.NoError(t, reg.Unregister())
+	require.ErrorIs(t, reg.Unregister(), metricsdk.ErrUnknownCallback)
+	processor.Reset()
+	sdk.Collect(ctx)
+	require.Equal(t, 1, calls)
+	require.Empty(t, processor.Values())
+
+	// The instrument can be registered again, and a callback
+	// can unregister itself.
+	var self metric.Registration
+	self, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		calls++
+		require.NoError(t, self.Unregister())
+		gauge.Observe(ctx, 2)
+		return nil
+	})
+	require.NoError(t, err)
+	sdk.Collect(ctx)
+	sdk.Collect(ctx)
+	require.Equal(t, 2, calls)
+	require.EqualValues(t, map[string]float64{
+		"gauge.lastvalue//": 2,
+	}, processor.Values())
+
+	var unknown *metricsdk.Registration
+	require.ErrorIs(t, unknown.Unregister(), metricsdk.ErrUnknownCallback)
+	require.NoError(t, testHandler.Flush())
+}
+
+func TestUnregisterCallbackConcurrent(t *testing.T) {
+	ctx := context.Background()
+	meter, sdk, _, _ := newSDK(t)
+
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			sdk.Collect(ctx)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		reg, err := meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+			gauge.Observe(ctx, 1)
+			return nil
+		})
+		require.NoError(t, err)
+		require.NoError(t, reg.Unregister())
+	}
+	<-done
+}
+
+func TestUnitConversion(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+	processor := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(processor,
+		metricsdk.WithUnitConverter("Cel", "K", func(v float64) float64 { return v + 273.15 }),
+		metricsdk.WithUnitConverter("s", unit.Milliseconds, func(v float64) float64 { return v * 1000 }),
+		metricsdk.WithUnitConversion(func(desc *sdkapi.Descriptor) (unit.Unit, bool) {
+			switch desc.Unit() {
+			case "Cel":
+				return "K", true
+			case "s", "By":
+				return unit.Milliseconds, true
+			}
+			return "", false
+		}),
+	)
+	meter := sdkapi.WrapMeterImpl(sdk)
+
+	temperature, err := meter.AsyncFloat64().Gauge("temperature.lastvalue", instrument.WithUnit("Cel"))
+	require.NoError(t, err)
+	require.Equal(t, unit.Unit("K"), temperature.(sdkapi.AsyncImpl).Descriptor().Unit())
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{temperature}, func(ctx context.Context) error {
+		temperature.Observe(ctx, 20)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Integer instruments are converted too.
+	duration, err := meter.SyncInt64().Histogram("duration.histogram", instrument.WithUnit("s"))
+	require.NoError(t, err)
+	duration.Record(ctx, 2)
+	duration.Record(ctx, 3)
+
+	plain, err := meter.SyncInt64().Counter("plain.sum")
+	require.NoError(t, err)
+	plain.Add(ctx, 1)
+
+	// There is no converter from bytes to milliseconds.
+	_, err = meter.SyncInt64().Counter("bytes.sum", instrument.WithUnit("By"))
+	require.ErrorIs(t, err, metricsdk.ErrNoUnitConverter)
+
+	require.Equal(t, 3, collect(t, ctx, sdk))
+	require.NoError(t, testHandler.Flush())
+	require.EqualValues(t, map[string]float64{
+		"temperature.lastvalue//": 293.15,
+		"duration.histogram//":    5000,
+		"plain.sum//":             1,
+	}, processor.Values())
+}
+
+func TestInstrumentRename(t *testing.T) {
+	ctx := context.Background()
+	testHandler.Reset()
+
+	// The test processor selects the aggregation by the suffix
+	// of the exported name.
+
+	processor := processortest.NewProcessor(
+		simple.NewWithHistogramDistribution(),
+		attribute.DefaultEncoder(),
+	)
+	sdk := metricsdk.NewAccumulator(
+		processor,
+		metricsdk.WithInstrumentRename("http.server.duration", "http_server_duration.histogram"),
+	)
+	meter := sdkapi.WrapMeterImpl(registry.NewUniqueInstrumentMeterImpl(sdk))
+
+	duration, err := meter.SyncFloat64().Histogram("http.server.duration")
+	require.NoError(t, err)
+
+	// Registrations are still checked under the original name.
+	_, err = meter.SyncInt64().Counter("http.server.duration")
+	require.ErrorIs(t, err, registry.ErrMetricKindMismatch)
+
+	duration.Record(ctx, 2.5, attribute.String("A", "B"))
+	collect(t, ctx, sdk)
+	require.NoError(t, testHandler.Flush())
+	require.EqualValues(t, map[string]float64{
+		"http_server_duration.histogram/A=B/": 2.5,
+	}, processor.Values())
+
+	// Another instrument renamed to the same name conflicts.
+	sdk = metricsdk.NewAccumulator(
+		processor,
+		metricsdk.WithInstrumentRename("http.server.duration", "http_server_duration.histogram"),
+		metricsdk.WithInstrumentRename("http.server.latency", "http_server_duration.histogram"),
+	)
+	meter = sdkapi.WrapMeterImpl(sdk)
+	_, err = meter.SyncFloat64().Histogram("http.server.duration")
+	require.NoError(t, err)
+	require.NoError(t, testHandler.Flush())
+	_, err = meter.SyncFloat64().Histogram("http.server.latency")
+	require.NoError(t, err)
+	require.ErrorIs(t, testHandler.Flush(), metricsdk.ErrRenameConflict)
+
+	// The new names follow the naming rules.
+	sdk = metricsdk.NewAccumulator(
+		processor,
+		metricsdk.WithInstrumentRename("http.server.duration", "http/server/duration"),
+	)
+	_, err = sdkapi.WrapMeterImpl(sdk).SyncFloat64().Histogram("http.server.duration")
+	require.ErrorIs(t, err, metricsdk.ErrInvalidInstrumentName)
+
+	// An instrument that fails to be created, here because of its
+	// alias, does not hold its new name.
+	sdk = metricsdk.NewAccumulator(
+		processor,
+		metricsdk.WithInstrumentRename("http.server.duration", "http_server_duration.histogram"),
+		metricsdk.WithInstrumentRename("http.server.latency", "http_server_duration.histogram"),
+		metricsdk.WithInstrumentAlias("http.server.duration", "http server duration"),
+	)
+	meter = sdkapi.WrapMeterImpl(sdk)
+	_, err = meter.SyncFloat64().Histogram("http.server.duration")
+	require.ErrorIs(t, err, metricsdk.ErrInvalidInstrumentName)
+	_, err = meter.SyncFloat64().Histogram("http.server.latency")
+	require.NoError(t, err)
+	require.NoError(t, testHandler.Flush())
+}
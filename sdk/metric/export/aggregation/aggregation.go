@@ -18,7 +18,9 @@ import (
 	"fmt"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // These interfaces describe the various ways to access state from an
@@ -46,6 +48,30 @@ type (
 		Count() (uint64, error)
 	}
 
+	// Min returns the minimum value over the set of values that
+	// were aggregated.
+	Min interface {
+		Aggregation
+		Min() (number.Number, error)
+	}
+
+	// Max returns the maximum value over the set of values that
+	// were aggregated.
+	Max interface {
+		Aggregation
+		Max() (number.Number, error)
+	}
+
+	// MinMaxSumCount supports the Min, Max, Sum, and Count
+	// interfaces.
+	MinMaxSumCount interface {
+		Aggregation
+		Min() (number.Number, error)
+		Max() (number.Number, error)
+		Sum() (number.Number, error)
+		Count() (uint64, error)
+	}
+
 	// LastValue returns the latest value that was aggregated.
 	LastValue interface {
 		Aggregation
@@ -63,6 +89,12 @@ type (
 
 		// Counts holds the count in each bucket.
 		Counts []uint64
+
+		// Exemplars holds the exemplar of each bucket, in the
+		// order of Counts, when the histogram samples them.
+		// The buckets without an exemplar have one with a
+		// zero Time.
+		Exemplars []Exemplar
 	}
 
 	// Histogram returns the count of events in pre-determined buckets.
@@ -72,6 +104,74 @@ type (
 		Sum() (number.Number, error)
 		Histogram() (Buckets, error)
 	}
+
+	// ExponentialBuckets represents the buckets of an exponential
+	// histogram, see the data model of the ExponentialHistogram:
+	// https://github.com/open-telemetry/opentelemetry-specification/blob/main/specification/metrics/data-model.md#exponentialhistogram
+	ExponentialBuckets struct {
+		// Scale sets the resolution of the buckets: the
+		// boundaries of the bucket with index i are
+		// (base**i, base**(i+1)], with base = 2**(2**-Scale).
+		Scale int32
+
+		// ZeroCount is the number of zero values.
+		ZeroCount uint64
+
+		// Positive holds the counts of the positive values,
+		// Negative those of the negative values by their
+		// absolute value.
+		Positive, Negative ExponentialBucketCounts
+	}
+
+	// ExponentialBucketCounts holds the counts of consecutive
+	// exponential buckets.
+	ExponentialBucketCounts struct {
+		// Offset is the index of the bucket of Counts[0].
+		Offset int32
+
+		// Counts holds the count in each bucket.
+		Counts []uint64
+	}
+
+	// ExponentialHistogram returns the count of events in
+	// exponential buckets, whose scale adapts to the range of the
+	// values.
+	ExponentialHistogram interface {
+		Aggregation
+		Count() (uint64, error)
+		Sum() (number.Number, error)
+		ExponentialHistogram() (ExponentialBuckets, error)
+	}
+
+	// Exemplars returns measurements sampled while a sampled span
+	// was active, e.g., to link a metric point to example traces.
+	// Aggregators that support exemplars implement it, and return
+	// no exemplars unless they were configured to sample them.
+	Exemplars interface {
+		Aggregation
+		Exemplars() ([]Exemplar, error)
+	}
+
+	// Exemplar is a measurement and the span in which it was
+	// recorded.
+	Exemplar struct {
+		// Value is the measured value, of the number kind of
+		// the instrument.
+		Value number.Number
+
+		// Time is when the measurement was made.
+		Time time.Time
+
+		// SpanContext identifies the span that was active
+		// when the measurement was made.
+		SpanContext trace.SpanContext
+
+		// FilteredAttributes are the attributes of the
+		// measurement that the attribute filter of the
+		// instrument removed from its series, e.g., because
+		// of their cardinality.
+		FilteredAttributes []attribute.KeyValue
+	}
 )
 
 type (
@@ -92,25 +192,42 @@ type (
 
 // Kind description constants.
 const (
-	SumKind       Kind = "Sum"
-	HistogramKind Kind = "Histogram"
-	LastValueKind Kind = "Lastvalue"
+	SumKind                  Kind = "Sum"
+	HistogramKind            Kind = "Histogram"
+	LastValueKind            Kind = "Lastvalue"
+	ExponentialHistogramKind Kind = "ExponentialHistogram"
+	MinMaxSumCountKind       Kind = "MinMaxSumCount"
+
+	// DropKind is the kind of no aggregation at all: the
+	// AggregatorSelector returns nil Aggregators and the
+	// measurements of the instrument are discarded.
+	DropKind Kind = "Drop"
 )
 
 // Sentinel errors for Aggregation interface.
 var (
 	ErrNegativeInput    = fmt.Errorf("negative value is out of range for this instrument")
 	ErrNaNInput         = fmt.Errorf("invalid input value: NaN")
+	ErrInfInput         = fmt.Errorf("invalid input value: Inf")
 	ErrInconsistentType = fmt.Errorf("inconsistent aggregator types")
 
 	// ErrNoCumulativeToDelta is returned when requesting delta
 	// export kind for a precomputed sum instrument.
 	ErrNoCumulativeToDelta = fmt.Errorf("cumulative to delta not implemented")
 
+	// ErrDeltaUpDownCounter is reported when delta temporality is
+	// requested for synchronous UpDownCounters, which are always
+	// cumulative, see InstrumentKindTemporalitySelector.
+	ErrDeltaUpDownCounter = fmt.Errorf("delta temporality of synchronous UpDownCounters not supported")
+
 	// ErrNoData is returned when (due to a race with collection)
 	// the Aggregator is check-pointed before the first value is set.
 	// The aggregator should simply be skipped in this case.
 	ErrNoData = fmt.Errorf("no data collected by this aggregator")
+
+	// ErrSumOverflow is reported when an integer sum overflows,
+	// and is saturated at the minimum or maximum int64 instead.
+	ErrSumOverflow = fmt.Errorf("integer sum overflowed, saturated")
 )
 
 // String returns the string value of Kind.
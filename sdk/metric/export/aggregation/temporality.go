@@ -17,6 +17,10 @@
 package aggregation // import "go.opentelemetry.io/otel/sdk/metric/export/aggregation"
 
 import (
+	"fmt"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
 )
 
@@ -59,13 +63,21 @@ func (t Temporality) MemoryRequired(mkind sdkapi.InstrumentKind) bool {
 }
 
 type (
-	constantTemporalitySelector  Temporality
-	statelessTemporalitySelector struct{}
+	constantTemporalitySelector       Temporality
+	statelessTemporalitySelector      struct{}
+	instrumentKindTemporalitySelector struct {
+		f func(sdkapi.InstrumentKind) Temporality
+
+		// rejected flags the instrument kinds whose delta
+		// temporality was reported as rejected, once.
+		rejected [sdkapi.UpDownCounterObserverInstrumentKind + 1]int32
+	}
 )
 
 var (
 	_ TemporalitySelector = constantTemporalitySelector(0)
 	_ TemporalitySelector = statelessTemporalitySelector{}
+	_ TemporalitySelector = &instrumentKindTemporalitySelector{}
 )
 
 // ConstantTemporalitySelector returns an TemporalitySelector that returns
@@ -93,6 +105,46 @@ func StatelessTemporalitySelector() TemporalitySelector {
 	return statelessTemporalitySelector{}
 }
 
+// InstrumentKindTemporalitySelector returns a TemporalitySelector that
+// returns the Temporality chosen by f for the kind of each instrument.
+// Synchronous UpDownCounters are always cumulative, since the deltas of
+// a non-monotonic sum are rarely useful on their own, and so are the
+// asynchronous counters, whose precomputed sums the processor cannot
+// convert to deltas.  Delta temporality chosen for them is reported once
+// per kind to the global error handler, wrapping ErrDeltaUpDownCounter
+// or ErrNoCumulativeToDelta.  The instruments for which f does not
+// return either CumulativeTemporality or DeltaTemporality are
+// cumulative.
+func InstrumentKindTemporalitySelector(f func(sdkapi.InstrumentKind) Temporality) TemporalitySelector {
+	return &instrumentKindTemporalitySelector{f: f}
+}
+
+// TemporalityFor implements TemporalitySelector.
+func (s *instrumentKindTemporalitySelector) TemporalityFor(desc *sdkapi.Descriptor, _ Kind) Temporality {
+	ikind := desc.InstrumentKind()
+	t := s.f(ikind)
+	switch {
+	case t != CumulativeTemporality && t != DeltaTemporality:
+		return CumulativeTemporality
+	case t == DeltaTemporality && ikind == sdkapi.UpDownCounterInstrumentKind:
+		s.reject(ikind, ErrDeltaUpDownCounter)
+		return CumulativeTemporality
+	case t == DeltaTemporality && ikind.PrecomputedSum():
+		s.reject(ikind, ErrNoCumulativeToDelta)
+		return CumulativeTemporality
+	}
+	return t
+}
+
+// reject reports that the delta temporality chosen for ikind is
+// replaced by cumulative temporality, the first time only.
+func (s *instrumentKindTemporalitySelector) reject(ikind sdkapi.InstrumentKind, err error) {
+	if ikind < 0 || int(ikind) >= len(s.rejected) || !atomic.CompareAndSwapInt32(&s.rejected[ikind], 0, 1) {
+		return
+	}
+	otel.Handle(fmt.Errorf("%v: delta temporality replaced by %v: %w", ikind, CumulativeTemporality, err))
+}
+
 // TemporalityFor implements TemporalitySelector.
 func (c constantTemporalitySelector) TemporalityFor(_ *sdkapi.Descriptor, _ Kind) Temporality {
 	return Temporality(c)
@@ -19,6 +19,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/sdk/metric/number"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
 )
@@ -71,3 +72,37 @@ func TestTemporalitySelectors(t *testing.T) {
 		require.False(t, sAggTemp.TemporalityFor(&desc, akind).MemoryRequired(ikind))
 	}
 }
+
+func TestInstrumentKindTemporalitySelector(t *testing.T) {
+	var handled []error
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		handled = append(handled, err)
+	}))
+	defer otel.SetErrorHandler(otel.ErrorHandlerFunc(func(error) {}))
+
+	delta := InstrumentKindTemporalitySelector(func(sdkapi.InstrumentKind) Temporality {
+		return DeltaTemporality
+	})
+	invalid := InstrumentKindTemporalitySelector(func(sdkapi.InstrumentKind) Temporality {
+		return CumulativeTemporality | DeltaTemporality
+	})
+
+	for i := 0; i < 2; i++ {
+		for _, ikind := range append(deltaMemoryTemporalties, cumulativeMemoryTemporalties...) {
+			desc := sdkapi.NewDescriptor("instrument", ikind, number.Int64Kind, "", "")
+
+			want := DeltaTemporality
+			if ikind == sdkapi.UpDownCounterInstrumentKind || ikind.PrecomputedSum() {
+				want = CumulativeTemporality
+			}
+			require.Equal(t, want, delta.TemporalityFor(&desc, SumKind), ikind)
+			require.Equal(t, CumulativeTemporality, invalid.TemporalityFor(&desc, SumKind), ikind)
+		}
+	}
+
+	// The rejected delta temporalities are reported once per kind.
+	require.Len(t, handled, 3)
+	require.ErrorIs(t, handled[0], ErrNoCumulativeToDelta)
+	require.ErrorIs(t, handled[1], ErrNoCumulativeToDelta)
+	require.ErrorIs(t, handled[2], ErrDeltaUpDownCounter)
+}
@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fanout provides an Exporter that forwards the metric data of
+// each collection to several Exporters, so that one controller can send
+// the same data to several backends without collecting it twice.
+package fanout // import "go.opentelemetry.io/otel/sdk/metric/export/fanout"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// ErrMixedTemporality is returned by New when the exporters do not
+// select the same temporality for some instrument.
+var ErrMixedTemporality = fmt.Errorf("exporters select different temporalities")
+
+// Exporter forwards each export to several Exporters concurrently.
+type Exporter struct {
+	exporters []export.Exporter
+}
+
+var (
+	_ export.Exporter           = &Exporter{}
+	_ export.CongestionReporter = &Exporter{}
+)
+
+// instrumentKinds and aggregationKinds are the kinds for which New
+// compares the temporalities of the exporters.
+var (
+	instrumentKinds = []sdkapi.InstrumentKind{
+		sdkapi.HistogramInstrumentKind,
+		sdkapi.GaugeObserverInstrumentKind,
+		sdkapi.CounterInstrumentKind,
+		sdkapi.UpDownCounterInstrumentKind,
+		sdkapi.CounterObserverInstrumentKind,
+		sdkapi.UpDownCounterObserverInstrumentKind,
+	}
+	aggregationKinds = []aggregation.Kind{
+		aggregation.SumKind,
+		aggregation.HistogramKind,
+		aggregation.LastValueKind,
+		aggregation.ExponentialHistogramKind,
+		aggregation.MinMaxSumCountKind,
+	}
+)
+
+// New returns an Exporter that forwards each export to every one of
+// exporters.  The exporters share the processor of the controller, which
+// computes a single temporality per instrument: an error wrapping
+// ErrMixedTemporality is returned when the exporters select different
+// temporalities for a kind of instrument and aggregation, e.g., when a
+// cumulative exporter is combined with a delta exporter.  Exporters whose
+// temporality depends on more than these kinds, e.g., on the name of the
+// instrument, are not supported.
+func New(exporters ...export.Exporter) (*Exporter, error) {
+	for i := 1; i < len(exporters); i++ {
+		for _, ikind := range instrumentKinds {
+			desc := sdkapi.NewDescriptor("", ikind, number.Int64Kind, "", "")
+			for _, akind := range aggregationKinds {
+				first, t := exporters[0].TemporalityFor(&desc, akind), exporters[i].TemporalityFor(&desc, akind)
+				if first != t {
+					return nil, fmt.Errorf("%w: %s and %s for %s with %s", ErrMixedTemporality, first, t, ikind, akind)
+				}
+			}
+		}
+	}
+	return &Exporter{
+		exporters: append([]export.Exporter(nil), exporters...),
+	}, nil
+}
+
+// Export implements export.Exporter.  The exporters run concurrently
+// with the same Context, so a slow exporter does not delay the others
+// and the deadline of the Context applies to all of them.  Export
+// returns when every exporter has returned, since the reader must not be
+// used afterwards; exporters are expected to return when the Context is
+// done.  When some exporters fail, the returned error is an *Error
+// holding the error of each.
+func (e *Exporter) Export(ctx context.Context, res *resource.Resource, reader export.InstrumentationLibraryReader) error {
+	errs := make([]error, len(e.exporters))
+	var wg sync.WaitGroup
+	for i, exp := range e.exporters {
+		wg.Add(1)
+		go func(i int, exp export.Exporter) {
+			defer wg.Done()
+			errs[i] = exp.Export(ctx, res, reader)
+		}(i, exp)
+	}
+	wg.Wait()
+
+	var failed Error
+	for _, err := range errs {
+		if err != nil {
+			failed.Errors = append(failed.Errors, err)
+		}
+	}
+	if len(failed.Errors) == 0 {
+		return nil
+	}
+	return &failed
+}
+
+// TemporalityFor implements aggregation.TemporalitySelector.  It returns
+// the temporality that all the exporters select, see New, or
+// CumulativeTemporality when there are no exporters.
+func (e *Exporter) TemporalityFor(desc *sdkapi.Descriptor, kind aggregation.Kind) aggregation.Temporality {
+	if len(e.exporters) == 0 {
+		return aggregation.CumulativeTemporality
+	}
+	return e.exporters[0].TemporalityFor(desc, kind)
+}
+
+// Congested implements export.CongestionReporter.  It returns whether
+// any of the exporters that implement export.CongestionReporter is
+// congested, since the next export waits for every exporter.
+func (e *Exporter) Congested() bool {
+	for _, exp := range e.exporters {
+		if reporter, ok := exp.(export.CongestionReporter); ok && reporter.Congested() {
+			return true
+		}
+	}
+	return false
+}
+
+// Error is returned by Export when some exporters fail.  errors.Is and
+// errors.As match each of its errors.
+type Error struct {
+	// Errors holds the errors of the failed exporters, in the
+	// order of the exporters.
+	Errors []error
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d exporter(s) failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Is reports whether any of the errors of e matches target.
+func (e *Error) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors of e that matches target.
+func (e *Error) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fanout_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/instrument"
+	sdk "go.opentelemetry.io/otel/sdk/metric"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/export/fanout"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/metric/processor/processortest"
+	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	first := processortest.New(aggregation.StatelessTemporalitySelector(), attribute.DefaultEncoder())
+	second := processortest.New(aggregation.StatelessTemporalitySelector(), attribute.DefaultEncoder())
+	fan, err := fanout.New(first, second)
+	require.NoError(t, err)
+
+	cont := controller.New(
+		processor.NewFactory(simple.NewWithInexpensiveDistribution(), fan),
+		controller.WithCollectPeriod(0),
+	)
+	meter := cont.Meter("test")
+	counter, err := meter.SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+	observer, err := meter.AsyncInt64().Counter("observer.sum")
+	require.NoError(t, err)
+	var observed int64
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{observer}, func(ctx context.Context) error {
+		observed += 10
+		observer.Observe(ctx, observed)
+		return nil
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		first.Reset()
+		second.Reset()
+		counter.Add(ctx, 1)
+		require.NoError(t, cont.Collect(ctx))
+		require.NoError(t, fan.Export(ctx, resource.Empty(), cont))
+	}
+
+	// Both exporters receive the same collection: the delta of the
+	// counter and the cumulative sum of the observer.
+	for _, exp := range []*processortest.Exporter{first, second} {
+		require.Equal(t, 1, exp.ExportCount())
+		require.EqualValues(t, map[string]float64{
+			"counter.sum//":  1,
+			"observer.sum//": 20,
+		}, exp.Values())
+	}
+}
+
+func TestMixedTemporality(t *testing.T) {
+	cumulative := processortest.New(aggregation.CumulativeTemporalitySelector(), attribute.DefaultEncoder())
+	delta := processortest.New(aggregation.DeltaTemporalitySelector(), attribute.DefaultEncoder())
+	stateless := processortest.New(aggregation.StatelessTemporalitySelector(), attribute.DefaultEncoder())
+
+	// The processor cannot compute the deltas of the observers for a
+	// delta exporter while keeping cumulative sums for the other.
+	for _, exporters := range [][]export.Exporter{
+		{cumulative, delta},
+		{delta, cumulative},
+		{cumulative, stateless},
+		{stateless, delta},
+		{delta, delta, stateless},
+	} {
+		_, err := fanout.New(exporters...)
+		require.ErrorIs(t, err, fanout.ErrMixedTemporality)
+	}
+}
+
+func TestTemporalityFor(t *testing.T) {
+	cumulative := processortest.New(aggregation.CumulativeTemporalitySelector(), attribute.DefaultEncoder())
+	delta := processortest.New(aggregation.DeltaTemporalitySelector(), attribute.DefaultEncoder())
+
+	for _, tc := range []struct {
+		exporters []export.Exporter
+		expected  aggregation.Temporality
+	}{
+		{nil, aggregation.CumulativeTemporality},
+		{[]export.Exporter{delta, delta}, aggregation.DeltaTemporality},
+		{[]export.Exporter{cumulative, cumulative}, aggregation.CumulativeTemporality},
+	} {
+		fan, err := fanout.New(tc.exporters...)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, fan.TemporalityFor(nil, aggregation.SumKind))
+	}
+}
+
+type congestedExporter struct {
+	*processortest.Exporter
+	congested bool
+}
+
+func (e *congestedExporter) Congested() bool { return e.congested }
+
+func TestCongested(t *testing.T) {
+	ctx := context.Background()
+	congested := &congestedExporter{
+		Exporter: processortest.New(aggregation.CumulativeTemporalitySelector(), attribute.DefaultEncoder()),
+	}
+	other := processortest.New(aggregation.CumulativeTemporalitySelector(), attribute.DefaultEncoder())
+	fan, err := fanout.New(other, congested)
+	require.NoError(t, err)
+
+	cont := controller.New(
+		processor.NewFactory(processortest.AggregatorSelector(), fan),
+		controller.WithCollectPeriod(0),
+		controller.WithResource(resource.Empty()),
+		controller.WithExporter(fan),
+	)
+	meter := cont.Meter("test")
+	gauge, err := meter.AsyncInt64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+	var backpressure []bool
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		backpressure = append(backpressure, sdk.Backpressure(ctx))
+		gauge.Observe(ctx, 1)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The callbacks see backpressure while one of the exporters
+	// is congested.
+	require.NoError(t, cont.Collect(ctx))
+	congested.congested = true
+	require.True(t, fan.Congested())
+	require.NoError(t, cont.Collect(ctx))
+	congested.congested = false
+	require.False(t, fan.Congested())
+	require.NoError(t, cont.Collect(ctx))
+	require.Equal(t, []bool{false, true, false}, backpressure)
+}
+
+// blockingExporter returns when the Context of the export is done.
+type blockingExporter struct {
+	aggregation.TemporalitySelector
+}
+
+func (blockingExporter) Export(ctx context.Context, _ *resource.Resource, _ export.InstrumentationLibraryReader) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+type failingExporter struct {
+	aggregation.TemporalitySelector
+	err error
+}
+
+func (e failingExporter) Export(context.Context, *resource.Resource, export.InstrumentationLibraryReader) error {
+	return e.err
+}
+
+func TestExportErrors(t *testing.T) {
+	errFailed := fmt.Errorf("failed")
+	ok := processortest.New(aggregation.CumulativeTemporalitySelector(), attribute.DefaultEncoder())
+	fan, err := fanout.New(
+		blockingExporter{aggregation.CumulativeTemporalitySelector()},
+		ok,
+		failingExporter{aggregation.CumulativeTemporalitySelector(), errFailed},
+	)
+	require.NoError(t, err)
+	cont := controller.New(
+		processor.NewFactory(simple.NewWithInexpensiveDistribution(), fan),
+		controller.WithCollectPeriod(0),
+	)
+	require.NoError(t, cont.Collect(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = fan.Export(ctx, resource.Empty(), cont)
+
+	// The blocking exporter does not prevent the others from
+	// exporting, and each error is reported.
+	require.Equal(t, 1, ok.ExportCount())
+	var fanErr *fanout.Error
+	require.True(t, errors.As(err, &fanErr))
+	require.Len(t, fanErr.Errors, 2)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorIs(t, err, errFailed)
+
+	fan, err = fanout.New(ok)
+	require.NoError(t, err)
+	require.NoError(t, fan.Export(context.Background(), resource.Empty(), cont))
+}
@@ -123,6 +123,27 @@ type Checkpointer interface {
 	FinishCollection() error
 }
 
+// Acknowledger is an optional interface implemented by Checkpointers
+// that retain delta state until it has been exported.  Controllers call
+// Acknowledge once the data read from the Checkpointer since the last
+// FinishCollection has been exported successfully.  The caller is
+// responsible for locking the Reader.
+type Acknowledger interface {
+	// Acknowledge confirms that the last collection was
+	// exported.
+	Acknowledge()
+}
+
+// CongestionReporter is an optional interface implemented by Exporters
+// that buffer the data they export.  Controllers signal backpressure to
+// the callbacks of asynchronous instruments while the Exporter is
+// congested, so that cooperative callbacks can reduce their work.
+type CongestionReporter interface {
+	// Congested returns whether the Exporter cannot accept more
+	// data without delay, e.g., because its buffer is full.
+	Congested() bool
+}
+
 // CheckpointerFactory is an interface for producing configured
 // Checkpointer instances.
 type CheckpointerFactory interface {
@@ -158,6 +179,25 @@ type InstrumentationLibraryReader interface {
 	ForEach(readerFunc func(instrumentation.Library, Reader) error) error
 }
 
+// Producer is a source of metric data from outside the SDK, such as a
+// bridge to another metrics library, whose records are collected and
+// exported together with those of the SDK instruments.
+type Producer interface {
+	// Produce returns the current metric data of the source,
+	// grouped by instrumentation scope.  It is called once per
+	// collection.
+	Produce(ctx context.Context) ([]ScopeMetrics, error)
+}
+
+// ScopeMetrics holds the records produced for one instrumentation
+// scope.  Records that do not report their temporality, see
+// Record.WithTemporality, are exported with the temporality selected by
+// the exporter.
+type ScopeMetrics struct {
+	Scope   instrumentation.Scope
+	Records []Record
+}
+
 // Reader allows a controller to access a complete checkpoint of
 // aggregated metrics from the Processor for a single library of
 // metric data.  This is passed to the Exporter which may then use
@@ -213,6 +253,7 @@ type Accumulation struct {
 type Record struct {
 	Metadata
 	aggregation aggregation.Aggregation
+	temporality aggregation.Temporality
 	start       time.Time
 	end         time.Time
 }
@@ -269,6 +310,21 @@ func (r Record) Aggregation() aggregation.Aggregation {
 	return r.aggregation
 }
 
+// Temporality returns the temporality of the aggregation, as chosen by
+// the TemporalitySelector passed to the ForEach of the Reader.  This
+// returns zero when the Reader did not set it, see WithTemporality.
+func (r Record) Temporality() aggregation.Temporality {
+	return r.temporality
+}
+
+// WithTemporality returns a copy of the Record with the temporality of
+// its aggregation set to t.  Processor implementations use this to
+// report the temporality used by each Record.
+func (r Record) WithTemporality(t aggregation.Temporality) Record {
+	r.temporality = t
+	return r
+}
+
 // StartTime is the start time of the interval covered by this aggregation.
 func (r Record) StartTime() time.Time {
 	return r.start
@@ -0,0 +1,486 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot // import "go.opentelemetry.io/otel/sdk/metric/export/snapshot"
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+// Version is the version of the binary encoding written by AppendBinary.
+// Version 1 did not encode the temporality of the records, and is not
+// decoded.
+const Version = 2
+
+var (
+	// ErrUnknownVersion is returned by UnmarshalBinary when the
+	// encoding has a version that is not supported.
+	ErrUnknownVersion = fmt.Errorf("unknown snapshot encoding version")
+
+	// ErrInvalidEncoding is returned by UnmarshalBinary when the
+	// encoding is truncated or malformed.
+	ErrInvalidEncoding = fmt.Errorf("invalid snapshot encoding")
+)
+
+// MarshalBinary returns the binary encoding of the Snapshot, see
+// AppendBinary.
+func (s *Snapshot) MarshalBinary() ([]byte, error) {
+	return s.AppendBinary(nil)
+}
+
+// AppendBinary appends the binary encoding of the Snapshot to b and
+// returns the extended buffer.  The encoding starts with a version byte,
+// followed by the instrumentation libraries and their records, including
+// the descriptors, attributes, aggregations, temporalities and
+// timestamps.
+func (s *Snapshot) AppendBinary(b []byte) ([]byte, error) {
+	b = append(b, Version)
+	b = appendUvarint(b, uint64(len(s.libraries)))
+	for _, l := range s.libraries {
+		b = appendString(b, l.library.Name)
+		b = appendString(b, l.library.Version)
+		b = appendString(b, l.library.SchemaURL)
+		b = appendUvarint(b, uint64(len(l.records)))
+		for i := range l.records {
+			var err error
+			if b, err = appendRecord(b, &l.records[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b, nil
+}
+
+func appendRecord(b []byte, r *record) ([]byte, error) {
+	b = appendString(b, r.descriptor.Name())
+	b = append(b, byte(r.descriptor.InstrumentKind()), byte(r.descriptor.NumberKind()))
+	b = appendString(b, r.descriptor.Description())
+	b = appendString(b, string(r.descriptor.Unit()))
+
+	b = appendUvarint(b, uint64(r.attrs.Len()))
+	for iter := r.attrs.Iter(); iter.Next(); {
+		b = appendAttribute(b, iter.Attribute())
+	}
+
+	b = appendTime(b, r.start)
+	b = appendTime(b, r.end)
+	b = append(b, byte(r.temporality))
+
+	b = append(b, byte(r.kind))
+	switch r.kind {
+	case sumKind:
+		b = appendNumber(b, r.sum.value)
+	case lastValueKind:
+		b = appendNumber(b, r.lastValue.value)
+		b = appendTime(b, r.lastValue.timestamp)
+	case histogramKind:
+		h := &r.histogram
+		b = appendUvarint(b, h.count)
+		b = appendNumber(b, h.sum)
+		b = appendUvarint(b, uint64(len(h.buckets.Boundaries)))
+		for _, boundary := range h.buckets.Boundaries {
+			b = appendUint64(b, math.Float64bits(boundary))
+		}
+		b = appendUvarint(b, uint64(len(h.buckets.Counts)))
+		for _, count := range h.buckets.Counts {
+			b = appendUvarint(b, count)
+		}
+	case exponentialHistogramKind:
+		e := &r.exponential
+		b = appendUvarint(b, e.count)
+		b = appendNumber(b, e.sum)
+		b = appendVarint(b, int64(e.buckets.Scale))
+		b = appendUvarint(b, e.buckets.ZeroCount)
+		b = appendExponentialCounts(b, e.buckets.Positive)
+		b = appendExponentialCounts(b, e.buckets.Negative)
+	case minMaxSumCountKind:
+		m := &r.minMaxSumCount
+		b = appendNumber(b, m.min)
+		b = appendNumber(b, m.max)
+		b = appendNumber(b, m.sum)
+		b = appendUvarint(b, m.count)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAggregation, r.descriptor.Name())
+	}
+	return b, nil
+}
+
+func appendExponentialCounts(b []byte, c aggregation.ExponentialBucketCounts) []byte {
+	b = appendVarint(b, int64(c.Offset))
+	b = appendUvarint(b, uint64(len(c.Counts)))
+	for _, count := range c.Counts {
+		b = appendUvarint(b, count)
+	}
+	return b
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendVarint(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutVarint(buf[:], v)]...)
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+func appendString(b []byte, s string) []byte {
+	b = appendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendNumber(b []byte, n number.Number) []byte {
+	return appendUint64(b, n.AsRaw())
+}
+
+// appendTime appends t as nanoseconds since the Unix epoch, preceded by a
+// byte distinguishing the zero time.
+func appendTime(b []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return append(b, 0)
+	}
+	b = append(b, 1)
+	return appendVarint(b, t.UnixNano())
+}
+
+func appendAttribute(b []byte, kv attribute.KeyValue) []byte {
+	b = appendString(b, string(kv.Key))
+	b = append(b, byte(kv.Value.Type()))
+	switch kv.Value.Type() {
+	case attribute.BOOL:
+		b = appendBool(b, kv.Value.AsBool())
+	case attribute.INT64:
+		b = appendVarint(b, kv.Value.AsInt64())
+	case attribute.FLOAT64:
+		b = appendUint64(b, math.Float64bits(kv.Value.AsFloat64()))
+	case attribute.STRING:
+		b = appendString(b, kv.Value.AsString())
+	case attribute.BOOLSLICE:
+		v := kv.Value.AsBoolSlice()
+		b = appendUvarint(b, uint64(len(v)))
+		for _, e := range v {
+			b = appendBool(b, e)
+		}
+	case attribute.INT64SLICE:
+		v := kv.Value.AsInt64Slice()
+		b = appendUvarint(b, uint64(len(v)))
+		for _, e := range v {
+			b = appendVarint(b, e)
+		}
+	case attribute.FLOAT64SLICE:
+		v := kv.Value.AsFloat64Slice()
+		b = appendUvarint(b, uint64(len(v)))
+		for _, e := range v {
+			b = appendUint64(b, math.Float64bits(e))
+		}
+	case attribute.STRINGSLICE:
+		v := kv.Value.AsStringSlice()
+		b = appendUvarint(b, uint64(len(v)))
+		for _, e := range v {
+			b = appendString(b, e)
+		}
+	}
+	return b
+}
+
+func appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 1)
+	}
+	return append(b, 0)
+}
+
+// UnmarshalBinary replaces the contents of the Snapshot with the decoded
+// binary encoding data, as produced by AppendBinary.  An error wrapping
+// ErrUnknownVersion is returned when data was encoded with an unsupported
+// version, and an error wrapping ErrInvalidEncoding when data is
+// malformed.  The Snapshot is unchanged when an error is returned.
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%w: empty", ErrInvalidEncoding)
+	}
+	if data[0] != Version {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, data[0])
+	}
+	d := decoder{buf: data[1:]}
+
+	var libraries []library
+	for n := d.length(); n > 0 && d.err == nil; n-- {
+		l := library{
+			library: instrumentation.Library{
+				Name:      d.string(),
+				Version:   d.string(),
+				SchemaURL: d.string(),
+			},
+		}
+		for m := d.length(); m > 0 && d.err == nil; m-- {
+			l.records = append(l.records, d.record())
+		}
+		libraries = append(libraries, l)
+	}
+	if d.err == nil && len(d.buf) != 0 {
+		d.fail("%d trailing bytes", len(d.buf))
+	}
+	if d.err != nil {
+		return d.err
+	}
+	s.libraries = libraries
+	return nil
+}
+
+// decoder reads the binary encoding of a Snapshot.  After the first
+// error, all reads return zero values.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) fail(format string, args ...interface{}) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidEncoding}, args...)...)
+	}
+	d.buf = nil
+}
+
+func (d *decoder) byte() byte {
+	if len(d.buf) < 1 {
+		d.fail("truncated")
+		return 0
+	}
+	v := d.buf[0]
+	d.buf = d.buf[1:]
+	return v
+}
+
+func (d *decoder) bool() bool {
+	switch d.byte() {
+	case 0:
+		return false
+	case 1:
+		return true
+	}
+	d.fail("invalid bool")
+	return false
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail("invalid varint")
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.fail("invalid varint")
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) int32() int32 {
+	v := d.varint()
+	if v < math.MinInt32 || v > math.MaxInt32 {
+		d.fail("invalid int32 %d", v)
+		return 0
+	}
+	return int32(v)
+}
+
+func (d *decoder) uint64() uint64 {
+	if len(d.buf) < 8 {
+		d.fail("truncated")
+		return 0
+	}
+	v := binary.LittleEndian.Uint64(d.buf)
+	d.buf = d.buf[8:]
+	return v
+}
+
+func (d *decoder) float64() float64 {
+	return math.Float64frombits(d.uint64())
+}
+
+// length reads the length of a sequence.  Every element is encoded with
+// at least one byte, so a length greater than the remaining input is
+// rejected before anything is allocated.
+func (d *decoder) length() int {
+	v := d.uvarint()
+	if v > uint64(len(d.buf)) {
+		d.fail("length %d exceeds input", v)
+		return 0
+	}
+	return int(v)
+}
+
+func (d *decoder) string() string {
+	n := d.length()
+	v := string(d.buf[:n])
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) time() time.Time {
+	if !d.bool() {
+		return time.Time{}
+	}
+	return time.Unix(0, d.varint())
+}
+
+func (d *decoder) record() record {
+	name := d.string()
+	ikind := sdkapi.InstrumentKind(d.byte())
+	nkind := number.Kind(d.byte())
+	description := d.string()
+	u := unit.Unit(d.string())
+	if ikind < sdkapi.HistogramInstrumentKind || ikind > sdkapi.UpDownCounterObserverInstrumentKind {
+		d.fail("invalid instrument kind %d", ikind)
+	}
+	if nkind != number.Int64Kind && nkind != number.Float64Kind {
+		d.fail("invalid number kind %d", nkind)
+	}
+
+	var kvs []attribute.KeyValue
+	for n := d.length(); n > 0 && d.err == nil; n-- {
+		kvs = append(kvs, d.attribute())
+	}
+
+	r := record{
+		descriptor:  sdkapi.NewDescriptor(name, ikind, nkind, description, u),
+		attrs:       attribute.NewSet(kvs...),
+		start:       d.time(),
+		end:         d.time(),
+		temporality: aggregation.Temporality(d.byte()),
+	}
+	// Zero is the temporality of the records that did not report one.
+	switch r.temporality {
+	case 0, aggregation.CumulativeTemporality, aggregation.DeltaTemporality:
+	default:
+		d.fail("invalid temporality %d", r.temporality)
+	}
+
+	switch r.kind = aggregationKind(d.byte()); r.kind {
+	case sumKind:
+		r.sum = sum{value: number.Number(d.uint64())}
+	case lastValueKind:
+		r.lastValue = lastValue{value: number.Number(d.uint64()), timestamp: d.time()}
+	case histogramKind:
+		h := &r.histogram
+		h.count = d.uvarint()
+		h.sum = number.Number(d.uint64())
+		if n := d.length(); n > 0 {
+			h.buckets.Boundaries = make([]float64, n)
+			for i := range h.buckets.Boundaries {
+				h.buckets.Boundaries[i] = d.float64()
+			}
+		}
+		if n := d.length(); n > 0 {
+			h.buckets.Counts = make([]uint64, n)
+			for i := range h.buckets.Counts {
+				h.buckets.Counts[i] = d.uvarint()
+			}
+		}
+	case exponentialHistogramKind:
+		e := &r.exponential
+		e.count = d.uvarint()
+		e.sum = number.Number(d.uint64())
+		e.buckets.Scale = d.int32()
+		e.buckets.ZeroCount = d.uvarint()
+		e.buckets.Positive = d.exponentialCounts()
+		e.buckets.Negative = d.exponentialCounts()
+	case minMaxSumCountKind:
+		r.minMaxSumCount = minMaxSumCount{
+			min:   number.Number(d.uint64()),
+			max:   number.Number(d.uint64()),
+			sum:   number.Number(d.uint64()),
+			count: d.uvarint(),
+		}
+	default:
+		d.fail("invalid aggregation tag %d", r.kind)
+	}
+	return r
+}
+
+func (d *decoder) exponentialCounts() aggregation.ExponentialBucketCounts {
+	c := aggregation.ExponentialBucketCounts{Offset: d.int32()}
+	if n := d.length(); n > 0 {
+		c.Counts = make([]uint64, n)
+		for i := range c.Counts {
+			c.Counts[i] = d.uvarint()
+		}
+	}
+	return c
+}
+
+func (d *decoder) attribute() attribute.KeyValue {
+	key := attribute.Key(d.string())
+	switch typ := attribute.Type(d.byte()); typ {
+	case attribute.BOOL:
+		return key.Bool(d.bool())
+	case attribute.INT64:
+		return key.Int64(d.varint())
+	case attribute.FLOAT64:
+		return key.Float64(d.float64())
+	case attribute.STRING:
+		return key.String(d.string())
+	case attribute.BOOLSLICE:
+		v := make([]bool, d.length())
+		for i := range v {
+			v[i] = d.bool()
+		}
+		return key.BoolSlice(v)
+	case attribute.INT64SLICE:
+		v := make([]int64, d.length())
+		for i := range v {
+			v[i] = d.varint()
+		}
+		return key.Int64Slice(v)
+	case attribute.FLOAT64SLICE:
+		v := make([]float64, d.length())
+		for i := range v {
+			v[i] = d.float64()
+		}
+		return key.Float64Slice(v)
+	case attribute.STRINGSLICE:
+		v := make([]string, d.length())
+		for i := range v {
+			v[i] = d.string()
+		}
+		return key.StringSlice(v)
+	default:
+		d.fail("invalid attribute type %d", typ)
+		return attribute.KeyValue{}
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snapshot provides a copy of the metric data of an
+// export.InstrumentationLibraryReader that can be encoded in a compact
+// binary form, e.g., to pass the metric data of a child process to its
+// parent.  A decoded Snapshot is itself an
+// export.InstrumentationLibraryReader, and can be passed to an Exporter.
+package snapshot // import "go.opentelemetry.io/otel/sdk/metric/export/snapshot"
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+// ErrUnsupportedAggregation is returned by New when a record has an
+// aggregation that is not an ExponentialHistogram, Histogram,
+// MinMaxSumCount, LastValue or Sum.
+var ErrUnsupportedAggregation = fmt.Errorf("unsupported aggregation")
+
+// Snapshot is a copy of the metric data of an
+// export.InstrumentationLibraryReader.
+type Snapshot struct {
+	libraries []library
+}
+
+type library struct {
+	library instrumentation.Library
+	records []record
+}
+
+// record holds the aggregation of kind inline, so that records can be
+// reused without allocating, see CollectInto.
+type record struct {
+	descriptor     sdkapi.Descriptor
+	attrs          attribute.Set
+	kind           aggregationKind
+	sum            sum
+	lastValue      lastValue
+	histogram      histogram
+	exponential    exponentialHistogram
+	minMaxSumCount minMaxSumCount
+	temporality    aggregation.Temporality
+	start          time.Time
+	end            time.Time
+}
+
+// aggregationKind identifies the aggregation of a record.  The values
+// are part of the binary encoding.
+type aggregationKind byte
+
+const (
+	sumKind aggregationKind = iota + 1
+	lastValueKind
+	histogramKind
+	exponentialHistogramKind
+	minMaxSumCountKind
+)
+
+var _ export.InstrumentationLibraryReader = &Snapshot{}
+
+// New returns a Snapshot of the records of reader, computed using the
+// temporality chosen by tempSelector.  The records must have
+// ExponentialHistogram, Histogram, MinMaxSumCount, LastValue or Sum
+// aggregations; otherwise an error wrapping ErrUnsupportedAggregation is
+// returned.  Exemplars are not copied, see aggregation.Exemplars.
+func New(reader export.InstrumentationLibraryReader, tempSelector aggregation.TemporalitySelector) (*Snapshot, error) {
+	s := &Snapshot{}
+	if err := CollectInto(reader, tempSelector, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// CollectInto is like New, but it replaces the contents of s instead of
+// returning a new Snapshot.  The memory of s is reused where possible,
+// which avoids most allocations when the records of reader do not change
+// much between calls.  The Records passed to an Exporter by a previous
+// ForEach of s must not be used after CollectInto is called.  When an
+// error is returned, s is empty.
+func CollectInto(reader export.InstrumentationLibraryReader, tempSelector aggregation.TemporalitySelector, s *Snapshot) error {
+	libraries := s.libraries[:0]
+	err := reader.ForEach(func(lib instrumentation.Library, r export.Reader) error {
+		libraries = extendLibraries(libraries)
+		l := &libraries[len(libraries)-1]
+		l.library = lib
+		records := l.records[:0]
+		err := r.ForEach(tempSelector, func(rec export.Record) error {
+			records = extendRecords(records)
+			dst := &records[len(records)-1]
+			dst.reset()
+			if err := dst.setAggregation(rec.Descriptor(), rec.Aggregation()); err != nil {
+				return err
+			}
+			dst.descriptor = *rec.Descriptor()
+			dst.attrs = *rec.Attributes()
+			dst.temporality = rec.Temporality()
+			dst.start = rec.StartTime()
+			dst.end = rec.EndTime()
+			return nil
+		})
+		l.records = records
+		return err
+	})
+	if err != nil {
+		libraries = libraries[:0]
+	}
+	// Clear the entries of the previous contents that were not
+	// reused, so that they do not retain memory.
+	for i := len(libraries); i < len(s.libraries); i++ {
+		s.libraries[i].library = instrumentation.Library{}
+		s.libraries[i].records = clearRecords(s.libraries[i].records)
+	}
+	for i := range libraries {
+		clearRecords(libraries[i].records[len(libraries[i].records):cap(libraries[i].records)])
+	}
+	s.libraries = libraries
+	return err
+}
+
+// extendLibraries returns libraries with one more element, reusing its
+// capacity.
+func extendLibraries(libraries []library) []library {
+	if len(libraries) < cap(libraries) {
+		return libraries[:len(libraries)+1]
+	}
+	return append(libraries, library{})
+}
+
+// extendRecords returns records with one more element, reusing its
+// capacity.
+func extendRecords(records []record) []record {
+	if len(records) < cap(records) {
+		return records[:len(records)+1]
+	}
+	return append(records, record{})
+}
+
+// clearRecords resets records and returns them truncated.
+func clearRecords(records []record) []record {
+	for i := range records {
+		records[i].reset()
+	}
+	return records[:0]
+}
+
+// reset clears r, keeping only the memory of its histogram buckets.
+func (r *record) reset() {
+	b := r.histogram.buckets
+	e := r.exponential.buckets
+	*r = record{}
+	r.histogram.buckets = aggregation.Buckets{
+		Boundaries: b.Boundaries[:0],
+		Counts:     b.Counts[:0],
+	}
+	r.exponential.buckets.Positive.Counts = e.Positive.Counts[:0]
+	r.exponential.buckets.Negative.Counts = e.Negative.Counts[:0]
+}
+
+// setAggregation copies agg into r, so that r does not depend on the state
+// of the aggregator that produced it.
+func (r *record) setAggregation(desc *sdkapi.Descriptor, agg aggregation.Aggregation) error {
+	// Test for the strongest interface first, see aggregation.Kind.
+	switch a := agg.(type) {
+	case aggregation.ExponentialHistogram:
+		count, err := a.Count()
+		if err != nil {
+			return err
+		}
+		sum, err := a.Sum()
+		if err != nil {
+			return err
+		}
+		buckets, err := a.ExponentialHistogram()
+		if err != nil {
+			return err
+		}
+		e := &r.exponential
+		r.kind = exponentialHistogramKind
+		e.count = count
+		e.sum = sum
+		e.buckets.Scale = buckets.Scale
+		e.buckets.ZeroCount = buckets.ZeroCount
+		e.buckets.Positive.Offset = buckets.Positive.Offset
+		e.buckets.Positive.Counts = append(e.buckets.Positive.Counts[:0], buckets.Positive.Counts...)
+		e.buckets.Negative.Offset = buckets.Negative.Offset
+		e.buckets.Negative.Counts = append(e.buckets.Negative.Counts[:0], buckets.Negative.Counts...)
+		return nil
+	case aggregation.Histogram:
+		count, err := a.Count()
+		if err != nil {
+			return err
+		}
+		sum, err := a.Sum()
+		if err != nil {
+			return err
+		}
+		buckets, err := a.Histogram()
+		if err != nil {
+			return err
+		}
+		r.kind = histogramKind
+		r.histogram.count = count
+		r.histogram.sum = sum
+		r.histogram.buckets.Boundaries = append(r.histogram.buckets.Boundaries[:0], buckets.Boundaries...)
+		r.histogram.buckets.Counts = append(r.histogram.buckets.Counts[:0], buckets.Counts...)
+		return nil
+	case aggregation.MinMaxSumCount:
+		min, err := a.Min()
+		if err != nil {
+			return err
+		}
+		max, err := a.Max()
+		if err != nil {
+			return err
+		}
+		sum, err := a.Sum()
+		if err != nil {
+			return err
+		}
+		count, err := a.Count()
+		if err != nil {
+			return err
+		}
+		r.kind = minMaxSumCountKind
+		r.minMaxSumCount = minMaxSumCount{min: min, max: max, sum: sum, count: count}
+		return nil
+	case aggregation.LastValue:
+		value, timestamp, err := a.LastValue()
+		if err != nil {
+			return err
+		}
+		r.kind = lastValueKind
+		r.lastValue = lastValue{value: value, timestamp: timestamp}
+		return nil
+	case aggregation.Sum:
+		value, err := a.Sum()
+		if err != nil {
+			return err
+		}
+		r.kind = sumKind
+		r.sum = sum{value: value}
+		return nil
+	}
+	return fmt.Errorf("%w: %s: %s", ErrUnsupportedAggregation, desc.Name(), agg.Kind())
+}
+
+// aggregation returns the aggregation of r.
+func (r *record) aggregation() aggregation.Aggregation {
+	switch r.kind {
+	case sumKind:
+		return &r.sum
+	case lastValueKind:
+		return &r.lastValue
+	case histogramKind:
+		return &r.histogram
+	case exponentialHistogramKind:
+		return &r.exponential
+	case minMaxSumCountKind:
+		return &r.minMaxSumCount
+	}
+	return nil
+}
+
+// ForEach calls readerFunc once per instrumentation library of the
+// Snapshot.  The temporality of the records is the one chosen when the
+// Snapshot was taken, the TemporalitySelector passed to the ForEach of
+// the Reader is ignored.
+func (s *Snapshot) ForEach(readerFunc func(instrumentation.Library, export.Reader) error) error {
+	for i := range s.libraries {
+		if err := readerFunc(s.libraries[i].library, &reader{library: &s.libraries[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reader is the export.Reader of a library.  A Snapshot is immutable, the
+// lock only satisfies the interface.
+type reader struct {
+	sync.RWMutex
+	library *library
+}
+
+var _ export.Reader = &reader{}
+
+func (r *reader) ForEach(_ aggregation.TemporalitySelector, recordFunc func(export.Record) error) error {
+	for i := range r.library.records {
+		rec := &r.library.records[i]
+		if err := recordFunc(export.NewRecord(&rec.descriptor, &rec.attrs, rec.aggregation(), rec.start, rec.end).WithTemporality(rec.temporality)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type sum struct {
+	value number.Number
+}
+
+var _ aggregation.Sum = &sum{}
+
+func (*sum) Kind() aggregation.Kind        { return aggregation.SumKind }
+func (s *sum) Sum() (number.Number, error) { return s.value, nil }
+
+type lastValue struct {
+	value     number.Number
+	timestamp time.Time
+}
+
+var _ aggregation.LastValue = &lastValue{}
+
+func (*lastValue) Kind() aggregation.Kind { return aggregation.LastValueKind }
+func (l *lastValue) LastValue() (number.Number, time.Time, error) {
+	return l.value, l.timestamp, nil
+}
+
+type histogram struct {
+	count   uint64
+	sum     number.Number
+	buckets aggregation.Buckets
+}
+
+var _ aggregation.Histogram = &histogram{}
+
+func (*histogram) Kind() aggregation.Kind                    { return aggregation.HistogramKind }
+func (h *histogram) Count() (uint64, error)                  { return h.count, nil }
+func (h *histogram) Sum() (number.Number, error)             { return h.sum, nil }
+func (h *histogram) Histogram() (aggregation.Buckets, error) { return h.buckets, nil }
+
+type minMaxSumCount struct {
+	min, max, sum number.Number
+	count         uint64
+}
+
+var _ aggregation.MinMaxSumCount = &minMaxSumCount{}
+
+func (*minMaxSumCount) Kind() aggregation.Kind        { return aggregation.MinMaxSumCountKind }
+func (m *minMaxSumCount) Min() (number.Number, error) { return m.min, nil }
+func (m *minMaxSumCount) Max() (number.Number, error) { return m.max, nil }
+func (m *minMaxSumCount) Sum() (number.Number, error) { return m.sum, nil }
+func (m *minMaxSumCount) Count() (uint64, error)      { return m.count, nil }
+
+type exponentialHistogram struct {
+	count   uint64
+	sum     number.Number
+	buckets aggregation.ExponentialBuckets
+}
+
+var _ aggregation.ExponentialHistogram = &exponentialHistogram{}
+
+func (*exponentialHistogram) Kind() aggregation.Kind        { return aggregation.ExponentialHistogramKind }
+func (e *exponentialHistogram) Count() (uint64, error)      { return e.count, nil }
+func (e *exponentialHistogram) Sum() (number.Number, error) { return e.sum, nil }
+func (e *exponentialHistogram) ExponentialHistogram() (aggregation.ExponentialBuckets, error) {
+	return e.buckets, nil
+}
@@ -0,0 +1,318 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/export/snapshot"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
+)
+
+// dump formats every field of the records of reader.
+func dump(t *testing.T, reader export.InstrumentationLibraryReader) []string {
+	var out []string
+	require.NoError(t, reader.ForEach(func(lib instrumentation.Library, r export.Reader) error {
+		return r.ForEach(aggregation.CumulativeTemporalitySelector(), func(rec export.Record) error {
+			desc := rec.Descriptor()
+			line := fmt.Sprintf("%v %s %v %v %q %q %s %d %d %v %s",
+				lib, desc.Name(), desc.InstrumentKind(), desc.NumberKind(), desc.Description(), desc.Unit(),
+				rec.Attributes().Encoded(attribute.DefaultEncoder()),
+				rec.StartTime().UnixNano(), rec.EndTime().UnixNano(),
+				rec.Temporality(), rec.Aggregation().Kind(),
+			)
+			switch agg := rec.Aggregation().(type) {
+			case aggregation.ExponentialHistogram:
+				count, _ := agg.Count()
+				sum, _ := agg.Sum()
+				buckets, _ := agg.ExponentialHistogram()
+				line += fmt.Sprintf(" %d %s %+v", count, sum.Emit(desc.NumberKind()), buckets)
+			case aggregation.MinMaxSumCount:
+				min, _ := agg.Min()
+				max, _ := agg.Max()
+				sum, _ := agg.Sum()
+				count, _ := agg.Count()
+				line += fmt.Sprint(" ", min.Emit(desc.NumberKind()), " ", max.Emit(desc.NumberKind()), " ", sum.Emit(desc.NumberKind()), " ", count)
+			case aggregation.Histogram:
+				count, _ := agg.Count()
+				sum, _ := agg.Sum()
+				buckets, _ := agg.Histogram()
+				line += fmt.Sprint(" ", count, " ", sum.Emit(desc.NumberKind()), " ", buckets)
+			case aggregation.LastValue:
+				value, timestamp, _ := agg.LastValue()
+				line += fmt.Sprint(" ", value.Emit(desc.NumberKind()), " ", timestamp.UnixNano())
+			case aggregation.Sum:
+				sum, _ := agg.Sum()
+				line += fmt.Sprint(" ", sum.Emit(desc.NumberKind()))
+			}
+			out = append(out, line)
+			return nil
+		})
+	}))
+	return out
+}
+
+func newSnapshot(t *testing.T) *snapshot.Snapshot {
+	ctx := context.Background()
+	cont := controller.New(
+		processor.NewFactory(
+			simple.NewWithHistogramDistribution(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+	)
+
+	meter := cont.Meter("first", metric.WithInstrumentationVersion("v1"))
+	counter, err := meter.SyncInt64().Counter("counter", instrument.WithDescription("A counter"), instrument.WithUnit("By"))
+	require.NoError(t, err)
+	hist, err := meter.SyncFloat64().Histogram("histogram")
+	require.NoError(t, err)
+
+	gauge, err := cont.Meter("second").AsyncFloat64().Gauge("gauge")
+	require.NoError(t, err)
+	_, err = cont.Meter("second").RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		gauge.Observe(ctx, -1.5, attribute.StringSlice("list", []string{"a", "b"}))
+		return nil
+	})
+	require.NoError(t, err)
+
+	counter.Add(ctx, 3, attribute.String("A", "B"), attribute.Bool("ok", true))
+	counter.Add(ctx, 4, attribute.Int64Slice("ids", []int64{1, -2}), attribute.Float64("f", 0.25))
+	hist.Record(ctx, 1)
+	hist.Record(ctx, 100, attribute.BoolSlice("flags", []bool{true, false}), attribute.Float64Slice("fs", []float64{1.5}))
+
+	require.NoError(t, cont.Collect(ctx))
+
+	s, err := snapshot.New(cont, aggregation.CumulativeTemporalitySelector())
+	require.NoError(t, err)
+	require.ElementsMatch(t, dump(t, cont), dump(t, s))
+	return s
+}
+
+// roundTrip returns the decoded binary encoding of s.
+func roundTrip(t *testing.T, s *snapshot.Snapshot) *snapshot.Snapshot {
+	data, err := s.MarshalBinary()
+	require.NoError(t, err)
+	var decoded snapshot.Snapshot
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	return &decoded
+}
+
+func TestExponentialHistogram(t *testing.T) {
+	ctx := context.Background()
+	cont := controller.New(
+		processor.NewFactory(
+			simple.NewWithExponentialHistogramDistribution(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+	)
+	hist, err := cont.Meter("test").SyncFloat64().Histogram("histogram")
+	require.NoError(t, err)
+	for _, v := range []float64{-4, 0, 1, 2, 2, 1000} {
+		hist.Record(ctx, v)
+	}
+	require.NoError(t, cont.Collect(ctx))
+
+	s, err := snapshot.New(cont, aggregation.CumulativeTemporalitySelector())
+	require.NoError(t, err)
+	expected := dump(t, cont)
+	require.Len(t, expected, 1)
+	require.Contains(t, expected[0], "ExponentialHistogram 6 1001")
+	require.Equal(t, expected, dump(t, s))
+	require.Equal(t, expected, dump(t, roundTrip(t, s)))
+}
+
+func TestMinMaxSumCount(t *testing.T) {
+	ctx := context.Background()
+	cont := controller.New(
+		processor.NewFactory(
+			simple.NewWithMinMaxSumCountDistribution(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+	)
+	hist, err := cont.Meter("test").SyncInt64().Histogram("histogram")
+	require.NoError(t, err)
+	for _, v := range []int64{5, -3, 10} {
+		hist.Record(ctx, v)
+	}
+	require.NoError(t, cont.Collect(ctx))
+
+	s, err := snapshot.New(cont, aggregation.CumulativeTemporalitySelector())
+	require.NoError(t, err)
+	expected := dump(t, cont)
+	require.Len(t, expected, 1)
+	require.Contains(t, expected[0], "MinMaxSumCount -3 10 12 3")
+	require.Equal(t, expected, dump(t, s))
+	require.Equal(t, expected, dump(t, roundTrip(t, s)))
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	s := newSnapshot(t)
+
+	data, err := s.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, byte(snapshot.Version), data[0])
+
+	var decoded snapshot.Snapshot
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.Equal(t, dump(t, s), dump(t, &decoded))
+	require.Len(t, dump(t, &decoded), 5)
+
+	// AppendBinary appends to its argument.
+	appended, err := s.AppendBinary([]byte("prefix"))
+	require.NoError(t, err)
+	require.Equal(t, append([]byte("prefix"), data...), appended)
+}
+
+func TestBinaryUnknownVersion(t *testing.T) {
+	data, err := newSnapshot(t).MarshalBinary()
+	require.NoError(t, err)
+	var decoded snapshot.Snapshot
+	for _, version := range []byte{1, snapshot.Version + 1} {
+		data[0] = version
+		require.ErrorIs(t, decoded.UnmarshalBinary(data), snapshot.ErrUnknownVersion, "version %d", version)
+	}
+}
+
+func TestBinaryInvalid(t *testing.T) {
+	data, err := newSnapshot(t).MarshalBinary()
+	require.NoError(t, err)
+
+	// Every truncation is rejected and leaves the Snapshot unchanged.
+	for i := 0; i < len(data); i++ {
+		var decoded snapshot.Snapshot
+		require.ErrorIs(t, decoded.UnmarshalBinary(data[:i]), snapshot.ErrInvalidEncoding, "length %d", i)
+		require.Empty(t, dump(t, &decoded))
+	}
+	var decoded snapshot.Snapshot
+	require.ErrorIs(t, decoded.UnmarshalBinary(append(data, 0)), snapshot.ErrInvalidEncoding)
+
+	// A huge length does not allocate.
+	require.ErrorIs(t, decoded.UnmarshalBinary([]byte{snapshot.Version, 0xff, 0xff, 0xff, 0xff, 0x0f}), snapshot.ErrInvalidEncoding)
+}
+
+// encodeSum returns the encoding of a single Int64 sum record with the
+// instrument kind ikind and the temporality temporality.
+func encodeSum(ikind, temporality byte) []byte {
+	return []byte{
+		snapshot.Version,
+		1,       // libraries
+		0, 0, 0, // name, version and schema URL
+		1,                // records
+		1, 'c', ikind, 0, // name, instrument and number kinds
+		0, 0, // description and unit
+		0,    // attributes
+		0, 0, // start and end times
+		temporality,
+		1, // sum
+		5, 0, 0, 0, 0, 0, 0, 0,
+	}
+}
+
+func TestBinaryInvalidKinds(t *testing.T) {
+	var decoded snapshot.Snapshot
+	require.NoError(t, decoded.UnmarshalBinary(encodeSum(byte(sdkapi.CounterInstrumentKind), byte(aggregation.DeltaTemporality))))
+	require.Len(t, dump(t, &decoded), 1)
+	require.NoError(t, decoded.UnmarshalBinary(encodeSum(byte(sdkapi.CounterInstrumentKind), 0)))
+
+	for _, ikind := range []byte{byte(sdkapi.UpDownCounterObserverInstrumentKind) + 1, 0xff} {
+		require.ErrorIs(t, decoded.UnmarshalBinary(encodeSum(ikind, byte(aggregation.CumulativeTemporality))), snapshot.ErrInvalidEncoding, "instrument kind %d", ikind)
+	}
+	for _, temporality := range []byte{3, 0xff} {
+		require.ErrorIs(t, decoded.UnmarshalBinary(encodeSum(byte(sdkapi.CounterInstrumentKind), temporality)), snapshot.ErrInvalidEncoding, "temporality %d", temporality)
+	}
+}
+
+// newController returns a controller that has collected n series of a
+// counter and of a histogram.
+func newController(tb testing.TB, n int) *controller.Controller {
+	ctx := context.Background()
+	cont := controller.New(
+		processor.NewFactory(
+			simple.NewWithHistogramDistribution(),
+			aggregation.CumulativeTemporalitySelector(),
+		),
+		controller.WithCollectPeriod(0),
+	)
+	meter := cont.Meter("test")
+	counter, err := meter.SyncInt64().Counter("counter")
+	require.NoError(tb, err)
+	hist, err := meter.SyncFloat64().Histogram("histogram")
+	require.NoError(tb, err)
+	for i := 0; i < n; i++ {
+		counter.Add(ctx, 1, attribute.Int("i", i))
+		hist.Record(ctx, float64(i), attribute.Int("i", i))
+	}
+	require.NoError(tb, cont.Collect(ctx))
+	return cont
+}
+
+func TestCollectInto(t *testing.T) {
+	s := newSnapshot(t)
+
+	// Fewer libraries and records than before: nothing stale remains.
+	cont := newController(t, 1)
+	require.NoError(t, snapshot.CollectInto(cont, aggregation.CumulativeTemporalitySelector(), s))
+	require.ElementsMatch(t, dump(t, cont), dump(t, s))
+	require.Len(t, dump(t, s), 2)
+
+	// More records than before.
+	cont = newController(t, 10)
+	require.NoError(t, snapshot.CollectInto(cont, aggregation.CumulativeTemporalitySelector(), s))
+	require.ElementsMatch(t, dump(t, cont), dump(t, s))
+	require.Len(t, dump(t, s), 20)
+
+	// An empty reader empties the Snapshot.
+	require.NoError(t, snapshot.CollectInto(newController(t, 0), aggregation.CumulativeTemporalitySelector(), s))
+	require.Empty(t, dump(t, s))
+}
+
+func BenchmarkNew(b *testing.B) {
+	cont := newController(b, 100)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := snapshot.New(cont, aggregation.CumulativeTemporalitySelector()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCollectInto(b *testing.B) {
+	cont := newController(b, 100)
+	var s snapshot.Snapshot
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := snapshot.CollectInto(cont, aggregation.CumulativeTemporalitySelector(), &s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package metric_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/instrument"
+	metricsdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/processor/processortest"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+// fuzzAttributeSets decodes the fuzzer input into a list of attribute
+// sets.  Attribute sets are separated by newlines, attributes by ';',
+// and keys from values by '='.  Keys are reused freely, so duplicate
+// keys, empty values and arbitrary unicode are all produced.
+func fuzzAttributeSets(input string) [][]attribute.KeyValue {
+	const maxSets = 1000
+
+	var sets [][]attribute.KeyValue
+	for _, line := range strings.Split(input, "\n") {
+		if len(sets) == maxSets {
+			break
+		}
+		var kvs []attribute.KeyValue
+		for _, field := range strings.Split(line, ";") {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) == 1 {
+				kvs = append(kvs, attribute.Bool(kv[0], true))
+				continue
+			}
+			kvs = append(kvs, attribute.String(kv[0], kv[1]))
+		}
+		sets = append(sets, kvs)
+	}
+	return sets
+}
+
+// recordProcessor sums the values of each instrument by record.  The
+// records are told apart by their attribute.Distinct, since the encoded
+// attributes of distinct sets of invalid UTF-8 may be equal.
+type recordProcessor struct {
+	export.AggregatorSelector
+	totals map[string]float64
+	sets   map[string]map[attribute.Distinct]struct{}
+}
+
+func newRecordProcessor() *recordProcessor {
+	p := &recordProcessor{AggregatorSelector: processortest.AggregatorSelector()}
+	p.reset()
+	return p
+}
+
+func (p *recordProcessor) reset() {
+	p.totals = map[string]float64{}
+	p.sets = map[string]map[attribute.Distinct]struct{}{}
+}
+
+func (p *recordProcessor) Process(accum export.Accumulation) error {
+	desc := accum.Descriptor()
+	sum, err := accum.Aggregator().Aggregation().(aggregation.Sum).Sum()
+	if err != nil {
+		return err
+	}
+	if v := sum.CoerceToFloat64(desc.NumberKind()); v < 0 {
+		return fmt.Errorf("negative sum %v of %s", v, desc.Name())
+	} else if v > 0 {
+		p.totals[desc.Name()] += v
+	}
+	if p.sets[desc.Name()] == nil {
+		p.sets[desc.Name()] = map[attribute.Distinct]struct{}{}
+	}
+	p.sets[desc.Name()][accum.Attributes().Equivalent()] = struct{}{}
+	return nil
+}
+
+func FuzzCollectAttributeSets(f *testing.F) {
+	f.Add("", int64(1))
+	f.Add("A=B", int64(0))
+	f.Add("A=B;A=C;A=", int64(10))
+	f.Add("A=B\nA=B\nB=A", int64(-3))
+	f.Add("ключ=значение;キー=値;🔑=🚪", int64(7))
+	f.Add(strings.Repeat("K=V;", 100), int64(1<<40))
+	f.Add(strings.Repeat("a=b\nc=d\n", 600), int64(2))
+	f.Add("\xba\xbb\xb8\x83\xcc\n\xee\xee\xee\xee\xf0", int64(-189))
+
+	f.Fuzz(func(t *testing.T, input string, value int64) {
+		ctx := context.Background()
+		testHandler.Reset()
+		processor := newRecordProcessor()
+		sdk := metricsdk.NewAccumulator(processor)
+		meter := sdkapi.WrapMeterImpl(sdk)
+
+		// Monotonic instruments only accept non-negative input.
+		if value < 0 {
+			value = -value
+		}
+		value %= 1 << 20
+
+		sets := fuzzAttributeSets(input)
+		distinct := map[attribute.Distinct]struct{}{}
+		for _, kvs := range sets {
+			set := attribute.NewSet(kvs...)
+			distinct[set.Equivalent()] = struct{}{}
+		}
+
+		counter, err := meter.SyncInt64().Counter("sync.sum")
+		require.NoError(t, err)
+		observer, err := meter.AsyncInt64().Counter("async.sum")
+		require.NoError(t, err)
+		_, err = meter.RegisterCallback(
+			[]instrument.Asynchronous{observer},
+			func(ctx context.Context) error {
+				for _, kvs := range sets {
+					observer.Observe(ctx, value, kvs...)
+				}
+				return nil
+			},
+		)
+		require.NoError(t, err)
+
+		for _, kvs := range sets {
+			counter.Add(ctx, value, kvs...)
+		}
+
+		for round := 0; round < 2; round++ {
+			processor.reset()
+			if _, err := sdk.Collect(ctx); err != nil {
+				t.Fatal(err)
+			}
+			require.NoError(t, testHandler.Flush())
+
+			for name := range processor.totals {
+				require.Contains(t, []string{"sync.sum", "async.sum"}, name)
+			}
+			syncTotal, asyncTotal := processor.totals["sync.sum"], processor.totals["async.sum"]
+
+			// Every synchronous measurement is counted once,
+			// in the first round only.
+			if round == 0 {
+				require.Equal(t, float64(value)*float64(len(sets)), syncTotal)
+			} else {
+				require.Equal(t, 0.0, syncTotal)
+			}
+			// Observations repeat every round, and the last
+			// observation of each attribute set wins.
+			require.Equal(t, float64(value)*float64(len(distinct)), asyncTotal)
+			require.Len(t, processor.sets["async.sum"], len(distinct))
+		}
+	})
+}
@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+func TestNewInstrumentShards(t *testing.T) {
+	m := NewAccumulator(nil,
+		WithInstrumentAlias("counter.sum", "alias.sum"),
+		WithInstrumentAlias("broken.sum", "alias.sum"),
+		WithInstrumentAlias("broken.sum", "invalid alias"),
+	)
+
+	_, err := m.NewSyncInstrument(sdkapi.NewDescriptor("counter.sum", sdkapi.CounterInstrumentKind, number.Int64Kind, "", ""))
+	require.NoError(t, err)
+	require.Len(t, m.shards, 2)
+
+	// The instrument and the aliases created before the failing one
+	// are not collected.
+	_, err = m.NewSyncInstrument(sdkapi.NewDescriptor("broken.sum", sdkapi.CounterInstrumentKind, number.Int64Kind, "", ""))
+	require.ErrorIs(t, err, ErrInvalidInstrumentName)
+	require.Len(t, m.shards, 2)
+}
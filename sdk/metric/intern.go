@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric // import "go.opentelemetry.io/otel/sdk/metric"
+
+import (
+	"math"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// The FNV-1a parameters of hashAttributes.
+	offset64 = 14695981039346656037
+	prime64  = 1099511628211
+)
+
+type (
+	// internCache is a fixed-size cache of the attribute sets of
+	// recent measurements, see WithAttributeInterning.  Each slot
+	// holds the *internedSet of the last attributes whose hash
+	// selects it, so that the cache is bounded and its lookups do
+	// not lock.
+	internCache struct {
+		slots []atomic.Value
+	}
+
+	// internedSet is an attribute set and the attributes, in the
+	// order they were passed, it was built from.
+	internedSet struct {
+		kvs []attribute.KeyValue
+		set attribute.Set
+	}
+)
+
+func newInternCache(size int) *internCache {
+	return &internCache{
+		slots: make([]atomic.Value, size),
+	}
+}
+
+// lookup returns the attribute set of kvs, if it is cached, and the hash
+// of kvs to store it with otherwise.  The cached attributes are compared
+// with kvs, so that a hash collision never returns the set of other
+// attributes.
+func (c *internCache) lookup(kvs []attribute.KeyValue) (attribute.Set, uint64, bool) {
+	hash := hashAttributes(kvs)
+	if is, ok := c.slots[hash%uint64(len(c.slots))].Load().(*internedSet); ok && equalAttributes(is.kvs, kvs) {
+		return is.set, hash, true
+	}
+	return attribute.Set{}, hash, false
+}
+
+// store caches the attribute set of kvs, whose hash is hash, replacing
+// the set in its slot.  kvs must not be modified afterwards.
+func (c *internCache) store(hash uint64, kvs []attribute.KeyValue, set attribute.Set) {
+	c.slots[hash%uint64(len(c.slots))].Store(&internedSet{
+		kvs: kvs,
+		set: set,
+	})
+}
+
+// hashAttributes returns the FNV-1a hash of kvs, in order.  The elements
+// of slice values are not hashed, only their type.
+func hashAttributes(kvs []attribute.KeyValue) uint64 {
+	h := uint64(offset64)
+	for _, kv := range kvs {
+		h = hashString(h, string(kv.Key))
+		h = (h ^ uint64(kv.Value.Type())) * prime64
+		switch kv.Value.Type() {
+		case attribute.BOOL:
+			if kv.Value.AsBool() {
+				h = (h ^ 1) * prime64
+			}
+		case attribute.INT64:
+			h = hashUint64(h, uint64(kv.Value.AsInt64()))
+		case attribute.FLOAT64:
+			h = hashUint64(h, math.Float64bits(kv.Value.AsFloat64()))
+		case attribute.STRING:
+			h = hashString(h, kv.Value.AsString())
+		}
+	}
+	return h
+}
+
+func hashString(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h = (h ^ uint64(s[i])) * prime64
+	}
+	// Separate the string from the next one.
+	return (h ^ 0xff) * prime64
+}
+
+func hashUint64(h, v uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h = (h ^ (v & 0xff)) * prime64
+		v >>= 8
+	}
+	return h
+}
+
+// equalAttributes returns whether a and b hold the same attributes in
+// the same order.
+func equalAttributes(a, b []attribute.KeyValue) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrictest // import "go.opentelemetry.io/otel/sdk/metric/metrictest"
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+)
+
+// AssertEqual reports a test error holding the Diff of expected and
+// actual, and returns false, when they differ.
+func AssertEqual(t testing.TB, expected, actual []ScopeMetrics) bool {
+	t.Helper()
+	if diff := Diff(expected, actual); diff != "" {
+		t.Errorf("collected metrics differ (-expected +actual):\n%s", diff)
+		return false
+	}
+	return true
+}
+
+// Diff compares the metrics collected by a Reader, and returns their
+// differences, or the empty string when they are equal.  The start and
+// end times of the points are ignored, as are the orders of the scopes,
+// of their metrics and of their points, which are not deterministic.
+// Each metric and each point is compared as a line of text, and the
+// lines of expected that actual lacks are returned prefixed with "-",
+// followed by the lines of actual for the same metric or point, prefixed
+// with "+".  The values compared depend on the kind of aggregation of
+// the metric: the sum of a sum, the last value of a last value, and the
+// sum, count and buckets of a histogram.
+func Diff(expected, actual []ScopeMetrics) string {
+	exp := snapshotLines(expected)
+	act := snapshotLines(actual)
+
+	keys := make([]string, 0, len(exp))
+	for key := range exp {
+		keys = append(keys, key)
+	}
+	for key := range act {
+		if _, ok := exp[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var diff strings.Builder
+	for _, key := range keys {
+		if e, a := exp[key], act[key]; e != a {
+			writePrefixed(&diff, "- ", e)
+			writePrefixed(&diff, "+ ", a)
+		}
+	}
+	return diff.String()
+}
+
+// writePrefixed writes each of lines prefixed with prefix.
+func writePrefixed(b *strings.Builder, prefix, lines string) {
+	for _, line := range strings.SplitAfter(lines, "\n") {
+		if line != "" {
+			b.WriteString(prefix + line)
+		}
+	}
+}
+
+// snapshotLines returns the lines of the metrics and points of scopes by
+// the scope and name of their metric, and the attributes of the points,
+// see Diff.  The lines of the points of the same attributes, which a
+// Reader does not collect, are joined.
+func snapshotLines(scopes []ScopeMetrics) map[string]string {
+	lines := map[string]string{}
+	add := func(key, values string) {
+		lines[key] += key + values + "\n"
+	}
+	for _, sm := range scopes {
+		scope := scopeName(sm.Scope)
+		for _, m := range sm.Metrics {
+			metric := scope + " " + m.Name
+			add(metric, fmt.Sprintf(": description=%q unit=%q %s %s %s %s",
+				m.Description, m.Unit, m.InstrumentKind, m.NumberKind, m.AggregationKind, m.Temporality))
+			for _, p := range m.Points {
+				add(metric+"{"+p.Attributes.Encoded(attribute.DefaultEncoder())+"}", " "+pointValues(m, p))
+			}
+		}
+	}
+	return lines
+}
+
+func scopeName(s Scope) string {
+	name := s.InstrumentationName
+	if s.InstrumentationVersion != "" {
+		name += "@" + s.InstrumentationVersion
+	}
+	if s.SchemaURL != "" {
+		name += "(" + s.SchemaURL + ")"
+	}
+	return name
+}
+
+// pointValues formats the values of p that the aggregation of m sets.
+func pointValues(m Metrics, p Point) string {
+	sum := "sum=" + formatNumber(p.Sum, m.NumberKind)
+	count := "count=" + strconv.FormatUint(p.Count, 10)
+	lastValue := "lastvalue=" + formatNumber(p.LastValue, m.NumberKind)
+	buckets := fmt.Sprintf("boundaries=%v counts=%v", p.Histogram.Boundaries, p.Histogram.Counts)
+
+	switch m.AggregationKind {
+	case aggregation.SumKind:
+		return sum
+	case aggregation.LastValueKind:
+		return lastValue
+	case aggregation.HistogramKind:
+		return strings.Join([]string{sum, count, buckets}, " ")
+	}
+	return strings.Join([]string{sum, count, lastValue, buckets}, " ")
+}
+
+// formatNumber formats n exactly, unlike Number.Emit.
+func formatNumber(n number.Number, kind number.Kind) string {
+	if kind == number.Float64Kind {
+		return strconv.FormatFloat(n.AsFloat64(), 'g', -1, 64)
+	}
+	return strconv.FormatInt(n.AsInt64(), 10)
+}
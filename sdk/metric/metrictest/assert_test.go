@@ -0,0 +1,212 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrictest_test // import "go.opentelemetry.io/otel/sdk/metric/metrictest"
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/metrictest"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+const assertScope = "go.opentelemetry.io/otel/sdk/metric/metrictest/assert_test"
+
+// collectAll collects one sum, one last value and one histogram.
+func collectAll(t *testing.T) []metrictest.ScopeMetrics {
+	ctx := context.Background()
+	mp, reader := metrictest.NewTestReader()
+	meter := mp.Meter(assertScope)
+
+	requests, err := meter.SyncInt64().Counter("requests")
+	require.NoError(t, err)
+	temperature, err := meter.AsyncFloat64().Gauge("temperature")
+	require.NoError(t, err)
+	latency, err := meter.SyncFloat64().Histogram("latency")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{temperature}, func(ctx context.Context) error {
+		temperature.Observe(ctx, 21.5, attribute.String("room", "kitchen"))
+		return nil
+	})
+	require.NoError(t, err)
+
+	requests.Add(ctx, 2, attribute.String("method", "GET"))
+	requests.Add(ctx, 1, attribute.String("method", "POST"))
+	latency.Record(ctx, 3)
+	latency.Record(ctx, 7)
+
+	scopes, err := reader.Collect(ctx)
+	require.NoError(t, err)
+	return scopes
+}
+
+// expectedAll is the result of collectAll, without times and in another
+// order.
+func expectedAll() []metrictest.ScopeMetrics {
+	boundaries := []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	counts := make([]uint64, len(boundaries)+1)
+	counts[9], counts[10] = 1, 1
+	return []metrictest.ScopeMetrics{{
+		Scope: metrictest.Scope{InstrumentationName: assertScope},
+		Metrics: []metrictest.Metrics{
+			{
+				Name:            "temperature",
+				InstrumentKind:  sdkapi.GaugeObserverInstrumentKind,
+				NumberKind:      number.Float64Kind,
+				AggregationKind: aggregation.LastValueKind,
+				Temporality:     aggregation.CumulativeTemporality,
+				Points: []metrictest.Point{{
+					Attributes: attribute.NewSet(attribute.String("room", "kitchen")),
+					LastValue:  number.NewFloat64Number(21.5),
+				}},
+			},
+			{
+				Name:            "requests",
+				InstrumentKind:  sdkapi.CounterInstrumentKind,
+				NumberKind:      number.Int64Kind,
+				AggregationKind: aggregation.SumKind,
+				Temporality:     aggregation.CumulativeTemporality,
+				Points: []metrictest.Point{
+					{
+						Attributes: attribute.NewSet(attribute.String("method", "POST")),
+						Sum:        number.NewInt64Number(1),
+					},
+					{
+						Attributes: attribute.NewSet(attribute.String("method", "GET")),
+						Sum:        number.NewInt64Number(2),
+					},
+				},
+			},
+			{
+				Name:            "latency",
+				InstrumentKind:  sdkapi.HistogramInstrumentKind,
+				NumberKind:      number.Float64Kind,
+				AggregationKind: aggregation.HistogramKind,
+				Temporality:     aggregation.CumulativeTemporality,
+				Points: []metrictest.Point{{
+					Attributes: *attribute.EmptySet(),
+					Sum:        number.NewFloat64Number(10),
+					Count:      2,
+					Histogram: aggregation.Buckets{
+						Boundaries: boundaries,
+						Counts:     counts,
+					},
+				}},
+			},
+		},
+	}}
+}
+
+// recordingT records the errors of a test.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertEqual(t *testing.T) {
+	actual := collectAll(t)
+	require.True(t, metrictest.AssertEqual(t, expectedAll(), actual))
+	require.Empty(t, metrictest.Diff(expectedAll(), actual))
+	require.Empty(t, metrictest.Diff(nil, nil))
+
+	rt := &recordingT{TB: t}
+	expected := expectedAll()
+	expected[0].Metrics[1].Points[0].Sum = number.NewInt64Number(3)
+	require.False(t, metrictest.AssertEqual(rt, expected, actual))
+	require.Equal(t, []string{
+		"collected metrics differ (-expected +actual):\n" +
+			"- " + assertScope + " requests{method=POST} sum=3\n" +
+			"+ " + assertScope + " requests{method=POST} sum=1\n",
+	}, rt.errors)
+}
+
+func TestDiff(t *testing.T) {
+	actual := collectAll(t)
+	for _, tc := range []struct {
+		name   string
+		modify func([]metrictest.ScopeMetrics)
+		diff   string
+	}{
+		{
+			name: "sum",
+			modify: func(s []metrictest.ScopeMetrics) {
+				s[0].Metrics[1].Points[1].Sum = number.NewInt64Number(5)
+			},
+			diff: "- " + assertScope + " requests{method=GET} sum=5\n" +
+				"+ " + assertScope + " requests{method=GET} sum=2\n",
+		},
+		{
+			name: "sum attributes",
+			modify: func(s []metrictest.ScopeMetrics) {
+				s[0].Metrics[1].Points[1].Attributes = attribute.NewSet(attribute.String("method", "PUT"))
+			},
+			diff: "+ " + assertScope + " requests{method=GET} sum=2\n" +
+				"- " + assertScope + " requests{method=PUT} sum=2\n",
+		},
+		{
+			name: "last value",
+			modify: func(s []metrictest.ScopeMetrics) {
+				s[0].Metrics[0].Points[0].LastValue = number.NewFloat64Number(19.25)
+			},
+			diff: "- " + assertScope + " temperature{room=kitchen} lastvalue=19.25\n" +
+				"+ " + assertScope + " temperature{room=kitchen} lastvalue=21.5\n",
+		},
+		{
+			name: "missing point",
+			modify: func(s []metrictest.ScopeMetrics) {
+				s[0].Metrics[0].Points = nil
+			},
+			diff: "+ " + assertScope + " temperature{room=kitchen} lastvalue=21.5\n",
+		},
+		{
+			name: "histogram",
+			modify: func(s []metrictest.ScopeMetrics) {
+				p := &s[0].Metrics[2].Points[0]
+				p.Count = 3
+				p.Histogram.Counts = append([]uint64(nil), p.Histogram.Counts...)
+				p.Histogram.Counts[11] = 1
+			},
+			diff: "- " + assertScope + " latency{} sum=10 count=3 boundaries=[0.005 0.01 0.025 0.05 0.1 0.25 0.5 1 2.5 5 10] counts=[0 0 0 0 0 0 0 0 0 1 1 1]\n" +
+				"+ " + assertScope + " latency{} sum=10 count=2 boundaries=[0.005 0.01 0.025 0.05 0.1 0.25 0.5 1 2.5 5 10] counts=[0 0 0 0 0 0 0 0 0 1 1 0]\n",
+		},
+		{
+			name: "temporality",
+			modify: func(s []metrictest.ScopeMetrics) {
+				s[0].Metrics[2].Temporality = aggregation.DeltaTemporality
+			},
+			diff: "- " + assertScope + " latency: description=\"\" unit=\"\" HistogramInstrumentKind Float64Kind Histogram DeltaTemporality\n" +
+				"+ " + assertScope + " latency: description=\"\" unit=\"\" HistogramInstrumentKind Float64Kind Histogram CumulativeTemporality\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			expected := expectedAll()
+			tc.modify(expected)
+			require.Equal(t, tc.diff, metrictest.Diff(expected, actual))
+		})
+	}
+}
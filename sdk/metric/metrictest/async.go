@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrictest // import "go.opentelemetry.io/otel/sdk/metric/metrictest"
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+var errNotAsync = fmt.Errorf("not an asynchronous SDK instrument")
+
+// Int64AsyncRecorder records observations of an int64 asynchronous
+// instrument directly, without registering a callback.  Observations are
+// captured exactly as if they were made by a callback during the next
+// Collect().
+type Int64AsyncRecorder struct {
+	impl sdkapi.AsyncImpl
+}
+
+// Float64AsyncRecorder records observations of a float64 asynchronous
+// instrument directly, without registering a callback.  Observations are
+// captured exactly as if they were made by a callback during the next
+// Collect().
+type Float64AsyncRecorder struct {
+	impl sdkapi.AsyncImpl
+}
+
+// NewInt64AsyncRecorder returns an Int64AsyncRecorder for inst, which must be
+// an int64 asynchronous instrument created by the SDK.
+func NewInt64AsyncRecorder(inst instrument.Asynchronous) (Int64AsyncRecorder, error) {
+	impl, err := asyncImpl(inst, number.Int64Kind)
+	return Int64AsyncRecorder{impl: impl}, err
+}
+
+// NewFloat64AsyncRecorder returns a Float64AsyncRecorder for inst, which must
+// be a float64 asynchronous instrument created by the SDK.
+func NewFloat64AsyncRecorder(inst instrument.Asynchronous) (Float64AsyncRecorder, error) {
+	impl, err := asyncImpl(inst, number.Float64Kind)
+	return Float64AsyncRecorder{impl: impl}, err
+}
+
+// Record observes value with the given attributes.
+func (r Int64AsyncRecorder) Record(value int64, attrs ...attribute.KeyValue) {
+	r.impl.ObserveOne(context.Background(), number.NewInt64Number(value), attrs)
+}
+
+// Record observes value with the given attributes.
+func (r Float64AsyncRecorder) Record(value float64, attrs ...attribute.KeyValue) {
+	r.impl.ObserveOne(context.Background(), number.NewFloat64Number(value), attrs)
+}
+
+func asyncImpl(inst instrument.Asynchronous, kind number.Kind) (sdkapi.AsyncImpl, error) {
+	impl, ok := inst.(sdkapi.AsyncImpl)
+	if !ok || impl == nil {
+		return nil, errNotAsync
+	}
+	if nk := impl.Descriptor().NumberKind(); nk != kind {
+		return nil, fmt.Errorf("%s: number kind is %v, not %v", impl.Descriptor().Name(), nk, kind)
+	}
+	return impl, nil
+}
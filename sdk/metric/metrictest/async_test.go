@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrictest_test // import "go.opentelemetry.io/otel/sdk/metric/metrictest"
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metrictest"
+)
+
+func TestAsyncRecorders(t *testing.T) {
+	mp, exp := metrictest.NewTestMeterProvider()
+	meter := mp.Meter("go.opentelemetry.io/otel/sdk/metric/metrictest/async_TestAsyncRecorders")
+
+	icnt, err := meter.AsyncInt64().Counter("iCount")
+	require.NoError(t, err)
+	irec, err := metrictest.NewInt64AsyncRecorder(icnt)
+	require.NoError(t, err)
+
+	fgauge, err := meter.AsyncFloat64().Gauge("fGauge")
+	require.NoError(t, err)
+	frec, err := metrictest.NewFloat64AsyncRecorder(fgauge)
+	require.NoError(t, err)
+
+	irec.Record(22, attribute.String("A", "B"))
+	frec.Record(1.5)
+	frec.Record(2.5)
+
+	require.NoError(t, exp.Collect(context.Background()))
+
+	out, err := exp.GetByNameAndAttributes("iCount", []attribute.KeyValue{attribute.String("A", "B")})
+	require.NoError(t, err)
+	assert.Equal(t, int64(22), out.Sum.AsInt64())
+
+	out, err = exp.GetByName("fGauge")
+	require.NoError(t, err)
+	assert.InDelta(t, 2.5, out.LastValue.AsFloat64(), 0.0001)
+
+	_, err = metrictest.NewFloat64AsyncRecorder(icnt)
+	assert.Error(t, err)
+	_, err = metrictest.NewInt64AsyncRecorder(nil)
+	assert.Error(t, err)
+}
@@ -14,10 +14,25 @@
 
 package metrictest // import "go.opentelemetry.io/otel/sdk/metric/metrictest"
 
-import "go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+import (
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+)
 
 type config struct {
 	temporalitySelector aggregation.TemporalitySelector
+	producers           []export.Producer
+}
+
+// controllerOptions returns the options of the controller of a test
+// MeterProvider.
+func (cfg config) controllerOptions() []controller.Option {
+	opts := []controller.Option{controller.WithCollectPeriod(0)}
+	for _, p := range cfg.producers {
+		opts = append(opts, controller.WithProducer(p))
+	}
+	return opts
 }
 
 func newConfig(opts ...Option) config {
@@ -44,8 +59,9 @@ func (f functionOption) apply(cfg config) config {
 // WithTemporalitySelector allows for the use of either cumulative (default) or
 // delta metrics.
 //
-// Warning: the current SDK does not convert async instruments into delta
-// temporality.
+// Warning: the Exporter does not convert async instruments into delta
+// temporality.  The Reader does, computing the changes of their observed
+// sums between collections.
 func WithTemporalitySelector(ts aggregation.TemporalitySelector) Option {
 	return functionOption(func(cfg config) config {
 		if ts == nil {
@@ -55,3 +71,12 @@ func WithTemporalitySelector(ts aggregation.TemporalitySelector) Option {
 		return cfg
 	})
 }
+
+// WithProducer adds a source of metric data from outside the SDK, whose
+// records are collected with those of the SDK instruments.
+func WithProducer(p export.Producer) Option {
+	return functionOption(func(cfg config) config {
+		cfg.producers = append(cfg.producers, p)
+		return cfg
+	})
+}
@@ -54,7 +54,7 @@ func NewTestMeterProvider(opts ...Option) (metric.MeterProvider, *Exporter) {
 			selector.NewWithHistogramDistribution(),
 			cfg.temporalitySelector,
 		),
-		controller.WithCollectPeriod(0),
+		cfg.controllerOptions()...,
 	)
 	exp := &Exporter{
 		controller:          c,
@@ -114,38 +114,17 @@ func (e *Exporter) Collect(ctx context.Context) error {
 				NumberKind:             rec.Descriptor().NumberKind(),
 			}
 
-			var err error
-			switch agg := rec.Aggregation().(type) {
-			case aggregation.Histogram:
+			if _, ok := rec.Aggregation().(aggregation.Histogram); ok {
 				record.AggregationKind = aggregation.HistogramKind
-				record.Histogram, err = agg.Histogram()
-				if err != nil {
-					return err
-				}
-				record.Sum, err = agg.Sum()
-				if err != nil {
-					return err
-				}
-				record.Count, err = agg.Count()
-				if err != nil {
-					return err
-				}
-			case aggregation.Count:
-				record.Count, err = agg.Count()
-				if err != nil {
-					return err
-				}
-			case aggregation.LastValue:
-				record.LastValue, _, err = agg.LastValue()
-				if err != nil {
-					return err
-				}
-			case aggregation.Sum:
-				record.Sum, err = agg.Sum()
-				if err != nil {
-					return err
-				}
 			}
+			p, err := newPoint(rec.Aggregation())
+			if err != nil {
+				return err
+			}
+			record.Sum = p.Sum
+			record.Count = p.Count
+			record.Histogram = p.Histogram
+			record.LastValue = p.LastValue
 
 			e.Records = append(e.Records, record)
 			return nil
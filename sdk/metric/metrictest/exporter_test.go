@@ -234,11 +234,12 @@ func TestAsyncInstruments(t *testing.T) {
 		fcnt, err := meter.AsyncFloat64().Counter("fCount")
 		require.NoError(t, err)
 
-		err = meter.RegisterCallback(
+		_, err = meter.RegisterCallback(
 			[]instrument.Asynchronous{
 				fcnt,
-			}, func(context.Context) {
+			}, func(context.Context) error {
 				fcnt.Observe(ctx, 2)
+				return nil
 			})
 		require.NoError(t, err)
 
@@ -257,11 +258,12 @@ func TestAsyncInstruments(t *testing.T) {
 		fudcnt, err := meter.AsyncFloat64().UpDownCounter("fUDCount")
 		require.NoError(t, err)
 
-		err = meter.RegisterCallback(
+		_, err = meter.RegisterCallback(
 			[]instrument.Asynchronous{
 				fudcnt,
-			}, func(context.Context) {
+			}, func(context.Context) error {
 				fudcnt.Observe(ctx, 3)
+				return nil
 			})
 		require.NoError(t, err)
 
@@ -280,11 +282,12 @@ func TestAsyncInstruments(t *testing.T) {
 		fgauge, err := meter.AsyncFloat64().Gauge("fGauge")
 		require.NoError(t, err)
 
-		err = meter.RegisterCallback(
+		_, err = meter.RegisterCallback(
 			[]instrument.Asynchronous{
 				fgauge,
-			}, func(context.Context) {
+			}, func(context.Context) error {
 				fgauge.Observe(ctx, 4)
+				return nil
 			})
 		require.NoError(t, err)
 
@@ -303,11 +306,12 @@ func TestAsyncInstruments(t *testing.T) {
 		icnt, err := meter.AsyncInt64().Counter("iCount")
 		require.NoError(t, err)
 
-		err = meter.RegisterCallback(
+		_, err = meter.RegisterCallback(
 			[]instrument.Asynchronous{
 				icnt,
-			}, func(context.Context) {
+			}, func(context.Context) error {
 				icnt.Observe(ctx, 22)
+				return nil
 			})
 		require.NoError(t, err)
 
@@ -326,11 +330,12 @@ func TestAsyncInstruments(t *testing.T) {
 		iudcnt, err := meter.AsyncInt64().UpDownCounter("iUDCount")
 		require.NoError(t, err)
 
-		err = meter.RegisterCallback(
+		_, err = meter.RegisterCallback(
 			[]instrument.Asynchronous{
 				iudcnt,
-			}, func(context.Context) {
+			}, func(context.Context) error {
 				iudcnt.Observe(ctx, 23)
+				return nil
 			})
 		require.NoError(t, err)
 
@@ -348,11 +353,12 @@ func TestAsyncInstruments(t *testing.T) {
 		igauge, err := meter.AsyncInt64().Gauge("iGauge")
 		require.NoError(t, err)
 
-		err = meter.RegisterCallback(
+		_, err = meter.RegisterCallback(
 			[]instrument.Asynchronous{
 				igauge,
-			}, func(context.Context) {
+			}, func(context.Context) error {
 				igauge.Observe(ctx, 25)
+				return nil
 			})
 		require.NoError(t, err)
 
@@ -0,0 +1,251 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrictest // import "go.opentelemetry.io/otel/sdk/metric/metrictest"
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+	selector "go.opentelemetry.io/otel/sdk/metric/selector/simple"
+)
+
+// Reader is a manually collected reader for testing instrumentation.
+// Unlike Exporter, it returns the collected data structured by
+// instrumentation scope and instrument, the metrics analog of an
+// in-memory span recorder.  It is safe for concurrent use.
+type Reader struct {
+	lock                sync.Mutex
+	controller          *controller.Controller
+	temporalitySelector aggregation.TemporalitySelector
+
+	// baselines are the last cumulative sums of the series whose
+	// deltas the Reader computes, see readerTemporality.
+	baselines map[baselineKey]baseline
+}
+
+// baselineKey identifies a series of a Reader.
+type baselineKey struct {
+	scope      Scope
+	name       string
+	attributes attribute.Distinct
+}
+
+// baseline is the cumulative sum of a series at the end of the last
+// collection.
+type baseline struct {
+	sum number.Number
+	end time.Time
+}
+
+// readerTemporality is the temporality selector of the processor of a
+// Reader: the precomputed sums of a delta Reader are cumulative, as the
+// processor cannot compute their deltas, and the Reader computes them.
+type readerTemporality struct {
+	aggregation.TemporalitySelector
+}
+
+// TemporalityFor implements aggregation.TemporalitySelector.
+func (s readerTemporality) TemporalityFor(desc *sdkapi.Descriptor, kind aggregation.Kind) aggregation.Temporality {
+	t := s.TemporalitySelector.TemporalityFor(desc, kind)
+	if t == aggregation.DeltaTemporality && kind == aggregation.SumKind && desc.InstrumentKind().PrecomputedSum() {
+		return aggregation.CumulativeTemporality
+	}
+	return t
+}
+
+// NewTestReader creates a MeterProvider and the Reader collecting it.
+func NewTestReader(opts ...Option) (metric.MeterProvider, *Reader) {
+	cfg := newConfig(opts...)
+
+	c := controller.New(
+		processor.NewFactory(
+			selector.NewWithHistogramDistribution(),
+			readerTemporality{cfg.temporalitySelector},
+			processor.WithMemory(memory(cfg.temporalitySelector)),
+		),
+		cfg.controllerOptions()...,
+	)
+	return c, &Reader{
+		controller:          c,
+		temporalitySelector: cfg.temporalitySelector,
+		baselines:           map[baselineKey]baseline{},
+	}
+}
+
+// memory returns whether the processor of a Reader remembers the
+// attribute sets that were not updated, so that the cumulative points
+// include them.  The delta points of those sets would repeat their last
+// change, so memory is only used when ts selects cumulative temporality.
+func memory(ts aggregation.TemporalitySelector) bool {
+	desc := sdkapi.NewDescriptor("", sdkapi.CounterInstrumentKind, number.Int64Kind, "", "")
+	return ts.TemporalityFor(&desc, aggregation.SumKind) == aggregation.CumulativeTemporality
+}
+
+// ScopeMetrics holds the metrics of the instruments of one
+// instrumentation scope.
+type ScopeMetrics struct {
+	Scope   Scope
+	Metrics []Metrics
+}
+
+// Metrics holds the points of one instrument.
+type Metrics struct {
+	Name            string
+	Description     string
+	Unit            unit.Unit
+	InstrumentKind  sdkapi.InstrumentKind
+	NumberKind      number.Kind
+	AggregationKind aggregation.Kind
+	Temporality     aggregation.Temporality
+	Points          []Point
+}
+
+// Point holds the aggregated values of one attribute set of an
+// instrument.  The values set depend on the kind of aggregation.
+type Point struct {
+	Attributes attribute.Set
+	StartTime  time.Time
+	EndTime    time.Time
+	Sum        number.Number
+	Count      uint64
+	Histogram  aggregation.Buckets
+	LastValue  number.Number
+}
+
+// Collect collects the SDK and returns the metrics of each
+// instrumentation scope, in the order their meters were created.  Each
+// call reflects the temporality of the Reader: cumulative points hold
+// the totals since the start, delta points the changes since the last
+// call.  The delta points of asynchronous counters are the changes of
+// the observed sums since the last call that observed them.
+func (r *Reader) Collect(ctx context.Context) ([]ScopeMetrics, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err := r.controller.Collect(ctx); err != nil {
+		return nil, err
+	}
+
+	var scopes []ScopeMetrics
+	err := r.controller.ForEach(func(l instrumentation.Library, reader export.Reader) error {
+		sm := ScopeMetrics{
+			Scope: Scope{
+				InstrumentationName:    l.Name,
+				InstrumentationVersion: l.Version,
+				SchemaURL:              l.SchemaURL,
+			},
+		}
+		index := map[string]int{}
+		err := reader.ForEach(readerTemporality{r.temporalitySelector}, func(rec export.Record) error {
+			p, err := newPoint(rec.Aggregation())
+			if err != nil {
+				return err
+			}
+			p.Attributes = *rec.Attributes()
+			p.StartTime = rec.StartTime()
+			p.EndTime = rec.EndTime()
+
+			desc := rec.Descriptor()
+			temporality := rec.Temporality()
+			kind := rec.Aggregation().Kind()
+			if temporality == aggregation.CumulativeTemporality && r.temporalitySelector.TemporalityFor(desc, kind) == aggregation.DeltaTemporality {
+				r.delta(sm.Scope, desc, &p)
+				temporality = aggregation.DeltaTemporality
+			}
+			i, ok := index[desc.Name()]
+			if !ok {
+				i = len(sm.Metrics)
+				index[desc.Name()] = i
+				sm.Metrics = append(sm.Metrics, Metrics{
+					Name:            desc.Name(),
+					Description:     desc.Description(),
+					Unit:            desc.Unit(),
+					InstrumentKind:  desc.InstrumentKind(),
+					NumberKind:      desc.NumberKind(),
+					AggregationKind: kind,
+					Temporality:     temporality,
+				})
+			}
+			sm.Metrics[i].Points = append(sm.Metrics[i].Points, p)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(sm.Metrics) != 0 {
+			scopes = append(scopes, sm)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scopes, nil
+}
+
+// delta replaces the cumulative sum of p, a point of the instrument
+// described by desc in scope, by its change since the last collection,
+// and records the sum as the baseline of the next collection.
+func (r *Reader) delta(scope Scope, desc *sdkapi.Descriptor, p *Point) {
+	key := baselineKey{
+		scope:      scope,
+		name:       desc.Name(),
+		attributes: p.Attributes.Equivalent(),
+	}
+	last, ok := r.baselines[key]
+	r.baselines[key] = baseline{sum: p.Sum, end: p.EndTime}
+	if !ok {
+		return
+	}
+	kind := desc.NumberKind()
+	p.Sum.AddNumber(kind, number.NewNumberSignChange(kind, last.sum))
+	p.StartTime = last.end
+}
+
+// newPoint returns the Point holding the values of agg.
+func newPoint(agg aggregation.Aggregation) (Point, error) {
+	var (
+		p   Point
+		err error
+	)
+	switch agg := agg.(type) {
+	case aggregation.Histogram:
+		if p.Histogram, err = agg.Histogram(); err != nil {
+			return p, err
+		}
+		if p.Sum, err = agg.Sum(); err != nil {
+			return p, err
+		}
+		p.Count, err = agg.Count()
+	case aggregation.Count:
+		p.Count, err = agg.Count()
+	case aggregation.LastValue:
+		p.LastValue, _, err = agg.LastValue()
+	case aggregation.Sum:
+		p.Sum, err = agg.Sum()
+	}
+	return p, err
+}
@@ -0,0 +1,252 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrictest_test // import "go.opentelemetry.io/otel/sdk/metric/metrictest"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/sum"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/metrictest"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+func TestReader(t *testing.T) {
+	ctx := context.Background()
+	mp, reader := metrictest.NewTestReader()
+	meter := mp.Meter("go.opentelemetry.io/otel/sdk/metric/metrictest/reader_TestReader")
+
+	requests, err := meter.SyncInt64().Counter("requests", instrument.WithDescription("Requests served"), instrument.WithUnit(unit.Dimensionless))
+	require.NoError(t, err)
+	get := attribute.String("method", "GET")
+	post := attribute.String("method", "POST")
+
+	requests.Add(ctx, 2, get)
+	requests.Add(ctx, 1, post)
+	requests.Add(ctx, 3, get)
+
+	scopes, err := reader.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, scopes, 1)
+	require.Equal(t, metrictest.Scope{
+		InstrumentationName: "go.opentelemetry.io/otel/sdk/metric/metrictest/reader_TestReader",
+	}, scopes[0].Scope)
+	require.Len(t, scopes[0].Metrics, 1)
+
+	m := scopes[0].Metrics[0]
+	require.Equal(t, "requests", m.Name)
+	require.Equal(t, "Requests served", m.Description)
+	require.Equal(t, unit.Dimensionless, m.Unit)
+	require.Equal(t, sdkapi.CounterInstrumentKind, m.InstrumentKind)
+	require.Equal(t, number.Int64Kind, m.NumberKind)
+	require.Equal(t, aggregation.SumKind, m.AggregationKind)
+	require.Equal(t, aggregation.CumulativeTemporality, m.Temporality)
+
+	sums := func(m metrictest.Metrics) map[string]int64 {
+		sums := map[string]int64{}
+		for _, p := range m.Points {
+			require.False(t, p.EndTime.Before(p.StartTime))
+			sums[p.Attributes.Encoded(attribute.DefaultEncoder())] = p.Sum.AsInt64()
+		}
+		return sums
+	}
+	require.Equal(t, map[string]int64{
+		"method=GET":  5,
+		"method=POST": 1,
+	}, sums(m))
+
+	// Cumulative points keep their totals.
+	requests.Add(ctx, 1, post)
+	scopes, err = reader.Collect(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{
+		"method=GET":  5,
+		"method=POST": 2,
+	}, sums(scopes[0].Metrics[0]))
+}
+
+func TestReaderDelta(t *testing.T) {
+	ctx := context.Background()
+	mp, reader := metrictest.NewTestReader(metrictest.WithTemporalitySelector(aggregation.DeltaTemporalitySelector()))
+	meter := mp.Meter("go.opentelemetry.io/otel/sdk/metric/metrictest/reader_TestReaderDelta")
+
+	requests, err := meter.SyncInt64().Counter("requests")
+	require.NoError(t, err)
+
+	requests.Add(ctx, 2)
+	scopes, err := reader.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, scopes, 1)
+	m := scopes[0].Metrics[0]
+	require.Equal(t, aggregation.DeltaTemporality, m.Temporality)
+	require.Len(t, m.Points, 1)
+	require.Equal(t, int64(2), m.Points[0].Sum.AsInt64())
+
+	// Delta points hold the changes since the last collection.
+	requests.Add(ctx, 3)
+	scopes, err = reader.Collect(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), scopes[0].Metrics[0].Points[0].Sum.AsInt64())
+
+	// Nothing changed.
+	scopes, err = reader.Collect(ctx)
+	require.NoError(t, err)
+	require.Empty(t, scopes)
+}
+
+func TestReaderDeltaIncrements(t *testing.T) {
+	ctx := context.Background()
+	mp, reader := metrictest.NewTestReader(metrictest.WithTemporalitySelector(aggregation.DeltaTemporalitySelector()))
+	meter := mp.Meter("go.opentelemetry.io/otel/sdk/metric/metrictest/reader_TestReaderDeltaIncrements")
+
+	requests, err := meter.SyncInt64().Counter("requests")
+	require.NoError(t, err)
+	latency, err := meter.SyncFloat64().Histogram("latency")
+	require.NoError(t, err)
+	bytes, err := meter.AsyncInt64().Counter("bytes")
+	require.NoError(t, err)
+	var sent int64
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{bytes}, func(ctx context.Context) error {
+		bytes.Observe(ctx, sent)
+		return nil
+	})
+	require.NoError(t, err)
+
+	type values struct {
+		temporality aggregation.Temporality
+		sum         float64
+		count       uint64
+	}
+	collect := func() map[string]values {
+		scopes, err := reader.Collect(ctx)
+		require.NoError(t, err)
+		require.Len(t, scopes, 1)
+		out := map[string]values{}
+		for _, m := range scopes[0].Metrics {
+			require.Len(t, m.Points, 1)
+			p := m.Points[0]
+			require.False(t, p.EndTime.Before(p.StartTime))
+			out[m.Name] = values{
+				temporality: m.Temporality,
+				sum:         p.Sum.CoerceToFloat64(m.NumberKind),
+				count:       p.Count,
+			}
+		}
+		return out
+	}
+
+	requests.Add(ctx, 2)
+	latency.Record(ctx, 1.5)
+	sent = 100
+	require.Equal(t, map[string]values{
+		"requests": {temporality: aggregation.DeltaTemporality, sum: 2},
+		"latency":  {temporality: aggregation.DeltaTemporality, sum: 1.5, count: 1},
+		"bytes":    {temporality: aggregation.DeltaTemporality, sum: 100},
+	}, collect())
+
+	// The second collection holds the increments only, including
+	// the change of the observed sum.
+	requests.Add(ctx, 3)
+	latency.Record(ctx, 2)
+	latency.Record(ctx, 4)
+	sent = 160
+	require.Equal(t, map[string]values{
+		"requests": {temporality: aggregation.DeltaTemporality, sum: 3},
+		"latency":  {temporality: aggregation.DeltaTemporality, sum: 6, count: 2},
+		"bytes":    {temporality: aggregation.DeltaTemporality, sum: 60},
+	}, collect())
+
+	// Only the observed sum is reported when nothing was recorded.
+	require.Equal(t, map[string]values{
+		"bytes": {temporality: aggregation.DeltaTemporality, sum: 0},
+	}, collect())
+}
+
+// fakeProducer produces the sum of allocations of a C library.
+type fakeProducer struct {
+	scope       instrumentation.Scope
+	allocations int64
+}
+
+func (p *fakeProducer) Produce(ctx context.Context) ([]export.ScopeMetrics, error) {
+	desc := metrictest.NewDescriptor("clib.allocations", sdkapi.CounterObserverInstrumentKind, number.Int64Kind)
+	attrs := attribute.NewSet(attribute.String("library", "clib"))
+	agg := &sum.New(1)[0]
+	if err := agg.Update(ctx, number.NewInt64Number(p.allocations), &desc); err != nil {
+		return nil, err
+	}
+	rec := export.NewRecord(&desc, &attrs, agg, time.Time{}, time.Now())
+	return []export.ScopeMetrics{{
+		Scope:   p.scope,
+		Records: []export.Record{rec.WithTemporality(aggregation.CumulativeTemporality)},
+	}}, nil
+}
+
+func TestReaderProducer(t *testing.T) {
+	ctx := context.Background()
+	const name = "go.opentelemetry.io/otel/sdk/metric/metrictest/reader_TestReaderProducer"
+	producer := &fakeProducer{
+		scope:       instrumentation.Scope{Name: name},
+		allocations: 42,
+	}
+	mp, reader := metrictest.NewTestReader(metrictest.WithProducer(producer))
+	meter := mp.Meter(name)
+
+	requests, err := meter.SyncInt64().Counter("requests")
+	require.NoError(t, err)
+	requests.Add(ctx, 2)
+
+	scopes, err := reader.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, scopes, 1)
+	require.Equal(t, name, scopes[0].Scope.InstrumentationName)
+	require.Len(t, scopes[0].Metrics, 2)
+
+	native, produced := scopes[0].Metrics[0], scopes[0].Metrics[1]
+	require.Equal(t, "requests", native.Name)
+	require.Equal(t, int64(2), native.Points[0].Sum.AsInt64())
+	require.Equal(t, "clib.allocations", produced.Name)
+	require.Equal(t, sdkapi.CounterObserverInstrumentKind, produced.InstrumentKind)
+	require.Equal(t, aggregation.CumulativeTemporality, produced.Temporality)
+	require.Len(t, produced.Points, 1)
+	require.Equal(t, int64(42), produced.Points[0].Sum.AsInt64())
+	require.Equal(t, "library=clib", produced.Points[0].Attributes.Encoded(attribute.DefaultEncoder()))
+
+	// The producer is called by every collection.
+	producer.allocations = 50
+	scopes, err = reader.Collect(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), scopes[0].Metrics[1].Points[0].Sum.AsInt64())
+
+	// Produced scopes without SDK instruments follow the others.
+	producer.scope = instrumentation.Scope{Name: "clib"}
+	scopes, err = reader.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, scopes, 2)
+	require.Equal(t, name, scopes[0].Scope.InstrumentationName)
+	require.Len(t, scopes[0].Metrics, 1)
+	require.Equal(t, "clib", scopes[1].Scope.InstrumentationName)
+	require.Equal(t, "clib.allocations", scopes[1].Metrics[0].Name)
+}
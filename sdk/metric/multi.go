@@ -0,0 +1,346 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric // import "go.opentelemetry.io/otel/sdk/metric"
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+type (
+	// multiMeterImpl creates each instrument in every one of
+	// impls, see NewMultiMeterImpl.
+	multiMeterImpl struct {
+		impls []sdkapi.MeterImpl
+	}
+
+	// multiSyncInstrument records every measurement in each of
+	// insts, which has the index of its MeterImpl.
+	multiSyncInstrument struct {
+		instrument.Synchronous
+		descriptor sdkapi.Descriptor
+		insts      []sdkapi.SyncImpl
+	}
+
+	// multiAsyncInstrument is the asynchronous counterpart of
+	// multiSyncInstrument.
+	multiAsyncInstrument struct {
+		instrument.Asynchronous
+		descriptor sdkapi.Descriptor
+		insts      []sdkapi.AsyncImpl
+	}
+
+	// multiRegistration is a callback registered with every
+	// MeterImpl of a multiMeterImpl.
+	multiRegistration []metric.Registration
+)
+
+var (
+	_ sdkapi.MeterImpl           = &multiMeterImpl{}
+	_ sdkapi.InstrumentDiscarder = &multiMeterImpl{}
+	_ sdkapi.SyncImpl            = &multiSyncInstrument{}
+	_ sdkapi.AsyncImpl           = &multiAsyncInstrument{}
+)
+
+// NewMultiMeterImpl returns a MeterImpl that creates each instrument in
+// every one of impls, e.g., in the Accumulators of the same scope of
+// several Controllers, so that the measurements of one instrument are
+// aggregated by each Accumulator with its own AggregatorSelector and
+// exported with its own temporality.  The measurements are recorded in
+// every Accumulator.  The observations made by a callback are recorded
+// in the Accumulator that runs the callback only, since each Accumulator
+// runs the callback when it is collected.  This holds for ObserveBatch,
+// ObserveDelta, ObserveSet and ObserveShared too.
+//
+// The attribute set of a synchronous measurement is computed once for
+// the Accumulators that do not filter its attributes, and each
+// Accumulator that filters them computes its reduced set once.  The
+// callbacks still run once per Accumulator, since each Accumulator is
+// collected on the schedule of its own Controller.
+func NewMultiMeterImpl(impls ...sdkapi.MeterImpl) sdkapi.MeterImpl {
+	return &multiMeterImpl{
+		impls: append([]sdkapi.MeterImpl(nil), impls...),
+	}
+}
+
+// NewSyncInstrument implements sdkapi.MeterImpl.  It fails when the
+// instrument cannot be created in one of the MeterImpls.  The instrument
+// is then discarded from the MeterImpls it was created in, see
+// sdkapi.InstrumentDiscarder.
+func (m *multiMeterImpl) NewSyncInstrument(descriptor sdkapi.Descriptor) (sdkapi.SyncImpl, error) {
+	inst := &multiSyncInstrument{
+		descriptor: descriptor,
+		insts:      make([]sdkapi.SyncImpl, len(m.impls)),
+	}
+	for i, impl := range m.impls {
+		s, err := impl.NewSyncInstrument(descriptor)
+		if err != nil {
+			for j, created := range inst.insts[:i] {
+				m.discard(j, created)
+			}
+			return nil, err
+		}
+		inst.insts[i] = s
+	}
+	return inst, nil
+}
+
+// NewAsyncInstrument implements sdkapi.MeterImpl.  It fails when the
+// instrument cannot be created in one of the MeterImpls.  The instrument
+// is then discarded from the MeterImpls it was created in, see
+// sdkapi.InstrumentDiscarder.
+func (m *multiMeterImpl) NewAsyncInstrument(descriptor sdkapi.Descriptor) (sdkapi.AsyncImpl, error) {
+	inst := &multiAsyncInstrument{
+		descriptor: descriptor,
+		insts:      make([]sdkapi.AsyncImpl, len(m.impls)),
+	}
+	for i, impl := range m.impls {
+		a, err := impl.NewAsyncInstrument(descriptor)
+		if err != nil {
+			for j, created := range inst.insts[:i] {
+				m.discard(j, created)
+			}
+			return nil, err
+		}
+		inst.insts[i] = a
+	}
+	return inst, nil
+}
+
+// discard discards inst from the i-th MeterImpl, if it can.
+func (m *multiMeterImpl) discard(i int, inst sdkapi.InstrumentImpl) {
+	if d, ok := m.impls[i].(sdkapi.InstrumentDiscarder); ok {
+		d.DiscardInstrument(inst)
+	}
+}
+
+// DiscardInstrument implements sdkapi.InstrumentDiscarder.  It discards
+// the instrument from every MeterImpl.
+func (m *multiMeterImpl) DiscardInstrument(inst sdkapi.InstrumentImpl) {
+	switch multi := inst.Implementation().(type) {
+	case *multiSyncInstrument:
+		for i, s := range multi.insts {
+			m.discard(i, s)
+		}
+	case *multiAsyncInstrument:
+		for i, a := range multi.insts {
+			m.discard(i, a)
+		}
+	}
+}
+
+// RegisterCallback implements sdkapi.MeterImpl.  It registers f with
+// every MeterImpl, for the instruments of insts created by that
+// MeterImpl.  When a registration fails, the earlier ones are
+// unregistered.
+func (m *multiMeterImpl) RegisterCallback(insts []instrument.Asynchronous, f metric.Callback) (metric.Registration, error) {
+	multis := make([]*multiAsyncInstrument, len(insts))
+	for i, inst := range insts {
+		impl, ok := inst.(sdkapi.AsyncImpl)
+		if !ok {
+			return nil, ErrBadInstrument
+		}
+		multi, ok := impl.Implementation().(*multiAsyncInstrument)
+		if !ok || len(multi.insts) != len(m.impls) {
+			return nil, ErrBadInstrument
+		}
+		multis[i] = multi
+	}
+
+	regs := make(multiRegistration, 0, len(m.impls))
+	for i, impl := range m.impls {
+		scoped := make([]instrument.Asynchronous, len(multis))
+		for j, multi := range multis {
+			scoped[j] = multi.insts[i]
+		}
+		reg, err := impl.RegisterCallback(scoped, f)
+		if err != nil {
+			_ = regs.Unregister()
+			return nil, err
+		}
+		regs = append(regs, reg)
+	}
+	return regs, nil
+}
+
+// Unregister implements metric.Registration.  It returns the first
+// error, after unregistering the callback from every MeterImpl.
+func (r multiRegistration) Unregister() error {
+	var err error
+	for _, reg := range r {
+		if uerr := reg.Unregister(); uerr != nil && err == nil {
+			err = uerr
+		}
+	}
+	return err
+}
+
+// Implementation implements sdkapi.InstrumentImpl.
+func (s *multiSyncInstrument) Implementation() interface{} {
+	return s
+}
+
+// Descriptor implements sdkapi.InstrumentImpl.
+func (s *multiSyncInstrument) Descriptor() sdkapi.Descriptor {
+	return s.descriptor
+}
+
+// RecordOne implements sdkapi.SyncImpl.  The attribute set of the
+// measurement is computed once for the instruments of all the
+// Accumulators that keep every attribute.  The instruments that filter
+// the attributes, see WithAttributeFilter, compute their reduced set
+// from the filtered attributes instead, which is cheaper than filtering
+// the full set, as do the instruments of Accumulators that normalize
+// attributes, see WithStringNormalization.
+func (s *multiSyncInstrument) RecordOne(ctx context.Context, num number.Number, attrs []attribute.KeyValue) {
+	var shared *sharedAttributes
+	for _, inst := range s.insts {
+		impl, ok := inst.Implementation().(*syncInstrument)
+		if !ok || impl.filter != nil || len(impl.meter.config.StringNormalizers) != 0 {
+			inst.RecordOne(ctx, num, attrs)
+			continue
+		}
+		if !impl.collected() {
+			continue
+		}
+		if shared == nil {
+			shared = &sharedAttributes{
+				kvs: attrs,
+				set: attribute.NewSet(attrs...),
+			}
+		}
+		impl.captureShared(ctx, num, shared)
+	}
+}
+
+// Implementation implements sdkapi.InstrumentImpl.
+func (a *multiAsyncInstrument) Implementation() interface{} {
+	return a
+}
+
+// Descriptor implements sdkapi.InstrumentImpl.
+func (a *multiAsyncInstrument) Descriptor() sdkapi.Descriptor {
+	return a.descriptor
+}
+
+// ObserveOne implements sdkapi.AsyncImpl.  An observation made by a
+// callback is recorded in the instrument of the Accumulator that runs
+// the callback, and an observation made outside of callbacks in every
+// instrument.
+func (a *multiAsyncInstrument) ObserveOne(ctx context.Context, num number.Number, attrs []attribute.KeyValue) {
+	attempt, ok := ctx.Value(asyncContextKey{}).(*callbackAttempt)
+	if !ok {
+		for _, inst := range a.insts {
+			inst.ObserveOne(ctx, num, attrs)
+		}
+		return
+	}
+	for _, inst := range a.insts {
+		if ai, ok := inst.Implementation().(*asyncInstrument); ok {
+			if _, ok := attempt.insts[ai]; ok {
+				inst.ObserveOne(ctx, num, attrs)
+				return
+			}
+		}
+	}
+	otel.Handle(fmt.Errorf("%s: %w", a.descriptor.Name(), ErrUndeclaredInstrument))
+}
+
+// implementations returns the instruments of a that an observation made
+// with ctx is captured by, like ObserveOne: the instrument of the
+// Accumulator running the callback of ctx, or every instrument outside
+// of callbacks.  When the callback does not declare a, the first
+// instrument is returned, for its observation to be reported.
+func (a *multiAsyncInstrument) implementations(ctx context.Context) []*asyncInstrument {
+	var impls []*asyncInstrument
+	for _, inst := range a.insts {
+		switch impl := inst.Implementation().(type) {
+		case *asyncInstrument:
+			impls = append(impls, impl)
+		case *multiAsyncInstrument:
+			impls = append(impls, impl.implementations(ctx)...)
+		}
+	}
+	attempt, ok := ctx.Value(asyncContextKey{}).(*callbackAttempt)
+	if !ok || len(impls) == 0 {
+		return impls
+	}
+	for _, impl := range impls {
+		if _, ok := attempt.insts[impl]; ok {
+			return []*asyncInstrument{impl}
+		}
+	}
+	return impls[:1]
+}
+
+// multiImplementations returns the instruments that an observation of
+// inst made with ctx is captured by, if inst is an instrument of a
+// multiMeterImpl.
+func multiImplementations(ctx context.Context, inst instrument.Asynchronous) ([]*asyncInstrument, bool) {
+	impl, ok := inst.(sdkapi.AsyncImpl)
+	if !ok || impl == nil {
+		return nil, false
+	}
+	multi, ok := impl.Implementation().(*multiAsyncInstrument)
+	if !ok {
+		return nil, false
+	}
+	return multi.implementations(ctx), true
+}
+
+// splitShared returns the observations of ObserveShared grouped by
+// Accumulator, after replacing the instruments of a multiMeterImpl by
+// the instruments they are captured by, or false when none of the
+// instruments is one of a multiMeterImpl.
+func splitShared(ctx context.Context, observations []SharedObservation) ([][]SharedObservation, bool) {
+	var (
+		groups [][]SharedObservation
+		index  = map[*Accumulator]int{}
+		split  bool
+	)
+	add := func(meter *Accumulator, o SharedObservation) {
+		i, ok := index[meter]
+		if !ok {
+			i = len(groups)
+			index[meter] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], o)
+	}
+	for _, o := range observations {
+		if impls, ok := multiImplementations(ctx, o.Instrument); ok {
+			split = true
+			for _, a := range impls {
+				add(a.meter, SharedObservation{Instrument: a, Number: o.Number})
+			}
+			continue
+		}
+		// Other instruments, including foreign ones, are checked
+		// by ObserveShared.
+		var meter *Accumulator
+		if a, ok := asyncImplementation(o.Instrument); ok {
+			meter = a.meter
+		}
+		add(meter, o)
+	}
+	return groups, split
+}
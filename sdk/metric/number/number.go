@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"math"
 	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/otel/internal"
 )
@@ -94,6 +95,12 @@ func NewFloat64Number(f float64) Number {
 	return NewNumberFromRaw(internal.Float64ToRaw(f))
 }
 
+// NewDurationNumber creates an integral Number of the nanoseconds of d,
+// the measurement of an int64 instrument in unit.Nanoseconds.
+func NewDurationNumber(d time.Duration) Number {
+	return NewInt64Number(int64(d))
+}
+
 // NewNumberSignChange returns a number with the same magnitude and
 // the opposite sign.  `kind` must describe the kind of number in `nn`.
 func NewNumberSignChange(kind Kind, nn Number) Number {
@@ -131,6 +138,12 @@ func (n *Number) AsFloat64() float64 {
 	return internal.RawToFloat64(n.AsRaw())
 }
 
+// AsDuration assumes that the value contains an int64 of nanoseconds
+// and returns it as a time.Duration.
+func (n *Number) AsDuration() time.Duration {
+	return time.Duration(n.AsInt64())
+}
+
 // - as x atomic
 
 // AsNumberAtomic gets the Number atomically.
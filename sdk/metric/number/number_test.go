@@ -17,6 +17,7 @@ package number
 import (
 	"math"
 	"testing"
+	"time"
 	"unsafe"
 
 	"github.com/stretchr/testify/require"
@@ -169,6 +170,14 @@ func TestNumberAsInterface(t *testing.T) {
 	require.Equal(t, 11.11, (&f64).AsInterface(Float64Kind).(float64))
 }
 
+func TestDurationNumber(t *testing.T) {
+	d := 1500 * time.Millisecond
+	n := NewDurationNumber(d)
+	require.Equal(t, NewInt64Number(1500000000), n)
+	require.Equal(t, d, n.AsDuration())
+	require.Equal(t, 1.5e9, n.CoerceToFloat64(Int64Kind))
+}
+
 func TestNumberSignChange(t *testing.T) {
 	t.Run("Int64", func(t *testing.T) {
 		posInt := NewInt64Number(10)
@@ -22,6 +22,7 @@ import (
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	controllerTime "go.opentelemetry.io/otel/sdk/metric/controller/time"
 	"go.opentelemetry.io/otel/sdk/metric/export"
 	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
@@ -61,9 +62,14 @@ type (
 		updated int64
 
 		// stateful indicates that a cumulative aggregation is
-		// being maintained, taken from the process start time.
+		// being maintained, since start.
 		stateful bool
 
+		// start is the start of the interval in which the
+		// attribute set was first processed, the start time of
+		// its cumulative aggregation when stateful.
+		start time.Time
+
 		// currentOwned indicates that "current" was allocated
 		// by the processor in order to merge results from
 		// multiple Accumulators during a single collection
@@ -82,6 +88,15 @@ type (
 		// by the processor used to store the last cumulative
 		// value.
 		cumulative aggregator.Aggregator
+
+		// unacknowledged, if non-nil, refers to an Aggregator
+		// owned by the processor used to store the deltas
+		// that have not been acknowledged, see
+		// WithRetainUnacknowledged.
+		unacknowledged aggregator.Aggregator
+
+		// pending indicates that unacknowledged holds deltas.
+		pending bool
 	}
 
 	state struct {
@@ -102,12 +117,17 @@ type (
 
 		startedCollection  int64
 		finishedCollection int64
+
+		// acknowledged indicates that the last collection was
+		// acknowledged, see WithRetainUnacknowledged.
+		acknowledged bool
 	}
 )
 
 var _ export.Processor = &Processor{}
 var _ export.Checkpointer = &Processor{}
 var _ export.Reader = &state{}
+var _ export.Acknowledger = &Processor{}
 
 // ErrInconsistentState is returned when the sequence of collection's starts and finishes are incorrectly balanced.
 var ErrInconsistentState = fmt.Errorf("inconsistent processor state")
@@ -146,7 +166,14 @@ func NewFactory(aselector export.AggregatorSelector, tselector aggregation.Tempo
 var _ export.CheckpointerFactory = factory{}
 
 func (f factory) NewCheckpointer() export.Checkpointer {
-	now := time.Now()
+	config := f.config
+	if config.Clock == nil {
+		config.Clock = controllerTime.NewAnchoredClock()
+	}
+	now := config.Clock.Now()
+	if !config.StartTime.IsZero() {
+		now = config.StartTime
+	}
 	p := &Processor{
 		AggregatorSelector:  f.aselector,
 		TemporalitySelector: f.tselector,
@@ -154,7 +181,7 @@ func (f factory) NewCheckpointer() export.Checkpointer {
 			values:        map[stateKey]*stateValue{},
 			processStart:  now,
 			intervalStart: now,
-			config:        f.config,
+			config:        config,
 		},
 	}
 	return p
@@ -181,6 +208,7 @@ func (b *Processor) Process(accum export.Accumulation) error {
 			attrs:    accum.Attributes(),
 			updated:  b.state.finishedCollection,
 			stateful: stateful,
+			start:    b.state.intervalStart,
 			current:  agg,
 		}
 		if stateful {
@@ -273,17 +301,31 @@ func (b *Processor) Reader() export.Reader {
 // StartCollection signals to the Processor one or more Accumulators
 // will begin calling Process() calls during collection.
 func (b *Processor) StartCollection() {
-	if b.startedCollection != 0 {
+	if b.startedCollection != 0 && (b.acknowledged || !b.config.RetainUnacknowledged) {
 		b.intervalStart = b.intervalEnd
 	}
+	b.acknowledged = false
 	b.startedCollection++
 }
 
+// Acknowledge implements export.Acknowledger.  When the Processor retains
+// unacknowledged deltas, they are forgotten and the next interval starts
+// at the end of the last collection.
+func (b *Processor) Acknowledge() {
+	b.acknowledged = true
+	for key, value := range b.values {
+		if value.pending {
+			_ = value.unacknowledged.SynchronizedMove(nil, key.descriptor)
+			value.pending = false
+		}
+	}
+}
+
 // FinishCollection signals to the Processor that a complete
 // collection has finished and that ForEach will be called to access
 // the Reader.
 func (b *Processor) FinishCollection() error {
-	b.intervalEnd = time.Now()
+	b.intervalEnd = b.config.Clock.Now()
 	if b.startedCollection != b.finishedCollection+1 {
 		return ErrInconsistentState
 	}
@@ -294,6 +336,11 @@ func (b *Processor) FinishCollection() error {
 		stale := value.updated != b.finishedCollection
 		stateless := !value.stateful
 
+		if stale && b.evicts(mkind) && !value.pending {
+			delete(b.values, key)
+			continue
+		}
+
 		// The following branch updates stateful aggregators.  Skip
 		// these updates if the aggregator is not stateful or if the
 		// aggregator is stale.
@@ -302,9 +349,14 @@ func (b *Processor) FinishCollection() error {
 			// stale, stateless entries can be removed.
 			// This implies that they were not updated
 			// over the previous full collection interval.
-			if stale && stateless && !b.config.Memory {
+			if stale && stateless && !b.config.Memory && !value.pending && !b.awaitsFullReport(mkind, value) {
 				delete(b.values, key)
 			}
+			if !stale && b.retains(mkind, value) {
+				if err := b.retain(key.descriptor, value); err != nil {
+					return err
+				}
+			}
 			continue
 		}
 
@@ -322,6 +374,43 @@ func (b *Processor) FinishCollection() error {
 	return nil
 }
 
+// awaitsFullReport returns whether value, a stale aggregation of an
+// instrument of kind mkind, is kept until a full report exports it, see
+// WithFullReportPeriod.  This holds for the last value of asynchronous
+// sums, which, unlike the cumulative sums of synchronous instruments, is
+// not kept otherwise.
+func (b *state) awaitsFullReport(mkind sdkapi.InstrumentKind, value *stateValue) bool {
+	period := int64(b.config.FullReportPeriod)
+	if period <= 0 || !mkind.PrecomputedSum() {
+		return false
+	}
+	// The last full report was exported after the collection
+	// numbered lastFull.
+	lastFull := b.finishedCollection - b.finishedCollection%period
+	return lastFull <= value.updated
+}
+
+// evicts returns whether the stale attribute sets of instruments of
+// kind mkind are removed, see WithStaleGaugeEviction.
+func (b *state) evicts(mkind sdkapi.InstrumentKind) bool {
+	return b.config.StaleGaugeEviction && mkind == sdkapi.GaugeObserverInstrumentKind
+}
+
+// retains returns whether the deltas of value are retained until
+// acknowledged.
+func (b *state) retains(mkind sdkapi.InstrumentKind, value *stateValue) bool {
+	return b.config.RetainUnacknowledged && !value.stateful && !mkind.PrecomputedSum()
+}
+
+// retain adds the current delta of value to its unacknowledged deltas.
+func (b *Processor) retain(desc *sdkapi.Descriptor, value *stateValue) error {
+	if value.unacknowledged == nil {
+		b.AggregatorFor(desc, &value.unacknowledged)
+	}
+	value.pending = true
+	return value.unacknowledged.Merge(value.current, desc)
+}
+
 // ForEach iterates through the Reader, passing an
 // export.Record with the appropriate Cumulative or Delta aggregation
 // to an exporter.
@@ -329,6 +418,7 @@ func (b *state) ForEach(exporter aggregation.TemporalitySelector, f func(export.
 	if b.startedCollection != b.finishedCollection {
 		return ErrInconsistentState
 	}
+	fullReport := b.config.FullReportPeriod > 0 && b.finishedCollection%int64(b.config.FullReportPeriod) == 0
 	for key, value := range b.values {
 		mkind := key.descriptor.InstrumentKind()
 
@@ -344,10 +434,11 @@ func (b *state) ForEach(exporter aggregation.TemporalitySelector, f func(export.
 			// value:
 			if value.stateful {
 				agg = value.cumulative.Aggregation()
+				start = value.start
 			} else {
 				agg = value.current.Aggregation()
+				start = b.processStart
 			}
-			start = b.processStart
 
 		case aggregation.DeltaTemporality:
 			// Precomputed sums are a special case.
@@ -357,6 +448,9 @@ func (b *state) ForEach(exporter aggregation.TemporalitySelector, f func(export.
 				return aggregation.ErrNoCumulativeToDelta
 			}
 			agg = value.current.Aggregation()
+			if b.retains(mkind, value) && value.pending {
+				agg = value.unacknowledged.Aggregation()
+			}
 			start = b.intervalStart
 
 		default:
@@ -364,8 +458,9 @@ func (b *state) ForEach(exporter aggregation.TemporalitySelector, f func(export.
 		}
 
 		// If the processor does not have Config.Memory and it was not updated
-		// in the prior round, do not visit this value.
-		if !b.config.Memory && value.updated != (b.finishedCollection-1) {
+		// in the prior round, do not visit this value, unless this is a
+		// full report.
+		if !b.config.Memory && value.updated != (b.finishedCollection-1) && !value.pending && !fullReport {
 			continue
 		}
 
@@ -375,7 +470,7 @@ func (b *state) ForEach(exporter aggregation.TemporalitySelector, f func(export.
 			agg,
 			start,
 			b.intervalEnd,
-		)); err != nil && !errors.Is(err, aggregation.ErrNoData) {
+		).WithTemporality(aggTemp)); err != nil && !errors.Is(err, aggregation.ErrNoData) {
 			return err
 		}
 	}
@@ -24,12 +24,14 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric/instrument"
 	"go.opentelemetry.io/otel/sdk/instrumentation"
 	sdk "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/aggregatortest"
+	"go.opentelemetry.io/otel/sdk/metric/controller/controllertest"
 	"go.opentelemetry.io/otel/sdk/metric/export"
 	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
 	"go.opentelemetry.io/otel/sdk/metric/metrictest"
@@ -452,9 +454,10 @@ func TestCounterObserverEndToEnd(t *testing.T) {
 	var calls int64
 	ctr, err := meter.AsyncInt64().Counter("observer.sum")
 	require.NoError(t, err)
-	err = meter.RegisterCallback([]instrument.Asynchronous{ctr}, func(ctx context.Context) {
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{ctr}, func(ctx context.Context) error {
 		calls++
 		ctr.Observe(ctx, calls)
+		return nil
 	})
 	require.NoError(t, err)
 	reader := proc.Reader()
@@ -508,3 +511,377 @@ func TestCounterObserverEndToEnd(t *testing.T) {
 	requireNotAfter(t, endTime[0], endTime[1])
 	requireNotAfter(t, endTime[1], endTime[2])
 }
+
+func TestRetainUnacknowledged(t *testing.T) {
+	aggTempSel := aggregation.DeltaTemporalitySelector()
+
+	desc := metrictest.NewDescriptor("inst.sum", sdkapi.CounterInstrumentKind, number.Int64Kind)
+	selector := processortest.AggregatorSelector()
+
+	processor := basic.New(selector, aggTempSel, basic.WithRetainUnacknowledged(true))
+	reader := processor.Reader()
+
+	collect := func(values ...int64) (map[string]float64, time.Time) {
+		processor.StartCollection()
+		for _, v := range values {
+			require.NoError(t, processor.Process(updateFor(t, &desc, selector, v, attribute.String("A", "B"))))
+		}
+		require.NoError(t, processor.FinishCollection())
+
+		var start time.Time
+		records := processortest.NewOutput(attribute.DefaultEncoder())
+		require.NoError(t, reader.ForEach(aggTempSel, func(rec export.Record) error {
+			start = rec.StartTime()
+			return records.AddRecord(rec)
+		}))
+		return records.Map(), start
+	}
+
+	values, start1 := collect(10)
+	require.EqualValues(t, map[string]float64{"inst.sum/A=B/": 10}, values)
+
+	// Not acknowledged: the deltas are combined and the interval
+	// start does not advance.
+	values, start := collect(5)
+	require.EqualValues(t, map[string]float64{"inst.sum/A=B/": 15}, values)
+	require.Equal(t, start1, start)
+
+	// Not updated, still not acknowledged.
+	values, start = collect()
+	require.EqualValues(t, map[string]float64{"inst.sum/A=B/": 15}, values)
+	require.Equal(t, start1, start)
+
+	processor.Acknowledge()
+
+	values, start = collect(1)
+	require.EqualValues(t, map[string]float64{"inst.sum/A=B/": 1}, values)
+	require.True(t, start.After(start1))
+
+	processor.Acknowledge()
+
+	values, _ = collect()
+	require.EqualValues(t, map[string]float64{}, values)
+}
+
+func TestBasicClock(t *testing.T) {
+	mock := controllertest.NewMockClock()
+	mock.Add(time.Hour)
+	processStart := mock.Now()
+
+	b := basic.New(
+		processortest.AggregatorSelector(),
+		aggregation.StatelessTemporalitySelector(),
+		basic.WithClock(mock),
+	)
+
+	desc := metrictest.NewDescriptor("inst", sdkapi.CounterInstrumentKind, number.Int64Kind)
+	accum := export.NewAccumulation(&desc, attribute.EmptySet(), aggregatortest.NoopAggregator{})
+
+	start := processStart
+	for i := 0; i < 3; i++ {
+		mock.Add(time.Minute)
+
+		b.StartCollection()
+		require.NoError(t, b.Process(accum))
+		require.NoError(t, b.FinishCollection())
+
+		require.NoError(t, b.ForEach(aggregation.StatelessTemporalitySelector(), func(rec export.Record) error {
+			require.Equal(t, start, rec.StartTime())
+			require.Equal(t, mock.Now(), rec.EndTime())
+			return nil
+		}))
+		start = mock.Now()
+	}
+}
+
+func TestCumulativeStartTime(t *testing.T) {
+	aggTempSel := aggregation.CumulativeTemporalitySelector()
+	counter := metrictest.NewDescriptor("inst.sum", sdkapi.CounterInstrumentKind, number.Int64Kind)
+	observer := metrictest.NewDescriptor("observer.sum", sdkapi.CounterObserverInstrumentKind, number.Int64Kind)
+	selector := processortest.AggregatorSelector()
+	mock := controllertest.NewMockClock()
+
+	type point struct {
+		value int64
+		start time.Time
+	}
+	collect := func(processor *basic.Processor, accums ...export.Accumulation) map[string]point {
+		mock.Add(time.Minute)
+		processor.StartCollection()
+		for _, accum := range accums {
+			require.NoError(t, processor.Process(accum))
+		}
+		require.NoError(t, processor.FinishCollection())
+
+		points := map[string]point{}
+		require.NoError(t, processor.ForEach(aggTempSel, func(rec export.Record) error {
+			sum, err := rec.Aggregation().(aggregation.Sum).Sum()
+			require.NoError(t, err)
+			key := rec.Descriptor().Name() + "/" + rec.Attributes().Encoded(attribute.DefaultEncoder())
+			points[key] = point{value: sum.AsInt64(), start: rec.StartTime()}
+			return nil
+		}))
+		return points
+	}
+
+	processStart := mock.Now()
+	processor := basic.New(selector, aggTempSel, basic.WithClock(mock), basic.WithMemory(true))
+	require.Equal(t, map[string]point{
+		"inst.sum/A=1": {1, processStart},
+	}, collect(processor, updateFor(t, &counter, selector, 1, attribute.Int("A", 1))))
+
+	// The start time is kept by later collections, a new
+	// attribute set starts with the interval of its first update.
+	secondStart := mock.Now()
+	require.Equal(t, map[string]point{
+		"inst.sum/A=1": {3, processStart},
+		"inst.sum/A=2": {5, secondStart},
+	}, collect(processor,
+		updateFor(t, &counter, selector, 2, attribute.Int("A", 1)),
+		updateFor(t, &counter, selector, 5, attribute.Int("A", 2)),
+	))
+	require.Equal(t, map[string]point{
+		"inst.sum/A=1": {3, processStart},
+		"inst.sum/A=2": {5, secondStart},
+	}, collect(processor))
+
+	// A restart resets the sums with a new start time, which is
+	// also the start time of the precomputed sums.
+	restart := mock.Now().Add(-time.Second)
+	processor = basic.New(selector, aggTempSel, basic.WithClock(mock), basic.WithStartTime(restart))
+	require.Equal(t, map[string]point{
+		"inst.sum/A=1":     {4, restart},
+		"observer.sum/A=1": {10, restart},
+	}, collect(processor,
+		updateFor(t, &counter, selector, 4, attribute.Int("A", 1)),
+		updateFor(t, &observer, selector, 10, attribute.Int("A", 1)),
+	))
+}
+
+func TestFullReportPeriod(t *testing.T) {
+	aggTempSel := aggregation.CumulativeTemporalitySelector()
+
+	desc := metrictest.NewDescriptor("inst.sum", sdkapi.CounterInstrumentKind, number.Int64Kind)
+	selector := processortest.AggregatorSelector()
+
+	processor := basic.New(selector, aggTempSel, basic.WithFullReportPeriod(3))
+	reader := processor.Reader()
+
+	collect := func(kvs ...attribute.KeyValue) map[string]float64 {
+		processor.StartCollection()
+		for _, kv := range kvs {
+			require.NoError(t, processor.Process(updateFor(t, &desc, selector, 10, kv)))
+		}
+		require.NoError(t, processor.FinishCollection())
+
+		records := processortest.NewOutput(attribute.DefaultEncoder())
+		require.NoError(t, reader.ForEach(aggTempSel, records.AddRecord))
+		return records.Map()
+	}
+
+	a, b := attribute.String("A", "a"), attribute.String("B", "b")
+
+	require.EqualValues(t, map[string]float64{
+		"inst.sum/A=a/": 10,
+		"inst.sum/B=b/": 10,
+	}, collect(a, b))
+	require.EqualValues(t, map[string]float64{
+		"inst.sum/A=a/": 20,
+	}, collect(a))
+	// The third collection reports the unchanged series too.
+	require.EqualValues(t, map[string]float64{
+		"inst.sum/A=a/": 30,
+		"inst.sum/B=b/": 10,
+	}, collect(a))
+	require.EqualValues(t, map[string]float64{}, collect())
+}
+
+func TestFullReportPeriodObserver(t *testing.T) {
+	aggTempSel := aggregation.CumulativeTemporalitySelector()
+
+	desc := metrictest.NewDescriptor("observer.sum", sdkapi.CounterObserverInstrumentKind, number.Int64Kind)
+	selector := processortest.AggregatorSelector()
+
+	processor := basic.New(selector, aggTempSel, basic.WithFullReportPeriod(3))
+	reader := processor.Reader()
+
+	collect := func(value int64, kvs ...attribute.KeyValue) map[string]float64 {
+		processor.StartCollection()
+		for _, kv := range kvs {
+			require.NoError(t, processor.Process(updateFor(t, &desc, selector, value, kv)))
+		}
+		require.NoError(t, processor.FinishCollection())
+
+		records := processortest.NewOutput(attribute.DefaultEncoder())
+		require.NoError(t, reader.ForEach(aggTempSel, records.AddRecord))
+		return records.Map()
+	}
+
+	a, b := attribute.String("A", "a"), attribute.String("B", "b")
+
+	require.EqualValues(t, map[string]float64{
+		"observer.sum/A=a/": 10,
+		"observer.sum/B=b/": 10,
+	}, collect(10, a, b))
+	// B skips an observation.
+	require.EqualValues(t, map[string]float64{
+		"observer.sum/A=a/": 20,
+	}, collect(20, a))
+	// The full report exports the last value of B...
+	require.EqualValues(t, map[string]float64{
+		"observer.sum/A=a/": 30,
+		"observer.sum/B=b/": 10,
+	}, collect(30, a))
+	// ... which is then forgotten.
+	require.EqualValues(t, map[string]float64{}, collect(40))
+	require.EqualValues(t, map[string]float64{}, collect(50))
+	require.EqualValues(t, map[string]float64{}, collect(60))
+}
+
+func TestRecordTemporality(t *testing.T) {
+	selector := processortest.AggregatorSelector()
+	counter := metrictest.NewDescriptor("counter.sum", sdkapi.CounterInstrumentKind, number.Int64Kind)
+	observer := metrictest.NewDescriptor("observer.sum", sdkapi.CounterObserverInstrumentKind, number.Int64Kind)
+
+	b := basic.New(selector, aggregation.CumulativeTemporalitySelector())
+	b.StartCollection()
+	require.NoError(t, b.Process(updateFor(t, &counter, selector, 1)))
+	require.NoError(t, b.Process(updateFor(t, &observer, selector, 2)))
+	require.NoError(t, b.FinishCollection())
+
+	// The stateless selector chooses the temporality of each
+	// instrument, which is reported by its Record.
+	got := map[string]aggregation.Temporality{}
+	require.NoError(t, b.ForEach(aggregation.StatelessTemporalitySelector(), func(rec export.Record) error {
+		got[rec.Descriptor().Name()] = rec.Temporality()
+		return nil
+	}))
+	require.Equal(t, map[string]aggregation.Temporality{
+		"counter.sum":  aggregation.DeltaTemporality,
+		"observer.sum": aggregation.CumulativeTemporality,
+	}, got)
+}
+
+func TestInstrumentKindTemporality(t *testing.T) {
+	selector := processortest.AggregatorSelector()
+	counter := metrictest.NewDescriptor("counter.sum", sdkapi.CounterInstrumentKind, number.Int64Kind)
+
+	for _, temporality := range []aggregation.Temporality{
+		aggregation.CumulativeTemporality,
+		aggregation.DeltaTemporality,
+	} {
+		temporality := temporality
+		tempSel := aggregation.InstrumentKindTemporalitySelector(func(ikind sdkapi.InstrumentKind) aggregation.Temporality {
+			if ikind == sdkapi.CounterInstrumentKind {
+				return temporality
+			}
+			return aggregation.CumulativeTemporality
+		})
+		b := basic.New(selector, tempSel)
+
+		var got aggregation.Temporality
+		var sum float64
+		for i := 0; i < 2; i++ {
+			b.StartCollection()
+			require.NoError(t, b.Process(updateFor(t, &counter, selector, 1)))
+			require.NoError(t, b.FinishCollection())
+
+			records := processortest.NewOutput(attribute.DefaultEncoder())
+			require.NoError(t, b.ForEach(tempSel, func(rec export.Record) error {
+				got = rec.Temporality()
+				return records.AddRecord(rec)
+			}))
+			sum = records.Map()["counter.sum//"]
+		}
+
+		require.Equal(t, temporality, got)
+		if temporality == aggregation.DeltaTemporality {
+			require.Equal(t, 1.0, sum)
+		} else {
+			require.Equal(t, 2.0, sum)
+		}
+	}
+}
+
+func TestInstrumentKindTemporalityAllDelta(t *testing.T) {
+	var handled []error
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		handled = append(handled, err)
+	}))
+	defer otel.SetErrorHandler(otel.ErrorHandlerFunc(func(error) {}))
+
+	selector := processortest.AggregatorSelector()
+	counter := metrictest.NewDescriptor("counter.sum", sdkapi.CounterInstrumentKind, number.Int64Kind)
+	observer := metrictest.NewDescriptor("observer.sum", sdkapi.CounterObserverInstrumentKind, number.Int64Kind)
+
+	tempSel := aggregation.InstrumentKindTemporalitySelector(func(sdkapi.InstrumentKind) aggregation.Temporality {
+		return aggregation.DeltaTemporality
+	})
+	b := basic.New(selector, tempSel)
+
+	for i := int64(1); i <= 2; i++ {
+		b.StartCollection()
+		require.NoError(t, b.Process(updateFor(t, &counter, selector, 1)))
+		require.NoError(t, b.Process(updateFor(t, &observer, selector, 10*i)))
+		require.NoError(t, b.FinishCollection())
+
+		// The asynchronous counter is exported as a cumulative
+		// sum, instead of failing the collection.
+		records := processortest.NewOutput(attribute.DefaultEncoder())
+		temporalities := map[string]aggregation.Temporality{}
+		require.NoError(t, b.ForEach(tempSel, func(rec export.Record) error {
+			temporalities[rec.Descriptor().Name()] = rec.Temporality()
+			return records.AddRecord(rec)
+		}))
+		require.EqualValues(t, map[string]float64{
+			"counter.sum//":  1,
+			"observer.sum//": float64(10 * i),
+		}, records.Map())
+		require.Equal(t, map[string]aggregation.Temporality{
+			"counter.sum":  aggregation.DeltaTemporality,
+			"observer.sum": aggregation.CumulativeTemporality,
+		}, temporalities)
+	}
+	require.Len(t, handled, 1)
+	require.ErrorIs(t, handled[0], aggregation.ErrNoCumulativeToDelta)
+}
+
+func TestStaleGaugeEviction(t *testing.T) {
+	selector := processortest.AggregatorSelector()
+	aggTempSel := aggregation.CumulativeTemporalitySelector()
+	gauge := metrictest.NewDescriptor("observer.lastvalue", sdkapi.GaugeObserverInstrumentKind, number.Int64Kind)
+	counter := metrictest.NewDescriptor("observer.sum", sdkapi.CounterObserverInstrumentKind, number.Int64Kind)
+	a, b := attribute.String("A", "a"), attribute.String("B", "b")
+
+	for _, evict := range []bool{false, true} {
+		processor := basic.New(selector, aggTempSel, basic.WithMemory(true), basic.WithStaleGaugeEviction(evict))
+		reader := processor.Reader()
+
+		collect := func(value int64, kv attribute.KeyValue) map[string]float64 {
+			processor.StartCollection()
+			require.NoError(t, processor.Process(updateFor(t, &gauge, selector, value, kv)))
+			require.NoError(t, processor.Process(updateFor(t, &counter, selector, value, kv)))
+			require.NoError(t, processor.FinishCollection())
+
+			records := processortest.NewOutput(attribute.DefaultEncoder())
+			require.NoError(t, reader.ForEach(aggTempSel, records.AddRecord))
+			return records.Map()
+		}
+
+		require.EqualValues(t, map[string]float64{
+			"observer.lastvalue/A=a/": 10,
+			"observer.sum/A=a/":       10,
+		}, collect(10, a))
+
+		want := map[string]float64{
+			"observer.lastvalue/B=b/": 20,
+			"observer.sum/A=a/":       10,
+			"observer.sum/B=b/":       20,
+		}
+		if !evict {
+			// Memory keeps reporting the last value of set A.
+			want["observer.lastvalue/A=a/"] = 10
+		}
+		require.EqualValues(t, want, collect(20, b), "evict=%v", evict)
+	}
+}
@@ -14,6 +14,12 @@
 
 package basic // import "go.opentelemetry.io/otel/sdk/metric/processor/basic"
 
+import (
+	"time"
+
+	controllerTime "go.opentelemetry.io/otel/sdk/metric/controller/time"
+)
+
 // config contains the options for configuring a basic metric processor.
 type config struct {
 	// Memory controls whether the processor remembers metric instruments and
@@ -21,6 +27,35 @@ type config struct {
 	// Reader.ForEach() will visit metrics that were not updated in the most
 	// recent interval.
 	Memory bool
+
+	// RetainUnacknowledged controls whether the processor keeps
+	// delta aggregations until their export is acknowledged.
+	// When true, the deltas of a collection that was not
+	// acknowledged are combined with the next collection.
+	RetainUnacknowledged bool
+
+	// Clock is used to read the start and end time of collection
+	// intervals.  The default is an AnchoredClock created with
+	// the Processor, so that the timestamps it reports are
+	// ordered even when the system wall clock is adjusted.
+	Clock controllerTime.Clock
+
+	// FullReportPeriod, when positive and Memory is false, is the
+	// number of collections after which every retained
+	// cumulative aggregation is reported, including those that
+	// were not updated.
+	FullReportPeriod int
+
+	// StaleGaugeEviction controls whether the attribute sets of
+	// asynchronous gauges that were not observed in a collection
+	// are removed.
+	StaleGaugeEviction bool
+
+	// StartTime, if not zero, is the start time of the
+	// cumulative aggregations of precomputed sums and of the
+	// first collection interval.  The default is the time the
+	// Processor is created.
+	StartTime time.Time
 }
 
 // Option configures a basic processor configuration.
@@ -41,3 +76,84 @@ func (m memoryOption) applyProcessor(cfg config) config {
 	cfg.Memory = bool(m)
 	return cfg
 }
+
+// WithRetainUnacknowledged sets whether a Processor retains the delta
+// aggregations it reports until their export is acknowledged, see
+// export.Acknowledger.  If an export fails, the deltas are not lost: they
+// are combined with those of the next collection, and the reported
+// interval starts at the end of the last acknowledged one.  This has no
+// effect on cumulative aggregations.
+func WithRetainUnacknowledged(retain bool) Option {
+	return retainUnacknowledgedOption(retain)
+}
+
+type retainUnacknowledgedOption bool
+
+func (o retainUnacknowledgedOption) applyProcessor(cfg config) config {
+	cfg.RetainUnacknowledged = bool(o)
+	return cfg
+}
+
+// WithClock sets the Clock used by a Processor to timestamp the start and
+// end of collection intervals.
+func WithClock(clock controllerTime.Clock) Option {
+	return clockOption{clock}
+}
+
+type clockOption struct{ controllerTime.Clock }
+
+func (o clockOption) applyProcessor(cfg config) config {
+	cfg.Clock = o.Clock
+	return cfg
+}
+
+// WithFullReportPeriod sets the number of collections after which a
+// Processor without Memory reports all its cumulative aggregations.  In
+// the other collections, only the aggregations updated since the prior
+// collection are reported, which keeps frequent collections cheap while
+// periodically refreshing unchanged series in the backend.  The last
+// value of an asynchronous counter that is no longer observed is kept
+// until the next full report.
+func WithFullReportPeriod(collections int) Option {
+	return fullReportPeriodOption(collections)
+}
+
+type fullReportPeriodOption int
+
+func (o fullReportPeriodOption) applyProcessor(cfg config) config {
+	cfg.FullReportPeriod = int(o)
+	return cfg
+}
+
+// WithStaleGaugeEviction sets whether a Processor removes the attribute
+// sets of asynchronous gauges that were not observed in a collection, so
+// that their last value is no longer reported, even with Memory or a full
+// report.  Asynchronous counters are not affected: an attribute set that
+// is not observed keeps its cumulative value.
+func WithStaleGaugeEviction(evict bool) Option {
+	return staleGaugeEvictionOption(evict)
+}
+
+type staleGaugeEvictionOption bool
+
+func (o staleGaugeEvictionOption) applyProcessor(cfg config) config {
+	cfg.StaleGaugeEviction = bool(o)
+	return cfg
+}
+
+// WithStartTime sets the start time reported by a Processor for the
+// cumulative values of asynchronous counters, which are computed by their
+// source, e.g., since the start of the process.  It is also the start of
+// the first collection interval.  The cumulative sums computed by the
+// Processor start with the interval in which their attribute set was
+// first processed, and keep that start time in later collections.
+func WithStartTime(start time.Time) Option {
+	return startTimeOption(start)
+}
+
+type startTimeOption time.Time
+
+func (o startTimeOption) applyProcessor(cfg config) config {
+	cfg.StartTime = time.Time(o)
+	return cfg
+}
@@ -0,0 +1,326 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinality // import "go.opentelemetry.io/otel/sdk/metric/processor/cardinality"
+
+import (
+	"math"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+// OverflowKey is the attribute of the series into which the series beyond
+// the limit of an instrument are merged.  It is the only attribute of
+// that series.  The Accumulator uses the same attribute for the overflow
+// series of its cardinality limit, see metric.WithCardinalityLimit, so
+// that both are exported as a single series.
+const OverflowKey = attribute.Key("otel.metric.overflow")
+
+// Policy decides which series of an instrument are kept when the
+// instrument has more series than the limit in a collection.
+type Policy int
+
+const (
+	// KeepFirst keeps the series that were kept in the previous
+	// collection, then admits new series in the order they are
+	// processed until the limit is reached.  A series that is not
+	// updated in a collection gives up its place.
+	KeepFirst Policy = iota
+
+	// TopK keeps the series with the highest values in each
+	// collection.  The value of a series is its sum for Sum and
+	// Histogram aggregations, and its last value for LastValue
+	// aggregations.  The kept series are chosen again in each
+	// collection, so a series can alternate between being kept and
+	// being merged into the overflow series.
+	TopK
+)
+
+type (
+	// Processor limits the number of series of each instrument
+	// that are passed to the next stage in an export pipeline.
+	Processor struct {
+		export.Checkpointer
+		limit     int
+		policy    Policy
+		aselector export.AggregatorSelector
+		config    config
+
+		// instruments holds the state of each instrument
+		// processed during the current collection.
+		instruments map[*sdkapi.Descriptor]*instrumentState
+	}
+
+	instrumentState struct {
+		// pending are the accumulations of the current
+		// collection, passed on in FinishCollection.
+		pending []export.Accumulation
+
+		// kept are the series kept by the KeepFirst policy,
+		// with whether they were processed during the current
+		// collection.
+		kept map[attribute.Distinct]bool
+	}
+)
+
+var _ export.Processor = &Processor{}
+var _ export.Checkpointer = &Processor{}
+var _ export.Acknowledger = &Processor{}
+
+var overflowAttributes = attribute.NewSet(OverflowKey.Bool(true))
+
+// New returns a Processor that passes at most limit series of each
+// instrument per collection to ckpter, plus one overflow series, chosen
+// by policy.  The aselector must be the AggregatorSelector of the export
+// pipeline; it provides the aggregators of the overflow series.  A
+// negative limit is treated as zero, merging every series into the
+// overflow series.
+func New(limit int, policy Policy, aselector export.AggregatorSelector, ckpter export.Checkpointer, opts ...Option) *Processor {
+	if limit < 0 {
+		limit = 0
+	}
+	var cfg config
+	for _, opt := range opts {
+		cfg = opt.apply(cfg)
+	}
+	return &Processor{
+		Checkpointer: ckpter,
+		limit:        limit,
+		policy:       policy,
+		aselector:    aselector,
+		config:       cfg,
+		instruments:  map[*sdkapi.Descriptor]*instrumentState{},
+	}
+}
+
+type factory struct {
+	limit     int
+	policy    Policy
+	aselector export.AggregatorSelector
+	next      export.CheckpointerFactory
+	opts      []Option
+}
+
+// NewFactory returns a CheckpointerFactory of Processors that pass their
+// data to Checkpointers of the next factory.  See New.
+func NewFactory(limit int, policy Policy, aselector export.AggregatorSelector, next export.CheckpointerFactory, opts ...Option) export.CheckpointerFactory {
+	return factory{
+		limit:     limit,
+		policy:    policy,
+		aselector: aselector,
+		next:      next,
+		opts:      opts,
+	}
+}
+
+var _ export.CheckpointerFactory = factory{}
+
+func (f factory) NewCheckpointer() export.Checkpointer {
+	return New(f.limit, f.policy, f.aselector, f.next.NewCheckpointer(), f.opts...)
+}
+
+// Process implements export.Processor.  The accumulation is buffered
+// until FinishCollection, so its aggregator must not be modified before
+// then, which the Accumulator guarantees.
+func (p *Processor) Process(accum export.Accumulation) error {
+	inst, ok := p.instruments[accum.Descriptor()]
+	if !ok {
+		inst = &instrumentState{}
+		p.instruments[accum.Descriptor()] = inst
+	}
+	inst.pending = append(inst.pending, accum)
+	return nil
+}
+
+// FinishCollection implements export.Checkpointer.  The series of each
+// instrument are chosen and passed to the next Checkpointer, before it
+// finishes its collection.  The state of instruments that were not
+// processed during the collection is forgotten.
+func (p *Processor) FinishCollection() error {
+	var firstErr error
+	for desc, inst := range p.instruments {
+		if len(inst.pending) == 0 {
+			delete(p.instruments, desc)
+			continue
+		}
+		if err := p.flush(desc, inst); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := p.Checkpointer.FinishCollection(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// flush passes the pending accumulations of an instrument on, merging
+// those beyond the limit into the overflow series.
+func (p *Processor) flush(desc *sdkapi.Descriptor, inst *instrumentState) error {
+	defer func() {
+		// Release the aggregators of the Accumulator.
+		for i := range inst.pending {
+			inst.pending[i] = export.Accumulation{}
+		}
+		inst.pending = inst.pending[:0]
+	}()
+
+	var overflow []export.Accumulation
+	switch p.policy {
+	case TopK:
+		overflow = p.topK(desc, inst)
+	default:
+		overflow = p.keepFirst(inst)
+	}
+
+	var firstErr error
+	for _, accum := range inst.pending {
+		if err := p.Checkpointer.Process(accum); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if len(overflow) == 0 {
+		return firstErr
+	}
+
+	var agg aggregator.Aggregator
+	p.aselector.AggregatorFor(desc, &agg)
+	if agg == nil {
+		return firstErr
+	}
+	for _, accum := range overflow {
+		if err := agg.Merge(accum.Aggregator(), desc); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if p.config.OverflowRecorder != nil {
+			p.config.OverflowRecorder.record(desc, accum.Attributes())
+		}
+	}
+	if err := p.Checkpointer.Process(export.NewAccumulation(desc, &overflowAttributes, agg)); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// topK moves the accumulations of the series with the highest values to
+// the front of the pending accumulations, truncates them to the limit and
+// returns the others.
+func (p *Processor) topK(desc *sdkapi.Descriptor, inst *instrumentState) []export.Accumulation {
+	if len(inst.pending) <= p.limit {
+		return nil
+	}
+	values := make([]float64, len(inst.pending))
+	for i, accum := range inst.pending {
+		values[i] = value(desc, accum.Aggregator().Aggregation())
+	}
+	sort.Stable(byValue{accums: inst.pending, values: values})
+
+	overflow := append([]export.Accumulation(nil), inst.pending[p.limit:]...)
+	for i := p.limit; i < len(inst.pending); i++ {
+		inst.pending[i] = export.Accumulation{}
+	}
+	inst.pending = inst.pending[:p.limit]
+	return overflow
+}
+
+// keepFirst keeps the accumulations of the previously kept series, then
+// of new series while there is room, and returns the others.
+func (p *Processor) keepFirst(inst *instrumentState) []export.Accumulation {
+	if inst.kept == nil {
+		inst.kept = map[attribute.Distinct]bool{}
+	}
+	var kept, overflow []export.Accumulation
+	var added []export.Accumulation
+	for _, accum := range inst.pending {
+		key := accum.Attributes().Equivalent()
+		if _, ok := inst.kept[key]; ok {
+			inst.kept[key] = true
+			kept = append(kept, accum)
+			continue
+		}
+		added = append(added, accum)
+	}
+	for _, accum := range added {
+		if len(kept) < p.limit {
+			inst.kept[accum.Attributes().Equivalent()] = true
+			kept = append(kept, accum)
+			continue
+		}
+		overflow = append(overflow, accum)
+	}
+	for key, used := range inst.kept {
+		if !used {
+			delete(inst.kept, key)
+			continue
+		}
+		inst.kept[key] = false
+	}
+	copy(inst.pending, kept)
+	for i := len(kept); i < len(inst.pending); i++ {
+		inst.pending[i] = export.Accumulation{}
+	}
+	inst.pending = inst.pending[:len(kept)]
+	return overflow
+}
+
+// value returns the value by which the TopK policy ranks a series.
+func value(desc *sdkapi.Descriptor, agg aggregation.Aggregation) float64 {
+	var n number.Number
+	var err error
+	switch a := agg.(type) {
+	case aggregation.LastValue:
+		n, _, err = a.LastValue()
+	case aggregation.Sum:
+		n, err = a.Sum()
+	default:
+		return math.NaN()
+	}
+	if err != nil {
+		return math.NaN()
+	}
+	return n.CoerceToFloat64(desc.NumberKind())
+}
+
+// byValue sorts accumulations by decreasing value, with NaN values last.
+type byValue struct {
+	accums []export.Accumulation
+	values []float64
+}
+
+func (b byValue) Len() int { return len(b.accums) }
+
+func (b byValue) Less(i, j int) bool {
+	if math.IsNaN(b.values[j]) {
+		return !math.IsNaN(b.values[i])
+	}
+	return b.values[i] > b.values[j]
+}
+
+func (b byValue) Swap(i, j int) {
+	b.accums[i], b.accums[j] = b.accums[j], b.accums[i]
+	b.values[i], b.values[j] = b.values[j], b.values[i]
+}
+
+// Acknowledge implements export.Acknowledger by acknowledging the next
+// Checkpointer, if it supports acknowledgement.
+func (p *Processor) Acknowledge() {
+	if a, ok := p.Checkpointer.(export.Acknowledger); ok {
+		a.Acknowledge()
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinality_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/instrument"
+	metricsdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/processor/cardinality"
+	"go.opentelemetry.io/otel/sdk/metric/processor/processortest"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+type testPipeline struct {
+	output  *processortest.Processor
+	proc    *cardinality.Processor
+	accum   *metricsdk.Accumulator
+	counter interface {
+		Add(context.Context, int64, ...attribute.KeyValue)
+	}
+}
+
+func newPipeline(t *testing.T, limit int, policy cardinality.Policy, opts ...cardinality.Option) *testPipeline {
+	output := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	proc := cardinality.New(limit, policy, processortest.AggregatorSelector(), processortest.NewCheckpointer(output), opts...)
+	accum := metricsdk.NewAccumulator(proc)
+	counter, err := sdkapi.WrapMeterImpl(accum).SyncInt64().Counter("counter.sum")
+	require.NoError(t, err)
+	return &testPipeline{
+		output:  output,
+		proc:    proc,
+		accum:   accum,
+		counter: counter,
+	}
+}
+
+func (p *testPipeline) collect(t *testing.T) map[string]float64 {
+	p.output.Reset()
+	p.proc.StartCollection()
+	p.accum.Collect(context.Background())
+	require.NoError(t, p.proc.FinishCollection())
+	return p.output.Values()
+}
+
+func TestTopK(t *testing.T) {
+	ctx := context.Background()
+	p := newPipeline(t, 2, cardinality.TopK)
+
+	for i := int64(1); i <= 5; i++ {
+		p.counter.Add(ctx, i, attribute.Int64("I", i))
+	}
+	require.EqualValues(t, map[string]float64{
+		"counter.sum/I=5/":                       5,
+		"counter.sum/I=4/":                       4,
+		"counter.sum/otel.metric.overflow=true/": 6,
+	}, p.collect(t))
+
+	// The kept series are chosen again in each collection.
+	p.counter.Add(ctx, 10, attribute.Int64("I", 1))
+	p.counter.Add(ctx, 1, attribute.Int64("I", 4))
+	p.counter.Add(ctx, 1, attribute.Int64("I", 5))
+	p.counter.Add(ctx, 2, attribute.Int64("I", 6))
+	require.EqualValues(t, map[string]float64{
+		"counter.sum/I=1/":                       10,
+		"counter.sum/I=6/":                       2,
+		"counter.sum/otel.metric.overflow=true/": 2,
+	}, p.collect(t))
+
+	// Within the limit, every series is kept.
+	p.counter.Add(ctx, 1, attribute.Int64("I", 1))
+	p.counter.Add(ctx, 1, attribute.Int64("I", 2))
+	require.EqualValues(t, map[string]float64{
+		"counter.sum/I=1/": 1,
+		"counter.sum/I=2/": 1,
+	}, p.collect(t))
+}
+
+func TestNegativeLimit(t *testing.T) {
+	ctx := context.Background()
+	for _, policy := range []cardinality.Policy{cardinality.TopK, cardinality.KeepFirst} {
+		p := newPipeline(t, -1, policy)
+
+		p.counter.Add(ctx, 1, attribute.Int64("I", 1))
+		p.counter.Add(ctx, 2, attribute.Int64("I", 2))
+		require.EqualValues(t, map[string]float64{
+			"counter.sum/otel.metric.overflow=true/": 3,
+		}, p.collect(t))
+	}
+}
+
+func TestKeepFirst(t *testing.T) {
+	ctx := context.Background()
+	p := newPipeline(t, 2, cardinality.KeepFirst)
+
+	p.counter.Add(ctx, 1, attribute.Int64("I", 1))
+	p.counter.Add(ctx, 2, attribute.Int64("I", 2))
+	require.EqualValues(t, map[string]float64{
+		"counter.sum/I=1/": 1,
+		"counter.sum/I=2/": 2,
+	}, p.collect(t))
+
+	// New series beyond the limit overflow, whatever their value.
+	p.counter.Add(ctx, 1, attribute.Int64("I", 1))
+	p.counter.Add(ctx, 1, attribute.Int64("I", 2))
+	p.counter.Add(ctx, 100, attribute.Int64("I", 3))
+	p.counter.Add(ctx, 200, attribute.Int64("I", 4))
+	require.EqualValues(t, map[string]float64{
+		"counter.sum/I=1/":                       1,
+		"counter.sum/I=2/":                       1,
+		"counter.sum/otel.metric.overflow=true/": 300,
+	}, p.collect(t))
+
+	// A series that is not updated gives up its place.
+	p.counter.Add(ctx, 1, attribute.Int64("I", 1))
+	p.counter.Add(ctx, 5, attribute.Int64("I", 3))
+	require.EqualValues(t, map[string]float64{
+		"counter.sum/I=1/": 1,
+		"counter.sum/I=3/": 5,
+	}, p.collect(t))
+	p.counter.Add(ctx, 1, attribute.Int64("I", 1))
+	p.counter.Add(ctx, 1, attribute.Int64("I", 2))
+	p.counter.Add(ctx, 1, attribute.Int64("I", 3))
+	require.EqualValues(t, map[string]float64{
+		"counter.sum/I=1/":                       1,
+		"counter.sum/I=3/":                       1,
+		"counter.sum/otel.metric.overflow=true/": 1,
+	}, p.collect(t))
+}
+
+func TestTopKLastValue(t *testing.T) {
+	output := processortest.NewProcessor(
+		processortest.AggregatorSelector(),
+		attribute.DefaultEncoder(),
+	)
+	proc := cardinality.New(1, cardinality.TopK, processortest.AggregatorSelector(), processortest.NewCheckpointer(output))
+	accum := metricsdk.NewAccumulator(proc)
+	meter := sdkapi.WrapMeterImpl(accum)
+
+	gauge, err := meter.AsyncFloat64().Gauge("gauge.lastvalue")
+	require.NoError(t, err)
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{gauge}, func(ctx context.Context) error {
+		gauge.Observe(ctx, -1, attribute.String("A", "a"))
+		gauge.Observe(ctx, 3, attribute.String("A", "b"))
+		return nil
+	})
+	require.NoError(t, err)
+
+	proc.StartCollection()
+	accum.Collect(context.Background())
+	require.NoError(t, proc.FinishCollection())
+	require.EqualValues(t, map[string]float64{
+		"gauge.lastvalue/A=b/":                       3,
+		"gauge.lastvalue/otel.metric.overflow=true/": -1,
+	}, output.Values())
+}
+
+func TestOverflowRecorder(t *testing.T) {
+	ctx := context.Background()
+	recorder := cardinality.NewOverflowRecorder(2)
+	p := newPipeline(t, 1, cardinality.TopK, cardinality.WithOverflowRecorder(recorder))
+
+	for round := 1; round <= 2; round++ {
+		for i := int64(1); i <= 4; i++ {
+			p.counter.Add(ctx, i, attribute.Int64("I", i))
+		}
+		require.EqualValues(t, map[string]float64{
+			"counter.sum/I=4/":                       4,
+			"counter.sum/otel.metric.overflow=true/": 6,
+		}, p.collect(t))
+	}
+
+	// The recorder holds the first two merged series, in
+	// decreasing order of value, and counts the third.
+	series, dropped := recorder.Flush()
+	require.Len(t, series, 2)
+	for i, s := range series {
+		require.Equal(t, "counter.sum", s.Descriptor.Name())
+		require.Equal(t, attribute.NewSet(attribute.Int64("I", int64(3-i))), s.Attributes)
+		require.Equal(t, 2, s.Collections)
+	}
+	require.Equal(t, 2, dropped)
+
+	// Flushing empties the recorder.
+	series, dropped = recorder.Flush()
+	require.Empty(t, series)
+	require.Zero(t, dropped)
+}
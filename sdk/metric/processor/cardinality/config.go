@@ -0,0 +1,43 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinality // import "go.opentelemetry.io/otel/sdk/metric/processor/cardinality"
+
+// config contains the options of a Processor.
+type config struct {
+	// OverflowRecorder, if not nil, records the series merged
+	// into the overflow series.
+	OverflowRecorder *OverflowRecorder
+}
+
+// Option configures a Processor.
+type Option interface {
+	apply(config) config
+}
+
+// WithOverflowRecorder records the attribute sets of the series merged
+// into the overflow series in recorder.  The recorder is bounded and is
+// flushed separately from the export pipeline.
+func WithOverflowRecorder(recorder *OverflowRecorder) Option {
+	return overflowRecorderOption{recorder}
+}
+
+type overflowRecorderOption struct {
+	recorder *OverflowRecorder
+}
+
+func (o overflowRecorderOption) apply(cfg config) config {
+	cfg.OverflowRecorder = o.recorder
+	return cfg
+}
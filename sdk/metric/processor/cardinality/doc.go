@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package cardinality implements a metrics Processor component that limits
+the number of series of each instrument.
+
+This package is currently in a pre-GA phase. Backwards incompatible changes
+may be introduced in subsequent minor version releases as we work to track the
+evolving OpenTelemetry specification and user feedback.
+
+The Processor buffers the accumulations of a collection and, when the
+collection finishes, passes at most a fixed number of series of each
+instrument to the next Processor.  The remaining series are merged into a
+single overflow series, marked by the OverflowKey attribute, so that the
+totals of the instrument are preserved while its memory is bounded.
+
+The Policy of the Processor decides which series are kept.  KeepFirst keeps
+the series that were kept before, for as long as they are updated, while
+TopK keeps the series with the highest values of each collection, so that
+the biggest contributors stay visible.
+
+The attribute sets of the merged series are not exported.  To find which
+dimensions cause the overflow, an OverflowRecorder passed with
+WithOverflowRecorder keeps a bounded number of them until it is flushed.
+
+For example, to keep the 100 biggest series of each instrument with a push
+controller and a basic metric processor:
+
+	selector := simple.NewWithHistogramDistribution()
+	controller := controller.New(
+	        cardinality.NewFactory(
+	                100,
+	                cardinality.TopK,
+	                selector,
+	                basic.NewFactory(selector, aggregation.CumulativeTemporalitySelector()),
+	        ),
+	        controller.WithExporter(exporter),
+	)
+*/
+package cardinality // import "go.opentelemetry.io/otel/sdk/metric/processor/cardinality"
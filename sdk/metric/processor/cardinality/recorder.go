@@ -0,0 +1,97 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinality // import "go.opentelemetry.io/otel/sdk/metric/processor/cardinality"
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+// OverflowRecorder records the attribute sets of the series that
+// Processors merge into their overflow series, for offline cardinality
+// analysis.  It holds a bounded number of distinct series until it is
+// flushed, and is not part of the exported data.  An OverflowRecorder is
+// safe for concurrent use, and can be shared by several Processors.
+type OverflowRecorder struct {
+	lock     sync.Mutex
+	capacity int
+	series   map[overflowKey]*OverflowedSeries
+	order    []overflowKey
+	dropped  int
+}
+
+type overflowKey struct {
+	descriptor *sdkapi.Descriptor
+	distinct   attribute.Distinct
+}
+
+// OverflowedSeries is a series that was merged into an overflow series.
+type OverflowedSeries struct {
+	Descriptor sdkapi.Descriptor
+	Attributes attribute.Set
+
+	// Collections is the number of collections, since the last
+	// flush, in which the series was merged.
+	Collections int
+}
+
+// NewOverflowRecorder returns an OverflowRecorder that holds at most
+// capacity distinct series between flushes.
+func NewOverflowRecorder(capacity int) *OverflowRecorder {
+	return &OverflowRecorder{
+		capacity: capacity,
+		series:   map[overflowKey]*OverflowedSeries{},
+	}
+}
+
+// record records that the series of desc with attrs was merged.
+func (r *OverflowRecorder) record(desc *sdkapi.Descriptor, attrs *attribute.Set) {
+	key := overflowKey{descriptor: desc, distinct: attrs.Equivalent()}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if s, ok := r.series[key]; ok {
+		s.Collections++
+		return
+	}
+	if len(r.order) >= r.capacity {
+		r.dropped++
+		return
+	}
+	r.series[key] = &OverflowedSeries{
+		Descriptor:  *desc,
+		Attributes:  *attrs,
+		Collections: 1,
+	}
+	r.order = append(r.order, key)
+}
+
+// Flush returns the series recorded since the last flush, in the order
+// they were first merged, and the number of merges that were not
+// recorded because the recorder was full, then empties the recorder.
+func (r *OverflowRecorder) Flush() (series []OverflowedSeries, dropped int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	series = make([]OverflowedSeries, len(r.order))
+	for i, key := range r.order {
+		series[i] = *r.series[key]
+	}
+	dropped = r.dropped
+	r.series = map[overflowKey]*OverflowedSeries{}
+	r.order = nil
+	r.dropped = 0
+	return series, dropped
+}
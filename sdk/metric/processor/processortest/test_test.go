@@ -45,9 +45,10 @@ func generateTestData(t *testing.T, proc export.Processor) {
 	counterObserver, err := meter.AsyncInt64().Counter("observer.sum")
 	require.NoError(t, err)
 
-	err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) {
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) error {
 		counterObserver.Observe(ctx, 10, attribute.String("K1", "V1"))
 		counterObserver.Observe(ctx, 11, attribute.String("K1", "V2"))
+		return nil
 	})
 	require.NoError(t, err)
 
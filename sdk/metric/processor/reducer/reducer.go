@@ -26,6 +26,27 @@ type (
 	Processor struct {
 		export.Checkpointer
 		filterSelector AttributeFilterSelector
+
+		// reduced caches the reduced attribute set of each
+		// input series, so that the filter is computed once
+		// per series rather than once per collection.
+		reduced map[reducedKey]*reducedValue
+	}
+
+	// reducedKey identifies an input series.
+	reducedKey struct {
+		descriptor *sdkapi.Descriptor
+		distinct   attribute.Distinct
+	}
+
+	// reducedValue is the reduced attribute set of an input
+	// series.
+	reducedValue struct {
+		attrs attribute.Set
+
+		// used indicates the series was processed during
+		// the current collection.
+		used bool
 	}
 
 	// AttributeFilterSelector selects an attribute filter based on the
@@ -37,6 +58,7 @@ type (
 
 var _ export.Processor = &Processor{}
 var _ export.Checkpointer = &Processor{}
+var _ export.Acknowledger = &Processor{}
 
 // New returns a dimensionality-reducing Processor that passes data to the
 // next stage in an export pipeline.
@@ -44,23 +66,57 @@ func New(filterSelector AttributeFilterSelector, ckpter export.Checkpointer) *Pr
 	return &Processor{
 		Checkpointer:   ckpter,
 		filterSelector: filterSelector,
+		reduced:        map[reducedKey]*reducedValue{},
 	}
 }
 
 // Process implements export.Processor.
 func (p *Processor) Process(accum export.Accumulation) error {
-	// Note: the removed attributes are returned and ignored here.
-	// Conceivably these inputs could be useful to a sampler.
-	reduced, _ := accum.Attributes().Filter(
-		p.filterSelector.AttributeFilterFor(
-			accum.Descriptor(),
-		),
-	)
+	key := reducedKey{
+		descriptor: accum.Descriptor(),
+		distinct:   accum.Attributes().Equivalent(),
+	}
+	value, ok := p.reduced[key]
+	if !ok {
+		// Note: the removed attributes are returned and ignored here.
+		// Conceivably these inputs could be useful to a sampler.
+		reduced, _ := accum.Attributes().Filter(
+			p.filterSelector.AttributeFilterFor(
+				accum.Descriptor(),
+			),
+		)
+		value = &reducedValue{attrs: reduced}
+		p.reduced[key] = value
+	}
+	value.used = true
+
 	return p.Checkpointer.Process(
 		export.NewAccumulation(
 			accum.Descriptor(),
-			&reduced,
+			&value.attrs,
 			accum.Aggregator(),
 		),
 	)
 }
+
+// FinishCollection implements export.Checkpointer.  Cached reductions
+// of series that were not processed during the collection are
+// forgotten.
+func (p *Processor) FinishCollection() error {
+	for key, value := range p.reduced {
+		if !value.used {
+			delete(p.reduced, key)
+			continue
+		}
+		value.used = false
+	}
+	return p.Checkpointer.FinishCollection()
+}
+
+// Acknowledge implements export.Acknowledger by acknowledging the next
+// Checkpointer, if it supports acknowledgement.
+func (p *Processor) Acknowledge() {
+	if a, ok := p.Checkpointer.(export.Acknowledger); ok {
+		a.Acknowledge()
+	}
+}
@@ -64,9 +64,10 @@ func generateData(t *testing.T, impl sdkapi.MeterImpl) {
 
 	counterObserver, err := meter.AsyncInt64().Counter("observer.sum")
 	require.NoError(t, err)
-	err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) {
+	_, err = meter.RegisterCallback([]instrument.Asynchronous{counterObserver}, func(ctx context.Context) error {
 		counterObserver.Observe(ctx, 10, kvs1...)
 		counterObserver.Observe(ctx, 10, kvs2...)
+		return nil
 	})
 	require.NoError(t, err)
 }
@@ -115,3 +116,53 @@ func TestFilterBasicProcessor(t *testing.T) {
 		"observer.sum/A=1,C=3/R=V": 20,
 	}, exporter.Values())
 }
+
+type countingFilter struct {
+	testFilter
+	calls int
+}
+
+func (f *countingFilter) AttributeFilterFor(desc *sdkapi.Descriptor) attribute.Filter {
+	f.calls++
+	return f.testFilter.AttributeFilterFor(desc)
+}
+
+func TestFilterComputedOncePerSeries(t *testing.T) {
+	filter := &countingFilter{}
+	basicProc := basic.New(processortest.AggregatorSelector(), aggregation.CumulativeTemporalitySelector())
+	reducerProc := reducer.New(filter, basicProc)
+	accum := metricsdk.NewAccumulator(reducerProc)
+	meter := sdkapi.WrapMeterImpl(accum)
+
+	counter, err := meter.SyncFloat64().Counter("counter.sum")
+	require.NoError(t, err)
+
+	collect := func() {
+		reducerProc.StartCollection()
+		accum.Collect(context.Background())
+		require.NoError(t, reducerProc.FinishCollection())
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		counter.Add(ctx, 100, kvs1...)
+		counter.Add(ctx, 100, kvs2...)
+		collect()
+	}
+	// One computation per input series.
+	require.Equal(t, 2, filter.calls)
+
+	// An idle collection forgets the cached series.
+	collect()
+	counter.Add(ctx, 100, kvs1...)
+	collect()
+	require.Equal(t, 3, filter.calls)
+
+	exporter := processortest.New(basicProc, attribute.DefaultEncoder())
+	require.NoError(t, exporter.Export(ctx, resource.Empty(), processortest.OneInstrumentationLibraryReader(instrumentation.Library{
+		Name: "test",
+	}, basicProc.Reader())))
+	require.EqualValues(t, map[string]float64{
+		"counter.sum/A=1,C=3/": 700,
+	}, exporter.Values())
+}
@@ -15,10 +15,11 @@
 package registry // import "go.opentelemetry.io/otel/sdk/metric/registry"
 
 import (
-	"context"
 	"fmt"
 	"sync"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/instrument"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
 )
@@ -26,25 +27,80 @@ import (
 // UniqueInstrumentMeterImpl implements the metric.MeterImpl interface, adding
 // uniqueness checking for instrument descriptors.
 type UniqueInstrumentMeterImpl struct {
-	lock  sync.Mutex
-	impl  sdkapi.MeterImpl
-	state map[string]sdkapi.InstrumentImpl
+	lock     sync.Mutex
+	impl     sdkapi.MeterImpl
+	state    map[string]sdkapi.InstrumentImpl
+	listener func(MetadataEvent)
+
+	// refs counts the times each instrument of state was
+	// returned, less the times it was discarded, see
+	// DiscardInstrument.
+	refs map[string]int
+}
+
+// MetadataEvent describes the registration of instrument metadata with a
+// UniqueInstrumentMeterImpl, see WithMetadataListener.
+type MetadataEvent struct {
+	// Registered is the descriptor of the registered instrument.
+	Registered sdkapi.Descriptor
+
+	// Requested is the descriptor passed by the caller.  For a
+	// new instrument it equals Registered.  Otherwise, the
+	// instrument was already registered and its description or
+	// unit differ from the requested ones.
+	Requested sdkapi.Descriptor
+}
+
+// Option configures a UniqueInstrumentMeterImpl.
+type Option interface {
+	apply(*UniqueInstrumentMeterImpl)
 }
 
-var _ sdkapi.MeterImpl = (*UniqueInstrumentMeterImpl)(nil)
+type listenerOption func(MetadataEvent)
+
+func (o listenerOption) apply(u *UniqueInstrumentMeterImpl) {
+	u.listener = o
+}
+
+// WithMetadataListener sets a function that is called when an instrument
+// is registered and when an instrument that is already registered is
+// requested with a different description or unit.  The registered
+// metadata is not changed in the latter case.  This allows an external
+// catalog of metrics to be kept in sync.  The function is called
+// synchronously and must not create instruments.
+func WithMetadataListener(f func(MetadataEvent)) Option {
+	return listenerOption(f)
+}
+
+var (
+	_ sdkapi.MeterImpl           = (*UniqueInstrumentMeterImpl)(nil)
+	_ sdkapi.InstrumentDiscarder = (*UniqueInstrumentMeterImpl)(nil)
+)
 
 // ErrMetricKindMismatch is the standard error for mismatched metric
 // instrument definitions.
 var ErrMetricKindMismatch = fmt.Errorf(
 	"a metric was already registered by this name with another kind or number type")
 
+// ErrMetadataConflict is the error handled when an instrument is
+// requested with the name of a compatible instrument but another
+// description or unit.  The registered instrument is returned with its
+// original metadata.
+var ErrMetadataConflict = fmt.Errorf(
+	"a metric was already registered by this name with another description or unit")
+
 // NewUniqueInstrumentMeterImpl returns a wrapped metric.MeterImpl
 // with the addition of instrument name uniqueness checking.
-func NewUniqueInstrumentMeterImpl(impl sdkapi.MeterImpl) *UniqueInstrumentMeterImpl {
-	return &UniqueInstrumentMeterImpl{
+func NewUniqueInstrumentMeterImpl(impl sdkapi.MeterImpl, opts ...Option) *UniqueInstrumentMeterImpl {
+	u := &UniqueInstrumentMeterImpl{
 		impl:  impl,
 		state: map[string]sdkapi.InstrumentImpl{},
+		refs:  map[string]int{},
 	}
+	for _, opt := range opts {
+		opt.apply(u)
+	}
+	return u
 }
 
 // MeterImpl gives the caller access to the underlying MeterImpl
@@ -63,6 +119,26 @@ func NewMetricKindMismatchError(desc sdkapi.Descriptor) error {
 		ErrMetricKindMismatch)
 }
 
+// newConflictError formats an error that describes both the registered
+// and the requested definition of a metric instrument.
+func newConflictError(registered, requested sdkapi.Descriptor, err error) error {
+	return fmt.Errorf("metric %s registered as %s, requested as %s: %w",
+		registered.Name(),
+		describe(registered),
+		describe(requested),
+		err)
+}
+
+// describe formats the fields of a descriptor that must match for two
+// registrations of an instrument to be identical.
+func describe(desc sdkapi.Descriptor) string {
+	return fmt.Sprintf("%s %s (unit %q, description %q)",
+		desc.NumberKind(),
+		desc.InstrumentKind(),
+		desc.Unit(),
+		desc.Description())
+}
+
 // Compatible determines whether two sdkapi.Descriptors are considered
 // the same for the purpose of uniqueness checking.
 func Compatible(candidate, existing sdkapi.Descriptor) bool {
@@ -70,11 +146,26 @@ func Compatible(candidate, existing sdkapi.Descriptor) bool {
 		candidate.NumberKind() == existing.NumberKind()
 }
 
+// Lookup returns the descriptor of the instrument registered with name,
+// if any.
+func (u *UniqueInstrumentMeterImpl) Lookup(name string) (sdkapi.Descriptor, bool) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	impl, ok := u.state[name]
+	if !ok {
+		return sdkapi.Descriptor{}, false
+	}
+	return impl.Descriptor(), true
+}
+
 // checkUniqueness returns an ErrMetricKindMismatch error if there is
 // a conflict between a descriptor that was already registered and the
 // `descriptor` argument.  If there is an existing compatible
-// registration, this returns the already-registered instrument.  If
-// there is no conflict and no prior registration, returns (nil, nil).
+// registration, this returns the already-registered instrument, after
+// handling an ErrMetadataConflict error if its description or unit
+// differ.  If there is no conflict and no prior registration, returns
+// (nil, nil).
 func (u *UniqueInstrumentMeterImpl) checkUniqueness(descriptor sdkapi.Descriptor) (sdkapi.InstrumentImpl, error) {
 	impl, ok := u.state[descriptor.Name()]
 	if !ok {
@@ -82,12 +173,26 @@ func (u *UniqueInstrumentMeterImpl) checkUniqueness(descriptor sdkapi.Descriptor
 	}
 
 	if !Compatible(descriptor, impl.Descriptor()) {
-		return nil, NewMetricKindMismatchError(impl.Descriptor())
+		return nil, newConflictError(impl.Descriptor(), descriptor, ErrMetricKindMismatch)
 	}
 
+	if registered := impl.Descriptor(); registered.Description() != descriptor.Description() || registered.Unit() != descriptor.Unit() {
+		otel.Handle(newConflictError(registered, descriptor, ErrMetadataConflict))
+		u.notify(registered, descriptor)
+	}
 	return impl, nil
 }
 
+// notify calls the metadata listener, if any.
+func (u *UniqueInstrumentMeterImpl) notify(registered, requested sdkapi.Descriptor) {
+	if u.listener != nil {
+		u.listener(MetadataEvent{
+			Registered: registered,
+			Requested:  requested,
+		})
+	}
+}
+
 // NewSyncInstrument implements sdkapi.MeterImpl.
 func (u *UniqueInstrumentMeterImpl) NewSyncInstrument(descriptor sdkapi.Descriptor) (sdkapi.SyncImpl, error) {
 	u.lock.Lock()
@@ -98,6 +203,7 @@ func (u *UniqueInstrumentMeterImpl) NewSyncInstrument(descriptor sdkapi.Descript
 	if err != nil {
 		return nil, err
 	} else if impl != nil {
+		u.refs[descriptor.Name()]++
 		return impl.(sdkapi.SyncImpl), nil
 	}
 
@@ -106,6 +212,8 @@ func (u *UniqueInstrumentMeterImpl) NewSyncInstrument(descriptor sdkapi.Descript
 		return nil, err
 	}
 	u.state[descriptor.Name()] = syncInst
+	u.refs[descriptor.Name()] = 1
+	u.notify(syncInst.Descriptor(), descriptor)
 	return syncInst, nil
 }
 
@@ -119,6 +227,7 @@ func (u *UniqueInstrumentMeterImpl) NewAsyncInstrument(descriptor sdkapi.Descrip
 	if err != nil {
 		return nil, err
 	} else if impl != nil {
+		u.refs[descriptor.Name()]++
 		return impl.(sdkapi.AsyncImpl), nil
 	}
 
@@ -127,11 +236,38 @@ func (u *UniqueInstrumentMeterImpl) NewAsyncInstrument(descriptor sdkapi.Descrip
 		return nil, err
 	}
 	u.state[descriptor.Name()] = asyncInst
+	u.refs[descriptor.Name()] = 1
+	u.notify(asyncInst.Descriptor(), descriptor)
 	return asyncInst, nil
 }
 
+// DiscardInstrument implements sdkapi.InstrumentDiscarder.  The
+// instrument is unregistered, and discarded by the underlying MeterImpl
+// when it implements sdkapi.InstrumentDiscarder, once it is discarded as
+// many times as it was returned.
+func (u *UniqueInstrumentMeterImpl) DiscardInstrument(inst sdkapi.InstrumentImpl) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	for name, impl := range u.state {
+		if impl != inst {
+			continue
+		}
+		u.refs[name]--
+		if u.refs[name] > 0 {
+			return
+		}
+		delete(u.state, name)
+		delete(u.refs, name)
+		if d, ok := u.impl.(sdkapi.InstrumentDiscarder); ok {
+			d.DiscardInstrument(inst)
+		}
+		return
+	}
+}
+
 // RegisterCallback registers callback with insts.
-func (u *UniqueInstrumentMeterImpl) RegisterCallback(insts []instrument.Asynchronous, callback func(context.Context)) error {
+func (u *UniqueInstrumentMeterImpl) RegisterCallback(insts []instrument.Asynchronous, callback metric.Callback) (metric.Registration, error) {
 	u.lock.Lock()
 	defer u.lock.Unlock()
 
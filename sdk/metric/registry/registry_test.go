@@ -20,8 +20,12 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/unit"
 	metricsdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/number"
 	"go.opentelemetry.io/otel/sdk/metric/registry"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
 )
@@ -110,3 +114,158 @@ func TestRegistryDiffInstruments(t *testing.T) {
 		}
 	}
 }
+
+func TestRegistryLookup(t *testing.T) {
+	impl := registry.NewUniqueInstrumentMeterImpl(metricsdk.NewAccumulator(nil))
+	meter := sdkapi.WrapMeterImpl(impl)
+
+	_, ok := impl.Lookup("counter")
+	require.False(t, ok)
+
+	_, err := meter.SyncInt64().Counter("counter", instrument.WithDescription("a"))
+	require.NoError(t, err)
+	desc, ok := impl.Lookup("counter")
+	require.True(t, ok)
+	require.Equal(t, sdkapi.CounterInstrumentKind, desc.InstrumentKind())
+	require.Equal(t, "a", desc.Description())
+}
+
+func TestRegistryDiscardInstrument(t *testing.T) {
+	impl := registry.NewUniqueInstrumentMeterImpl(metricsdk.NewAccumulator(nil))
+	desc := sdkapi.NewDescriptor("counter", sdkapi.CounterInstrumentKind, number.Int64Kind, "", "")
+
+	first, err := impl.NewSyncInstrument(desc)
+	require.NoError(t, err)
+	second, err := impl.NewSyncInstrument(desc)
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	// The instrument stays registered until it is discarded as
+	// many times as it was returned.
+	impl.DiscardInstrument(second)
+	_, ok := impl.Lookup("counter")
+	require.True(t, ok)
+	impl.DiscardInstrument(first)
+	_, ok = impl.Lookup("counter")
+	require.False(t, ok)
+
+	_, err = impl.NewAsyncInstrument(sdkapi.NewDescriptor("counter", sdkapi.GaugeObserverInstrumentKind, number.Int64Kind, "", ""))
+	require.NoError(t, err)
+}
+
+func TestRegistryMetadataListener(t *testing.T) {
+	var events []registry.MetadataEvent
+	meter := sdkapi.WrapMeterImpl(registry.NewUniqueInstrumentMeterImpl(
+		metricsdk.NewAccumulator(nil),
+		registry.WithMetadataListener(func(event registry.MetadataEvent) {
+			events = append(events, event)
+		}),
+	))
+
+	_, err := meter.SyncInt64().Counter("counter", instrument.WithDescription("a"), instrument.WithUnit("ms"))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, events[0].Registered, events[0].Requested)
+	require.Equal(t, "a", events[0].Registered.Description())
+
+	// Identical metadata: no event.
+	_, err = meter.SyncInt64().Counter("counter", instrument.WithDescription("a"), instrument.WithUnit("ms"))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	// Changed metadata: the registered descriptor is unchanged.
+	_, err = meter.SyncInt64().Counter("counter", instrument.WithDescription("b"), instrument.WithUnit("s"))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, "a", events[1].Registered.Description())
+	require.Equal(t, "b", events[1].Requested.Description())
+	require.Equal(t, unit.Unit("s"), events[1].Requested.Unit())
+
+	// Incompatible kinds fail without an event.
+	_, err = meter.SyncFloat64().Counter("counter", instrument.WithDescription("c"))
+	require.Error(t, err)
+	require.Len(t, events, 2)
+
+	_, err = meter.AsyncInt64().Gauge("gauge")
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	require.Equal(t, "gauge", events[2].Registered.Name())
+}
+
+func TestRegistryConflicts(t *testing.T) {
+	var handled []error
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		handled = append(handled, err)
+	}))
+	defer otel.SetErrorHandler(otel.ErrorHandlerFunc(func(error) {}))
+
+	registered := sdkapi.NewDescriptor("inst", sdkapi.CounterInstrumentKind, number.Int64Kind, "a", unit.Milliseconds)
+
+	for _, test := range []struct {
+		name      string
+		requested sdkapi.Descriptor
+		err       error
+		handled   error
+	}{
+		{
+			name:      "identical",
+			requested: registered,
+		},
+		{
+			name:      "description",
+			requested: sdkapi.NewDescriptor("inst", sdkapi.CounterInstrumentKind, number.Int64Kind, "b", unit.Milliseconds),
+			handled:   registry.ErrMetadataConflict,
+		},
+		{
+			name:      "unit",
+			requested: sdkapi.NewDescriptor("inst", sdkapi.CounterInstrumentKind, number.Int64Kind, "a", unit.Bytes),
+			handled:   registry.ErrMetadataConflict,
+		},
+		{
+			name:      "number kind",
+			requested: sdkapi.NewDescriptor("inst", sdkapi.CounterInstrumentKind, number.Float64Kind, "a", unit.Milliseconds),
+			err:       registry.ErrMetricKindMismatch,
+		},
+		{
+			name:      "instrument kind",
+			requested: sdkapi.NewDescriptor("inst", sdkapi.UpDownCounterInstrumentKind, number.Int64Kind, "a", unit.Milliseconds),
+			err:       registry.ErrMetricKindMismatch,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			handled = nil
+			u := registry.NewUniqueInstrumentMeterImpl(metricsdk.NewAccumulator(nil))
+
+			inst, err := u.NewSyncInstrument(registered)
+			require.NoError(t, err)
+
+			other, err := u.NewSyncInstrument(test.requested)
+			if test.err != nil {
+				require.ErrorIs(t, err, test.err)
+				require.Nil(t, other)
+				require.Empty(t, handled)
+
+				// The error names both descriptors.
+				require.Contains(t, err.Error(), registered.InstrumentKind().String())
+				require.Contains(t, err.Error(), test.requested.InstrumentKind().String())
+				require.Contains(t, err.Error(), registered.NumberKind().String())
+				require.Contains(t, err.Error(), test.requested.NumberKind().String())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, inst, other)
+			require.Equal(t, registered, other.Descriptor())
+			if test.handled == nil {
+				require.Empty(t, handled)
+				return
+			}
+			require.Len(t, handled, 1)
+			require.ErrorIs(t, handled[0], test.handled)
+			require.Contains(t, handled[0].Error(), string(registered.Unit()))
+			require.Contains(t, handled[0].Error(), string(test.requested.Unit()))
+			require.Contains(t, handled[0].Error(), registered.Description())
+			require.Contains(t, handled[0].Error(), test.requested.Description())
+		})
+	}
+}
@@ -16,17 +16,26 @@ package metric // import "go.opentelemetry.io/otel/sdk/metric"
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/instrument"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/exemplar"
 	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
 	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/processor/cardinality"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
 )
 
@@ -41,26 +50,79 @@ type (
 	// timer to call Collect() periodically.  Pull-based processors
 	// will call Collect() when a pull request arrives.
 	Accumulator struct {
-		// current maps `mapkey` to *record.
-		current sync.Map
+		// shardsLock protects shards.
+		shardsLock sync.Mutex
+
+		// shards are the record maps of every instrument, see
+		// baseInstrument.records.
+		shards []*sync.Map
 
 		callbackLock sync.Mutex
 		callbacks    map[*callback]struct{}
 
+		// asyncInsts are the asynchronous instruments that
+		// have not been registered with a callback yet, nor
+		// reported, see ErrNoCallback.  It is protected by
+		// callbackLock.
+		asyncInsts []*asyncInstrument
+
 		// currentEpoch is the current epoch number. It is
-		// incremented in `Collect()`.
+		// incremented in `Collect()`, after the callbacks ran,
+		// so that callbacks observe in the epoch being
+		// collected.
 		currentEpoch int64
 
+		// epochLock protects currentEpoch from observations
+		// made by callbacks, see observeIn.  Collect holds it
+		// while the records are collected, so that no
+		// asynchronous observation inserts a record until the
+		// collection ends.
+		epochLock sync.RWMutex
+
 		// processor is the configured processor+configuration.
 		processor export.Processor
 
 		// collectLock prevents simultaneous calls to Collect().
 		collectLock sync.Mutex
+
+		// growthLock protects growth.
+		growthLock sync.Mutex
+
+		// growth counts the records created for each
+		// instrument in the current collection cycle, see
+		// WithSeriesGrowthLimit.
+		growth map[*baseInstrument]int
+
+		// renameLock protects renamed.
+		renameLock sync.Mutex
+
+		// renamed maps the new names of renamed instruments
+		// to their names, see WithInstrumentRename.
+		renamed map[string]string
+
+		// interned caches the attribute sets of recent
+		// measurements, see WithAttributeInterning.  It is nil
+		// when interning is disabled.
+		interned *internCache
+
+		// shutdown is set to one, atomically, by Shutdown.
+		shutdown int32
+
+		config config
 	}
 
 	callback struct {
 		insts map[*asyncInstrument]struct{}
-		f     func(context.Context)
+		f     metric.Callback
+
+		// name identifies the callback function in
+		// diagnostics.
+		name string
+
+		// tags select the callback for CollectTagged, see
+		// TagCallback.  They are protected by the
+		// callbackLock of the Accumulator.
+		tags []string
 	}
 
 	asyncContextKey struct{}
@@ -68,6 +130,11 @@ type (
 	asyncInstrument struct {
 		baseInstrument
 		instrument.Asynchronous
+
+		// observedOutside is set to one, atomically, once
+		// the instrument is observed outside of callbacks,
+		// which it then may not need, see ErrNoCallback.
+		observedOutside int32
 	}
 
 	syncInstrument struct {
@@ -80,15 +147,19 @@ type (
 	mapkey struct {
 		descriptor *sdkapi.Descriptor
 		ordered    attribute.Distinct
+
+		// custom replaces ordered when a series key
+		// function is configured, see WithSeriesKey.
+		custom interface{}
 	}
 
 	// record maintains the state of one metric instrument.  Due
 	// the use of lock-free algorithms, there may be more than one
 	// `record` in existence at a time, although at most one can
-	// be referenced from the `Accumulator.current` map.
+	// be referenced from the `baseInstrument.records` map.
 	record struct {
 		// refMapped keeps track of refcounts and the mapping state to the
-		// Accumulator.current map.
+		// baseInstrument.records map.
 		refMapped refcountMapped
 
 		// updateCount is incremented on every Update.
@@ -98,6 +169,15 @@ type (
 		// supports checking for no updates during a round.
 		collectedCount int64
 
+		// observeLock serializes the observations of an
+		// asynchronous instrument, see lastValueWins.
+		observeLock sync.Mutex
+
+		// observer is the callback that last observed the
+		// record in the current collection, if any.  It is
+		// protected by observeLock.
+		observer *callback
+
 		// attrs is the stored attribute set for this record, except in cases
 		// where a attribute set is shared due to batch recording.
 		attrs attribute.Set
@@ -114,11 +194,91 @@ type (
 		// metric was disabled by the exporter.
 		current    aggregator.Aggregator
 		checkpoint aggregator.Aggregator
+
+		// running holds the total of all observations of a
+		// delta-observed instrument, see ObserveDelta.
+		running aggregator.Aggregator
+
+		// limited is true when the record counts against the
+		// cardinality limit of its instrument, see
+		// WithCardinalityLimit.
+		limited bool
 	}
 
 	baseInstrument struct {
 		meter      *Accumulator
 		descriptor sdkapi.Descriptor
+
+		// records maps `mapkey` to the *record of each
+		// attribute set of the instrument.  A map per
+		// instrument keeps the instruments from contending
+		// when they add records.
+		records *sync.Map
+
+		// exported is the descriptor of the exported data.
+		// It differs from descriptor in the name of a renamed
+		// instrument, see WithInstrumentRename.
+		exported sdkapi.Descriptor
+
+		// delta is true for asynchronous counters that are
+		// observed using ObserveDelta.
+		delta bool
+
+		// enrichment holds the attributes added to every
+		// measurement, see WithAttributeEnrichment.
+		enrichment []attribute.KeyValue
+
+		// filter, if not nil, selects the attributes of a
+		// measurement that are kept, see WithAttributeFilter.
+		filter attribute.Filter
+
+		// baggage are the keys of the baggage members added to
+		// the attributes of every observation, see
+		// WithBaggageAttributes.
+		baggage []string
+
+		// excluded is true for instruments that are not
+		// collected, see WithExcludedInstruments.
+		excluded bool
+
+		// dropped is set to one, atomically, once the
+		// AggregatorSelector returned no aggregator for the
+		// instrument, e.g., for aggregation.DropKind.  Its
+		// measurements are then discarded before looking up
+		// their record.
+		dropped int32
+
+		// noProcessorReported is set to one, atomically, once
+		// a measurement of the instrument was reported as
+		// dropped for lack of a processor, see ErrNoProcessor.
+		noProcessorReported int32
+
+		// convert, if not nil, converts measurements to the
+		// unit of descriptor, see WithUnitConversion.
+		convert func(float64) float64
+
+		// aliases are the other instruments that every
+		// measurement is also recorded in, see
+		// WithInstrumentAlias.
+		aliases []*baseInstrument
+
+		// cardinality, if not nil, limits the number of
+		// attribute sets of the instrument, see
+		// WithCardinalityLimit.
+		cardinality *cardinalityLimit
+	}
+
+	// cardinalityLimit counts the attribute sets of an
+	// instrument against its limit.
+	cardinalityLimit struct {
+		// series is the number of records of the
+		// instrument in the current map, not counting its
+		// overflow record.
+		series int64
+		limit  int64
+
+		// reported is set once the overflow is reported.
+		reported int32
 	}
 )
 
@@ -131,6 +291,91 @@ var (
 	// ErrBadInstrument is returned when an instrument from another SDK is
 	// attempted to be registered with this SDK.
 	ErrBadInstrument = fmt.Errorf("use of a instrument from another SDK")
+
+	// ErrDeltaObservation is returned when the observation method used
+	// does not match the configuration of an asynchronous counter, see
+	// WithDeltaObservers.
+	ErrDeltaObservation = fmt.Errorf("observation does not match the delta configuration of the instrument")
+
+	// ErrUnitMismatch is returned when the unit of an instrument
+	// contradicts the unit suffix of its name, see WithUnitMismatch.
+	ErrUnitMismatch = fmt.Errorf("instrument unit does not match its name")
+
+	// ErrSeriesGrowth is reported when an instrument creates more
+	// new attribute sets in one collection cycle than allowed, see
+	// WithSeriesGrowthLimit.
+	ErrSeriesGrowth = fmt.Errorf("too many new attribute sets in one collection cycle")
+
+	// ErrNoUnitConverter is returned when an instrument is
+	// configured to convert its unit but no converter is
+	// registered for the conversion, see WithUnitConversion.
+	ErrNoUnitConverter = fmt.Errorf("no unit converter registered")
+
+	// ErrLateObservation is reported when a callback observes an
+	// instrument after it returned, e.g., from a goroutine that
+	// outlives the callback, even while the collection that ran
+	// it has not ended.  The observation is dropped.
+	ErrLateObservation = fmt.Errorf("observation after the end of its collection")
+
+	// ErrNoProcessor is reported when an Accumulator created
+	// without a Processor records the first measurement of an
+	// instrument, and returned when it is collected.
+	ErrNoProcessor = fmt.Errorf("accumulator has no processor")
+
+	// ErrUnknownCallback is returned when unregistering a callback
+	// that is not registered, e.g., twice.
+	ErrUnknownCallback = fmt.Errorf("callback is not registered")
+
+	// ErrCallbackPanic is returned by Collect, wrapped in a
+	// CallbackError, when a callback panics.
+	ErrCallbackPanic = fmt.Errorf("callback panicked")
+
+	// ErrCardinalityLimit is reported once per instrument when
+	// the measurements of an instrument start to be folded into
+	// its overflow series, see WithCardinalityLimit.
+	ErrCardinalityLimit = fmt.Errorf("cardinality limit reached, attribute sets are folded into the overflow series")
+
+	// ErrUndeclaredInstrument is reported when a callback observes
+	// an instrument it was not registered with.  The observation
+	// is dropped.
+	ErrUndeclaredInstrument = fmt.Errorf("instrument is not registered with the callback")
+
+	// ErrRenameConflict is reported when an instrument is renamed
+	// to the name another instrument was already renamed to, see
+	// WithInstrumentRename.  Both instruments are exported under
+	// that name.
+	ErrRenameConflict = fmt.Errorf("instruments renamed to the same name")
+
+	// ErrInvalidInstrumentName is returned when an instrument is
+	// created with a name that does not follow the OpenTelemetry
+	// naming rules: it must start with an ASCII letter, continue
+	// with ASCII letters, digits, underscores, dots and dashes,
+	// and be at most 63 characters long.
+	ErrInvalidInstrumentName = fmt.Errorf("invalid instrument name")
+
+	// ErrInvalidUnit is returned when an instrument is created
+	// with a unit that is not valid UTF-8.
+	ErrInvalidUnit = fmt.Errorf("instrument unit is not valid UTF-8")
+
+	// ErrInvalidDescription is returned when an instrument is
+	// created with a description that is not valid UTF-8.
+	ErrInvalidDescription = fmt.Errorf("instrument description is not valid UTF-8")
+
+	// ErrCallbackTimeout is reported when a callback is abandoned
+	// because it did not return in time, see WithCallbackTimeout.
+	ErrCallbackTimeout = fmt.Errorf("callback timed out")
+
+	// ErrNoCallback is reported once per asynchronous instrument
+	// when it is collected without being registered with a
+	// callback nor observed outside of callbacks, which is
+	// usually a forgotten RegisterCallback, see
+	// WithMissingCallbackWarnings.
+	ErrNoCallback = fmt.Errorf("asynchronous instrument has no callback")
+
+	// ErrObservationConflict is reported when callbacks observe
+	// the same attribute set of an instrument in a collection.
+	// The last observation wins.
+	ErrObservationConflict = fmt.Errorf("attribute set observed by several callbacks")
 )
 
 func (b *baseInstrument) Descriptor() sdkapi.Descriptor {
@@ -146,22 +391,75 @@ func (s *syncInstrument) Implementation() interface{} {
 }
 
 // acquireHandle gets or creates a `*record` corresponding to `kvs`,
-// the input attributes.
+// the input attributes.  It returns nil when a new record is needed but
+// the instrument has reached its series growth limit.
 func (b *baseInstrument) acquireHandle(kvs []attribute.KeyValue) *record {
+	return b.acquire(kvs, false)
+}
+
+// acquire returns the record of kvs, or of the overflow series of b
+// when the cardinality limit of b is reached, see WithCardinalityLimit.
+// The overflow record itself does not count against the limit.
+func (b *baseInstrument) acquire(kvs []attribute.KeyValue, overflow bool) *record {
+	if b.filter != nil && !overflow {
+		kvs = filterAttributes(kvs, b.filter)
+	}
+	if len(b.enrichment) != 0 {
+		// The measurement attributes come last, so that
+		// they take precedence over the added attributes.
+		kvs = append(append(make([]attribute.KeyValue, 0, len(b.enrichment)+len(kvs)), b.enrichment...), kvs...)
+	}
+	if len(b.meter.config.StringNormalizers) != 0 {
+		kvs = b.meter.normalize(kvs)
+	}
+
+	// No lock is held while the attribute set and the aggregators
+	// are built: concurrent callers of a new set may each build a
+	// record, and LoadOrStore below keeps the first one.
+	//
 	// This memory allocation may not be used, but it's
 	// needed for the `sortSlice` field, to avoid an
 	// allocation while sorting.
 	rec := &record{}
-	rec.attrs = attribute.NewSetWithSortable(kvs, &rec.sortSlice)
+	if b.meter.interned == nil {
+		rec.attrs = attribute.NewSetWithSortable(kvs, &rec.sortSlice)
+		return b.acquireRecord(rec, overflow)
+	}
+	set, hash, ok := b.meter.interned.lookup(kvs)
+	if !ok {
+		// kvs is copied before it is sorted by
+		// NewSetWithSortable.
+		raw := append([]attribute.KeyValue(nil), kvs...)
+		set = attribute.NewSetWithSortable(kvs, &rec.sortSlice)
+		b.meter.interned.store(hash, raw, set)
+	}
+	rec.attrs = set
+	return b.acquireRecord(rec, overflow)
+}
+
+// acquireShared returns the record of the attributes shared by the
+// observations of ObserveShared, whose set is computed once for all the
+// instruments, or of the set passed to ObserveSet.  The set is only
+// recomputed for the instruments that enrich the attributes.
+func (b *baseInstrument) acquireShared(shared *sharedAttributes) *record {
+	if len(b.enrichment) != 0 {
+		return b.acquire(shared.attributes(), false)
+	}
+	rec := &record{attrs: shared.set}
+	if b.filter != nil {
+		rec.attrs, _ = shared.set.Filter(b.filter)
+	}
+	return b.acquireRecord(rec, false)
+}
 
+// acquireRecord returns the record of the attributes of rec, storing rec
+// when there is none.
+func (b *baseInstrument) acquireRecord(rec *record, overflow bool) *record {
 	// Create lookup key for sync.Map (one allocation, as this
 	// passes through an interface{})
-	mk := mapkey{
-		descriptor: &b.descriptor,
-		ordered:    rec.attrs.Equivalent(),
-	}
+	mk := b.meter.mapkey(&b.descriptor, &rec.attrs)
 
-	if actual, ok := b.meter.current.Load(mk); ok {
+	if actual, ok := b.records.Load(mk); ok {
 		// Existing record case.
 		existingRec := actual.(*record)
 		if existingRec.refMapped.ref() {
@@ -172,21 +470,54 @@ func (b *baseInstrument) acquireHandle(kvs []attribute.KeyValue) *record {
 		// This entry is no longer mapped, try to add a new entry.
 	}
 
+	limited := b.cardinality != nil && !overflow
+	if limited && !b.cardinality.reserve() {
+		b.cardinality.report(&b.descriptor)
+		b.meter.countDropped(&b.descriptor, "cardinality_limit")
+		return b.acquire([]attribute.KeyValue{overflowAttribute}, true)
+	}
+
+	if !b.meter.admitSeries(b) {
+		b.meter.countDropped(&b.descriptor, "series_growth")
+		if limited {
+			b.cardinality.release()
+		}
+		return nil
+	}
+
 	rec.refMapped = refcountMapped{value: 2}
 	rec.inst = b
+	rec.limited = limited
 
-	b.meter.processor.AggregatorFor(&b.descriptor, &rec.current, &rec.checkpoint)
+	if b.delta {
+		b.meter.processor.AggregatorFor(&b.descriptor, &rec.current, &rec.checkpoint, &rec.running)
+	} else {
+		b.meter.processor.AggregatorFor(&b.descriptor, &rec.current, &rec.checkpoint)
+	}
+	if rec.current == nil {
+		// The instrument is disabled according to the
+		// AggregatorSelector, which depends only on the
+		// descriptor: no record is needed, now or later.
+		atomic.StoreInt32(&b.dropped, 1)
+		if limited {
+			b.cardinality.release()
+		}
+		return nil
+	}
 
 	for {
 		// Load/Store: there's a memory allocation to place `mk` into
 		// an interface here.
-		if actual, loaded := b.meter.current.LoadOrStore(mk, rec); loaded {
+		if actual, loaded := b.records.LoadOrStore(mk, rec); loaded {
 			// Existing record case. Cannot change rec here because if fail
 			// will try to add rec again to avoid new allocations.
 			oldRec := actual.(*record)
 			if oldRec.refMapped.ref() {
 				// At this moment it is guaranteed that the entry is in
 				// the map and will not be removed.
+				if limited {
+					b.cardinality.release()
+				}
 				return oldRec
 			}
 			// This loaded entry is marked as unmapped (so Collect will remove
@@ -211,7 +542,144 @@ func (b *baseInstrument) acquireHandle(kvs []attribute.KeyValue) *record {
 //
 // The order of the input array `kvs` may be sorted after the function is called.
 func (s *syncInstrument) RecordOne(ctx context.Context, num number.Number, kvs []attribute.KeyValue) {
-	h := s.acquireHandle(kvs)
+	if !s.collected() || s.meter.isShutdown() {
+		return
+	}
+	s.capture(ctx, num, kvs)
+}
+
+// collected returns whether the measurements of b are collected, either
+// for b itself or for one of its aliases.
+func (b *baseInstrument) collected() bool {
+	return !b.excluded || len(b.aliases) != 0
+}
+
+// capture records a measurement of b and of its aliases.
+func (b *baseInstrument) capture(ctx context.Context, num number.Number, kvs []attribute.KeyValue) {
+	if !b.excluded {
+		b.captureSeries(ctx, num, kvs)
+	}
+	for _, alias := range b.aliases {
+		alias.captureSeries(ctx, num, kvs)
+	}
+}
+
+// seed creates the records of the seed attribute sets of b and of its
+// aliases, see WithSeedAttributes.
+func (b *baseInstrument) seed() {
+	if !b.excluded {
+		b.seedSeries()
+	}
+	for _, alias := range b.aliases {
+		alias.seedSeries()
+	}
+}
+
+// seedSeries creates the records of the seed attribute sets of b, and
+// marks them updated, so that they are collected with a zero value
+// before any measurement.
+func (b *baseInstrument) seedSeries() {
+	if b.meter.config.SeedAttributes == nil || !b.descriptor.InstrumentKind().Adding() {
+		return
+	}
+	if b.meter.processor == nil || atomic.LoadInt32(&b.dropped) != 0 {
+		return
+	}
+	for _, set := range b.meter.config.SeedAttributes(&b.descriptor) {
+		rec := b.acquireHandle(set.ToSlice())
+		if rec == nil {
+			continue
+		}
+		atomic.AddInt64(&rec.updateCount, 1)
+		rec.unbind()
+	}
+}
+
+// captureSeries records a measurement of b.
+func (b *baseInstrument) captureSeries(ctx context.Context, num number.Number, kvs []attribute.KeyValue) {
+	if b.meter.isShutdown() {
+		return
+	}
+	if b.meter.processor == nil {
+		b.reportNoProcessor()
+		return
+	}
+	if atomic.LoadInt32(&b.dropped) != 0 {
+		return
+	}
+	if len(b.baggage) != 0 {
+		kvs = promoteBaggage(ctx, b.baggage, kvs)
+	}
+	b.captureRecord(b.exemplarContext(ctx, kvs), num, b.acquireHandle(kvs))
+}
+
+// exemplarContext returns ctx with the attributes of kvs that the
+// attribute filter of b removes, which the exemplar of the measurement
+// keeps.  They are only computed when the exemplar is sampled.
+func (b *baseInstrument) exemplarContext(ctx context.Context, kvs []attribute.KeyValue) context.Context {
+	if b.filter == nil || !exemplar.Sampled(ctx) {
+		return ctx
+	}
+	var filtered []attribute.KeyValue
+	for _, kv := range kvs {
+		if !b.filter(kv) {
+			filtered = append(filtered, kv)
+		}
+	}
+	if len(filtered) == 0 {
+		return ctx
+	}
+	return exemplar.ContextWithFilteredAttributes(ctx, filtered)
+}
+
+// captureShared records a measurement of b and of its aliases with the
+// attributes shared by the observations of ObserveShared.
+func (b *baseInstrument) captureShared(ctx context.Context, num number.Number, shared *sharedAttributes) {
+	if !b.excluded {
+		b.captureSeriesShared(ctx, num, shared)
+	}
+	for _, alias := range b.aliases {
+		alias.captureSeriesShared(ctx, num, shared)
+	}
+}
+
+// reportNoProcessor reports the first measurement of b dropped for lack
+// of a processor.
+func (b *baseInstrument) reportNoProcessor() {
+	if atomic.CompareAndSwapInt32(&b.noProcessorReported, 0, 1) {
+		otel.Handle(fmt.Errorf("%s: %w", b.descriptor.Name(), ErrNoProcessor))
+	}
+}
+
+// captureSeriesShared records a measurement of b, like captureSeries.
+func (b *baseInstrument) captureSeriesShared(ctx context.Context, num number.Number, shared *sharedAttributes) {
+	if len(b.baggage) != 0 {
+		// The promoted baggage members are not shared.
+		b.captureSeries(ctx, num, shared.attributes())
+		return
+	}
+	if b.meter.isShutdown() {
+		return
+	}
+	if b.meter.processor == nil {
+		b.reportNoProcessor()
+		return
+	}
+	if atomic.LoadInt32(&b.dropped) != 0 {
+		return
+	}
+	if b.filter != nil {
+		ctx = b.exemplarContext(ctx, shared.attributes())
+	}
+	b.captureRecord(ctx, num, b.acquireShared(shared))
+}
+
+// captureRecord records a measurement in h, which may be nil when no
+// record could be acquired.
+func (b *baseInstrument) captureRecord(ctx context.Context, num number.Number, h *record) {
+	if h == nil {
+		return
+	}
 	defer h.unbind()
 	h.captureOne(ctx, num)
 }
@@ -220,9 +688,266 @@ func (s *syncInstrument) RecordOne(ctx context.Context, num number.Number, kvs [
 
 // The order of the input array `kvs` may be sorted after the function is called.
 func (a *asyncInstrument) ObserveOne(ctx context.Context, num number.Number, attrs []attribute.KeyValue) {
-	h := a.acquireHandle(attrs)
-	defer h.unbind()
-	h.captureOne(ctx, num)
+	if !a.collected() {
+		return
+	}
+	if a.delta {
+		otel.Handle(fmt.Errorf("%s: %w", a.descriptor.Name(), ErrDeltaObservation))
+		return
+	}
+	if attempt, ok := ctx.Value(asyncContextKey{}).(*callbackAttempt); ok {
+		a.observeIn(ctx, attempt, num, attrs)
+		return
+	}
+	a.observeOutside(ctx, num, attrs)
+}
+
+// observe captures an observation of a.
+func (a *asyncInstrument) observe(ctx context.Context, num number.Number, attrs []attribute.KeyValue) {
+	a.capture(ctx, num, attrs)
+}
+
+// observeOutside captures an observation made outside of callbacks,
+// after the collection in progress, if any, has collected the records.
+func (a *asyncInstrument) observeOutside(ctx context.Context, num number.Number, attrs []attribute.KeyValue) {
+	if atomic.LoadInt32(&a.observedOutside) == 0 {
+		atomic.StoreInt32(&a.observedOutside, 1)
+	}
+	a.meter.epochLock.RLock()
+	defer a.meter.epochLock.RUnlock()
+	a.observe(ctx, num, attrs)
+}
+
+// ObserveDelta captures the change in value of an asynchronous counter
+// since its last observation.  The delta is added to a running total
+// that is exported as the cumulative value of the counter, which
+// inverts the usual semantics of asynchronous counters.
+//
+// The instrument must be configured using WithDeltaObservers, otherwise
+// the observation is dropped and an ErrDeltaObservation error is
+// handled.
+func ObserveDelta(ctx context.Context, inst instrument.Asynchronous, delta number.Number, attrs ...attribute.KeyValue) {
+	a, ok := asyncImplementation(inst)
+	if !ok {
+		if impls, ok := multiImplementations(ctx, inst); ok {
+			for _, a := range impls {
+				ObserveDelta(ctx, a, delta, attrs...)
+			}
+			return
+		}
+		otel.Handle(ErrBadInstrument)
+		return
+	}
+	if !a.collected() {
+		return
+	}
+	if !a.delta {
+		otel.Handle(fmt.Errorf("%s: %w", a.descriptor.Name(), ErrDeltaObservation))
+		return
+	}
+	if attempt, ok := ctx.Value(asyncContextKey{}).(*callbackAttempt); ok {
+		a.observeIn(ctx, attempt, delta, attrs)
+		return
+	}
+	a.observeOutside(ctx, delta, attrs)
+}
+
+// Observation is one observation of a batch, see ObserveBatch.
+type Observation struct {
+	Number     number.Number
+	Attributes []attribute.KeyValue
+}
+
+// ObserveBatch captures the observations of an asynchronous instrument
+// made by a callback that reads many related values at once, e.g.,
+// per-CPU statistics.  The result is the same as observing each of them
+// in turn, but the instrument and the callback are checked, and the
+// collection is synchronized with, once for the whole batch.
+//
+// The order of the attributes of each observation may be sorted after
+// the function is called.
+func ObserveBatch(ctx context.Context, inst instrument.Asynchronous, observations []Observation) {
+	a, ok := asyncImplementation(inst)
+	if !ok {
+		if impls, ok := multiImplementations(ctx, inst); ok {
+			for _, a := range impls {
+				ObserveBatch(ctx, a, observations)
+			}
+			return
+		}
+		otel.Handle(ErrBadInstrument)
+		return
+	}
+	if !a.collected() {
+		return
+	}
+	if a.delta {
+		otel.Handle(fmt.Errorf("%s: %w", a.descriptor.Name(), ErrDeltaObservation))
+		return
+	}
+	if attempt, ok := ctx.Value(asyncContextKey{}).(*callbackAttempt); ok {
+		a.observeBatchIn(ctx, attempt, observations)
+		return
+	}
+	a.meter.epochLock.RLock()
+	defer a.meter.epochLock.RUnlock()
+	for _, o := range observations {
+		a.observe(ctx, o.Number, o.Attributes)
+	}
+}
+
+// SharedObservation is one observation of ObserveShared.
+type SharedObservation struct {
+	Instrument instrument.Asynchronous
+	Number     number.Number
+}
+
+// sharedAttributes are the attributes of the observations of
+// ObserveShared and ObserveSet.
+type sharedAttributes struct {
+	// kvs are the attributes as observed, or nil when the
+	// observation passed set, see ObserveSet.
+	kvs []attribute.KeyValue
+	// set is the set of kvs, normalized, see
+	// WithStringNormalization.
+	set attribute.Set
+}
+
+// attributes returns the attributes as observed.  The attributes of an
+// observation that passed a set are only built when they are needed,
+// e.g., to enrich them.
+func (s *sharedAttributes) attributes() []attribute.KeyValue {
+	if s.kvs == nil {
+		return s.set.ToSlice()
+	}
+	return s.kvs
+}
+
+// ObserveShared captures observations of several asynchronous
+// instruments that share the attributes attrs, e.g., the user, system and
+// idle CPU times read at once.  The attribute set is computed once for
+// all of them, and the observations are captured in the same collection.
+// Each instrument is checked as in ObserveBatch: the observations of the
+// instruments that are not declared by the callback are dropped.
+func ObserveShared(ctx context.Context, attrs []attribute.KeyValue, observations []SharedObservation) {
+	if groups, ok := splitShared(ctx, observations); ok {
+		for _, group := range groups {
+			ObserveShared(ctx, attrs, group)
+		}
+		return
+	}
+	attempt, inCallback := ctx.Value(asyncContextKey{}).(*callbackAttempt)
+	insts := make([]*asyncInstrument, 0, len(observations))
+	nums := make([]number.Number, 0, len(observations))
+	var meter *Accumulator
+	for _, o := range observations {
+		a, ok := asyncImplementation(o.Instrument)
+		if !ok {
+			otel.Handle(ErrBadInstrument)
+			continue
+		}
+		if !a.collected() {
+			continue
+		}
+		if a.delta {
+			otel.Handle(fmt.Errorf("%s: %w", a.descriptor.Name(), ErrDeltaObservation))
+			continue
+		}
+		if meter == nil {
+			meter = a.meter
+		} else if a.meter != meter {
+			otel.Handle(fmt.Errorf("%s: %w", a.descriptor.Name(), ErrBadInstrument))
+			continue
+		}
+		insts = append(insts, a)
+		nums = append(nums, o.Number)
+	}
+	if len(insts) == 0 {
+		return
+	}
+
+	normalized := attrs
+	if len(meter.config.StringNormalizers) != 0 {
+		normalized = meter.normalize(attrs)
+	}
+	// The set sorts a copy, as normalized may still be attrs.
+	shared := &sharedAttributes{
+		kvs: attrs,
+		set: attribute.NewSet(normalized...),
+	}
+
+	meter.epochLock.RLock()
+	defer meter.epochLock.RUnlock()
+	if inCallback {
+		n := 0
+		for i, a := range insts {
+			if err := attempt.check(a); err != nil {
+				otel.Handle(err)
+				continue
+			}
+			insts[n], nums[n] = a, nums[i]
+			n++
+		}
+		insts, nums = insts[:n], nums[:n]
+		if attempt.bufferShared(ctx, insts, nums, shared) {
+			return
+		}
+	}
+	for i, a := range insts {
+		a.captureShared(ctx, nums[i], shared)
+	}
+}
+
+// ObserveSet captures an observation of an asynchronous instrument with
+// the attribute set set, e.g., in a callback that observes the same
+// attributes every collection with a set built once.  The record of the
+// set is looked up without sorting the attributes nor building their set
+// again.  The result is the same as observing the attributes of set.
+func ObserveSet(ctx context.Context, inst instrument.Asynchronous, num number.Number, set attribute.Set) {
+	a, ok := asyncImplementation(inst)
+	if !ok {
+		if impls, ok := multiImplementations(ctx, inst); ok {
+			for _, a := range impls {
+				ObserveSet(ctx, a, num, set)
+			}
+			return
+		}
+		otel.Handle(ErrBadInstrument)
+		return
+	}
+	if !a.collected() {
+		return
+	}
+	if a.delta {
+		otel.Handle(fmt.Errorf("%s: %w", a.descriptor.Name(), ErrDeltaObservation))
+		return
+	}
+	shared := &sharedAttributes{set: set}
+	if len(a.meter.config.StringNormalizers) != 0 {
+		shared.kvs = set.ToSlice()
+		shared.set = attribute.NewSet(a.meter.normalize(shared.kvs)...)
+	}
+	if attempt, ok := ctx.Value(asyncContextKey{}).(*callbackAttempt); ok {
+		a.observeSetIn(ctx, attempt, num, shared)
+		return
+	}
+	if atomic.LoadInt32(&a.observedOutside) == 0 {
+		atomic.StoreInt32(&a.observedOutside, 1)
+	}
+	a.meter.epochLock.RLock()
+	defer a.meter.epochLock.RUnlock()
+	a.captureShared(ctx, num, shared)
+}
+
+// asyncImplementation returns the implementation of inst, if inst is an
+// asynchronous instrument of this SDK.
+func asyncImplementation(inst instrument.Asynchronous) (*asyncInstrument, bool) {
+	impl, ok := inst.(sdkapi.AsyncImpl)
+	if !ok || impl == nil {
+		return nil, false
+	}
+	a, ok := impl.Implementation().(*asyncInstrument)
+	return a, ok
 }
 
 // NewAccumulator constructs a new Accumulator for the given
@@ -234,59 +959,259 @@ func (a *asyncInstrument) ObserveOne(ctx context.Context, num number.Number, att
 // processor will call Collect() when it receives a request to scrape
 // current metric values.  A push-based processor should configure its
 // own periodic collection.
-func NewAccumulator(processor export.Processor) *Accumulator {
-	return &Accumulator{
+//
+// The processor must not be nil to record measurements: the measurements
+// of an Accumulator without a processor are dropped and reported as
+// ErrNoProcessor, which its Collect returns.
+func NewAccumulator(processor export.Processor, opts ...Option) *Accumulator {
+	m := &Accumulator{
 		processor: processor,
 		callbacks: map[*callback]struct{}{},
+		config:    newConfig(opts...),
 	}
+	if m.config.AttributeInterning > 0 {
+		m.interned = newInternCache(m.config.AttributeInterning)
+	}
+	return m
 }
 
-var _ sdkapi.MeterImpl = &Accumulator{}
+var (
+	_ sdkapi.MeterImpl           = &Accumulator{}
+	_ sdkapi.InstrumentDiscarder = &Accumulator{}
+)
 
 // NewSyncInstrument implements sdkapi.MetricImpl.
 func (m *Accumulator) NewSyncInstrument(descriptor sdkapi.Descriptor) (sdkapi.SyncImpl, error) {
-	return &syncInstrument{
-		baseInstrument: baseInstrument{
-			descriptor: descriptor,
-			meter:      m,
-		},
-	}, nil
+	base, err := m.newInstrument(descriptor)
+	if err != nil {
+		return nil, err
+	}
+	s := &syncInstrument{baseInstrument: base}
+	s.seed()
+	return s, nil
 }
 
 // NewAsyncInstrument implements sdkapi.MetricImpl.
 func (m *Accumulator) NewAsyncInstrument(descriptor sdkapi.Descriptor) (sdkapi.AsyncImpl, error) {
-	a := &asyncInstrument{
-		baseInstrument: baseInstrument{
-			descriptor: descriptor,
-			meter:      m,
-		},
+	base, err := m.newInstrument(descriptor)
+	if err != nil {
+		return nil, err
+	}
+	a := &asyncInstrument{baseInstrument: base}
+	a.cardinality = m.cardinalityLimitFor(&a.descriptor)
+	a.baggage = m.baggageKeys(&a.descriptor)
+	for _, alias := range a.aliases {
+		alias.cardinality = m.cardinalityLimitFor(&alias.descriptor)
+		alias.baggage = m.baggageKeys(&alias.descriptor)
+	}
+	if a.collected() && !m.config.IgnoreMissingCallbacks {
+		m.callbackLock.Lock()
+		m.asyncInsts = append(m.asyncInsts, a)
+		m.callbackLock.Unlock()
+	}
+	if _, ok := m.config.DeltaObservers[descriptor.Name()]; ok {
+		if !descriptor.InstrumentKind().PrecomputedSum() {
+			otel.Handle(fmt.Errorf("%s: delta observation of %s: %w",
+				descriptor.Name(), descriptor.InstrumentKind(), ErrDeltaObservation))
+		} else {
+			a.delta = true
+			for _, alias := range a.aliases {
+				alias.delta = true
+			}
+		}
 	}
+	a.seed()
 	return a, nil
 }
 
-// RegisterCallback registers f to be called for insts.
-func (m *Accumulator) RegisterCallback(insts []instrument.Asynchronous, f func(context.Context)) error {
+// DiscardInstrument implements sdkapi.InstrumentDiscarder.  The records
+// of inst and of its aliases are no longer collected, and an
+// asynchronous inst is not reported as lacking a callback.
+func (m *Accumulator) DiscardInstrument(inst sdkapi.InstrumentImpl) {
+	var b *baseInstrument
+	switch impl := inst.Implementation().(type) {
+	case *syncInstrument:
+		b = &impl.baseInstrument
+	case *asyncInstrument:
+		b = &impl.baseInstrument
+		m.callbackLock.Lock()
+		for i, a := range m.asyncInsts {
+			if a == impl {
+				m.asyncInsts = append(m.asyncInsts[:i:i], m.asyncInsts[i+1:]...)
+				break
+			}
+		}
+		m.callbackLock.Unlock()
+	}
+	if b == nil || b.meter != m {
+		return
+	}
+	discarded := map[*sync.Map]struct{}{b.records: {}}
+	for _, alias := range b.aliases {
+		discarded[alias.records] = struct{}{}
+	}
+	m.shardsLock.Lock()
+	defer m.shardsLock.Unlock()
+	shards := make([]*sync.Map, 0, len(m.shards))
+	for _, records := range m.shards {
+		if _, ok := discarded[records]; !ok {
+			shards = append(shards, records)
+		}
+	}
+	m.shards = shards
+}
+
+// newInstrument returns the baseInstrument described by descriptor,
+// with its aliases.  Their records are only collected, and their renames
+// registered, once they were all created, so that an error leaves no
+// trace of them.
+func (m *Accumulator) newInstrument(descriptor sdkapi.Descriptor) (baseInstrument, error) {
+	base, err := m.newBaseInstrument(descriptor)
+	if err != nil {
+		return baseInstrument{}, err
+	}
+	for _, name := range m.config.Aliases[descriptor.Name()] {
+		alias, err := m.newBaseInstrument(sdkapi.NewDescriptor(
+			name,
+			descriptor.InstrumentKind(),
+			descriptor.NumberKind(),
+			descriptor.Description(),
+			descriptor.Unit(),
+		))
+		if err != nil {
+			return baseInstrument{}, fmt.Errorf("alias %s of %s: %w", name, descriptor.Name(), err)
+		}
+		if !alias.excluded {
+			base.aliases = append(base.aliases, &alias)
+		}
+	}
+
+	base.exported = m.rename(base.descriptor)
+	for _, alias := range base.aliases {
+		alias.exported = m.rename(alias.descriptor)
+	}
+	m.shardsLock.Lock()
+	m.shards = append(m.shards, base.records)
+	for _, alias := range base.aliases {
+		m.shards = append(m.shards, alias.records)
+	}
+	m.shardsLock.Unlock()
+	return base, nil
+}
+
+func (m *Accumulator) newBaseInstrument(descriptor sdkapi.Descriptor) (baseInstrument, error) {
+	if err := validate(descriptor); err != nil {
+		return baseInstrument{}, err
+	}
+	if name, ok := m.config.Renames[descriptor.Name()]; ok {
+		if err := validateName(name); err != nil {
+			return baseInstrument{}, fmt.Errorf("rename of %s: %w", descriptor.Name(), err)
+		}
+	}
+	if err := m.checkUnit(descriptor); err != nil {
+		return baseInstrument{}, err
+	}
+	descriptor, convert, err := m.convertUnit(descriptor)
+	if err != nil {
+		return baseInstrument{}, err
+	}
+
+	return baseInstrument{
+		descriptor: descriptor,
+		exported:   descriptor,
+		meter:      m,
+		records:    &sync.Map{},
+		enrichment: m.enrichment(&descriptor),
+		filter:     m.attributeFilter(&descriptor),
+		excluded:   m.excluded(&descriptor),
+		convert:    convert,
+	}, nil
+}
+
+// rename returns the descriptor that the data of the instrument described
+// by descriptor is exported with, see WithInstrumentRename.
+func (m *Accumulator) rename(descriptor sdkapi.Descriptor) sdkapi.Descriptor {
+	name, ok := m.config.Renames[descriptor.Name()]
+	if !ok {
+		return descriptor
+	}
+
+	m.renameLock.Lock()
+	defer m.renameLock.Unlock()
+	if m.renamed == nil {
+		m.renamed = map[string]string{}
+	}
+	if other, ok := m.renamed[name]; !ok {
+		m.renamed[name] = descriptor.Name()
+	} else if other != descriptor.Name() {
+		otel.Handle(fmt.Errorf("%s: %w: %s is also renamed to %s", descriptor.Name(), ErrRenameConflict, other, name))
+	}
+
+	return sdkapi.NewDescriptor(
+		name,
+		descriptor.InstrumentKind(),
+		descriptor.NumberKind(),
+		descriptor.Description(),
+		descriptor.Unit(),
+	)
+}
+
+// RegisterCallback registers f to be called for insts.  The callback is
+// called by Collect with its Context, and is expected to return promptly
+// once the Context is done, since it delays the collection until then.
+// The callback may only observe insts, its observations of other
+// instruments are dropped, see ErrUndeclaredInstrument.  When an
+// instrument is observed more than once with the same attributes in a
+// collection, the last observation wins.  Several callbacks may observe
+// the same instrument, each for its own attribute sets; when two of them
+// observe the same attribute set, an error wrapping
+// ErrObservationConflict is reported.  The returned Registration, a
+// *Registration, unregisters the callback.
+func (m *Accumulator) RegisterCallback(insts []instrument.Asynchronous, f metric.Callback) (metric.Registration, error) {
+	reg, err := m.register(insts, f)
+	if err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+// Register is like RegisterCallback, but returns the *Registration.
+//
+// Deprecated: use RegisterCallback, which returns the Registration.
+func (m *Accumulator) Register(insts []instrument.Asynchronous, f metric.Callback) (*Registration, error) {
+	return m.register(insts, f)
+}
+
+func (m *Accumulator) register(insts []instrument.Asynchronous, f metric.Callback) (*Registration, error) {
 	cb := &callback{
 		insts: map[*asyncInstrument]struct{}{},
 		f:     f,
+		name:  callbackName(f),
 	}
 	for _, inst := range insts {
 		impl, ok := inst.(sdkapi.AsyncImpl)
 		if !ok {
-			return ErrBadInstrument
+			return nil, ErrBadInstrument
 		}
 
 		ai, err := m.fromAsync(impl)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if ai.collected() {
+			cb.insts[ai] = struct{}{}
 		}
-		cb.insts[ai] = struct{}{}
+	}
+	if len(insts) != 0 && len(cb.insts) == 0 {
+		// All the instruments are excluded, the callback
+		// would have no effect.
+		return &Registration{accumulator: m}, nil
 	}
 
 	m.callbackLock.Lock()
 	defer m.callbackLock.Unlock()
 	m.callbacks[cb] = struct{}{}
-	return nil
+	return &Registration{accumulator: m, callback: cb}, nil
 }
 
 // Collect traverses the list of active records and observers and
@@ -296,88 +1221,606 @@ func (m *Accumulator) RegisterCallback(insts []instrument.Asynchronous, f func(c
 // During the collection pass, the export.Processor will receive
 // one Export() call per current aggregation.
 //
-// Returns the number of records that were checkpointed.
-func (m *Accumulator) Collect(ctx context.Context) int {
+// The callbacks run with ctx, so that its deadline and cancellation
+// propagate to them.  Callbacks that have not run when ctx is done are
+// skipped, with an error wrapping the error of ctx.
+//
+// The records are collected until ctx is done, which is checked every
+// collectCheckInterval records, so that a collection of a very large
+// number of attribute sets returns in time.  The records collected
+// before then form a consistent partial result, and the others keep
+// their updates for the next collection.
+//
+// Returns the number of records that were checkpointed, and a
+// *CallbackError holding the errors of the callbacks that failed, if
+// any, or an error wrapping the error of ctx when the records were
+// partially collected.  The records are collected even when callbacks
+// fail, including the observations of the callbacks that succeeded.
+func (m *Accumulator) Collect(ctx context.Context) (int, error) {
+	if m.processor == nil {
+		return 0, ErrNoProcessor
+	}
+
 	m.collectLock.Lock()
 	defer m.collectLock.Unlock()
 
-	m.runAsyncCallbacks(ctx)
-	checkpointed := m.collectInstruments()
+	m.checkCallbacks()
+	return m.collect(ctx, nil)
+}
+
+// CollectTagged is like Collect, but runs only the callbacks tagged with
+// one of tags, see TagCallback, and checkpoints only the records of the
+// instruments those callbacks observe, e.g., to flush the observations
+// of one subsystem without running every callback.  The records of the
+// other instruments, synchronous ones included, keep their updates for a
+// later collection.  Nothing is collected when no callback matches.
+//
+// The Processor is passed the checkpointed records only, so that a
+// Processor that expects complete collections, like one of delta
+// temporality, exports a partial result.  The Accumulators of a
+// Controller are collected this way by its CollectTagged method.
+func (m *Accumulator) CollectTagged(ctx context.Context, tags ...string) (int, error) {
+	if m.processor == nil {
+		return 0, ErrNoProcessor
+	}
+
+	m.collectLock.Lock()
+	defer m.collectLock.Unlock()
+
+	return m.collect(ctx, func(cb *callback) bool {
+		return cb.tagged(tags)
+	})
+}
+
+// collect runs the callbacks that selected returns true for, or every
+// callback when selected is nil, and checkpoints the records of their
+// instruments, or every record when selected is nil.  The collectLock
+// must be held.
+func (m *Accumulator) collect(ctx context.Context, selected func(*callback) bool) (int, error) {
+	callbacks := m.selectCallbacks(selected)
+	err := m.runAsyncCallbacks(ctx, callbacks)
+
+	// Every callback has returned: end their epoch, so that their
+	// observations in progress complete before the records are
+	// collected, and later ones are dropped rather than collected
+	// next time.  The epoch lock is held until the records are
+	// collected, so that the asynchronous observations made
+	// outside of callbacks wait for the collection to end.
+	m.epochLock.Lock()
 	m.currentEpoch++
+	var shards []*sync.Map
+	if selected == nil {
+		m.shardsLock.Lock()
+		shards = m.shards
+		m.shardsLock.Unlock()
+	} else {
+		shards = callbackShards(callbacks)
+	}
+	checkpointed, cerr := m.collectInstruments(ctx, shards)
+	m.epochLock.Unlock()
+
+	m.growthLock.Lock()
+	m.growth = nil
+	m.growthLock.Unlock()
+
+	if cerr != nil {
+		if err == nil {
+			err = cerr
+		} else {
+			err = fmt.Errorf("%s: %w", cerr.Error(), err)
+		}
+	}
+	return checkpointed, err
+}
 
-	return checkpointed
+// collectCheckInterval is the number of records collected between the
+// checks of the context of Collect.
+const collectCheckInterval = 1024
+
+// callbackShards returns the record maps of the instruments observed by
+// callbacks, and of their aliases, once each.
+func callbackShards(callbacks []*callback) []*sync.Map {
+	seen := map[*sync.Map]struct{}{}
+	var shards []*sync.Map
+	add := func(b *baseInstrument) {
+		if _, ok := seen[b.records]; ok {
+			return
+		}
+		seen[b.records] = struct{}{}
+		shards = append(shards, b.records)
+	}
+	for _, cb := range callbacks {
+		for inst := range cb.insts {
+			add(&inst.baseInstrument)
+			for _, alias := range inst.aliases {
+				add(alias)
+			}
+		}
+	}
+	return shards
 }
 
-func (m *Accumulator) collectInstruments() int {
+// collectInstruments checkpoints the updated records of shards, and
+// unmaps the others.  It stops when ctx is done, returning an error
+// wrapping the error of ctx.
+func (m *Accumulator) collectInstruments(ctx context.Context, shards []*sync.Map) (int, error) {
 	checkpointed := 0
+	visited := 0
+	var stopped error
+
+	// When collecting concurrently, records are gathered here
+	// and checkpointed after the map has been traversed.
+	var pending []*record
+	checkpoint := func(r *record) {
+		if m.config.CollectConcurrency > 1 {
+			pending = append(pending, r)
+			return
+		}
+		checkpointed += m.checkpointRecord(r)
+	}
 
-	m.current.Range(func(key interface{}, value interface{}) bool {
-		// Note: always continue to iterate over the entire
-		// map by returning `true` in this function.
-		inuse := value.(*record)
+	for _, records := range shards {
+		if stopped != nil {
+			break
+		}
+		records.Range(func(key interface{}, value interface{}) bool {
+			// Note: always continue to iterate over the entire
+			// map by returning `true` in this function, unless
+			// ctx is done.
+			if visited++; visited%collectCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					stopped = fmt.Errorf("collection stopped after %d records: %w", visited-1, err)
+					return false
+				}
+			}
+			inuse := value.(*record)
+
+			mods := atomic.LoadInt64(&inuse.updateCount)
+			coll := inuse.collectedCount
+
+			if mods != coll || inuse.inst.delta {
+				// Updates happened in this interval, or
+				// the running total of a delta-observed
+				// instrument has to be reported,
+				// checkpoint and continue.
+				checkpoint(inuse)
+				atomic.StoreInt64(&inuse.collectedCount, mods)
+				return true
+			}
+
+			// Having no updates since last collection, try to unmap:
+			if unmapped := inuse.refMapped.tryUnmap(); !unmapped {
+				// The record is referenced by a binding, continue.
+				return true
+			}
 
-		mods := atomic.LoadInt64(&inuse.updateCount)
-		coll := inuse.collectedCount
+			// If any other goroutines are now trying to re-insert this
+			// entry in the map, they are busy calling Gosched() awaiting
+			// this deletion:
+			records.Delete(inuse.mapkey())
+			if inuse.limited {
+				inuse.inst.cardinality.release()
+			}
 
-		if mods != coll {
-			// Updates happened in this interval,
-			// checkpoint and continue.
-			checkpointed += m.checkpointRecord(inuse)
-			inuse.collectedCount = mods
+			// There's a potential race between `LoadInt64` and
+			// `tryUnmap` in this function.  Since this is the
+			// last we'll see of this record, checkpoint
+			mods = atomic.LoadInt64(&inuse.updateCount)
+			if mods != coll {
+				checkpoint(inuse)
+			}
 			return true
+		})
+	}
+
+	if pending != nil {
+		return m.checkpointConcurrently(pending), stopped
+	}
+	return checkpointed, stopped
+}
+
+// checkpointConcurrently moves the current state of each record into
+// its checkpoint using up to CollectConcurrency goroutines, then
+// passes the checkpoints to the Processor in the order given.  Records
+// are partitioned by instrument, so that each goroutine synchronizes
+// with the writers of a disjoint set of instruments.
+func (m *Accumulator) checkpointConcurrently(records []*record) int {
+	groups := map[*baseInstrument][]int{}
+	var order []*baseInstrument
+	for i, r := range records {
+		if _, ok := groups[r.inst]; !ok {
+			order = append(order, r.inst)
 		}
+		groups[r.inst] = append(groups[r.inst], i)
+	}
 
-		// Having no updates since last collection, try to unmap:
-		if unmapped := inuse.refMapped.tryUnmap(); !unmapped {
-			// The record is referenced by a binding, continue.
-			return true
+	workers := m.config.CollectConcurrency
+	if workers > len(order) {
+		workers = len(order)
+	}
+	work := make(chan []int)
+	moved := make([]bool, len(records))
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for indices := range work {
+				for _, i := range indices {
+					moved[i] = m.moveRecord(records[i])
+				}
+			}
+		}()
+	}
+	for _, inst := range order {
+		work <- groups[inst]
+	}
+	close(work)
+	wg.Wait()
+
+	// The Processor is not safe for concurrent use.
+	checkpointed := 0
+	for i, r := range records {
+		if moved[i] {
+			m.processRecord(r)
+			checkpointed++
 		}
+	}
+	return checkpointed
+}
 
-		// If any other goroutines are now trying to re-insert this
-		// entry in the map, they are busy calling Gosched() awaiting
-		// this deletion:
-		m.current.Delete(inuse.mapkey())
+// checkCallbacks reports the asynchronous instruments that are not
+// registered with a callback, once each, see ErrNoCallback.
+func (m *Accumulator) checkCallbacks() {
+	m.callbackLock.Lock()
+	defer m.callbackLock.Unlock()
+	if len(m.asyncInsts) == 0 {
+		return
+	}
+	registered := map[*asyncInstrument]struct{}{}
+	for cb := range m.callbacks {
+		for inst := range cb.insts {
+			registered[inst] = struct{}{}
+		}
+	}
+	var names []string
+	for _, inst := range m.asyncInsts {
+		if _, ok := registered[inst]; ok || atomic.LoadInt32(&inst.observedOutside) != 0 {
+			continue
+		}
+		names = append(names, inst.descriptor.Name())
+	}
+	// The instruments are checked once.
+	m.asyncInsts = nil
+	if len(names) != 0 {
+		otel.Handle(fmt.Errorf("%s: %w", strings.Join(names, ", "), ErrNoCallback))
+	}
+}
 
-		// There's a potential race between `LoadInt64` and
-		// `tryUnmap` in this function.  Since this is the
-		// last we'll see of this record, checkpoint
-		mods = atomic.LoadInt64(&inuse.updateCount)
-		if mods != coll {
-			checkpointed += m.checkpointRecord(inuse)
+// selectCallbacks returns the registered callbacks that selected returns
+// true for, or every one when selected is nil.
+func (m *Accumulator) selectCallbacks(selected func(*callback) bool) []*callback {
+	// The callbacks run without holding the lock, so that they
+	// can register and unregister callbacks.
+	m.callbackLock.Lock()
+	defer m.callbackLock.Unlock()
+	callbacks := make([]*callback, 0, len(m.callbacks))
+	for cb := range m.callbacks {
+		if selected == nil || selected(cb) {
+			callbacks = append(callbacks, cb)
 		}
-		return true
-	})
+	}
+	return callbacks
+}
 
-	return checkpointed
+func (m *Accumulator) runAsyncCallbacks(ctx context.Context, callbacks []*callback) error {
+	if len(callbacks) == 0 {
+		return nil
+	}
+	backpressure := m.config.Backpressure != nil && m.config.Backpressure()
+	results := make([]error, len(callbacks))
+	workers := m.config.CallbackConcurrency
+	if workers > len(callbacks) {
+		workers = len(callbacks)
+	}
+	if workers < 2 {
+		for i, cb := range callbacks {
+			results[i] = m.runRegisteredCallback(ctx, cb, backpressure)
+		}
+	} else {
+		// Each callback has its own attempt, and observations
+		// are captured as concurrently as synchronous
+		// measurements are.
+		work := make(chan int)
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for w := 0; w < workers; w++ {
+			go func() {
+				defer wg.Done()
+				for i := range work {
+					results[i] = m.runRegisteredCallback(ctx, callbacks[i], backpressure)
+				}
+			}()
+		}
+		for i := range callbacks {
+			work <- i
+		}
+		close(work)
+		wg.Wait()
+	}
+
+	var errs []error
+	for _, err := range results {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &CallbackError{Errors: errs}
+}
+
+// runRegisteredCallback runs cb unless it was unregistered, or ctx is
+// done, and records its duration, see WithCallbackDurations.
+func (m *Accumulator) runRegisteredCallback(ctx context.Context, cb *callback, backpressure bool) error {
+	if !m.registered(cb) {
+		// Unregistered by a previous callback.
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("callback %s skipped: %w", cb.name, err)
+	}
+	start := time.Now()
+	err := m.runCallback(ctx, cb, backpressure)
+	if m.config.CallbackDurations != nil {
+		elapsed := float64(time.Since(start)) / float64(time.Millisecond)
+		m.config.CallbackDurations.Record(ctx, elapsed, attribute.String("callback", cb.name))
+	}
+	if err != nil {
+		return fmt.Errorf("callback %s: %w", cb.name, err)
+	}
+	return nil
 }
 
-func (m *Accumulator) runAsyncCallbacks(ctx context.Context) {
+// registered returns whether cb is registered.
+func (m *Accumulator) registered(cb *callback) bool {
 	m.callbackLock.Lock()
 	defer m.callbackLock.Unlock()
+	_, ok := m.callbacks[cb]
+	return ok
+}
 
-	ctx = context.WithValue(ctx, asyncContextKey{}, m)
+// overflowAttribute identifies the overflow series of an instrument,
+// see WithCardinalityLimit.
+var overflowAttribute = cardinality.OverflowKey.Bool(true)
+
+// cardinalityLimitFor returns the cardinality limit of the asynchronous
+// instrument described by desc, or nil when it has none.
+func (m *Accumulator) cardinalityLimitFor(desc *sdkapi.Descriptor) *cardinalityLimit {
+	limit := m.config.CardinalityLimit
+	if m.config.CardinalityLimits != nil {
+		if l, ok := m.config.CardinalityLimits(desc); ok {
+			limit = l
+		}
+	}
+	if limit <= 0 {
+		return nil
+	}
+	return &cardinalityLimit{limit: int64(limit)}
+}
 
-	for cb := range m.callbacks {
-		cb.f(ctx)
+// reserve counts a new attribute set against the limit, and returns
+// false when the limit is reached.  One of the limit is left for the
+// overflow series.
+func (c *cardinalityLimit) reserve() bool {
+	if atomic.AddInt64(&c.series, 1) < c.limit {
+		return true
+	}
+	atomic.AddInt64(&c.series, -1)
+	return false
+}
+
+// release stops counting an attribute set against the limit.
+func (c *cardinalityLimit) release() {
+	atomic.AddInt64(&c.series, -1)
+}
+
+// report reports the first overflow of the instrument described by
+// desc.
+func (c *cardinalityLimit) report(desc *sdkapi.Descriptor) {
+	if atomic.CompareAndSwapInt32(&c.reported, 0, 1) {
+		otel.Handle(fmt.Errorf("%s: %w", desc.Name(), ErrCardinalityLimit))
+	}
+}
+
+// admitSeries returns whether a new record of b can be created in the
+// current collection cycle, see WithSeriesGrowthLimit.  The first
+// record refused in a cycle is reported.
+func (m *Accumulator) admitSeries(b *baseInstrument) bool {
+	limit := m.config.SeriesGrowthLimit
+	if limit <= 0 {
+		return true
+	}
+	m.growthLock.Lock()
+	n := m.growth[b] + 1
+	if n <= limit+1 {
+		if m.growth == nil {
+			m.growth = map[*baseInstrument]int{}
+		}
+		m.growth[b] = n
+	}
+	m.growthLock.Unlock()
+
+	if n <= limit {
+		return true
+	}
+	if n == limit+1 {
+		otel.Handle(fmt.Errorf("%s: %w: limit is %d", b.descriptor.Name(), ErrSeriesGrowth, limit))
+	}
+	return false
+}
+
+// excluded returns whether the instrument described by desc is excluded
+// from collection.
+func (m *Accumulator) excluded(desc *sdkapi.Descriptor) bool {
+	return m.config.ExcludeInstrument != nil && m.config.ExcludeInstrument(desc)
+}
+
+// normalize applies the configured string normalizers to kvs.  The input
+// slice is copied before it is modified.
+func (m *Accumulator) normalize(kvs []attribute.KeyValue) []attribute.KeyValue {
+	copied := false
+	for i, kv := range kvs {
+		if kv.Value.Type() != attribute.STRING {
+			continue
+		}
+		normalizers, ok := m.config.StringNormalizers[kv.Key]
+		if !ok {
+			continue
+		}
+		value := kv.Value.AsString()
+		for _, f := range normalizers {
+			value = f(value)
+		}
+		if value == kv.Value.AsString() {
+			continue
+		}
+		if !copied {
+			kvs = append([]attribute.KeyValue(nil), kvs...)
+			copied = true
+		}
+		kvs[i] = kv.Key.String(value)
+	}
+	return kvs
+}
+
+// enrichment returns the attributes added to every measurement of the
+// instrument described by desc.  The default attributes come first, so
+// that the enrichment attributes take precedence over them.
+func (m *Accumulator) enrichment(desc *sdkapi.Descriptor) []attribute.KeyValue {
+	if m.config.AttributeEnrichment == nil {
+		return m.config.DefaultAttributes
+	}
+	kvs := m.config.AttributeEnrichment(desc)
+	if len(m.config.DefaultAttributes) == 0 {
+		return kvs
 	}
+	return append(append(make([]attribute.KeyValue, 0, len(m.config.DefaultAttributes)+len(kvs)), m.config.DefaultAttributes...), kvs...)
+}
+
+// baggageKeys returns the keys of the baggage members promoted to
+// attributes of the observations of the instrument described by desc.
+func (m *Accumulator) baggageKeys(desc *sdkapi.Descriptor) []string {
+	if m.config.BaggageAttributes == nil {
+		return nil
+	}
+	return m.config.BaggageAttributes(desc)
+}
+
+// promoteBaggage returns kvs preceded by the members of the baggage of
+// ctx with one of keys, so that the attributes of kvs take precedence.
+// kvs is not modified, since it belongs to the caller.
+func promoteBaggage(ctx context.Context, keys []string, kvs []attribute.KeyValue) []attribute.KeyValue {
+	bag := baggage.FromContext(ctx)
+	if bag.Len() == 0 {
+		return kvs
+	}
+	var promoted []attribute.KeyValue
+	for _, key := range keys {
+		if member := bag.Member(key); member.Key() != "" {
+			promoted = append(promoted, attribute.String(key, member.Value()))
+		}
+	}
+	if len(promoted) == 0 {
+		return kvs
+	}
+	return append(promoted, kvs...)
+}
+
+// attributeFilter returns the filter of the attributes of the
+// measurements of the instrument described by desc, or nil.
+func (m *Accumulator) attributeFilter(desc *sdkapi.Descriptor) attribute.Filter {
+	if m.config.AttributeFilter == nil {
+		return nil
+	}
+	return m.config.AttributeFilter(desc)
+}
+
+// filterAttributes returns the attributes of kvs kept by filter.  kvs is
+// not modified, since it belongs to the caller.
+func filterAttributes(kvs []attribute.KeyValue, filter attribute.Filter) []attribute.KeyValue {
+	for i, kv := range kvs {
+		if filter(kv) {
+			continue
+		}
+		// Copy the kept attributes once the first one is
+		// dropped.
+		kept := append(make([]attribute.KeyValue, 0, len(kvs)-1), kvs[:i]...)
+		for _, kv := range kvs[i+1:] {
+			if filter(kv) {
+				kept = append(kept, kv)
+			}
+		}
+		return kept
+	}
+	return kvs
+}
+
+// callbackName returns the name of the function f.
+func callbackName(f metric.Callback) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return "unknown"
 }
 
 func (m *Accumulator) checkpointRecord(r *record) int {
-	if r.current == nil {
+	if !m.moveRecord(r) {
 		return 0
 	}
+	m.processRecord(r)
+	return 1
+}
+
+// moveRecord moves the current state of r into its checkpoint and
+// reports whether the checkpoint is ready to be processed.
+func (m *Accumulator) moveRecord(r *record) bool {
+	if r.current == nil {
+		return false
+	}
 	err := r.current.SynchronizedMove(r.checkpoint, &r.inst.descriptor)
 	if err != nil {
 		otel.Handle(err)
-		return 0
+		return false
 	}
+	if r.running != nil {
+		// The checkpoint contains the deltas of this
+		// interval: fold them into the running total
+		// and report the total.
+		if err := r.running.Merge(r.checkpoint, &r.inst.descriptor); err != nil {
+			otel.Handle(err)
+			return false
+		}
+		if err := r.checkpoint.SynchronizedMove(nil, &r.inst.descriptor); err != nil {
+			otel.Handle(err)
+			return false
+		}
+		if err := r.checkpoint.Merge(r.running, &r.inst.descriptor); err != nil {
+			otel.Handle(err)
+			return false
+		}
+	}
+	return true
+}
 
-	a := export.NewAccumulation(&r.inst.descriptor, &r.attrs, r.checkpoint)
-	err = m.processor.Process(a)
-	if err != nil {
+// processRecord passes the checkpoint of r to the Processor.
+func (m *Accumulator) processRecord(r *record) {
+	a := export.NewAccumulation(&r.inst.exported, &r.attrs, r.checkpoint)
+	if err := m.processor.Process(a); err != nil {
 		otel.Handle(err)
 	}
-	return 1
 }
 
 func (r *record) captureOne(ctx context.Context, num number.Number) {
@@ -385,12 +1828,37 @@ func (r *record) captureOne(ctx context.Context, num number.Number) {
 		// The instrument is disabled according to the AggregatorSelector.
 		return
 	}
+	if r.inst.convert != nil {
+		num = r.inst.convertNumber(num)
+	}
 	if err := aggregator.RangeTest(num, &r.inst.descriptor); err != nil {
-		otel.Handle(err)
+		r.inst.meter.handleInvalid(ctx, &r.inst.descriptor, err)
 		return
 	}
+	if r.lastValueWins() {
+		r.observeLock.Lock()
+		defer r.observeLock.Unlock()
+		var observer *callback
+		if attempt, ok := ctx.Value(asyncContextKey{}).(*callbackAttempt); ok {
+			observer = attempt.callback
+		}
+		if atomic.LoadInt64(&r.updateCount) != atomic.LoadInt64(&r.collectedCount) {
+			// The record was observed earlier in this
+			// collection: the new observation replaces it.
+			if r.observer != nil && observer != nil && r.observer != observer {
+				otel.Handle(fmt.Errorf("%s: {%s} observed by callbacks %s and %s: %w",
+					r.inst.descriptor.Name(), r.attrs.Encoded(attribute.DefaultEncoder()),
+					r.observer.name, observer.name, ErrObservationConflict))
+			}
+			if err := r.current.SynchronizedMove(nil, &r.inst.descriptor); err != nil {
+				otel.Handle(err)
+				return
+			}
+		}
+		r.observer = observer
+	}
 	if err := r.current.Update(ctx, num, &r.inst.descriptor); err != nil {
-		otel.Handle(err)
+		r.inst.meter.handleInvalid(ctx, &r.inst.descriptor, err)
 		return
 	}
 	// Record was modified, inform the Collect() that things need
@@ -398,14 +1866,121 @@ func (r *record) captureOne(ctx context.Context, num number.Number) {
 	atomic.AddInt64(&r.updateCount, 1)
 }
 
+// lastValueWins returns whether an observation of r replaces the earlier
+// observations of r in the same collection.  This holds for asynchronous
+// instruments, which observe current values, except delta-observed
+// instruments, whose observations are summed, see ObserveDelta, and the
+// overflow series, which combines the attribute sets past the cardinality
+// limit, see WithCardinalityLimit, and filtered instruments, which combine
+// the attribute sets that are equal after filtering, see
+// WithAttributeFilter.
+func (r *record) lastValueWins() bool {
+	overflow := r.inst.cardinality != nil && !r.limited
+	return r.inst.descriptor.InstrumentKind().Asynchronous() && !r.inst.delta && !overflow && r.inst.filter == nil
+}
+
+// handleInvalid reports a measurement rejected with err.  NaN and
+// infinite values are reported with the name of the instrument, or
+// counted instead when an invalid measurements counter is configured,
+// see WithInvalidMeasurements.
+func (m *Accumulator) handleInvalid(ctx context.Context, desc *sdkapi.Descriptor, err error) {
+	var reason string
+	switch {
+	case errors.Is(err, aggregation.ErrNaNInput):
+		reason = "nan"
+	case errors.Is(err, aggregation.ErrInfInput):
+		reason = "inf"
+	default:
+		otel.Handle(err)
+		return
+	}
+	counter := m.config.InvalidMeasurements
+	if counter == nil {
+		otel.Handle(fmt.Errorf("%s: %w", desc.Name(), err))
+		return
+	}
+	counter.Add(ctx, 1,
+		attribute.String("instrument", desc.Name()),
+		attribute.String("reason", reason),
+	)
+}
+
+// countDropped counts a measurement of the instrument described by desc
+// dropped for reason, when a dropped measurements counter is configured,
+// see WithDroppedMeasurements.
+func (m *Accumulator) countDropped(desc *sdkapi.Descriptor, reason string) {
+	counter := m.config.DroppedMeasurements
+	if counter == nil {
+		return
+	}
+	counter.Add(context.Background(), 1,
+		attribute.String("instrument", desc.Name()),
+		attribute.String("reason", reason),
+	)
+}
+
+// Shutdown stops m from recording measurements and releases its records,
+// once m is no longer collected.  The instruments of m remain usable, but
+// their measurements are dropped.
+func (m *Accumulator) Shutdown() {
+	atomic.StoreInt32(&m.shutdown, 1)
+
+	m.shardsLock.Lock()
+	shards := m.shards
+	m.shards = nil
+	m.shardsLock.Unlock()
+	for _, records := range shards {
+		records.Range(func(key, _ interface{}) bool {
+			records.Delete(key)
+			return true
+		})
+	}
+}
+
+// isShutdown returns whether Shutdown was called.
+func (m *Accumulator) isShutdown() bool {
+	return atomic.LoadInt32(&m.shutdown) != 0
+}
+
+// SeriesCounts returns the number of attribute sets held for each
+// instrument, by instrument name, e.g., to find the instruments of high
+// cardinality.  The attribute sets of a record that is no longer updated
+// are counted until the end of the collection that removes it.
+func (m *Accumulator) SeriesCounts() map[string]int {
+	m.shardsLock.Lock()
+	shards := m.shards
+	m.shardsLock.Unlock()
+
+	counts := map[string]int{}
+	for _, records := range shards {
+		records.Range(func(_, value interface{}) bool {
+			counts[value.(*record).inst.exported.Name()]++
+			return true
+		})
+	}
+	return counts
+}
+
 func (r *record) unbind() {
 	r.refMapped.unref()
 }
 
 func (r *record) mapkey() mapkey {
+	return r.inst.meter.mapkey(&r.inst.descriptor, &r.attrs)
+}
+
+// mapkey returns the key of the record of desc and attrs in the current
+// map.
+func (m *Accumulator) mapkey(desc *sdkapi.Descriptor, attrs *attribute.Set) mapkey {
+	if m.config.SeriesKey != nil {
+		return mapkey{
+			descriptor: desc,
+			custom:     m.config.SeriesKey(attrs),
+		}
+	}
 	return mapkey{
-		descriptor: &r.inst.descriptor,
-		ordered:    r.attrs.Equivalent(),
+		descriptor: desc,
+		ordered:    attrs.Equivalent(),
 	}
 }
 
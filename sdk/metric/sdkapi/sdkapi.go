@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/instrument"
 	"go.opentelemetry.io/otel/sdk/metric/number"
 )
@@ -35,8 +36,19 @@ type MeterImpl interface {
 	// one occur.
 	NewAsyncInstrument(descriptor Descriptor) (AsyncImpl, error)
 
-	// Etc.
-	RegisterCallback(insts []instrument.Asynchronous, callback func(context.Context)) error
+	// RegisterCallback registers callback to be called during
+	// collection for insts, and returns a Registration that
+	// unregisters it.
+	RegisterCallback(insts []instrument.Asynchronous, callback metric.Callback) (metric.Registration, error)
+}
+
+// InstrumentDiscarder is optionally implemented by a MeterImpl that can
+// discard an instrument it returned, e.g., when the same instrument could
+// not be created in another MeterImpl.
+type InstrumentDiscarder interface {
+	// DiscardInstrument discards inst, which must not be used
+	// afterwards.  Its measurements are no longer collected.
+	DiscardInstrument(inst InstrumentImpl)
 }
 
 // InstrumentImpl is a common interface for synchronous and
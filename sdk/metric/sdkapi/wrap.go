@@ -72,7 +72,7 @@ func (m meter) SyncInt64() syncint64.InstrumentProvider {
 	return siMeter{m}
 }
 
-func (m meter) RegisterCallback(insts []instrument.Asynchronous, cb func(ctx context.Context)) error {
+func (m meter) RegisterCallback(insts []instrument.Asynchronous, cb metric.Callback) (metric.Registration, error) {
 	return m.MeterImpl.RegisterCallback(insts, cb)
 }
 
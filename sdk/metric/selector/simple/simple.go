@@ -15,26 +15,83 @@
 package simple // import "go.opentelemetry.io/otel/sdk/metric/selector/simple"
 
 import (
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/exponential"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/lastvalue"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/minmaxsumcount"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/sum"
 	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
 )
 
 type (
 	selectorInexpensive struct{}
 	selectorHistogram   struct {
-		options []histogram.Option
+		options     []histogram.Option
+		compensated bool
+
+		// exemplars is the number of exemplars of each sum,
+		// zero if exemplars are disabled.
+		exemplars int
+	}
+	selectorExponential struct {
+		options []exponential.Option
+	}
+	selectorMinMaxSumCount struct{}
+	selectorKinds          struct {
+		kinds    map[sdkapi.InstrumentKind]aggregation.Kind
+		fallback selectorHistogram
+	}
+	selectorHistogramConfigs struct {
+		selectorHistogram
+		configs func(*sdkapi.Descriptor) (HistogramConfig, bool)
+
+		// options caches the histogram options of each
+		// instrument, so that its configuration is validated
+		// once.
+		options *sync.Map
 	}
 )
 
+// HistogramConfig configures the histogram aggregators of an instrument,
+// see NewWithHistogramConfigs.
+type HistogramConfig struct {
+	// Boundaries are the explicit boundaries of the buckets, in
+	// strictly increasing order.  The histograms have one more
+	// bucket than boundaries.
+	Boundaries []float64
+}
+
 var (
 	_ export.AggregatorSelector = selectorInexpensive{}
 	_ export.AggregatorSelector = selectorHistogram{}
+	_ export.AggregatorSelector = selectorExponential{}
+	_ export.AggregatorSelector = selectorMinMaxSumCount{}
+	_ export.AggregatorSelector = selectorKinds{}
+	_ export.AggregatorSelector = selectorHistogramConfigs{}
 )
 
+// ErrInvalidAggregation indicates that an aggregation was chosen for an
+// instrument kind it does not apply to.
+var ErrInvalidAggregation = fmt.Errorf("invalid aggregation for the instrument kind")
+
+// instrumentKinds are the kinds of instrument known to
+// NewWithAggregationKinds.
+var instrumentKinds = []sdkapi.InstrumentKind{
+	sdkapi.HistogramInstrumentKind,
+	sdkapi.GaugeObserverInstrumentKind,
+	sdkapi.CounterInstrumentKind,
+	sdkapi.UpDownCounterInstrumentKind,
+	sdkapi.CounterObserverInstrumentKind,
+	sdkapi.UpDownCounterObserverInstrumentKind,
+}
+
 // NewWithInexpensiveDistribution returns a simple aggregator selector
 // that uses minmaxsumcount aggregators for `Histogram`
 // instruments.  This selector is faster and uses less memory than the
@@ -51,6 +108,118 @@ func NewWithHistogramDistribution(options ...histogram.Option) export.Aggregator
 	return selectorHistogram{options: options}
 }
 
+// NewWithCompensatedSums returns a simple aggregator selector that
+// uses histogram aggregators for `Histogram` instruments, like
+// NewWithHistogramDistribution, and compensated sum aggregators for
+// the other sum instruments.  Compensated sums of floating point
+// values are more accurate and reproducible at the cost of a lock per
+// update, see sum.CompensatedAggregator.
+func NewWithCompensatedSums(options ...histogram.Option) export.AggregatorSelector {
+	return selectorHistogram{options: options, compensated: true}
+}
+
+// NewWithExemplars returns a simple aggregator selector like
+// NewWithHistogramDistribution whose aggregators also sample exemplars:
+// the measurements made in sampled spans, see aggregation.Exemplars.
+// Sums keep at most size exemplars and histograms keep one per bucket.
+func NewWithExemplars(size int, options ...histogram.Option) export.AggregatorSelector {
+	if size < 1 {
+		size = 1
+	}
+	return selectorHistogram{
+		options:   append(options[:len(options):len(options)], histogram.WithExemplars()),
+		exemplars: size,
+	}
+}
+
+// NewWithHistogramConfigs returns a simple aggregator selector like
+// NewWithHistogramDistribution whose histogram aggregators use the
+// HistogramConfig returned by f for each instrument, e.g., to set the
+// boundaries of the latency histograms by instrument name.  The
+// instruments for which f returns false use the options.  The result of
+// f is cached by instrument.
+//
+// Boundaries that are not finite and strictly increasing, e.g., with
+// duplicates, are handled as an error wrapping histogram.ErrInvalidBounds,
+// see histogram.ValidateBounds, and the instrument uses the options
+// instead.
+func NewWithHistogramConfigs(f func(*sdkapi.Descriptor) (HistogramConfig, bool), options ...histogram.Option) export.AggregatorSelector {
+	return selectorHistogramConfigs{
+		selectorHistogram: selectorHistogram{options: options},
+		configs:           f,
+		options:           &sync.Map{},
+	}
+}
+
+// NewWithExponentialHistogramDistribution returns a simple aggregator
+// selector that uses base-2 exponential histogram aggregators for
+// `Histogram` instruments.  Unlike NewWithHistogramDistribution, the
+// bucket boundaries need not be known in advance: the scale of the
+// buckets adjusts to the range of the recorded values, see
+// exponential.Aggregator.
+func NewWithExponentialHistogramDistribution(options ...exponential.Option) export.AggregatorSelector {
+	return selectorExponential{options: options}
+}
+
+// NewWithMinMaxSumCountDistribution returns a simple aggregator selector
+// that uses minmaxsumcount aggregators for `Histogram` instruments.  They
+// summarize the distribution by its minimum, maximum, sum and count,
+// e.g., for the backends that cannot ingest histograms.
+func NewWithMinMaxSumCountDistribution() export.AggregatorSelector {
+	return selectorMinMaxSumCount{}
+}
+
+// NewWithAggregationKinds returns an aggregator selector that uses the
+// aggregation returned by f for the kind of each instrument, e.g., to
+// use histograms for every synchronous instrument.  f is called once per
+// instrument kind by NewWithAggregationKinds.  The options configure the
+// histogram aggregators.
+//
+// Sums apply to adding instruments and Histograms, histograms,
+// exponential histograms and minmaxsumcounts to synchronous instruments,
+// and last values to synchronous instruments and GaugeObservers, e.g., to
+// report the latest value recorded by an UpDownCounter as a gauge.
+// Exponential histograms use the default exponential.Option values.
+// aggregation.DropKind applies to every instrument, whose measurements
+// are then discarded.  The instrument kinds for which f returns an
+// aggregation that does not apply, or no aggregation, use the
+// aggregation of NewWithHistogramDistribution.  An invalid aggregation
+// is also handled as an error wrapping ErrInvalidAggregation.
+func NewWithAggregationKinds(f func(sdkapi.InstrumentKind) aggregation.Kind, options ...histogram.Option) export.AggregatorSelector {
+	s := selectorKinds{
+		kinds:    map[sdkapi.InstrumentKind]aggregation.Kind{},
+		fallback: selectorHistogram{options: options},
+	}
+	for _, ikind := range instrumentKinds {
+		akind := f(ikind)
+		if akind == "" {
+			continue
+		}
+		if !validAggregation(ikind, akind) {
+			otel.Handle(fmt.Errorf("%w: %s aggregation of %s instruments", ErrInvalidAggregation, akind, ikind))
+			continue
+		}
+		s.kinds[ikind] = akind
+	}
+	return s
+}
+
+// validAggregation returns whether akind applies to instruments of kind
+// ikind.
+func validAggregation(ikind sdkapi.InstrumentKind, akind aggregation.Kind) bool {
+	switch akind {
+	case aggregation.SumKind:
+		return ikind.Adding() || ikind == sdkapi.HistogramInstrumentKind
+	case aggregation.HistogramKind, aggregation.ExponentialHistogramKind, aggregation.MinMaxSumCountKind:
+		return ikind.Synchronous()
+	case aggregation.LastValueKind:
+		return ikind.Synchronous() || ikind.Grouping()
+	case aggregation.DropKind:
+		return true
+	}
+	return false
+}
+
 func sumAggs(aggPtrs []*aggregator.Aggregator) {
 	aggs := sum.New(len(aggPtrs))
 	for i := range aggPtrs {
@@ -65,6 +234,20 @@ func lastValueAggs(aggPtrs []*aggregator.Aggregator) {
 	}
 }
 
+func exponentialAggs(descriptor *sdkapi.Descriptor, options []exponential.Option, aggPtrs []*aggregator.Aggregator) {
+	aggs := exponential.New(len(aggPtrs), descriptor, options...)
+	for i := range aggPtrs {
+		*aggPtrs[i] = &aggs[i]
+	}
+}
+
+func minMaxSumCountAggs(descriptor *sdkapi.Descriptor, aggPtrs []*aggregator.Aggregator) {
+	aggs := minmaxsumcount.New(len(aggPtrs), descriptor)
+	for i := range aggPtrs {
+		*aggPtrs[i] = &aggs[i]
+	}
+}
+
 func (selectorInexpensive) AggregatorFor(descriptor *sdkapi.Descriptor, aggPtrs ...*aggregator.Aggregator) {
 	switch descriptor.InstrumentKind() {
 	case sdkapi.GaugeObserverInstrumentKind:
@@ -88,7 +271,97 @@ func (s selectorHistogram) AggregatorFor(descriptor *sdkapi.Descriptor, aggPtrs
 		for i := range aggPtrs {
 			*aggPtrs[i] = &aggs[i]
 		}
+	default:
+		if s.compensated {
+			aggs := sum.NewCompensated(len(aggPtrs), descriptor)
+			for i := range aggPtrs {
+				*aggPtrs[i] = &aggs[i]
+			}
+			return
+		}
+		if s.exemplars != 0 {
+			aggs := sum.NewWithExemplars(len(aggPtrs), s.exemplars)
+			for i := range aggPtrs {
+				*aggPtrs[i] = &aggs[i]
+			}
+			return
+		}
+		sumAggs(aggPtrs)
+	}
+}
+
+func (s selectorExponential) AggregatorFor(descriptor *sdkapi.Descriptor, aggPtrs ...*aggregator.Aggregator) {
+	switch descriptor.InstrumentKind() {
+	case sdkapi.GaugeObserverInstrumentKind:
+		lastValueAggs(aggPtrs)
+	case sdkapi.HistogramInstrumentKind:
+		exponentialAggs(descriptor, s.options, aggPtrs)
 	default:
 		sumAggs(aggPtrs)
 	}
 }
+
+func (selectorMinMaxSumCount) AggregatorFor(descriptor *sdkapi.Descriptor, aggPtrs ...*aggregator.Aggregator) {
+	switch descriptor.InstrumentKind() {
+	case sdkapi.GaugeObserverInstrumentKind:
+		lastValueAggs(aggPtrs)
+	case sdkapi.HistogramInstrumentKind:
+		minMaxSumCountAggs(descriptor, aggPtrs)
+	default:
+		sumAggs(aggPtrs)
+	}
+}
+
+func (s selectorKinds) AggregatorFor(descriptor *sdkapi.Descriptor, aggPtrs ...*aggregator.Aggregator) {
+	switch s.kinds[descriptor.InstrumentKind()] {
+	case aggregation.SumKind:
+		sumAggs(aggPtrs)
+	case aggregation.HistogramKind:
+		aggs := histogram.New(len(aggPtrs), descriptor, s.fallback.options...)
+		for i := range aggPtrs {
+			*aggPtrs[i] = &aggs[i]
+		}
+	case aggregation.ExponentialHistogramKind:
+		exponentialAggs(descriptor, nil, aggPtrs)
+	case aggregation.MinMaxSumCountKind:
+		minMaxSumCountAggs(descriptor, aggPtrs)
+	case aggregation.LastValueKind:
+		lastValueAggs(aggPtrs)
+	case aggregation.DropKind:
+		for i := range aggPtrs {
+			*aggPtrs[i] = nil
+		}
+	default:
+		s.fallback.AggregatorFor(descriptor, aggPtrs...)
+	}
+}
+
+func (s selectorHistogramConfigs) AggregatorFor(descriptor *sdkapi.Descriptor, aggPtrs ...*aggregator.Aggregator) {
+	if descriptor.InstrumentKind() != sdkapi.HistogramInstrumentKind {
+		s.selectorHistogram.AggregatorFor(descriptor, aggPtrs...)
+		return
+	}
+	aggs := histogram.New(len(aggPtrs), descriptor, s.histogramOptions(descriptor)...)
+	for i := range aggPtrs {
+		*aggPtrs[i] = &aggs[i]
+	}
+}
+
+// histogramOptions returns the histogram options of the instrument
+// described by descriptor.
+func (s selectorHistogramConfigs) histogramOptions(descriptor *sdkapi.Descriptor) []histogram.Option {
+	if options, ok := s.options.Load(*descriptor); ok {
+		return options.([]histogram.Option)
+	}
+	options := s.selectorHistogram.options
+	if cfg, ok := s.configs(descriptor); ok {
+		if err := histogram.ValidateBounds(cfg.Boundaries); err != nil {
+			otel.Handle(fmt.Errorf("%s: %w", descriptor.Name(), err))
+		} else {
+			boundaries := append([]float64(nil), cfg.Boundaries...)
+			options = append(options[:len(options):len(options)], histogram.WithExplicitBoundaries(boundaries))
+		}
+	}
+	actual, _ := s.options.LoadOrStore(*descriptor, options)
+	return actual.([]histogram.Option)
+}
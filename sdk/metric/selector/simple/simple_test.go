@@ -15,19 +15,26 @@
 package simple_test
 
 import (
+	"context"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/exponential"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/lastvalue"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/minmaxsumcount"
 	"go.opentelemetry.io/otel/sdk/metric/aggregator/sum"
 	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
 	"go.opentelemetry.io/otel/sdk/metric/metrictest"
 	"go.opentelemetry.io/otel/sdk/metric/number"
 	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
 	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -64,3 +71,185 @@ func TestHistogramDistribution(t *testing.T) {
 	require.IsType(t, (*histogram.Aggregator)(nil), oneAgg(hist, &testHistogramDesc))
 	testFixedSelectors(t, hist)
 }
+
+func TestExponentialHistogramDistribution(t *testing.T) {
+	exp := simple.NewWithExponentialHistogramDistribution(exponential.WithMaxSize(20))
+	require.IsType(t, (*exponential.Aggregator)(nil), oneAgg(exp, &testHistogramDesc))
+	testFixedSelectors(t, exp)
+}
+
+func TestMinMaxSumCountDistribution(t *testing.T) {
+	mmsc := simple.NewWithMinMaxSumCountDistribution()
+	require.IsType(t, (*minmaxsumcount.Aggregator)(nil), oneAgg(mmsc, &testHistogramDesc))
+	testFixedSelectors(t, mmsc)
+}
+
+func TestExemplars(t *testing.T) {
+	sel := simple.NewWithExemplars(4)
+	require.IsType(t, (*histogram.Aggregator)(nil), oneAgg(sel, &testHistogramDesc))
+	testFixedSelectors(t, sel)
+
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	for _, desc := range []*sdkapi.Descriptor{&testHistogramDesc, &testCounterDesc} {
+		agg := oneAgg(sel, desc)
+		require.NoError(t, agg.Update(ctx, number.NewInt64Number(1), desc))
+		exemplars, err := agg.Aggregation().(aggregation.Exemplars).Exemplars()
+		require.NoError(t, err)
+		require.Len(t, exemplars, 1, desc.Name())
+	}
+}
+
+func TestCompensatedSums(t *testing.T) {
+	comp := simple.NewWithCompensatedSums()
+	require.IsType(t, (*lastvalue.Aggregator)(nil), oneAgg(comp, &testGaugeObserverDesc))
+	require.IsType(t, (*histogram.Aggregator)(nil), oneAgg(comp, &testHistogramDesc))
+	require.IsType(t, (*sum.CompensatedAggregator)(nil), oneAgg(comp, &testCounterDesc))
+	require.IsType(t, (*sum.CompensatedAggregator)(nil), oneAgg(comp, &testUpDownCounterDesc))
+	require.IsType(t, (*sum.CompensatedAggregator)(nil), oneAgg(comp, &testCounterObserverDesc))
+	require.IsType(t, (*sum.CompensatedAggregator)(nil), oneAgg(comp, &testUpDownCounterObserverDesc))
+}
+
+func TestAggregationKinds(t *testing.T) {
+	var handled []error
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		handled = append(handled, err)
+	}))
+	defer otel.SetErrorHandler(otel.ErrorHandlerFunc(func(error) {}))
+
+	sel := simple.NewWithAggregationKinds(func(ikind sdkapi.InstrumentKind) aggregation.Kind {
+		switch ikind {
+		case sdkapi.CounterInstrumentKind, sdkapi.CounterObserverInstrumentKind:
+			// Not valid for the CounterObserver.
+			return aggregation.HistogramKind
+		case sdkapi.HistogramInstrumentKind:
+			return aggregation.LastValueKind
+		case sdkapi.UpDownCounterInstrumentKind:
+			return aggregation.ExponentialHistogramKind
+		}
+		return ""
+	})
+
+	require.IsType(t, (*histogram.Aggregator)(nil), oneAgg(sel, &testCounterDesc))
+	require.IsType(t, (*sum.Aggregator)(nil), oneAgg(sel, &testCounterObserverDesc))
+	require.IsType(t, (*lastvalue.Aggregator)(nil), oneAgg(sel, &testHistogramDesc))
+	require.IsType(t, (*exponential.Aggregator)(nil), oneAgg(sel, &testUpDownCounterDesc))
+	testFixedSelectors(t, simple.NewWithAggregationKinds(func(sdkapi.InstrumentKind) aggregation.Kind {
+		return ""
+	}))
+
+	drop := simple.NewWithAggregationKinds(func(sdkapi.InstrumentKind) aggregation.Kind {
+		return aggregation.DropKind
+	})
+	require.Nil(t, oneAgg(drop, &testCounterDesc))
+	require.Nil(t, oneAgg(drop, &testGaugeObserverDesc))
+
+	require.Len(t, handled, 1)
+	require.ErrorIs(t, handled[0], simple.ErrInvalidAggregation)
+	require.Contains(t, handled[0].Error(), "CounterObserverInstrumentKind")
+
+	// Minmaxsumcounts apply to synchronous instruments only.
+	handled = nil
+	mmsc := simple.NewWithAggregationKinds(func(sdkapi.InstrumentKind) aggregation.Kind {
+		return aggregation.MinMaxSumCountKind
+	})
+	require.IsType(t, (*minmaxsumcount.Aggregator)(nil), oneAgg(mmsc, &testCounterDesc))
+	require.IsType(t, (*minmaxsumcount.Aggregator)(nil), oneAgg(mmsc, &testHistogramDesc))
+	require.IsType(t, (*lastvalue.Aggregator)(nil), oneAgg(mmsc, &testGaugeObserverDesc))
+	require.Len(t, handled, 3)
+}
+
+func TestSynchronousLastValues(t *testing.T) {
+	var handled []error
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		handled = append(handled, err)
+	}))
+	defer otel.SetErrorHandler(otel.ErrorHandlerFunc(func(error) {}))
+
+	sel := simple.NewWithAggregationKinds(func(sdkapi.InstrumentKind) aggregation.Kind {
+		return aggregation.LastValueKind
+	})
+	require.IsType(t, (*lastvalue.Aggregator)(nil), oneAgg(sel, &testCounterDesc))
+	require.IsType(t, (*lastvalue.Aggregator)(nil), oneAgg(sel, &testUpDownCounterDesc))
+	require.IsType(t, (*lastvalue.Aggregator)(nil), oneAgg(sel, &testHistogramDesc))
+	require.IsType(t, (*lastvalue.Aggregator)(nil), oneAgg(sel, &testGaugeObserverDesc))
+
+	// Last values do not apply to the adding asynchronous instruments.
+	require.IsType(t, (*sum.Aggregator)(nil), oneAgg(sel, &testCounterObserverDesc))
+	require.IsType(t, (*sum.Aggregator)(nil), oneAgg(sel, &testUpDownCounterObserverDesc))
+	require.Len(t, handled, 2)
+}
+
+func TestHistogramConfigs(t *testing.T) {
+	var handled []error
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		handled = append(handled, err)
+	}))
+	defer otel.SetErrorHandler(otel.ErrorHandlerFunc(func(error) {}))
+
+	latency := metrictest.NewDescriptor("latency", sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	unsorted := metrictest.NewDescriptor("unsorted", sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	duplicates := metrictest.NewDescriptor("duplicates", sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	infinite := metrictest.NewDescriptor("infinite", sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	nan := metrictest.NewDescriptor("nan", sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	other := metrictest.NewDescriptor("other", sdkapi.HistogramInstrumentKind, number.Float64Kind)
+	sel := simple.NewWithHistogramConfigs(func(desc *sdkapi.Descriptor) (simple.HistogramConfig, bool) {
+		switch desc.Name() {
+		case "latency":
+			return simple.HistogramConfig{Boundaries: []float64{1, 5, 10}}, true
+		case "unsorted":
+			return simple.HistogramConfig{Boundaries: []float64{5, 1}}, true
+		case "duplicates":
+			return simple.HistogramConfig{Boundaries: []float64{1, 1, 2}}, true
+		case "infinite":
+			return simple.HistogramConfig{Boundaries: []float64{1, math.Inf(1)}}, true
+		case "nan":
+			return simple.HistogramConfig{Boundaries: []float64{math.NaN(), 1}}, true
+		}
+		return simple.HistogramConfig{}, false
+	}, histogram.WithExplicitBoundaries([]float64{100}))
+	testFixedSelectors(t, sel)
+
+	buckets := func(desc *sdkapi.Descriptor, values ...float64) aggregation.Buckets {
+		agg := oneAgg(sel, desc)
+		for _, v := range values {
+			require.NoError(t, agg.Update(context.Background(), number.NewFloat64Number(v), desc))
+		}
+		var ckpt aggregator.Aggregator
+		sel.AggregatorFor(desc, &ckpt)
+		require.NoError(t, agg.SynchronizedMove(ckpt, desc))
+		b, err := ckpt.(aggregation.Histogram).Histogram()
+		require.NoError(t, err)
+		return b
+	}
+
+	// Each bucket holds the values from its lower boundary, up to
+	// its upper boundary excluded.
+	require.Equal(t, aggregation.Buckets{
+		Boundaries: []float64{1, 5, 10},
+		Counts:     []uint64{1, 2, 0, 3},
+	}, buckets(&latency, 0.5, 1, 3, 10, 11, 1000))
+	require.Empty(t, handled)
+
+	// Invalid boundaries use the options, and are reported once.
+	defaults := aggregation.Buckets{
+		Boundaries: []float64{100},
+		Counts:     []uint64{1, 1},
+	}
+	require.Equal(t, defaults, buckets(&unsorted, 3, 300))
+	require.Equal(t, defaults, buckets(&duplicates, 3, 300))
+	require.Equal(t, defaults, buckets(&infinite, 3, 300))
+	require.Equal(t, defaults, buckets(&nan, 3, 300))
+	require.Len(t, handled, 4)
+	for i, name := range []string{"unsorted", "duplicates", "infinite", "nan"} {
+		require.ErrorIs(t, handled[i], histogram.ErrInvalidBounds)
+		require.Contains(t, handled[i].Error(), name)
+	}
+
+	// Instruments without a configuration use the options too.
+	require.Equal(t, defaults, buckets(&other, 3, 300))
+	require.Len(t, handled, 4)
+}
@@ -0,0 +1,127 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric // import "go.opentelemetry.io/otel/sdk/metric"
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/sdk/metric/number"
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+// UnitMismatch is the action taken when the unit of an instrument
+// contradicts the unit suffix of its name.
+type UnitMismatch int
+
+const (
+	// UnitMismatchIgnore creates the instrument without checking
+	// its unit.
+	UnitMismatchIgnore UnitMismatch = iota
+	// UnitMismatchWarn creates the instrument and reports an error
+	// wrapping ErrUnitMismatch to the global error handler.
+	UnitMismatchWarn
+	// UnitMismatchError fails the creation of the instrument with an
+	// error wrapping ErrUnitMismatch.
+	UnitMismatchError
+)
+
+// unitSuffixes maps the conventional unit suffixes of instrument names to
+// the units they imply.  Names are matched on a final "_" or "."
+// separated component.
+var unitSuffixes = map[string]unit.Unit{
+	"seconds":      unit.Seconds,
+	"milliseconds": unit.Milliseconds,
+	"nanoseconds":  unit.Nanoseconds,
+	"bytes":        unit.Bytes,
+	"ratio":        unit.Dimensionless,
+	"utilization":  unit.Dimensionless,
+}
+
+// checkUnit applies the configured UnitMismatch action to descriptor.
+func (m *Accumulator) checkUnit(descriptor sdkapi.Descriptor) error {
+	if m.config.UnitMismatch == UnitMismatchIgnore {
+		return nil
+	}
+	err := unitMismatch(descriptor)
+	if err == nil {
+		return nil
+	}
+	if m.config.UnitMismatch == UnitMismatchError {
+		return err
+	}
+	otel.Handle(err)
+	return nil
+}
+
+// unitMismatch returns an error wrapping ErrUnitMismatch if the unit of
+// descriptor is set and contradicts the unit suffix of its name.
+func unitMismatch(descriptor sdkapi.Descriptor) error {
+	u := descriptor.Unit()
+	if u == "" {
+		return nil
+	}
+	name := descriptor.Name()
+	suffix := name[strings.LastIndexAny(name, "_.")+1:]
+	expect, ok := unitSuffixes[strings.ToLower(suffix)]
+	if !ok || u == expect {
+		return nil
+	}
+	return fmt.Errorf("%s: unit %q, expected %q: %w", name, u, expect, ErrUnitMismatch)
+}
+
+// unitConversion identifies a conversion between units.
+type unitConversion struct {
+	from, to unit.Unit
+}
+
+// convertUnit returns the descriptor of the converted measurements of an
+// instrument, and the function converting them, as configured by
+// WithUnitConversion.  The function is nil when the measurements are not
+// converted.
+func (m *Accumulator) convertUnit(descriptor sdkapi.Descriptor) (sdkapi.Descriptor, func(float64) float64, error) {
+	if m.config.UnitConversion == nil {
+		return descriptor, nil, nil
+	}
+	to, ok := m.config.UnitConversion(&descriptor)
+	if !ok || to == descriptor.Unit() {
+		return descriptor, nil, nil
+	}
+	convert, ok := m.config.UnitConverters[unitConversion{from: descriptor.Unit(), to: to}]
+	if !ok {
+		return descriptor, nil, fmt.Errorf("%s: from %q to %q: %w", descriptor.Name(), descriptor.Unit(), to, ErrNoUnitConverter)
+	}
+	return sdkapi.NewDescriptor(
+		descriptor.Name(),
+		descriptor.InstrumentKind(),
+		descriptor.NumberKind(),
+		descriptor.Description(),
+		to,
+	), convert, nil
+}
+
+// convertNumber converts a measurement of b.  Converted integer values
+// are rounded to the nearest integer.
+func (b *baseInstrument) convertNumber(num number.Number) number.Number {
+	kind := b.descriptor.NumberKind()
+	v := b.convert(num.CoerceToFloat64(kind))
+	if kind == number.Int64Kind {
+		return number.NewInt64Number(int64(math.Round(v)))
+	}
+	return number.NewFloat64Number(v)
+}
@@ -0,0 +1,65 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric // import "go.opentelemetry.io/otel/sdk/metric"
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/sdk/metric/sdkapi"
+)
+
+// maxNameLength is the maximum length of an instrument name.
+const maxNameLength = 63
+
+// validate checks the name, unit and description of a new instrument.
+func validate(descriptor sdkapi.Descriptor) error {
+	if err := validateName(descriptor.Name()); err != nil {
+		return err
+	}
+	if !utf8.ValidString(string(descriptor.Unit())) {
+		return fmt.Errorf("%s: %q: %w", descriptor.Name(), descriptor.Unit(), ErrInvalidUnit)
+	}
+	if !utf8.ValidString(descriptor.Description()) {
+		return fmt.Errorf("%s: %q: %w", descriptor.Name(), descriptor.Description(), ErrInvalidDescription)
+	}
+	return nil
+}
+
+// validateName checks that name follows the OpenTelemetry naming rules:
+// it starts with an ASCII letter, continues with ASCII letters, digits,
+// underscores, dots and dashes, and is at most 63 characters long.
+func validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty name", ErrInvalidInstrumentName)
+	}
+	if len(name) > maxNameLength {
+		return fmt.Errorf("%q: %w: longer than %d characters", name, ErrInvalidInstrumentName, maxNameLength)
+	}
+	if !isLetter(name[0]) {
+		return fmt.Errorf("%q: %w: must start with a letter", name, ErrInvalidInstrumentName)
+	}
+	for i := 1; i < len(name); i++ {
+		c := name[i]
+		if !isLetter(c) && !('0' <= c && c <= '9') && c != '_' && c != '.' && c != '-' {
+			return fmt.Errorf("%q: %w: invalid character %q", name, ErrInvalidInstrumentName, rune(c))
+		}
+	}
+	return nil
+}
+
+func isLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}